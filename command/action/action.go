@@ -0,0 +1,65 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package action contains vidar's code actions: named sets of edits,
+// like quick fixes and refactorings, that plugins offer for a range
+// of an editor's text.  Plugins offer actions by binding a Provider
+// to the show-code-actions command, which lists the actions that are
+// available at the caret.
+package action
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/piece"
+)
+
+// Action is a named set of edits.
+type Action struct {
+	// Name describes the action to the user, e.g. "Add json tags".
+	Name string
+
+	// Edits are the edits that the action makes.  Like the edits
+	// passed to input.Handler.Apply, their offsets are all in the
+	// text before any of them are applied, and they must not
+	// overlap.
+	Edits []input.Edit
+}
+
+// Provider is a hook that offers code actions.
+type Provider interface {
+	// Actions returns the actions that apply to the text from start
+	// to end in e.  When nothing is selected, start and end are both
+	// the caret.
+	Actions(e input.Editor, start, end int) []Action
+}
+
+// Check returns an error if edits cannot be applied to text as one
+// action: if there are none, if any of them overlap, or if any of
+// them replace text that is not in text.
+func Check(text *piece.Table, edits []input.Edit) error {
+	if len(edits) == 0 {
+		return fmt.Errorf("action: no edits to apply")
+	}
+	sorted := append([]input.Edit(nil), edits...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].At < sorted[j].At
+	})
+	end := 0
+	for _, e := range sorted {
+		if e.At < end {
+			return fmt.Errorf("action: edit at %d overlaps the edit before it", e.At)
+		}
+		end = e.At + len(e.Old)
+		if e.At < 0 || end > text.Len() {
+			return fmt.Errorf("action: edit at %d is past the end of the text", e.At)
+		}
+		if string(text.Slice(e.At, end)) != string(e.Old) {
+			return fmt.Errorf("action: edit at %d does not match the text", e.At)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package action_test
+
+import (
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/command/action"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/piece"
+)
+
+func TestCheck(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, *piece.Table) {
+		return expect.New(t), piece.New([]rune("type foo struct {\n\tA int\n\tB string\n}"))
+	})
+
+	o.Spec("it accepts separate edits in any order", func(expect expect.Expectation, text *piece.Table) {
+		err := action.Check(text, []input.Edit{
+			{At: 34, New: []rune(" `json:\"b\"`")},
+			{At: 24, New: []rune(" `json:\"a\"`")},
+			{At: 5, Old: []rune("foo"), New: []rune("Foo")},
+		})
+		expect(err).To(Not(HaveOccurred()))
+	})
+
+	o.Spec("it refuses overlapping edits", func(expect expect.Expectation, text *piece.Table) {
+		err := action.Check(text, []input.Edit{
+			{At: 5, Old: []rune("foo")},
+			{At: 6, Old: []rune("o")},
+		})
+		expect(err).To(HaveOccurred())
+
+		err = action.Check(text, nil)
+		expect(err).To(HaveOccurred())
+	})
+
+	o.Spec("it refuses edits that do not match the text", func(expect expect.Expectation, text *piece.Table) {
+		err := action.Check(text, []input.Edit{{At: 5, Old: []rune("bar")}})
+		expect(err).To(HaveOccurred())
+
+		err = action.Check(text, []input.Edit{{At: 40, Old: []rune("}\n")}})
+		expect(err).To(HaveOccurred())
+	})
+}
@@ -0,0 +1,208 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package action
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/gxui/math"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/plugin/status"
+)
+
+// Editor is the type of editor that code actions are listed for.
+type Editor interface {
+	input.Editor
+	Controller() *gxui.TextBoxController
+}
+
+// Applier is a type that can apply edits, like the input handler.
+type Applier interface {
+	Apply(input.Editor, ...input.Edit)
+}
+
+type elementer interface {
+	Elements() []interface{}
+}
+
+// List is a command that lists the code actions available at the
+// caret and applies the one that the user chooses, either by its
+// number or by part of its name.
+type List struct {
+	status.General
+
+	providers []Provider
+
+	display gxui.Label
+	choice  gxui.TextBox
+	input   gxui.Focusable
+	actions []Action
+
+	editor  Editor
+	applier Applier
+}
+
+// NewList returns a List with no providers bound to it.
+func NewList(theme gxui.Theme) *List {
+	l := &List{
+		display: theme.CreateLabel(),
+		choice:  theme.CreateTextBox(),
+	}
+	l.Theme = theme
+	l.display.SetMultiline(true)
+	l.choice.SetDesiredWidth(math.MaxSize.W)
+	return l
+}
+
+func (l *List) Name() string {
+	return "show-code-actions"
+}
+
+func (l *List) Menu() string {
+	return "Edit"
+}
+
+func (l *List) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl,
+		Key:      gxui.KeyPeriod,
+	}}
+}
+
+func (l *List) Bind(h bind.Bindable) (bind.HookedMultiOp, error) {
+	p, ok := h.(Provider)
+	if !ok {
+		return nil, fmt.Errorf("expected hook to be an action.Provider, but got %T", h)
+	}
+	newL := NewList(l.Theme)
+	newL.providers = append(append(newL.providers, l.providers...), p)
+	return newL, nil
+}
+
+// Start finds the actions at the caret of the editor that on
+// contains, and displays them so that the user can choose one.
+func (l *List) Start(on gxui.Control) gxui.Control {
+	l.actions = nil
+	l.choice.SetText("")
+	e := findEditor(on)
+	if e == nil {
+		return nil
+	}
+	sels := e.Controller().SelectionSlice()
+	if len(sels) == 0 {
+		return nil
+	}
+	sel := sels[len(sels)-1]
+	for _, p := range l.providers {
+		l.actions = append(l.actions, p.Actions(e, sel.Start(), sel.End())...)
+	}
+	if len(l.actions) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(l.actions))
+	for i, a := range l.actions {
+		names = append(names, fmt.Sprintf("%d: %s", i+1, a.Name))
+	}
+	l.display.SetText(strings.Join(names, "\n"))
+	l.input = l.choice
+	return l.display
+}
+
+func (l *List) Next() gxui.Focusable {
+	input := l.input
+	l.input = nil
+	return input
+}
+
+func (l *List) Reset() {
+	l.editor = nil
+	l.applier = nil
+}
+
+func (l *List) Store(elem interface{}) bind.Status {
+	switch src := elem.(type) {
+	case Editor:
+		l.editor = src
+	case Applier:
+		l.applier = src
+	}
+	if l.editor != nil && l.applier != nil {
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+func (l *List) Exec() error {
+	if len(l.actions) == 0 {
+		l.Warn = "No code actions are available at the caret"
+		return nil
+	}
+	a, err := choose(l.actions, l.choice.Text())
+	if err != nil {
+		l.Err = err.Error()
+		return err
+	}
+	if len(a.Edits) == 0 {
+		return nil
+	}
+	if err := Check(input.Snapshot(l.editor), a.Edits); err != nil {
+		l.Err = fmt.Sprintf("Could not apply %s: %s", a.Name, err)
+		return err
+	}
+	l.applier.Apply(l.editor, a.Edits...)
+	return nil
+}
+
+// choose returns the action in actions that the user chose with
+// choice: its number, or part of its name.  If there is only one
+// action, an empty choice chooses it.
+func choose(actions []Action, choice string) (Action, error) {
+	choice = strings.TrimSpace(choice)
+	if choice == "" {
+		if len(actions) == 1 {
+			return actions[0], nil
+		}
+		return Action{}, errors.New("No code action was chosen")
+	}
+	if n, err := strconv.Atoi(choice); err == nil {
+		if n < 1 || n > len(actions) {
+			return Action{}, fmt.Errorf("There is no code action %d", n)
+		}
+		return actions[n-1], nil
+	}
+	var found []Action
+	for _, a := range actions {
+		if strings.Contains(strings.ToLower(a.Name), strings.ToLower(choice)) {
+			found = append(found, a)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return Action{}, fmt.Errorf("No code action matches %q", choice)
+	case 1:
+		return found[0], nil
+	default:
+		return Action{}, fmt.Errorf("%d code actions match %q", len(found), choice)
+	}
+}
+
+func findEditor(elem interface{}) Editor {
+	switch src := elem.(type) {
+	case Editor:
+		return src
+	case elementer:
+		for _, child := range src.Elements() {
+			if e := findEditor(child); e != nil {
+				return e
+			}
+		}
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ package caret
 import (
 	"fmt"
 
+	"github.com/nelsam/gxui"
 	"github.com/nelsam/vidar/commander/bind"
 	"github.com/nelsam/vidar/commander/input"
 )
@@ -36,6 +37,11 @@ type CaretHandler interface {
 	SetCarets(...int)
 }
 
+// SelectionHandler is an editor that can select text.
+type SelectionHandler interface {
+	SelectSlice([]gxui.TextSelection)
+}
+
 // MovingHook is a hook that needs to trigger when the caret is moving.
 // If d and/or carets need to be modified by the hook before carets
 // actually move, the MovingHook can return a different direction
@@ -78,11 +84,12 @@ const (
 )
 
 type Mover struct {
-	direction Direction
-	mod       Mod
-	carets    []int
-	moving    []MovingHook
-	moved     []MovedHook
+	direction  Direction
+	mod        Mod
+	carets     []int
+	selections []gxui.TextSelection
+	moving     []MovingHook
+	moved      []MovedHook
 
 	editor input.Editor
 	ctrl   Controller
@@ -113,6 +120,24 @@ func (m *Mover) To(carets ...int) bind.Bindable {
 	}
 }
 
+// Select returns a bindable that replaces the editor's selections
+// with sels.  MovingHooks are passed the carets of sels; if a hook
+// changes them, the carets it returns are used instead of sels.
+func (m *Mover) Select(sels ...gxui.TextSelection) bind.Bindable {
+	carets := make([]int, 0, len(sels))
+	for _, s := range sels {
+		carets = append(carets, s.Caret())
+	}
+	return &Mover{
+		carets:     carets,
+		selections: sels,
+		moving:     m.moving,
+		moved:      m.moved,
+		editor:     m.editor,
+		ctrl:       m.ctrl,
+	}
+}
+
 func (m *Mover) Bind(b bind.Bindable) (bind.HookedMultiOp, error) {
 	newM := &Mover{
 		direction: m.direction,
@@ -157,6 +182,9 @@ func (m *Mover) Store(elem interface{}) bind.Status {
 func (m *Mover) trigger(h MovingHook, d Direction, mod Mod, carets []int) bind.Bindable {
 	newDir, newMod, newCarets := h.Moving(m.editor, d, mod, carets)
 	if newDir == NoDirection {
+		if m.selections != nil && equal(newCarets, carets) {
+			return m
+		}
 		return m.To(newCarets...)
 	}
 	return m.For(newDir, newMod)
@@ -169,6 +197,10 @@ func (m *Mover) Exec() error {
 	// TODO: a lot of this is workaround BS.  This should be cleaned up soon.
 	switch m.direction {
 	case NoDirection:
+		if m.selections != nil {
+			m.editor.(SelectionHandler).SelectSlice(m.selections)
+			break
+		}
 		if m.carets == nil {
 			return nil
 		}
@@ -224,3 +256,15 @@ func (m *Mover) Exec() error {
 	}
 	return nil
 }
+
+func equal(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
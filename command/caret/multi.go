@@ -0,0 +1,370 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package caret
+
+import (
+	"fmt"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/commander/input"
+)
+
+// defaultTabWidth is the tab width used for editors that don't
+// report one.
+const defaultTabWidth = 4
+
+// Selections is a type that holds an editor's selections, like its
+// controller.
+type Selections interface {
+	SelectionSlice() []gxui.TextSelection
+}
+
+// Executor is a type that can execute bindables, like the commander.
+type Executor interface {
+	Execute(bind.Bindable)
+}
+
+type tabWidther interface {
+	TabWidth() int
+}
+
+type runeScroller interface {
+	ScrollToRune(int)
+}
+
+// selector holds what the multiple caret commands need in order to
+// change an editor's selections through a Mover, so that Mover hooks
+// see the change.
+type selector struct {
+	editor input.Editor
+	sels   Selections
+	mover  *Mover
+	exec   Executor
+}
+
+func (s *selector) Reset() {
+	s.editor = nil
+	s.sels = nil
+	s.mover = nil
+	s.exec = nil
+}
+
+func (s *selector) Store(elem interface{}) bind.Status {
+	switch src := elem.(type) {
+	case *Mover:
+		s.mover = src
+	case input.Editor:
+		s.editor = src
+	case Selections:
+		s.sels = src
+	case Executor:
+		s.exec = src
+	}
+	if s.editor != nil && s.sels != nil && s.mover != nil && s.exec != nil {
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+func (s *selector) tabWidth() int {
+	if t, ok := s.editor.(tabWidther); ok && t.TabWidth() > 0 {
+		return t.TabWidth()
+	}
+	return defaultTabWidth
+}
+
+// set replaces the editor's selections with sels and scrolls to show
+// the caret of show.
+func (s *selector) set(sels []gxui.TextSelection, show gxui.TextSelection) {
+	s.exec.Execute(s.mover.Select(sels...))
+	if scroller, ok := s.editor.(runeScroller); ok {
+		scroller.ScrollToRune(show.Caret())
+	}
+}
+
+// occurrences remembers the occurrence that was selected last, so
+// that the next one is found after it even once the search wraps
+// around to the start of the file.
+type occurrences struct {
+	last gxui.TextSelection
+}
+
+func (o *occurrences) primary(sels []gxui.TextSelection) int {
+	for i, s := range sels {
+		if s.Start() == o.last.Start() && s.End() == o.last.End() {
+			return i
+		}
+	}
+	return len(sels) - 1
+}
+
+// NewOccurrenceCommands returns the commands that add, skip and
+// select occurrences of the selected text.  The commands share state
+// so that skipping an occurrence continues from the last one added.
+func NewOccurrenceCommands() []bind.Bindable {
+	o := &occurrences{}
+	return []bind.Bindable{
+		&AddNext{occurrences: o},
+		&AddNext{occurrences: o, skip: true},
+		&SelectOccurrences{},
+	}
+}
+
+// AddNext adds a selection at the next occurrence of the selected
+// text.  If the selection is empty, the word around each caret is
+// selected first.  When skip is set, the last selection that was
+// added is moved to the next occurrence instead.
+type AddNext struct {
+	selector
+	*occurrences
+
+	skip bool
+}
+
+func (a *AddNext) Name() string {
+	if a.skip {
+		return "skip-occurrence"
+	}
+	return "add-next-occurrence"
+}
+
+func (a *AddNext) Menu() string {
+	return "Edit"
+}
+
+func (a *AddNext) Defaults() []fmt.Stringer {
+	key := gxui.KeyD
+	if a.skip {
+		key = gxui.KeyK
+	}
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl,
+		Key:      key,
+	}}
+}
+
+func (a *AddNext) Exec() error {
+	text := a.editor.Runes()
+	sels := a.sels.SelectionSlice()
+	if len(sels) == 0 {
+		return nil
+	}
+	primary := a.primary(sels)
+	if sels[primary].Length() == 0 {
+		words := Words(text, sels)
+		a.last = words[len(words)-1]
+		a.set(words, a.last)
+		return nil
+	}
+	next, ok := NextOccurrence(text, sels, primary)
+	if !ok {
+		return nil
+	}
+	if a.skip {
+		sels = append(sels[:primary:primary], sels[primary+1:]...)
+	}
+	a.last = next
+	a.set(merge(append(sels, next)), next)
+	return nil
+}
+
+// SelectOccurrences selects every occurrence of the selected text.
+type SelectOccurrences struct {
+	selector
+}
+
+func (s *SelectOccurrences) Name() string {
+	return "select-all-occurrences"
+}
+
+func (s *SelectOccurrences) Menu() string {
+	return "Edit"
+}
+
+func (s *SelectOccurrences) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl | gxui.ModShift,
+		Key:      gxui.KeyD,
+	}}
+}
+
+func (s *SelectOccurrences) Exec() error {
+	text := s.editor.Runes()
+	sels := s.sels.SelectionSlice()
+	if len(sels) == 0 {
+		return nil
+	}
+	last := sels[len(sels)-1]
+	if last.Length() == 0 {
+		start, end := WordAt(text, last.Start())
+		last = gxui.CreateTextSelection(start, end, false)
+	}
+	if last.Length() == 0 {
+		return nil
+	}
+	s.set(Occurrences(text, last), last)
+	return nil
+}
+
+// AddCaret adds a caret on the line above or below each caret.
+type AddCaret struct {
+	selector
+
+	direction Direction
+}
+
+// NewAddCaret returns an AddCaret that adds carets in d, which must
+// be Up or Down.
+func NewAddCaret(d Direction) *AddCaret {
+	return &AddCaret{direction: d}
+}
+
+func (a *AddCaret) Name() string {
+	if a.direction == Up {
+		return "add-caret-above"
+	}
+	return "add-caret-below"
+}
+
+func (a *AddCaret) Menu() string {
+	return "Edit"
+}
+
+func (a *AddCaret) Defaults() []fmt.Stringer {
+	key := gxui.KeyDown
+	if a.direction == Up {
+		key = gxui.KeyUp
+	}
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl | gxui.ModShift,
+		Key:      key,
+	}}
+}
+
+func (a *AddCaret) Exec() error {
+	sels := a.sels.SelectionSlice()
+	if len(sels) == 0 {
+		return nil
+	}
+	delta, show := 1, -1
+	if a.direction == Up {
+		delta, show = -1, 0
+	}
+	added := AddLine(input.Snapshot(a.editor), sels, delta, a.tabWidth())
+	if show < 0 {
+		show = len(added) - 1
+	}
+	a.set(added, added[show])
+	return nil
+}
+
+// block is the rectangle that the block selection commands are
+// extending, along with the selections that it was last shown as.
+type block struct {
+	anchor, head Point
+	shown        []gxui.TextSelection
+}
+
+// NewBlockCommands returns the commands that extend a rectangular
+// block selection in each direction.
+func NewBlockCommands() []bind.Bindable {
+	b := &block{}
+	var cmds []bind.Bindable
+	for _, d := range []Direction{Up, Down, Left, Right} {
+		cmds = append(cmds, &BlockSelect{block: b, direction: d})
+	}
+	return cmds
+}
+
+// BlockSelect extends a rectangular block selection by a line or a
+// column.  If the editor's selections aren't the block that was last
+// selected, a new block is started at the last caret.
+type BlockSelect struct {
+	selector
+	*block
+
+	direction Direction
+}
+
+func (b *BlockSelect) Name() string {
+	switch b.direction {
+	case Up:
+		return "block-select-up"
+	case Down:
+		return "block-select-down"
+	case Left:
+		return "block-select-left"
+	default:
+		return "block-select-right"
+	}
+}
+
+func (b *BlockSelect) Menu() string {
+	return "Edit"
+}
+
+func (b *BlockSelect) Defaults() []fmt.Stringer {
+	e := gxui.KeyboardEvent{Modifier: gxui.ModAlt | gxui.ModShift}
+	switch b.direction {
+	case Up:
+		e.Key = gxui.KeyUp
+	case Down:
+		e.Key = gxui.KeyDown
+	case Left:
+		e.Key = gxui.KeyLeft
+	default:
+		e.Key = gxui.KeyRight
+	}
+	return []fmt.Stringer{e}
+}
+
+func (b *BlockSelect) Exec() error {
+	text := input.Snapshot(b.editor)
+	sels := b.sels.SelectionSlice()
+	if len(sels) == 0 {
+		return nil
+	}
+	tabWidth := b.tabWidth()
+	if !same(sels, b.shown) {
+		b.anchor = PointAt(text, sels[len(sels)-1].Caret(), tabWidth)
+		b.head = b.anchor
+	}
+	switch b.direction {
+	case Up:
+		if b.head.Line > 0 {
+			b.head.Line--
+		}
+	case Down:
+		if b.head.Line < text.Lines()-1 {
+			b.head.Line++
+		}
+	case Left:
+		if b.head.Col > 0 {
+			b.head.Col--
+		}
+	case Right:
+		b.head.Col++
+	}
+	b.shown = Block(text, b.anchor, b.head, tabWidth)
+	show := b.shown[0]
+	if b.head.Line > b.anchor.Line {
+		show = b.shown[len(b.shown)-1]
+	}
+	b.set(b.shown, show)
+	return nil
+}
+
+func same(a, b []gxui.TextSelection) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Start() != b[i].Start() || a[i].End() != b[i].End() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,238 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package caret
+
+import (
+	"sort"
+	"unicode"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/piece"
+)
+
+// Point is a position in text as a line and a visual column, where
+// tabs are expanded to the next multiple of the tab width.  Points
+// may be past the end of their line.
+type Point struct {
+	Line, Col int
+}
+
+// PointAt returns the Point for the rune index pos in text.
+func PointAt(text *piece.Table, pos, tabWidth int) Point {
+	line := text.LineOf(pos)
+	start, _ := text.LineStart(line)
+	return Point{Line: line, Col: column(text.Slice(start, pos), tabWidth)}
+}
+
+// WordAt returns the bounds of the word that pos is in or touching.
+// If there is no word at pos, start and end are both pos.
+func WordAt(text []rune, pos int) (start, end int) {
+	start, end = pos, pos
+	for start > 0 && isWord(text[start-1]) {
+		start--
+	}
+	for end < len(text) && isWord(text[end]) {
+		end++
+	}
+	return start, end
+}
+
+// Words expands each empty selection in sels to the word around it.
+// Selections that aren't in a word are left alone.
+func Words(text []rune, sels []gxui.TextSelection) []gxui.TextSelection {
+	words := make([]gxui.TextSelection, 0, len(sels))
+	for _, s := range sels {
+		if s.Length() == 0 {
+			start, end := WordAt(text, s.Start())
+			s = gxui.CreateTextSelection(start, end, false)
+		}
+		words = append(words, s)
+	}
+	return merge(words)
+}
+
+// NextOccurrence returns the first occurrence of the text selected
+// by sels[primary] after it, wrapping around to the start of text,
+// that doesn't overlap any of sels.  It returns false if the
+// selection is empty or every occurrence is already selected.
+func NextOccurrence(text []rune, sels []gxui.TextSelection, primary int) (gxui.TextSelection, bool) {
+	p := sels[primary]
+	needle := text[p.Start():p.End()]
+	if len(needle) == 0 {
+		return gxui.TextSelection{}, false
+	}
+	for i, wrapped := p.End(), false; ; i++ {
+		if i+len(needle) > len(text) {
+			if wrapped {
+				return gxui.TextSelection{}, false
+			}
+			i, wrapped = 0, true
+		}
+		if wrapped && i >= p.Start() {
+			return gxui.TextSelection{}, false
+		}
+		if !match(text, i, needle) {
+			continue
+		}
+		s := gxui.CreateTextSelection(i, i+len(needle), false)
+		if !overlaps(s, sels) {
+			return s, true
+		}
+	}
+}
+
+// Occurrences returns a selection for every occurrence of the text
+// selected by sel that doesn't overlap another one.
+func Occurrences(text []rune, sel gxui.TextSelection) []gxui.TextSelection {
+	needle := text[sel.Start():sel.End()]
+	if len(needle) == 0 {
+		return []gxui.TextSelection{sel}
+	}
+	var sels []gxui.TextSelection
+	for i := 0; i+len(needle) <= len(text); i++ {
+		if match(text, i, needle) {
+			sels = append(sels, gxui.CreateTextSelection(i, i+len(needle), false))
+			i += len(needle) - 1
+		}
+	}
+	return sels
+}
+
+// AddLine returns sels along with a caret on the line before (for a
+// negative delta) or after each of their carets, at the same visual
+// column where the line is long enough and at its end otherwise.
+func AddLine(text *piece.Table, sels []gxui.TextSelection, delta, tabWidth int) []gxui.TextSelection {
+	added := append([]gxui.TextSelection(nil), sels...)
+	for _, s := range sels {
+		p := PointAt(text, s.Caret(), tabWidth)
+		p.Line += delta
+		start, line, ok := lineAt(text, p.Line)
+		if !ok {
+			continue
+		}
+		pos, _ := atColumn(line, start, p.Col, tabWidth)
+		added = append(added, gxui.CreateTextSelection(pos, pos, false))
+	}
+	return merge(added)
+}
+
+// Block returns the selections for a rectangular block of text from
+// anchor to head.  Lines that end before the block starts are left
+// out, except for head's line, which always has a caret.
+func Block(text *piece.Table, anchor, head Point, tabWidth int) []gxui.TextSelection {
+	first, last := anchor.Line, head.Line
+	if first > last {
+		first, last = last, first
+	}
+	left, right := anchor.Col, head.Col
+	if left > right {
+		left, right = right, left
+	}
+	atStart := head.Col < anchor.Col
+	var sels []gxui.TextSelection
+	for l := first; l <= last; l++ {
+		lineStart, line, ok := lineAt(text, l)
+		if !ok {
+			break
+		}
+		start, ok := atColumn(line, lineStart, left, tabWidth)
+		if !ok && l != head.Line {
+			continue
+		}
+		end, _ := atColumn(line, lineStart, right, tabWidth)
+		sels = append(sels, gxui.CreateTextSelection(start, end, atStart))
+	}
+	return sels
+}
+
+// merge sorts sels and removes any that overlap an earlier one.
+func merge(sels []gxui.TextSelection) []gxui.TextSelection {
+	sort.Slice(sels, func(i, j int) bool {
+		return sels[i].Start() < sels[j].Start()
+	})
+	merged := sels[:0]
+	for _, s := range sels {
+		if len(merged) > 0 {
+			prev := merged[len(merged)-1]
+			if s.Start() < prev.End() || s.Start() == prev.Start() {
+				continue
+			}
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+func overlaps(s gxui.TextSelection, sels []gxui.TextSelection) bool {
+	for _, o := range sels {
+		if s.Start() < o.End() && o.Start() < s.End() {
+			return true
+		}
+	}
+	return false
+}
+
+func match(text []rune, at int, needle []rune) bool {
+	for i, r := range needle {
+		if text[at+i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+func isWord(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// lineAt returns the offset of the start of line in text and the
+// runes on it, without its newline.  It returns false if text doesn't
+// have that many lines.
+func lineAt(text *piece.Table, line int) (int, []rune, bool) {
+	start, ok := text.LineStart(line)
+	if line < 0 || !ok {
+		return 0, nil, false
+	}
+	end := text.Len()
+	if next, ok := text.LineStart(line + 1); ok {
+		end = next - 1
+	}
+	return start, text.Slice(start, end), true
+}
+
+// column returns the visual width of line.
+func column(line []rune, tabWidth int) int {
+	col := 0
+	for _, r := range line {
+		if r == '\t' && tabWidth > 0 {
+			col += tabWidth - col%tabWidth
+			continue
+		}
+		col++
+	}
+	return col
+}
+
+// atColumn returns the index of the rune at visual column col on
+// line, which starts at start.  If the line ends before col, it
+// returns the end of the line and false.  A column inside of a tab is
+// rounded to the start of the tab.
+func atColumn(line []rune, start, col, tabWidth int) (int, bool) {
+	c := 0
+	for i, r := range line {
+		if c >= col {
+			return start + i, true
+		}
+		w := 1
+		if r == '\t' && tabWidth > 0 {
+			w = tabWidth - c%tabWidth
+		}
+		if c+w > col {
+			return start + i, true
+		}
+		c += w
+	}
+	return start + len(line), c >= col
+}
@@ -0,0 +1,105 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package caret_test
+
+import (
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/command/caret"
+	"github.com/nelsam/vidar/piece"
+)
+
+type span struct {
+	start, end int
+}
+
+func spans(sels []gxui.TextSelection) []span {
+	var s []span
+	for _, sel := range sels {
+		s = append(s, span{sel.Start(), sel.End()})
+	}
+	return s
+}
+
+func sel(start, end int) gxui.TextSelection {
+	return gxui.CreateTextSelection(start, end, false)
+}
+
+func TestOccurrences(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, []rune) {
+		return expect.New(t), []rune("foo bar foo\nfoo_baz foo")
+	})
+
+	o.Spec("it expands carets to words", func(expect expect.Expectation, text []rune) {
+		words := caret.Words(text, []gxui.TextSelection{sel(1, 1), sel(14, 14)})
+		expect(spans(words)).To(Equal([]span{{0, 3}, {12, 19}}))
+	})
+
+	o.Spec("it finds the next occurrence after the primary selection", func(expect expect.Expectation, text []rune) {
+		next, ok := caret.NextOccurrence(text, []gxui.TextSelection{sel(0, 3)}, 0)
+		expect(ok).To(BeTrue())
+		expect(spans([]gxui.TextSelection{next})).To(Equal([]span{{8, 11}}))
+	})
+
+	o.Spec("it wraps around and skips selected occurrences", func(expect expect.Expectation, text []rune) {
+		sels := []gxui.TextSelection{sel(8, 11), sel(12, 15), sel(20, 23)}
+		next, ok := caret.NextOccurrence(text, sels, 2)
+		expect(ok).To(BeTrue())
+		expect(next.Start()).To(Equal(0))
+
+		sels = append([]gxui.TextSelection{sel(0, 3)}, sels...)
+		_, ok = caret.NextOccurrence(text, sels, 3)
+		expect(ok).To(BeFalse())
+	})
+
+	o.Spec("it selects every occurrence", func(expect expect.Expectation, text []rune) {
+		all := caret.Occurrences(text, sel(8, 11))
+		expect(spans(all)).To(Equal([]span{{0, 3}, {8, 11}, {12, 15}, {20, 23}}))
+		expect(spans(caret.Occurrences([]rune("aaaa"), sel(0, 2)))).To(Equal([]span{{0, 2}, {2, 4}}))
+	})
+}
+
+func TestColumns(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, *piece.Table) {
+		return expect.New(t), piece.New([]rune("abcdef\nab\n\tcdef\nabcdefgh"))
+	})
+
+	o.Spec("it finds visual columns", func(expect expect.Expectation, text *piece.Table) {
+		expect(caret.PointAt(text, 3, 4)).To(Equal(caret.Point{Line: 0, Col: 3}))
+		expect(caret.PointAt(text, 12, 4)).To(Equal(caret.Point{Line: 2, Col: 5}))
+	})
+
+	o.Spec("it adds carets on the next and previous lines", func(expect expect.Expectation, text *piece.Table) {
+		below := caret.AddLine(text, []gxui.TextSelection{sel(4, 4)}, 1, 4)
+		expect(spans(below)).To(Equal([]span{{4, 4}, {9, 9}}))
+
+		above := caret.AddLine(text, []gxui.TextSelection{sel(21, 21)}, -1, 4)
+		expect(spans(above)).To(Equal([]span{{12, 12}, {21, 21}}))
+
+		expect(spans(caret.AddLine(text, []gxui.TextSelection{sel(1, 1)}, -1, 4))).To(Equal([]span{{1, 1}}))
+	})
+
+	o.Spec("it selects rectangular blocks", func(expect expect.Expectation, text *piece.Table) {
+		block := caret.Block(text, caret.Point{Line: 0, Col: 1}, caret.Point{Line: 3, Col: 4}, 4)
+		expect(spans(block)).To(Equal([]span{{1, 4}, {8, 9}, {10, 11}, {17, 20}}))
+		for _, s := range block {
+			expect(s.CaretAtStart()).To(BeFalse())
+		}
+
+		block = caret.Block(text, caret.Point{Line: 2, Col: 6}, caret.Point{Line: 1, Col: 3}, 4)
+		expect(spans(block)).To(Equal([]span{{9, 9}, {10, 13}}))
+		expect(block[1].CaretAtStart()).To(BeTrue())
+	})
+}
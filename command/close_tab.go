@@ -17,6 +17,12 @@ type CurrentEditorCloser interface {
 	CurrentEditor() input.Editor
 }
 
+// closeNotifier is an editor that needs to clean up after itself
+// when it's closed.
+type closeNotifier interface {
+	Closed()
+}
+
 type BindPopper interface {
 	Pop() []bind.Bindable
 }
@@ -64,7 +70,11 @@ func (s *CloseTab) Store(target interface{}) bind.Status {
 }
 
 func (s *CloseTab) Exec() error {
-	s.closer.CloseCurrentEditor()
+	if _, editor := s.closer.CloseCurrentEditor(); editor != nil {
+		if n, ok := editor.(closeNotifier); ok {
+			n.Closed()
+		}
+	}
 	if s.closer.CurrentEditor() == nil {
 		s.binder.Pop()
 	}
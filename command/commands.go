@@ -9,7 +9,9 @@ import (
 	"github.com/nelsam/gxui/themes/basic"
 	"github.com/nelsam/vidar/command/caret"
 	"github.com/nelsam/vidar/command/focus"
+	"github.com/nelsam/vidar/command/fold"
 	"github.com/nelsam/vidar/command/history"
+	"github.com/nelsam/vidar/command/input"
 	"github.com/nelsam/vidar/command/project"
 	"github.com/nelsam/vidar/command/scroll"
 	"github.com/nelsam/vidar/commander/bind"
@@ -32,6 +34,9 @@ func Bindables(cmdr command.Commander, driver gxui.Driver, theme *basic.Theme) [
 		EditHook{Theme: theme, Driver: driver},
 		ViewHook{},
 		NavHook{Commander: cmdr},
+		fold.Hook{Theme: theme},
+		input.AutoCloseHook{},
+		input.AutoIndentHook{},
 	)
 	b = append(b, history.Bindables(cmdr, driver, theme)...)
 	return b
@@ -0,0 +1,159 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/plugin/status"
+	"github.com/nelsam/vidar/textfile"
+)
+
+// FormatSetter is an editor whose file can be converted to another
+// encoding or line ending style.
+type FormatSetter interface {
+	input.Editor
+	FormatEditor
+	SetFileFormat(textfile.Format)
+}
+
+// ConvertEncoding converts the current file to another encoding the
+// next time that it's saved.
+type ConvertEncoding struct {
+	status.General
+
+	encoding textfile.Encoding
+	bom      bool
+	editor   FormatSetter
+}
+
+// NewConvertEncodings returns a *ConvertEncoding for each encoding
+// in textfile.Encodings, along with one for UTF-8 with a byte order
+// mark.
+func NewConvertEncodings(theme gxui.Theme) []bind.Bindable {
+	var b []bind.Bindable
+	for _, enc := range textfile.Encodings {
+		// UTF-16 is almost always written with a byte order mark,
+		// since it can't be read reliably without one.
+		bom := enc == textfile.UTF16LE || enc == textfile.UTF16BE
+		b = append(b, NewConvertEncoding(theme, enc, bom))
+		if enc == textfile.UTF8 {
+			b = append(b, NewConvertEncoding(theme, enc, true))
+		}
+	}
+	return b
+}
+
+// NewConvertEncoding returns a *ConvertEncoding that converts files
+// to enc, starting them with a byte order mark if bom is true.
+func NewConvertEncoding(theme gxui.Theme, enc textfile.Encoding, bom bool) *ConvertEncoding {
+	c := &ConvertEncoding{encoding: enc, bom: bom}
+	c.Theme = theme
+	return c
+}
+
+func (c *ConvertEncoding) Name() string {
+	name := "convert-to-" + strings.ToLower(strings.Replace(c.encoding.String(), " ", "", -1))
+	if c.bom && c.encoding == textfile.UTF8 {
+		name += "-bom"
+	}
+	return name
+}
+
+func (c *ConvertEncoding) Menu() string {
+	return "File"
+}
+
+func (c *ConvertEncoding) Defaults() []fmt.Stringer {
+	return nil
+}
+
+func (c *ConvertEncoding) Reset() {
+	c.editor = nil
+}
+
+func (c *ConvertEncoding) Store(target interface{}) bind.Status {
+	editor, ok := target.(FormatSetter)
+	if !ok {
+		return bind.Waiting
+	}
+	c.editor = editor
+	return bind.Done
+}
+
+func (c *ConvertEncoding) Exec() error {
+	f := c.editor.FileFormat()
+	f.Encoding = c.encoding
+	f.BOM = c.bom
+	return convert(&c.General, c.editor, f)
+}
+
+// ConvertLineEndings converts the line endings of the current file
+// the next time that it's saved.
+type ConvertLineEndings struct {
+	status.General
+
+	ending textfile.LineEnding
+	editor FormatSetter
+}
+
+// NewConvertLineEndings returns a *ConvertLineEndings for each line
+// ending in textfile.LineEndings.
+func NewConvertLineEndings(theme gxui.Theme) []bind.Bindable {
+	var b []bind.Bindable
+	for _, ending := range textfile.LineEndings {
+		c := &ConvertLineEndings{ending: ending}
+		c.Theme = theme
+		b = append(b, c)
+	}
+	return b
+}
+
+func (c *ConvertLineEndings) Name() string {
+	return "convert-to-" + strings.ToLower(c.ending.String())
+}
+
+func (c *ConvertLineEndings) Menu() string {
+	return "File"
+}
+
+func (c *ConvertLineEndings) Defaults() []fmt.Stringer {
+	return nil
+}
+
+func (c *ConvertLineEndings) Reset() {
+	c.editor = nil
+}
+
+func (c *ConvertLineEndings) Store(target interface{}) bind.Status {
+	editor, ok := target.(FormatSetter)
+	if !ok {
+		return bind.Waiting
+	}
+	c.editor = editor
+	return bind.Done
+}
+
+func (c *ConvertLineEndings) Exec() error {
+	f := c.editor.FileFormat()
+	f.LineEnding = c.ending
+	return convert(&c.General, c.editor, f)
+}
+
+// convert sets the format of editor's file to f, as long as its text
+// can be saved in f.
+func convert(s *status.General, editor FormatSetter, f textfile.Format) error {
+	if _, err := textfile.Encode(editor.Text(), f); err != nil {
+		s.Err = fmt.Sprintf("Could not convert %s: %s", editor.Filepath(), err)
+		return err
+	}
+	editor.SetFileFormat(f)
+	s.Info = fmt.Sprintf("%s will be saved as %s", editor.Filepath(), f)
+	return nil
+}
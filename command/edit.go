@@ -7,6 +7,7 @@ package command
 import (
 	"github.com/nelsam/gxui"
 	"github.com/nelsam/gxui/themes/basic"
+	"github.com/nelsam/vidar/command/action"
 	"github.com/nelsam/vidar/commander/bind"
 )
 
@@ -33,5 +34,6 @@ func (h EditHook) FileBindables(string) []bind.Bindable {
 		NewCut(h.Driver),
 		NewPaste(h.Driver, h.Theme),
 		NewGotoLine(h.Theme),
+		action.NewList(h.Theme),
 	}
 }
@@ -22,10 +22,12 @@ func (h FileHook) OpName() string {
 }
 
 func (h FileHook) FileBindables(string) []bind.Bindable {
-	return []bind.Bindable{
+	b := []bind.Bindable{
 		NewSave(h.Theme),
 		NewSaveAll(h.Theme),
 		NewCloseTab(),
 		&EditorRedraw{},
 	}
+	b = append(b, NewConvertEncodings(h.Theme)...)
+	return append(b, NewConvertLineEndings(h.Theme)...)
 }
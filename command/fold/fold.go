@@ -0,0 +1,207 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package fold contains commands for folding (hiding) regions of
+// text in an editor.  The regions themselves are provided by
+// language plugins (see input.FoldRegion).
+package fold
+
+import (
+	"fmt"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/commander/input"
+)
+
+// Editor is the type of editor that supports folding.
+type Editor interface {
+	input.Editor
+
+	Carets() []int
+
+	// FoldRegions returns the regions that can be folded.
+	FoldRegions() []input.FoldRegion
+
+	// Folded returns the regions that are currently folded.
+	Folded() []input.FoldRegion
+
+	// SetFolded replaces the regions that are currently folded.
+	SetFolded([]input.FoldRegion)
+}
+
+// innermost returns the index of the innermost region in regions
+// containing pos, or -1 if no region contains pos.
+func innermost(regions []input.FoldRegion, pos int) int {
+	found := -1
+	for i, r := range regions {
+		if r.Start > pos || pos > r.End {
+			continue
+		}
+		if found == -1 || r.End-r.Start < regions[found].End-regions[found].Start {
+			found = i
+		}
+	}
+	return found
+}
+
+func contains(regions []input.FoldRegion, r input.FoldRegion) bool {
+	for _, f := range regions {
+		if f.Span == r.Span {
+			return true
+		}
+	}
+	return false
+}
+
+// Fold is a command that folds the innermost region containing
+// each caret.
+type Fold struct{}
+
+func NewFold() *Fold {
+	return &Fold{}
+}
+
+func (f *Fold) Name() string {
+	return "fold"
+}
+
+func (f *Fold) Menu() string {
+	return "View"
+}
+
+func (f *Fold) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl | gxui.ModShift,
+		Key:      gxui.KeyLeftBracket,
+	}}
+}
+
+func (f *Fold) Exec(target interface{}) bind.Status {
+	e, ok := target.(Editor)
+	if !ok {
+		return bind.Waiting
+	}
+	regions := e.FoldRegions()
+	folded := e.Folded()
+	for _, c := range e.Carets() {
+		// Ignore regions that are already folded, so that
+		// repeated folds work their way outward.
+		var unfolded []input.FoldRegion
+		for _, r := range regions {
+			if !contains(folded, r) {
+				unfolded = append(unfolded, r)
+			}
+		}
+		i := innermost(unfolded, c)
+		if i == -1 {
+			continue
+		}
+		folded = append(folded, unfolded[i])
+	}
+	e.SetFolded(folded)
+	return bind.Done
+}
+
+// Unfold is a command that unfolds the innermost folded region
+// containing each caret.
+type Unfold struct{}
+
+func NewUnfold() *Unfold {
+	return &Unfold{}
+}
+
+func (u *Unfold) Name() string {
+	return "unfold"
+}
+
+func (u *Unfold) Menu() string {
+	return "View"
+}
+
+func (u *Unfold) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl | gxui.ModShift,
+		Key:      gxui.KeyRightBracket,
+	}}
+}
+
+func (u *Unfold) Exec(target interface{}) bind.Status {
+	e, ok := target.(Editor)
+	if !ok {
+		return bind.Waiting
+	}
+	folded := e.Folded()
+	for _, c := range e.Carets() {
+		i := innermost(folded, c)
+		if i == -1 {
+			continue
+		}
+		folded = append(folded[:i:i], folded[i+1:]...)
+	}
+	e.SetFolded(folded)
+	return bind.Done
+}
+
+// FoldAll is a command that folds every region.
+type FoldAll struct{}
+
+func NewFoldAll() *FoldAll {
+	return &FoldAll{}
+}
+
+func (f *FoldAll) Name() string {
+	return "fold-all"
+}
+
+func (f *FoldAll) Menu() string {
+	return "View"
+}
+
+func (f *FoldAll) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl | gxui.ModAlt,
+		Key:      gxui.KeyLeftBracket,
+	}}
+}
+
+func (f *FoldAll) Exec(target interface{}) bind.Status {
+	e, ok := target.(Editor)
+	if !ok {
+		return bind.Waiting
+	}
+	e.SetFolded(e.FoldRegions())
+	return bind.Done
+}
+
+// UnfoldAll is a command that unfolds every folded region.
+type UnfoldAll struct{}
+
+func NewUnfoldAll() *UnfoldAll {
+	return &UnfoldAll{}
+}
+
+func (u *UnfoldAll) Name() string {
+	return "unfold-all"
+}
+
+func (u *UnfoldAll) Menu() string {
+	return "View"
+}
+
+func (u *UnfoldAll) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl | gxui.ModAlt,
+		Key:      gxui.KeyRightBracket,
+	}}
+}
+
+func (u *UnfoldAll) Exec(target interface{}) bind.Status {
+	e, ok := target.(Editor)
+	if !ok {
+		return bind.Waiting
+	}
+	e.SetFolded(nil)
+	return bind.Done
+}
@@ -0,0 +1,117 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package fold_test
+
+import (
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/command/fold"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/commander/input"
+)
+
+type fakeEditor struct {
+	text    string
+	carets  []int
+	regions []input.FoldRegion
+	folded  []input.FoldRegion
+}
+
+func (e *fakeEditor) Filepath() string                    { return "foo.go" }
+func (e *fakeEditor) Text() string                        { return e.text }
+func (e *fakeEditor) Runes() []rune                       { return []rune(e.text) }
+func (e *fakeEditor) SetText(t string)                    { e.text = t }
+func (e *fakeEditor) SyntaxLayers() []input.SyntaxLayer   { return nil }
+func (e *fakeEditor) SetSyntaxLayers([]input.SyntaxLayer) {}
+func (e *fakeEditor) Carets() []int                       { return e.carets }
+func (e *fakeEditor) FoldRegions() []input.FoldRegion     { return e.regions }
+func (e *fakeEditor) Folded() []input.FoldRegion          { return append([]input.FoldRegion(nil), e.folded...) }
+func (e *fakeEditor) SetFolded(folded []input.FoldRegion) { e.folded = folded }
+
+const src = `func foo() {
+	if bar {
+		baz()
+	}
+}
+`
+
+var (
+	outer = input.FoldRegion{Span: input.Span{Start: 0, End: 36}, Level: 1}
+	inner = input.FoldRegion{Span: input.Span{Start: 14, End: 34}, Level: 2}
+)
+
+func TestFold(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, *fakeEditor) {
+		return expect.New(t), &fakeEditor{
+			text:    src,
+			regions: []input.FoldRegion{outer, inner},
+		}
+	})
+
+	o.Spec("it folds the innermost region at the caret", func(expect expect.Expectation, e *fakeEditor) {
+		e.carets = []int{20}
+		expect(fold.NewFold().Exec(e)).To(Equal(bind.Done))
+		expect(e.folded).To(Equal([]input.FoldRegion{inner}))
+	})
+
+	o.Spec("it folds outward when the inner region is already folded", func(expect expect.Expectation, e *fakeEditor) {
+		e.carets = []int{15}
+		e.folded = []input.FoldRegion{inner}
+		expect(fold.NewFold().Exec(e)).To(Equal(bind.Done))
+		expect(e.folded).To(Equal([]input.FoldRegion{inner, outer}))
+	})
+
+	o.Spec("it unfolds the innermost folded region at the caret", func(expect expect.Expectation, e *fakeEditor) {
+		e.carets = []int{15}
+		e.folded = []input.FoldRegion{outer, inner}
+		expect(fold.NewUnfold().Exec(e)).To(Equal(bind.Done))
+		expect(e.folded).To(Equal([]input.FoldRegion{outer}))
+	})
+
+	o.Spec("it folds and unfolds everything", func(expect expect.Expectation, e *fakeEditor) {
+		expect(fold.NewFoldAll().Exec(e)).To(Equal(bind.Done))
+		expect(e.folded).To(Equal([]input.FoldRegion{outer, inner}))
+		expect(fold.NewUnfoldAll().Exec(e)).To(Equal(bind.Done))
+		expect(e.folded).To(HaveLen(0))
+	})
+
+	o.Spec("it waits for an editor that supports folding", func(expect expect.Expectation, e *fakeEditor) {
+		expect(fold.NewFold().Exec(struct{}{})).To(Equal(bind.Waiting))
+	})
+}
+
+func TestOnChange(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, *fakeEditor) {
+		return expect.New(t), &fakeEditor{
+			text:    src,
+			regions: []input.FoldRegion{outer, inner},
+			folded:  []input.FoldRegion{inner},
+		}
+	})
+
+	o.Spec("it shifts folded regions after edits", func(expect expect.Expectation, e *fakeEditor) {
+		fold.OnChange{}.Applied(e, []input.Edit{{At: 0, New: []rune("// x\n")}})
+		expect(e.folded).To(Equal([]input.FoldRegion{{Span: input.Span{Start: 19, End: 39}, Level: 2}}))
+	})
+
+	o.Spec("it unfolds regions that a caret moves in to", func(expect expect.Expectation, e *fakeEditor) {
+		fold.OnChange{}.Moved(e, []int{25})
+		expect(e.folded).To(HaveLen(0))
+	})
+
+	o.Spec("it leaves regions folded when a caret is on their first line", func(expect expect.Expectation, e *fakeEditor) {
+		fold.OnChange{}.Moved(e, []int{16})
+		expect(e.folded).To(Equal([]input.FoldRegion{inner}))
+	})
+}
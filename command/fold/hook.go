@@ -0,0 +1,102 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package fold
+
+import (
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/piece"
+)
+
+// Hook is a hook that binds the folding commands and hooks to
+// every file.
+type Hook struct {
+	Theme gxui.Theme
+}
+
+func (h Hook) Name() string {
+	return "fold-hook"
+}
+
+func (h Hook) OpName() string {
+	return "focus-location"
+}
+
+func (h Hook) FileBindables(string) []bind.Bindable {
+	return []bind.Bindable{
+		OnChange{},
+		NewFold(),
+		NewUnfold(),
+		NewFoldAll(),
+		NewUnfoldAll(),
+		NewToLevel(h.Theme),
+	}
+}
+
+// OnChange is a hook that keeps folded regions in place while text
+// is edited and unfolds any regions that carets move in to.
+type OnChange struct{}
+
+func (OnChange) Name() string {
+	return "fold-on-change"
+}
+
+func (OnChange) OpNames() []string {
+	return []string{"input-handler", "caret-movement"}
+}
+
+// Applied moves folded regions to account for edits.
+func (OnChange) Applied(ie input.Editor, edits []input.Edit) {
+	e, ok := ie.(Editor)
+	if !ok {
+		return
+	}
+	folded := e.Folded()
+	if len(folded) == 0 {
+		return
+	}
+	for i, r := range folded {
+		folded[i] = r.Move(edits)
+	}
+	e.SetFolded(folded)
+}
+
+// Moved unfolds any folded regions that would hide a caret.
+func (OnChange) Moved(ie input.Editor, carets []int) {
+	e, ok := ie.(Editor)
+	if !ok {
+		return
+	}
+	folded := e.Folded()
+	if len(folded) == 0 {
+		return
+	}
+	text := input.Snapshot(e)
+	visible := folded[:0:0]
+	for _, r := range folded {
+		if !hides(text, r, carets) {
+			visible = append(visible, r)
+		}
+	}
+	if len(visible) == len(folded) {
+		return
+	}
+	e.SetFolded(visible)
+}
+
+// hides returns whether or not r, when folded, hides any of
+// carets.
+func hides(text *piece.Table, r input.FoldRegion, carets []int) bool {
+	for _, c := range carets {
+		if c <= r.Start || c > r.End || c > text.Len() {
+			continue
+		}
+		if text.LineOf(c) > text.LineOf(r.Start) {
+			return true
+		}
+	}
+	return false
+}
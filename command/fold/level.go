@@ -0,0 +1,116 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package fold
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/plugin/status"
+)
+
+// ToLevel is a command that folds every region at or below a
+// level, and unfolds every region above it.  Folding to level 1
+// leaves only top level declarations visible.
+type ToLevel struct {
+	status.General
+
+	levelInput gxui.TextBox
+	input      gxui.Focusable
+
+	editor Editor
+}
+
+func NewToLevel(theme gxui.Theme) *ToLevel {
+	input := theme.CreateTextBox()
+	input.OnTextChanged(func([]gxui.TextBoxEdit) {
+		runes := []rune(input.Text())
+		for index := 0; index < len(runes); index++ {
+			if !unicode.IsDigit(runes[index]) {
+				runes = append(runes[:index], runes[index+1:]...)
+				index--
+			}
+		}
+		text := string(runes)
+		if text != input.Text() {
+			input.SetText(text)
+		}
+	})
+	l := &ToLevel{}
+	l.Theme = theme
+	l.levelInput = input
+	return l
+}
+
+func (l *ToLevel) Start(on gxui.Control) gxui.Control {
+	l.levelInput.SetText("")
+	l.input = l.levelInput
+	return nil
+}
+
+func (l *ToLevel) Name() string {
+	return "fold-to-level"
+}
+
+func (l *ToLevel) Menu() string {
+	return "View"
+}
+
+func (l *ToLevel) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl | gxui.ModAlt,
+		Key:      gxui.KeyL,
+	}}
+}
+
+func (l *ToLevel) Next() gxui.Focusable {
+	input := l.input
+	l.input = nil
+	return input
+}
+
+func (l *ToLevel) Reset() {
+	l.editor = nil
+}
+
+func (l *ToLevel) Store(elem interface{}) bind.Status {
+	e, ok := elem.(Editor)
+	if !ok {
+		return bind.Waiting
+	}
+	l.editor = e
+	return bind.Done
+}
+
+func (l *ToLevel) Exec() error {
+	levelStr := l.levelInput.Text()
+	if levelStr == "" {
+		l.Warn = "No fold level provided"
+		return nil
+	}
+	level, err := strconv.Atoi(levelStr)
+	if err != nil {
+		l.Err = fmt.Sprintf("%s is not a valid level", levelStr)
+		return err
+	}
+	l.editor.SetFolded(atOrBelow(l.editor.FoldRegions(), level))
+	return nil
+}
+
+// atOrBelow returns the regions in regions with a level of n or
+// more.
+func atOrBelow(regions []input.FoldRegion, n int) []input.FoldRegion {
+	var folded []input.FoldRegion
+	for _, r := range regions {
+		if r.Level >= n {
+			folded = append(folded, r)
+		}
+	}
+	return folded
+}
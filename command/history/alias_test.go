@@ -10,4 +10,5 @@ import "github.com/poy/onpar/matchers"
 var (
 	not   = matchers.Not
 	equal = matchers.Equal
+	beNil = matchers.BeNil
 )
@@ -18,7 +18,7 @@ type node struct {
 
 	// the above should be kept first in the struct for byte alignment.
 
-	edit input.Edit
+	edits []input.Edit
 }
 
 // next performs atomic incantations to load n.nextP, returning
@@ -62,7 +62,9 @@ type branch struct {
 
 	// the above should be kept first in the struct for byte alignment.
 
-	edit input.Edit
+	// edits are the edits that one call to an input.Handler's
+	// Apply made, which are undone and redone together.
+	edits []input.Edit
 }
 
 // prev performs atomic incantations to load b.prevP and return
@@ -96,9 +98,9 @@ func (b *branch) siblings() []*branch {
 	return *sibs
 }
 
-// push adds e to the next empty child branch of b.
-func (b *branch) push(e input.Edit) *branch {
-	next := &branch{edit: e, prevP: unsafe.Pointer(b)}
+// push adds edits to the next empty child branch of b.
+func (b *branch) push(edits []input.Edit) *branch {
+	next := &branch{edits: edits, prevP: unsafe.Pointer(b)}
 	np := unsafe.Pointer(next)
 	done := atomic.CompareAndSwapPointer(&b.nextP, nil, np)
 	if !done {
@@ -118,7 +120,7 @@ func (b *branch) push(e input.Edit) *branch {
 }
 
 // tree is a simple tree implementation to atomically store a branching
-// history of input.Edit values.
+// history of edits.
 type tree struct {
 	// trunkP *branch
 	trunkP unsafe.Pointer
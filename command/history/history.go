@@ -47,9 +47,6 @@ func (h *History) OpNames() []string {
 	return []string{"input-handler", "focus-location"}
 }
 
-// Init implements input.ChangeHook
-func (h *History) Init(input.Editor, []rune) {}
-
 // resetCurrent resets h.current.trunk to a previous history (if one
 // exists for path) or a new empty branch.
 func (h *History) resetCurrent(path string) {
@@ -60,11 +57,12 @@ func (h *History) resetCurrent(path string) {
 	h.current.setTrunk(&branch{})
 }
 
-// addSkip takes an edit that has been returned by h (from either
-// Rewind or FastForward) and adds it to h.skipP, so that h will
-// ignore e when it is triggered by TextChanged.
-func (h *History) addSkip(e input.Edit) {
-	n := &node{edit: e}
+// addSkip takes edits that an input.Handler will pass to h once
+// edits returned by h (from either Rewind or FastForward) have been
+// applied, and adds them to h.skip, so that h will ignore them when
+// they are passed to Applied.
+func (h *History) addSkip(edits []input.Edit) {
+	n := &node{edits: edits}
 	added := h.skip.casNext(nil, n)
 	if !added {
 		for curr := h.skip.next(); !added; curr = curr.next() {
@@ -73,45 +71,63 @@ func (h *History) addSkip(e input.Edit) {
 	}
 }
 
-// shouldSkip reports whether e is an edit that was created by h and
-// should be skipped.
-func (h *History) shouldSkip(e input.Edit) bool {
+// shouldSkip reports whether edits were created by h and should be
+// skipped.
+func (h *History) shouldSkip(edits []input.Edit) bool {
 	skip := h.skip.next()
-	if skip == nil {
+	if skip == nil || len(skip.edits) != len(edits) {
 		return false
 	}
-	return skip.edit.At == e.At &&
-		string(skip.edit.Old) == string(e.Old) &&
-		string(skip.edit.New) == string(e.New)
+	for i, e := range edits {
+		s := skip.edits[i]
+		if s.At != e.At || string(s.Old) != string(e.Old) || string(s.New) != string(e.New) {
+			return false
+		}
+	}
+	return true
 }
 
-// TextChanged hooks into the input handler to trigger off of changes
-// in the editor so that h can track the history of those changes.
-func (h *History) TextChanged(_ input.Editor, e input.Edit) {
-	if h.shouldSkip(e) {
+// Applied hooks into the input handler to trigger off of changes in
+// the editor so that h can track the history of those changes.  The
+// edits from each call to the handler's Apply are undone and redone
+// as a single step.
+func (h *History) Applied(_ input.Editor, edits []input.Edit) {
+	if len(edits) == 0 {
+		return
+	}
+	if h.shouldSkip(edits) {
 		h.skip.setNext(h.skip.next().next())
 		return
 	}
-	h.current.setTrunk(h.current.trunk().push(e))
+	h.current.setTrunk(h.current.trunk().push(edits))
 }
 
-// Rewind tells h to rewind its current state and return the
-// input.Edit that needs to be applied in order to rewind the
-// text to its previous state.
-func (h *History) Rewind() input.Edit {
+// Rewind tells h to rewind its current state and return the edits
+// that need to be applied in order to rewind the text to its previous
+// state.  Like the edits passed to an input.Handler's Apply, their
+// offsets are all in the text before any of them are applied.  It
+// returns nil if there is nothing to rewind.
+func (h *History) Rewind() []input.Edit {
 	curr := h.current.trunk()
 	prev := curr.prev()
 	if prev == nil {
-		return input.Edit{At: -1}
+		return nil
 	}
-	e := curr.edit
 	h.current.setTrunk(prev)
-	undo := input.Edit{
-		At:  e.At,
-		Old: e.New,
-		New: e.Old,
+
+	// The handler passes each edit to h with its offset moved by
+	// the edits before it, which makes each offset correct in the
+	// text after all of them are applied.  Undoing them from there
+	// moves the offsets back to where they were to begin with.
+	undo := make([]input.Edit, 0, len(curr.edits))
+	applied := make([]input.Edit, 0, len(curr.edits))
+	delta := 0
+	for _, e := range curr.edits {
+		undo = append(undo, input.Edit{At: e.At, Old: e.New, New: e.Old})
+		applied = append(applied, input.Edit{At: e.At - delta, Old: e.New, New: e.Old})
+		delta += len(e.New) - len(e.Old)
 	}
-	h.addSkip(undo)
+	h.addSkip(applied)
 	return undo
 }
 
@@ -126,22 +142,28 @@ func (h *History) Branches() uint {
 }
 
 // FastForward moves h's state forward in the history, based
-// on branch.  To fast forward the most recent undo, run
-// h.FastForward(h.Branches() - 1).
-func (h *History) FastForward(branch uint) input.Edit {
+// on branch, returning the edits that need to be applied.  To fast
+// forward the most recent undo, run h.FastForward(h.Branches() - 1).
+// It returns nil if there is nothing to fast forward.
+func (h *History) FastForward(branch uint) []input.Edit {
 	ff := h.current.trunk().next(branch)
 	if ff == nil {
-		return input.Edit{At: -1}
+		return nil
 	}
 	h.current.setTrunk(ff)
-	h.addSkip(ff.edit)
-	return ff.edit
+	h.addSkip(ff.edits)
+
+	// Move the offsets back to the text before any of the edits
+	// are applied, the way that Rewind does.
+	redo := make([]input.Edit, 0, len(ff.edits))
+	delta := 0
+	for _, e := range ff.edits {
+		redo = append(redo, input.Edit{At: e.At - delta, Old: e.Old, New: e.New})
+		delta += len(e.New) - len(e.Old)
+	}
+	return redo
 }
 
-// Apply is unused on history - recording the changes is all
-// that is needed.
-func (h *History) Apply(input.Editor) error { return nil }
-
 // FileChanged updates the current history when the focused
 // file is changed.
 func (h *History) FileChanged(oldPath, newPath string) {
@@ -11,6 +11,6 @@ import (
 )
 
 var (
-	_ input.ChangeHook  = &history.History{}
-	_ focus.FileChanger = &history.History{}
+	_ input.AppliedChangeHook = &history.History{}
+	_ focus.FileChanger       = &history.History{}
 )
@@ -17,58 +17,85 @@ func TestHistory(t *testing.T) {
 	o := onpar.New()
 	defer o.Run(t)
 
-	// errEdit is the type of edit that we expect when we request history
-	// that doesn't exist.
-	var errEdit = input.Edit{At: -1}
-
 	o.BeforeEach(func(t *testing.T) (expect.Expectation, *history.History, input.Edit) {
 		all := history.Bindables(nil, nil, nil)
 		hist := findHistory(t, all)
 		ed := input.Edit{At: 300, Old: []rune("foo"), New: []rune("bacon")}
-		hist.TextChanged(nil, ed)
+		hist.Applied(nil, []input.Edit{ed})
 		return expect.New(t), hist, ed
 	})
 
 	o.Spec("it knows how to rewind history", func(expect expect.Expectation, h *history.History, ed input.Edit) {
 		expected := input.Edit{At: ed.At, Old: ed.New, New: ed.Old}
-		expect(h.Rewind()).To(equal(expected))
+		expect(h.Rewind()).To(equal([]input.Edit{expected}))
 	})
 
 	o.Spec("it ignores edits returned from Rewind()", func(expect expect.Expectation, h *history.History, ed input.Edit) {
-		h.TextChanged(nil, h.Rewind())
-		expect(h.Rewind()).To(equal(errEdit))
+		h.Applied(nil, h.Rewind())
+		expect(h.Rewind()).To(beNil())
+	})
+
+	o.Spec("it rewinds and fast forwards the edits of one Apply as one step", func(expect expect.Expectation, h *history.History, ed input.Edit) {
+		// "foo bar baz" had "foo" replaced with "x" and "baz" with
+		// "quux", which the handler passes along as offsets in the
+		// text after the edits before them were applied.
+		applied := []input.Edit{
+			{At: 0, Old: []rune("foo"), New: []rune("x")},
+			{At: 6, Old: []rune("baz"), New: []rune("quux")},
+		}
+		h.Applied(nil, applied)
+
+		undo := h.Rewind()
+		expect(undo).To(equal([]input.Edit{
+			{At: 0, Old: []rune("x"), New: []rune("foo")},
+			{At: 6, Old: []rune("quux"), New: []rune("baz")},
+		}))
+		h.Applied(nil, []input.Edit{
+			{At: 0, Old: []rune("x"), New: []rune("foo")},
+			{At: 8, Old: []rune("quux"), New: []rune("baz")},
+		})
+
+		redo := h.FastForward(0)
+		expect(redo).To(equal([]input.Edit{
+			{At: 0, Old: []rune("foo"), New: []rune("x")},
+			{At: 8, Old: []rune("baz"), New: []rune("quux")},
+		}))
+		h.Applied(nil, applied)
+
+		expect(h.FastForward(0)).To(beNil())
+		expect(h.Rewind()).To(equal(undo))
 	})
 
 	o.Group("after rewinding history", func() {
 		o.BeforeEach(func(expect expect.Expectation, h *history.History, ed input.Edit) (expect.Expectation, *history.History, input.Edit) {
-			h.TextChanged(nil, h.Rewind())
+			h.Applied(nil, h.Rewind())
 			return expect, h, ed
 		})
 
 		o.Spec("it knows how to fast forward history", func(expect expect.Expectation, h *history.History, ed input.Edit) {
-			expect(h.FastForward(0)).To(equal(ed))
+			expect(h.FastForward(0)).To(equal([]input.Edit{ed}))
 		})
 
 		o.Spec("it ignores edits returned from FastForward()", func(expect expect.Expectation, h *history.History, ed input.Edit) {
-			h.TextChanged(nil, h.FastForward(0))
-			expect(h.FastForward(0)).To(equal(errEdit))
+			h.Applied(nil, h.FastForward(0))
+			expect(h.FastForward(0)).To(beNil())
 		})
 
 		o.Spec("it handles branching history", func(expect expect.Expectation, h *history.History, ed input.Edit) {
 			expect(h.Branches()).To(equal(uint(1)))
 
 			branch := input.Edit{At: 123, Old: []rune("eggs"), New: []rune("eggs")}
-			h.TextChanged(nil, branch)
-			h.TextChanged(nil, h.Rewind())
+			h.Applied(nil, []input.Edit{branch})
+			h.Applied(nil, h.Rewind())
 			expect(h.Branches()).To(equal(uint(2)))
 
 			ff := h.FastForward(0)
-			expect(ff).To(equal(ed))
+			expect(ff).To(equal([]input.Edit{ed}))
 
-			h.TextChanged(nil, ff)
-			h.TextChanged(nil, h.Rewind())
+			h.Applied(nil, ff)
+			h.Applied(nil, h.Rewind())
 			ff = h.FastForward(1)
-			expect(ff).To(equal(branch))
+			expect(ff).To(equal([]input.Edit{branch}))
 		})
 	})
 }
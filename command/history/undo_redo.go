@@ -84,12 +84,12 @@ func (u *Undo) Store(target interface{}) bind.Status {
 }
 
 func (u *Undo) Exec() error {
-	edit := u.history.Rewind()
-	if edit.At == -1 {
+	edits := u.history.Rewind()
+	if edits == nil {
 		u.Warn = "undo: nothing to undo"
 		return nil
 	}
-	u.applier.Apply(u.editor, edit)
+	u.applier.Apply(u.editor, edits...)
 	return nil
 }
 
@@ -141,11 +141,11 @@ func (r *Redo) Store(target interface{}) bind.Status {
 func (r *Redo) Exec() error {
 	// Overflow will just result in a high number, so no need to
 	// check for it.
-	edit := r.history.FastForward(r.history.Branches() - 1)
-	if edit.At == -1 {
+	edits := r.history.FastForward(r.history.Branches() - 1)
+	if edits == nil {
 		r.Warn = "redo: nothing to redo"
 		return nil
 	}
-	r.applier.Apply(r.editor, edit)
+	r.applier.Apply(r.editor, edits...)
 	return nil
 }
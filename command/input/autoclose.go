@@ -0,0 +1,231 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package input
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/setting"
+)
+
+const defaultPairs = `()[]{}""''`
+
+// languagePairs holds the pairs for languages that differ from
+// defaultPairs, keyed by file extension.
+var languagePairs = map[string]string{
+	"go": defaultPairs + "``",
+}
+
+// A PairCloser is a hook that closes pairs of runes as they are
+// typed.  Only the most recently bound PairCloser will be used.
+type PairCloser interface {
+	// Typed returns the edits to apply when ch is typed at each of
+	// sels, along with the positions that the carets should be moved
+	// to afterward.  If carets is nil, the carets will be moved as
+	// they would be for any other edit.
+	Typed(text []rune, sels []gxui.TextSelection, ch rune) (edits []input.Edit, carets []int)
+
+	// Backspace returns the edit to apply when backspace is pressed
+	// with the caret at caret and nothing selected.  If ok is false,
+	// backspace will delete a single rune as usual.
+	Backspace(text []rune, caret int) (edit input.Edit, ok bool)
+}
+
+// Pair is a pair of runes that are closed automatically.
+type Pair struct {
+	Open, Close rune
+}
+
+// ParsePairs parses s as a list of pairs, where each opening rune is
+// followed by its closing rune.  A trailing rune with no closing
+// rune is ignored.
+func ParsePairs(s string) []Pair {
+	runes := []rune(s)
+	var pairs []Pair
+	for i := 0; i+1 < len(runes); i += 2 {
+		pairs = append(pairs, Pair{Open: runes[i], Close: runes[i+1]})
+	}
+	return pairs
+}
+
+// AutoCloseHook is a hook that binds an *AutoClose to every file,
+// using the pairs configured for the file's extension.
+type AutoCloseHook struct{}
+
+func (AutoCloseHook) Name() string {
+	return "auto-close-hook"
+}
+
+func (AutoCloseHook) OpName() string {
+	return "focus-location"
+}
+
+func (AutoCloseHook) FileBindables(path string) []bind.Bindable {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	pairs, ok := setting.AutoClose(ext)
+	if !ok {
+		pairs, ok = languagePairs[ext]
+	}
+	if !ok {
+		pairs = defaultPairs
+	}
+	return []bind.Bindable{NewAutoClose(ParsePairs(pairs)...)}
+}
+
+// AutoClose is a PairCloser that inserts the closing rune of a pair
+// when the opening rune is typed, skips over closing runes that it
+// inserted when they are typed, and deletes both runes of an empty
+// pair on backspace.
+type AutoClose struct {
+	pairs []Pair
+
+	// inserted holds the closing runes that were inserted
+	// automatically, and pending holds the ones that will be
+	// inserted by the edits most recently returned from Typed.
+	inserted []input.Span
+	pending  []input.Span
+}
+
+// NewAutoClose returns an *AutoClose for pairs.
+func NewAutoClose(pairs ...Pair) *AutoClose {
+	return &AutoClose{pairs: pairs}
+}
+
+func (a *AutoClose) Name() string {
+	return "auto-close"
+}
+
+func (a *AutoClose) OpName() string {
+	return "input-handler"
+}
+
+func (a *AutoClose) Typed(text []rune, sels []gxui.TextSelection, ch rune) ([]input.Edit, []int) {
+	sels = append([]gxui.TextSelection(nil), sels...)
+	sort.Slice(sels, func(i, j int) bool {
+		return sels[i].Start() < sels[j].Start()
+	})
+	a.pending = nil
+	var (
+		edits   []input.Edit
+		carets  []int
+		delta   int
+		handled bool
+	)
+	for _, s := range sels {
+		start, end := s.Start(), s.End()
+		if start < 0 {
+			continue
+		}
+		if start == end && a.skips(text, start, ch) {
+			carets = append(carets, start+delta+1)
+			handled = true
+			continue
+		}
+		if close, ok := a.closes(text, start, end, ch); ok {
+			edits = append(edits, input.Edit{At: start, New: []rune{ch, close}})
+			a.pending = append(a.pending, input.Span{Start: start + delta + 1, End: start + delta + 2})
+			carets = append(carets, start+delta+1)
+			delta += 2
+			handled = true
+			continue
+		}
+		edits = append(edits, input.Edit{At: start, Old: text[start:end], New: []rune{ch}})
+		carets = append(carets, start+delta+1)
+		delta += 1 - (end - start)
+	}
+	if !handled {
+		return edits, nil
+	}
+	return edits, carets
+}
+
+func (a *AutoClose) Backspace(text []rune, caret int) (input.Edit, bool) {
+	if caret <= 0 || caret >= len(text) {
+		return input.Edit{}, false
+	}
+	for _, p := range a.pairs {
+		if text[caret-1] == p.Open && text[caret] == p.Close {
+			return input.Edit{At: caret - 1, Old: text[caret-1 : caret+1]}, true
+		}
+	}
+	return input.Edit{}, false
+}
+
+// Applied keeps track of the closing runes that a has inserted as
+// the text is edited.
+func (a *AutoClose) Applied(_ input.Editor, edits []input.Edit) {
+	inserted := a.inserted[:0]
+	for _, s := range a.inserted {
+		s = s.Move(edits)
+		if s.End-s.Start != 1 {
+			continue
+		}
+		inserted = append(inserted, s)
+	}
+	a.inserted = append(inserted, a.pending...)
+	a.pending = nil
+}
+
+// skips returns whether typing ch at pos should move past a closing
+// rune that a inserted instead of inserting a new one.  Closing runes
+// are only skipped once.
+func (a *AutoClose) skips(text []rune, pos int, ch rune) bool {
+	if pos >= len(text) || text[pos] != ch {
+		return false
+	}
+	for i, s := range a.inserted {
+		if s.Start == pos {
+			a.inserted = append(a.inserted[:i], a.inserted[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// closes returns the rune that should be inserted to close ch when
+// it is typed over text[start:end].  Pairs are only closed when there
+// is nothing selected and the caret is not directly before a word.
+// Pairs that open and close with the same rune (like quotes) are also
+// not closed directly after a word, to leave apostrophes alone.
+func (a *AutoClose) closes(text []rune, start, end int, ch rune) (rune, bool) {
+	if start != end {
+		return 0, false
+	}
+	for _, p := range a.pairs {
+		if p.Open != ch {
+			continue
+		}
+		if start < len(text) && !unicode.IsSpace(text[start]) && !a.isCloser(text[start]) {
+			return 0, false
+		}
+		if p.Open == p.Close && start > 0 && (isWord(text[start-1]) || text[start-1] == ch) {
+			return 0, false
+		}
+		if p.Open == p.Close && start < len(text) && text[start] == ch {
+			return 0, false
+		}
+		return p.Close, true
+	}
+	return 0, false
+}
+
+func (a *AutoClose) isCloser(r rune) bool {
+	for _, p := range a.pairs {
+		if p.Close == r {
+			return true
+		}
+	}
+	return false
+}
+
+func isWord(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,76 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package input_test
+
+import (
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/command/input"
+	cinput "github.com/nelsam/vidar/commander/input"
+)
+
+func carets(positions ...int) []gxui.TextSelection {
+	var sels []gxui.TextSelection
+	for _, p := range positions {
+		sels = append(sels, gxui.CreateTextSelection(p, p, false))
+	}
+	return sels
+}
+
+func TestAutoClose(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, *input.AutoClose) {
+		return expect.New(t), input.NewAutoClose(input.ParsePairs(`()""`)...)
+	})
+
+	o.Spec("it closes pairs at each caret with a single edit", func(expect expect.Expectation, a *input.AutoClose) {
+		edits, newCarets := a.Typed([]rune("a\nb"), carets(1, 3), '(')
+		expect(edits).To(Equal([]cinput.Edit{
+			{At: 1, New: []rune("()")},
+			{At: 3, New: []rune("()")},
+		}))
+		expect(newCarets).To(Equal([]int{2, 6}))
+	})
+
+	o.Spec("it skips over closers that it inserted", func(expect expect.Expectation, a *input.AutoClose) {
+		edits, _ := a.Typed([]rune("f"), carets(1), '(')
+		a.Applied(nil, edits)
+		edits, newCarets := a.Typed([]rune("f()"), carets(2), ')')
+		expect(edits).To(HaveLen(0))
+		expect(newCarets).To(Equal([]int{3}))
+	})
+
+	o.Spec("it does not skip closers that were typed", func(expect expect.Expectation, a *input.AutoClose) {
+		edits, newCarets := a.Typed([]rune("f()"), carets(2), ')')
+		expect(edits).To(Equal([]cinput.Edit{{At: 2, Old: []rune{}, New: []rune(")")}}))
+		expect(newCarets).To(BeNil())
+	})
+
+	o.Spec("it does not close pairs before a word", func(expect expect.Expectation, a *input.AutoClose) {
+		edits, newCarets := a.Typed([]rune("foo"), carets(0), '(')
+		expect(edits).To(Equal([]cinput.Edit{{At: 0, Old: []rune{}, New: []rune("(")}}))
+		expect(newCarets).To(BeNil())
+	})
+
+	o.Spec("it does not close quotes after a word", func(expect expect.Expectation, a *input.AutoClose) {
+		_, newCarets := a.Typed([]rune("don"), carets(3), '"')
+		expect(newCarets).To(BeNil())
+	})
+
+	o.Spec("it deletes empty pairs on backspace", func(expect expect.Expectation, a *input.AutoClose) {
+		edit, ok := a.Backspace([]rune("f()"), 2)
+		expect(ok).To(BeTrue())
+		expect(edit).To(Equal(cinput.Edit{At: 1, Old: []rune("()")}))
+
+		_, ok = a.Backspace([]rune("f(x)"), 2)
+		expect(ok).To(BeFalse())
+	})
+}
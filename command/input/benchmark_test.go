@@ -0,0 +1,186 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package input_test
+
+import (
+	"image"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/gxui/math"
+	"github.com/nelsam/gxui/themes/basic"
+	"github.com/nelsam/vidar/command/caret"
+	"github.com/nelsam/vidar/command/fold"
+	"github.com/nelsam/vidar/command/history"
+	"github.com/nelsam/vidar/command/input"
+	"github.com/nelsam/vidar/command/scroll"
+	"github.com/nelsam/vidar/commander/bind"
+	cinput "github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/editor"
+	"github.com/nelsam/vidar/theme"
+)
+
+// benchDriver runs the functions passed to Call once the benchmark
+// is idle, the way that gxui runs them once the UI goroutine is free.
+type benchDriver struct {
+	mu     sync.Mutex
+	queued []func()
+}
+
+func (d *benchDriver) Call(f func()) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queued = append(d.queued, f)
+	return true
+}
+
+func (d *benchDriver) CallSync(f func()) bool {
+	f()
+	return true
+}
+
+// idle runs the queued functions, including any that they queue.
+func (d *benchDriver) idle() {
+	for {
+		d.mu.Lock()
+		if len(d.queued) == 0 {
+			d.mu.Unlock()
+			return
+		}
+		f := d.queued[0]
+		d.queued = d.queued[1:]
+		d.mu.Unlock()
+		f()
+	}
+}
+
+func (d *benchDriver) Terminate()                                              {}
+func (d *benchDriver) SetClipboard(string)                                     {}
+func (d *benchDriver) GetClipboard() (string, error)                           { return "", nil }
+func (d *benchDriver) CreateFont([]byte, int) (gxui.Font, error)               { return benchFont{}, nil }
+func (d *benchDriver) CreateWindowedViewport(int, int, string) gxui.Viewport   { return nil }
+func (d *benchDriver) CreateFullscreenViewport(int, int, string) gxui.Viewport { return nil }
+func (d *benchDriver) CreateCanvas(math.Size) gxui.Canvas                      { return nil }
+func (d *benchDriver) CreateTexture(image.Image, float32) gxui.Texture         { return nil }
+func (d *benchDriver) AssertUIGoroutine()                                      {}
+
+// benchFont is a monospace font that lays out runes without loading
+// any glyphs.
+type benchFont struct{}
+
+func (benchFont) Index(rune) int                      { return 0 }
+func (benchFont) LoadGlyphs(first, last rune)         {}
+func (benchFont) Size() int                           { return 12 }
+func (benchFont) GlyphMaxSize() math.Size             { return math.Size{W: 8, H: 12} }
+func (benchFont) Measure(b *gxui.TextBlock) math.Size { return math.Size{W: 8 * len(b.Runes), H: 12} }
+func (benchFont) Layout(b *gxui.TextBlock) []math.Point {
+	offsets := make([]math.Point, len(b.Runes))
+	for i := range offsets {
+		offsets[i] = math.Point{X: 8 * i}
+	}
+	return offsets
+}
+
+// benchCommander executes bindables against a single editor, storing
+// the editor and its elements the way that vidar's commander does.
+type benchCommander struct {
+	editor    *editor.CodeEditor
+	bindables map[string]bind.Bindable
+}
+
+func (c *benchCommander) Bindable(name string) bind.Bindable {
+	return c.bindables[name]
+}
+
+func (c *benchCommander) Execute(b bind.Bindable) {
+	if s, ok := b.(interface{ Store(interface{}) bind.Status }); ok {
+		s.Store(c.editor)
+		for _, elem := range c.editor.Elements() {
+			s.Store(elem)
+		}
+	}
+	if e, ok := b.(interface{ Exec() error }); ok {
+		e.Exec()
+	}
+}
+
+// benchEditor returns an editor that has loaded a large file and an
+// input handler for it with the hooks that run when text is typed.
+func benchEditor(b *testing.B) (*benchDriver, *editor.CodeEditor, cinput.Handler) {
+	dir, err := ioutil.TempDir("", "vidar-bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { os.RemoveAll(dir) })
+
+	// The editor's file watcher keeps running, and logs errors once
+	// dir is removed.  Benchmarks run after the package's tests, so
+	// their logs are unaffected.
+	log.SetOutput(ioutil.Discard)
+	path := filepath.Join(dir, "bench.go")
+	text := strings.Repeat("\tfoo := bar(baz, \"qux\") // some generated code\n", 80000)
+	if err := ioutil.WriteFile(path, []byte(text), 0600); err != nil {
+		b.Fatal(err)
+	}
+
+	d := &benchDriver{}
+	cmdr := &benchCommander{bindables: make(map[string]bind.Bindable)}
+	e := &editor.CodeEditor{}
+	e.Init(d, cmdr, &basic.Theme{DriverInfo: d}, theme.Theme{}, benchFont{}, path, "")
+	d.idle()
+	cmdr.editor = e
+
+	mover, err := (&caret.Mover{}).Bind(fold.OnChange{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	cmdr.bindables["caret-movement"] = mover
+	cmdr.bindables["scroll"] = &scroll.Scroller{}
+
+	var h cinput.Handler = input.New(d, cmdr)
+	for _, hook := range []bind.Bindable{
+		history.Bindables(nil, nil, nil)[0],
+		fold.OnChange{},
+		&caret.OnEdit{Commander: cmdr},
+		&scroll.OnEdit{Commander: cmdr},
+	} {
+		h, err = h.Bind(hook)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	return d, e, h
+}
+
+// BenchmarkApply measures typing a rune in the middle of a 4MB file
+// through the input handler, with the hooks that run on every edit.
+// The text box isn't brought up to date, so this is the time that
+// typing waits for.
+func BenchmarkApply(b *testing.B) {
+	_, e, h := benchEditor(b)
+	at := e.Snapshot().Len() / 2
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Apply(e, cinput.Edit{At: at + i%1000, New: []rune{'x'}})
+	}
+}
+
+// BenchmarkApplyIdle is BenchmarkApply, followed by bringing the text
+// box up to date once the UI goroutine is free.
+func BenchmarkApplyIdle(b *testing.B) {
+	d, e, h := benchEditor(b)
+	at := e.Snapshot().Len() / 2
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Apply(e, cinput.Edit{At: at + i%1000, New: []rune{'x'}})
+		d.idle()
+	}
+}
@@ -24,7 +24,8 @@ type ContextChangeHook interface {
 	// TextChanged is called in a new goroutine whenever any text
 	// is changed in the editor.  Any changes to the UI should be
 	// saved for Apply, since most of those calls must be called
-	// in the UI goroutine.
+	// in the UI goroutine.  For the same reason, the editor's text
+	// should be read with input.Snapshot.
 	//
 	// If TextChanged is currently running and new edits come
 	// through, the context.Context will be cancelled and
@@ -14,6 +14,7 @@ import (
 	"github.com/nelsam/vidar/commander/bind"
 	"github.com/nelsam/vidar/commander/input"
 	"github.com/nelsam/vidar/editor"
+	"github.com/nelsam/vidar/theme"
 )
 
 type Binder interface {
@@ -21,6 +22,11 @@ type Binder interface {
 	Execute(bind.Bindable)
 }
 
+// Mover is a type that can move carets.
+type Mover interface {
+	To(...int) bind.Bindable
+}
+
 type textChangeHook interface {
 	init(input.Editor, []rune)
 	textChanged(input.Editor, []input.Edit) error
@@ -45,6 +51,9 @@ type Handler struct {
 	applied    []AppliedChangeHook
 	cancellers []Canceler
 	confirmers []Confirmer
+	tabbers    []Tabber
+	closer     PairCloser
+	indenter   Indenter
 }
 
 func New(d gxui.Driver, b Binder) *Handler {
@@ -65,6 +74,9 @@ func (e *Handler) Bind(b bind.Bindable) (input.Handler, error) {
 	newH.applied = append(newH.applied, e.applied...)
 	newH.cancellers = append(newH.cancellers, e.cancellers...)
 	newH.confirmers = append(newH.confirmers, e.confirmers...)
+	newH.tabbers = append(newH.tabbers, e.tabbers...)
+	newH.closer = e.closer
+	newH.indenter = e.indenter
 
 	didBind := false
 	if c, isCanceler := b.(Canceler); isCanceler {
@@ -77,6 +89,21 @@ func (e *Handler) Bind(b bind.Bindable) (input.Handler, error) {
 		didBind = true
 	}
 
+	if t, ok := b.(Tabber); ok {
+		newH.tabbers = append(newH.tabbers, t)
+		didBind = true
+	}
+
+	if c, ok := b.(PairCloser); ok {
+		didBind = true
+		newH.closer = c
+	}
+
+	if i, ok := b.(Indenter); ok {
+		didBind = true
+		newH.indenter = i
+	}
+
 	if a, ok := b.(AppliedChangeHook); ok {
 		didBind = true
 		newH.applied = append(newH.applied, a)
@@ -107,8 +134,9 @@ func (e *Handler) Init(newEditor input.Editor, contents []rune) {
 
 func (h *Handler) Apply(e input.Editor, edits ...input.Edit) {
 	editor := e.(*editor.CodeEditor)
-	c := editor.Controller()
-	text := c.TextRunes()
+	if editor.ReadOnly() {
+		return
+	}
 	delta := 0
 	sort.Slice(edits, func(i, j int) bool {
 		return edits[i].At < edits[j].At
@@ -121,26 +149,21 @@ func (h *Handler) Apply(e input.Editor, edits ...input.Edit) {
 		edit.New = clone(edit.New)
 		edit.At += delta
 		edits[i] = edit
-
-		oldE := edit.At + len(edit.Old)
-		newE := edit.At + len(edit.New)
-		if oldE != newE {
-			if newE > oldE {
-				text = append(text, make([]rune, newE-oldE)...)
-			}
-			if newE < len(text) {
-				copy(text[newE:], text[oldE:])
-			}
-			if oldE > newE {
-				text = text[:len(text)-(oldE-newE)]
-			}
-		}
-		copy(text[edit.At:newE], edit.New)
-		delta += newE - oldE
+		delta += len(edit.New) - len(edit.Old)
 	}
-	c.Deselect(false)
 	h.driver.CallSync(func() {
-		c.SetTextRunesNoEvent(text)
+		doc := editor.Snapshot()
+		from := doc.Len()
+		if len(edits) > 0 {
+			from = edits[0].At
+		}
+		for _, edit := range edits {
+			doc = doc.Replace(edit.At, len(edit.Old), edit.New)
+		}
+		// Deselecting doesn't depend on the text, so there's no need
+		// to wait for the text box to catch up with doc first.
+		editor.CodeEditor.Controller().Deselect(false)
+		editor.SetDocument(doc, from)
 		h.textEdited(e, edits)
 	})
 }
@@ -159,18 +182,20 @@ func (e *Handler) HandleEvent(focused input.Editor, ev gxui.KeyboardEvent) {
 				return
 			}
 		}
-		var edits []input.Edit
-		for _, s := range editor.Controller().SelectionSlice() {
-			if s.Start() < 0 {
-				continue
+		e.newline(focused, ctrl)
+	case gxui.KeyTab:
+		back := ev.Modifier.Shift()
+		for _, t := range e.tabbers {
+			if t.Tab(focused, back) {
+				return
 			}
-			edits = append(edits, input.Edit{
-				At:  s.Start(),
-				Old: ctrl.TextRunes()[s.Start():s.End()],
-				New: []rune{'\n'},
-			})
 		}
-		e.Apply(focused, edits...)
+		// TODO: Gain knowledge about scope, so we know how much to indent.
+		if back {
+			ctrl.UnindentSelection()
+			return
+		}
+		ctrl.IndentSelection()
 	case gxui.KeyEscape:
 		for _, c := range e.cancellers {
 			if c.Cancel(focused) {
@@ -195,6 +220,12 @@ func (e *Handler) HandleEvent(focused input.Editor, ev gxui.KeyboardEvent) {
 				Old: ctrl.TextRunes()[s.Start():s.End()],
 			}
 			if s.Start() == s.End() {
+				if ev.Key == gxui.KeyBackspace && e.closer != nil {
+					if pairEdit, ok := e.closer.Backspace(ctrl.TextRunes(), s.Start()); ok {
+						edits = append(edits, pairEdit)
+						continue
+					}
+				}
 				if ev.Key == gxui.KeyBackspace {
 					if edit.At == 0 {
 						continue
@@ -215,15 +246,124 @@ func (e *Handler) HandleInput(focused input.Editor, ev gxui.KeyStrokeEvent) {
 	}
 	editor := focused.(*editor.CodeEditor)
 	ctrl := editor.Controller()
-	var edits []input.Edit
-	for _, s := range editor.Controller().SelectionSlice() {
+	text := ctrl.TextRunes()
+	var (
+		edits  []input.Edit
+		carets []int
+	)
+	if e.closer != nil {
+		edits, carets = e.closer.Typed(text, ctrl.SelectionSlice(), ev.Character)
+	} else {
+		for _, s := range ctrl.SelectionSlice() {
+			edits = append(edits, input.Edit{
+				At:  s.Start(),
+				Old: text[s.Start():s.End()],
+				New: []rune{ev.Character},
+			})
+		}
+	}
+	if e.indenter != nil && carets == nil {
+		// Any carets returned from the PairCloser would be thrown
+		// off by changing the indentation, so lines are only
+		// dedented when the carets move as usual.
+		e.dedent(focused, text, edits)
+	}
+	if len(edits) > 0 {
+		e.Apply(focused, edits...)
+	}
+	if carets != nil {
+		e.moveCarets(carets)
+	}
+}
+
+// newline inserts a new line at each selection, using e.indenter to
+// indent the new lines if it is set.
+func (e *Handler) newline(focused input.Editor, ctrl *gxui.TextBoxController) {
+	text := ctrl.TextRunes()
+	sels := append([]gxui.TextSelection(nil), ctrl.SelectionSlice()...)
+	sort.Slice(sels, func(i, j int) bool {
+		return sels[i].Start() < sels[j].Start()
+	})
+	var (
+		edits  []input.Edit
+		carets []int
+		delta  int
+		move   bool
+	)
+	for _, s := range sels {
+		if s.Start() < 0 {
+			continue
+		}
+		insert, caret := []rune{'\n'}, 1
+		if e.indenter != nil {
+			insert, caret = e.indenter.Newline(text, s.Start(), s.End())
+		}
 		edits = append(edits, input.Edit{
 			At:  s.Start(),
-			Old: ctrl.TextRunes()[s.Start():s.End()],
-			New: []rune{ev.Character},
+			Old: text[s.Start():s.End()],
+			New: insert,
 		})
+		carets = append(carets, s.Start()+delta+caret)
+		delta += len(insert) - s.Length()
+		move = move || caret != len(insert)
 	}
 	e.Apply(focused, edits...)
+	if move {
+		e.moveCarets(carets)
+	}
+}
+
+// dedent replaces any edits that insert a single rune at the end of
+// a blank line with edits that also change the line's indentation,
+// if e.indenter says that the rune should change it.
+func (e *Handler) dedent(focused input.Editor, text []rune, edits []input.Edit) {
+	var ignore []input.Span
+	for i, edit := range edits {
+		if len(edit.Old) != 0 || len(edit.New) != 1 {
+			continue
+		}
+		start := lineStart(text, edit.At)
+		if len(leadingSpace(text[start:edit.At])) != edit.At-start {
+			continue
+		}
+		if ignore == nil {
+			ignore = ignoredSpans(focused)
+		}
+		indent, ok := e.indenter.Dedent(text, edit.At, edit.New[0], ignore)
+		if !ok {
+			continue
+		}
+		edits[i] = input.Edit{
+			At:  start,
+			Old: text[start:edit.At],
+			New: append(indent, edit.New[0]),
+		}
+	}
+}
+
+// ignoredSpans returns the spans of the strings and comments in
+// focused's syntax layers, sorted by their Start, so that brackets in
+// them are ignored when indenting.
+func ignoredSpans(focused input.Editor) []input.Span {
+	ignore := []input.Span{}
+	for _, l := range focused.SyntaxLayers() {
+		if l.Construct == theme.String || l.Construct == theme.Comment {
+			ignore = append(ignore, l.Spans...)
+		}
+	}
+	sort.Slice(ignore, func(i, j int) bool {
+		return ignore[i].Start < ignore[j].Start
+	})
+	return ignore
+}
+
+func (e *Handler) moveCarets(carets []int) {
+	m, ok := e.binder.Bindable("caret-movement").(Mover)
+	if !ok {
+		log.Printf("Error: caret-movement is not a Mover; carets will not be moved")
+		return
+	}
+	e.binder.Execute(m.To(carets...))
 }
 
 func (e *Handler) textEdited(focused input.Editor, edits []input.Edit) {
@@ -0,0 +1,230 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package input
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/commander/input"
+)
+
+// languageIndents holds the indentation rules for each language that
+// vidar knows about, keyed by file extension.  Files with other
+// extensions use defaultIndent.
+var languageIndents = map[string]*BlockIndent{
+	"go":   {Unit: "\t", Open: "{([:", Close: "})]"},
+	"py":   {Unit: "    ", Open: "{([:", Close: "})]"},
+	"yaml": {Unit: "  "},
+	"yml":  {Unit: "  "},
+}
+
+var defaultIndent = BlockIndent{Open: "{([", Close: "})]"}
+
+// unitScanLimit is how many runes at the start of a file are searched
+// for an indented line when detecting the indentation unit.
+const unitScanLimit = 64 * 1024
+
+// An Indenter is a hook that indents lines as they are typed.  Only
+// the most recently bound Indenter will be used.
+type Indenter interface {
+	// Newline returns the text that should replace text[start:end]
+	// when enter is pressed, along with the offset in that text that
+	// the caret should be moved to.
+	Newline(text []rune, start, end int) (insert []rune, caret int)
+
+	// Dedent returns the indentation that the line containing pos
+	// should have when ch is typed at pos.  It will only be called
+	// when there is nothing but whitespace between the start of the
+	// line and pos.  If ok is false, the indentation is left alone.
+	//
+	// ignore holds the spans of text, like strings and comments,
+	// that don't open or close blocks, sorted by their Start.
+	Dedent(text []rune, pos int, ch rune, ignore []input.Span) (indent []rune, ok bool)
+}
+
+// AutoIndentHook is a hook that binds a *BlockIndent to every file,
+// using the rules for the file's extension.
+type AutoIndentHook struct{}
+
+func (AutoIndentHook) Name() string {
+	return "auto-indent-hook"
+}
+
+func (AutoIndentHook) OpName() string {
+	return "focus-location"
+}
+
+func (AutoIndentHook) FileBindables(path string) []bind.Bindable {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	rules, ok := languageIndents[ext]
+	if !ok {
+		rules = &defaultIndent
+	}
+	i := *rules
+	return []bind.Bindable{&i}
+}
+
+// BlockIndent is an Indenter for languages that use brackets (or
+// similar runes) to open and close blocks.  New lines keep the
+// indentation of the line before them, with an extra level after any
+// rune in Open.  Typing a rune in Close on a blank line moves it back
+// to the indentation of the line that opened the block.
+type BlockIndent struct {
+	// Unit is the text used for a single level of indentation.  If
+	// it is empty, it will be detected from the text, falling back
+	// to a tab.
+	Unit string
+
+	// Open is the list of runes that open a block when they are the
+	// last rune on a line.
+	Open string
+
+	// Close is the list of runes that close a block.
+	Close string
+
+	// detected is the indentation unit detected from the text, if
+	// Unit is empty.
+	detected string
+}
+
+func (i *BlockIndent) Name() string {
+	return "auto-indent"
+}
+
+func (i *BlockIndent) OpName() string {
+	return "input-handler"
+}
+
+func (i *BlockIndent) Newline(text []rune, start, end int) ([]rune, int) {
+	lineStart := lineStart(text, start)
+	indent := leadingSpace(text[lineStart:start])
+	before := []rune(strings.TrimRightFunc(string(text[lineStart:start]), unicode.IsSpace))
+	if len(before) == 0 || !strings.ContainsRune(i.Open, before[len(before)-1]) {
+		insert := append([]rune{'\n'}, indent...)
+		return insert, len(insert)
+	}
+	inner := append(append([]rune{'\n'}, indent...), []rune(i.unit(text))...)
+	if end < len(text) && strings.ContainsRune(i.Close, text[end]) {
+		// The caret is between an opening and closing rune, so the
+		// closing rune gets its own line.
+		insert := append(append(append([]rune(nil), inner...), '\n'), indent...)
+		return insert, len(inner)
+	}
+	return inner, len(inner)
+}
+
+func (i *BlockIndent) Dedent(text []rune, pos int, ch rune, ignore []input.Span) ([]rune, bool) {
+	if !strings.ContainsRune(i.Close, ch) {
+		return nil, false
+	}
+	width := pos - lineStart(text, pos)
+	if width == 0 {
+		// The line can't be dedented any further.
+		return nil, false
+	}
+	// skip is the last span in ignore that starts at or before the
+	// rune being checked.
+	skip := len(ignore) - 1
+	ignored := func(p int) (start int, ok bool) {
+		for skip >= 0 && ignore[skip].Start > p {
+			skip--
+		}
+		if skip >= 0 && p < ignore[skip].End {
+			return ignore[skip].Start, true
+		}
+		return 0, false
+	}
+	depth, floor := 0, width
+	for end := lineStart(text, pos) - 1; end >= 0; {
+		start := lineStart(text, end)
+		for p := end - 1; p >= start; p-- {
+			if spanStart, ok := ignored(p); ok {
+				p = spanStart
+				continue
+			}
+			switch {
+			case strings.ContainsRune(i.Close, text[p]):
+				depth++
+			case strings.ContainsRune(i.Open, text[p]) && text[p] != ':':
+				// Colons open blocks (like case clauses) that are
+				// never closed by a rune, so they can't be matched.
+				if depth > 0 {
+					depth--
+					continue
+				}
+				return leadingSpace(text[start:p]), true
+			}
+		}
+		// The lines in a block are indented at least as much as
+		// the line that opened it, so the search ends at the first
+		// line that is indented less than a shallower line that
+		// came after it (like a case clause), or not at all.
+		// Blank lines and lines that start inside of a string or
+		// comment don't count.
+		indent := leadingSpace(text[start:end])
+		if _, ok := ignored(start); !ok && len(indent) < end-start {
+			switch {
+			case len(indent) == 0, len(indent) < floor && floor < width:
+				return nil, false
+			case len(indent) < floor:
+				floor = len(indent)
+			}
+		}
+		end = start - 1
+	}
+	return nil, false
+}
+
+// unit returns the text for a single level of indentation in text.
+// If i.Unit is empty, the indentation of the first indented line
+// near the start of text is used, and remembered once it is found.
+func (i *BlockIndent) unit(text []rune) string {
+	if i.Unit != "" {
+		return i.Unit
+	}
+	if i.detected != "" {
+		return i.detected
+	}
+	if len(text) > unitScanLimit {
+		text = text[:unitScanLimit]
+	}
+	for start := 0; start < len(text); {
+		end := start
+		for end < len(text) && text[end] != '\n' {
+			end++
+		}
+		line := text[start:end]
+		start = end + 1
+		indent := leadingSpace(line)
+		if len(indent) == 0 || len(indent) == len(line) {
+			continue
+		}
+		if indent[0] == '\t' {
+			i.detected = "\t"
+		} else {
+			i.detected = string(indent)
+		}
+		return i.detected
+	}
+	return "\t"
+}
+
+func lineStart(text []rune, pos int) int {
+	for pos > 0 && text[pos-1] != '\n' {
+		pos--
+	}
+	return pos
+}
+
+func leadingSpace(line []rune) []rune {
+	end := 0
+	for end < len(line) && (line[end] == ' ' || line[end] == '\t') {
+		end++
+	}
+	return append([]rune(nil), line[:end]...)
+}
@@ -0,0 +1,107 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package input_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/command/input"
+	cinput "github.com/nelsam/vidar/commander/input"
+)
+
+func TestBlockIndent(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, *input.BlockIndent) {
+		return expect.New(t), &input.BlockIndent{Unit: "\t", Open: "{(:", Close: "})"}
+	})
+
+	o.Spec("it keeps the indentation of the current line", func(expect expect.Expectation, i *input.BlockIndent) {
+		text := []rune("\t\tfoo()")
+		insert, caret := i.Newline(text, len(text), len(text))
+		expect(string(insert)).To(Equal("\n\t\t"))
+		expect(caret).To(Equal(3))
+	})
+
+	o.Spec("it indents after an opening rune", func(expect expect.Expectation, i *input.BlockIndent) {
+		text := []rune("\tswitch x {\n\tcase 1:")
+		insert, _ := i.Newline(text, len(text), len(text))
+		expect(string(insert)).To(Equal("\n\t\t"))
+
+		text = []rune("\tif x { ")
+		insert, _ = i.Newline(text, len(text), len(text))
+		expect(string(insert)).To(Equal("\n\t\t"))
+	})
+
+	o.Spec("it moves the closing rune to its own line", func(expect expect.Expectation, i *input.BlockIndent) {
+		text := []rune("\tif x {}")
+		insert, caret := i.Newline(text, 7, 7)
+		expect(string(insert)).To(Equal("\n\t\t\n\t"))
+		expect(caret).To(Equal(3))
+	})
+
+	o.Spec("it dedents closing runes to the line that opened the block", func(expect expect.Expectation, i *input.BlockIndent) {
+		text := []rune("\tswitch x {\n\tcase 1:\n\t\tfoo()\n\t\t")
+		indent, ok := i.Dedent(text, len(text), '}', nil)
+		expect(ok).To(BeTrue())
+		expect(string(indent)).To(Equal("\t"))
+
+		_, ok = i.Dedent(text, len(text), 'x', nil)
+		expect(ok).To(BeFalse())
+	})
+
+	o.Spec("it ignores closing runes in strings and comments", func(expect expect.Expectation, i *input.BlockIndent) {
+		text := []rune("\tif x {\n\t\tfoo(\"{\") // )\n\t\t")
+		str := strings.Index(string(text), `"{"`)
+		comment := strings.Index(string(text), "//")
+		ignore := []cinput.Span{{Start: str, End: str + 3}, {Start: comment, End: comment + 4}}
+		indent, ok := i.Dedent(text, len(text), '}', ignore)
+		expect(ok).To(BeTrue())
+		expect(string(indent)).To(Equal("\t"))
+	})
+
+	o.Spec("it stops looking for the opening rune once lines are indented less than the block", func(expect expect.Expectation, i *input.BlockIndent) {
+		text := []rune("foo {\nbar\n\t\tbaz\n\t\t")
+		_, ok := i.Dedent(text, len(text), '}', nil)
+		expect(ok).To(BeFalse())
+
+		text = []rune("\tfoo {\n\tbar\n\t\tbaz\n\t\t\tqux\n\t\t\t")
+		_, ok = i.Dedent(text, len(text), '}', nil)
+		expect(ok).To(BeFalse())
+
+		text = []rune("foo {\n\tbar\n\t")
+		indent, ok := i.Dedent(text, len(text), '}', nil)
+		expect(ok).To(BeTrue())
+		expect(string(indent)).To(Equal(""))
+
+		text = []rune("foo {\n}\n")
+		_, ok = i.Dedent(text, len(text), '}', nil)
+		expect(ok).To(BeFalse())
+	})
+
+	o.Spec("it detects the indentation unit when none is set", func(expect expect.Expectation, i *input.BlockIndent) {
+		i.Unit = ""
+		text := []rune("a {\n  b {")
+		insert, _ := i.Newline(text, len(text), len(text))
+		expect(string(insert)).To(Equal("\n    "))
+
+		// The unit is only detected once.
+		text = []rune("a {\n\tb {")
+		insert, _ = i.Newline(text, len(text), len(text))
+		expect(string(insert)).To(Equal("\n\t  "))
+	})
+
+	o.Spec("it only detects the indentation unit near the start of the text", func(expect expect.Expectation, i *input.BlockIndent) {
+		i.Unit = ""
+		text := []rune(strings.Repeat("a\n", 64*1024) + "  b {")
+		insert, _ := i.Newline(text, len(text), len(text))
+		expect(string(insert)).To(Equal("\n  \t"))
+	})
+}
@@ -0,0 +1,18 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package input
+
+import "github.com/nelsam/vidar/commander/input"
+
+// Tabber is a type that needs to know when tab is pressed, like a
+// snippet moving between its tab stops.  The input handler calls all
+// tabbers when tab (or shift-tab) is pressed, and only indents the
+// selection if none of them consume the event.
+type Tabber interface {
+	// Tab is called when tab is pressed.  back will be true if
+	// shift is held.  It should return true if it should consume
+	// the tab event.
+	Tab(e input.Editor, back bool) (consumed bool)
+}
@@ -140,7 +140,7 @@ func (n NavHook) OpName() string {
 }
 
 func (n NavHook) FileBindables(string) []bind.Bindable {
-	return []bind.Bindable{
+	b := []bind.Bindable{
 		&caret.OnEdit{Commander: n.Commander},
 		&scroll.OnEdit{Commander: n.Commander},
 		NewPrevLine(),
@@ -159,7 +159,11 @@ func (n NavHook) FileBindables(string) []bind.Bindable {
 		NewSelectLineStart(),
 		NewLineEnd(),
 		NewSelectLineEnd(),
+		caret.NewAddCaret(caret.Up),
+		caret.NewAddCaret(caret.Down),
 	}
+	b = append(b, caret.NewOccurrenceCommands()...)
+	return append(b, caret.NewBlockCommands()...)
 }
 
 func NewPrevLine() bind.Command {
@@ -14,7 +14,9 @@ import (
 	"github.com/nelsam/vidar/commander/bind"
 	"github.com/nelsam/vidar/commander/input"
 	"github.com/nelsam/vidar/plugin/status"
+	"github.com/nelsam/vidar/safefile"
 	"github.com/nelsam/vidar/setting"
+	"github.com/nelsam/vidar/textfile"
 )
 
 type Applier interface {
@@ -27,15 +29,43 @@ type SaveEditor interface {
 	LastKnownMTime() time.Time
 }
 
+// DiskChecker is an editor that can check whether its file was
+// changed on disk and let the user decide what to do about it.
+type DiskChecker interface {
+	CheckDisk() (changed bool)
+}
+
+// FormatEditor is an editor that knows the encoding and line endings
+// that its file is saved with.
+type FormatEditor interface {
+	FileFormat() textfile.Format
+}
+
+// ReadOnlyEditor is an editor that may refuse edits.
+type ReadOnlyEditor interface {
+	ReadOnly() bool
+}
+
 type Projecter interface {
 	Project() setting.Project
 }
 
+// A BeforeSaver is a hook that can modify a file's contents before
+// it is saved.  If BeforeSave returns an error along with non-empty
+// newContents, the error is displayed but newContents are still
+// used.
 type BeforeSaver interface {
 	Name() string
 	BeforeSave(proj setting.Project, path, contents string) (newContents string, err error)
 }
 
+// A Blocker is an error returned from a BeforeSaver that may need to
+// prevent the file from being saved.
+type Blocker interface {
+	error
+	Blocking() bool
+}
+
 type AfterSaver interface {
 	Name() string
 	AfterSave(proj setting.Project, path, contents string) error
@@ -112,6 +142,10 @@ func (s *SaveCurrent) Store(target interface{}) bind.Status {
 
 func (s *SaveCurrent) Exec() error {
 	filepath := s.editor.Filepath()
+	if ro, ok := s.editor.(ReadOnlyEditor); ok && ro.ReadOnly() {
+		s.Warn = fmt.Sprintf("%s is read-only", filepath)
+		return nil
+	}
 	if !s.editor.LastKnownMTime().IsZero() {
 		finfo, err := os.Stat(filepath)
 		if err != nil {
@@ -119,24 +153,41 @@ func (s *SaveCurrent) Exec() error {
 			return err
 		}
 		if finfo.ModTime().After(s.editor.LastKnownMTime()) {
-			// TODO: prompt for override
-			s.Err = fmt.Sprintf("File %s changed on disk.  Cowardly refusing to overwrite.", filepath)
-			return err
+			checker, ok := s.editor.(DiskChecker)
+			if !ok {
+				s.Err = fmt.Sprintf("File %s changed on disk.  Cowardly refusing to overwrite.", filepath)
+				return nil
+			}
+			if checker.CheckDisk() {
+				s.Warn = fmt.Sprintf("File %s changed on disk and was not saved.", filepath)
+				return nil
+			}
 		}
 	}
 
+	format := textfile.Default
+	if f, ok := s.editor.(FormatEditor); ok {
+		format = f.FileFormat()
+	}
+
 	text := s.editor.Text()
 	formatted := text
-	if !strings.HasSuffix(formatted, "\n") {
+	if format.FinalNewline && !strings.HasSuffix(formatted, "\n") {
 		formatted += "\n"
 	}
 
 	proj := *s.proj
 	for _, b := range s.before {
-		newText, err := b.BeforeSave(proj, filepath, text)
+		newText, err := b.BeforeSave(proj, filepath, formatted)
 		if err != nil {
+			if blocker, ok := err.(Blocker); ok && blocker.Blocking() {
+				s.Err = fmt.Sprintf("%s: %s", b.Name(), err)
+				return err
+			}
 			s.Warn += fmt.Sprintf("%s: %s  ", b.Name(), err)
-			continue
+			if newText == "" {
+				continue
+			}
 		}
 		formatted = newText
 	}
@@ -150,25 +201,26 @@ func (s *SaveCurrent) Exec() error {
 		text = formatted
 	}
 
-	f, err := os.Create(filepath)
+	data, err := textfile.Encode(text, format)
 	if err != nil {
-		s.Err = fmt.Sprintf("Could not open %s for writing: %s", filepath, err)
+		s.Err = fmt.Sprintf("Could not save %s: %s", filepath, err)
 		return err
 	}
-	defer func() {
-		f.Close()
-		for _, a := range s.after {
-			if err := a.AfterSave(proj, filepath, text); err != nil {
-				s.Warn += fmt.Sprintf("%s: %s  ", a.Name(), err)
-			}
+	if backup := setting.Backups(); backup.Dir != "" {
+		if err := safefile.Backup(filepath, backup.Dir, backup.Count); err != nil {
+			s.Warn += fmt.Sprintf("Could not back up %s: %s  ", filepath, err)
 		}
-	}()
-
-	if _, err := f.WriteString(text); err != nil {
+	}
+	if err := safefile.Write(filepath, data); err != nil {
 		s.Err = fmt.Sprintf("Could not write to file %s: %s", filepath, err)
 		return err
 	}
 	s.Info = fmt.Sprintf("Successfully saved %s", filepath)
 	s.editor.FlushedChanges()
+	for _, a := range s.after {
+		if err := a.AfterSave(proj, filepath, text); err != nil {
+			s.Warn += fmt.Sprintf("%s: %s  ", a.Name(), err)
+		}
+	}
 	return nil
 }
@@ -71,13 +71,31 @@ func (b *commandBox) Finish() {
 		b.Clear()
 		return
 	}
-	b.status = statuser.Status()
-	if b.status == nil {
+	b.showStatus(statuser.Status())
+}
+
+// ShowStatus displays status in b, unless b is currently waiting on
+// input for a command.  A nil status clears any status that is
+// being displayed.
+func (b *commandBox) ShowStatus(status gxui.Control) {
+	if b.input != nil {
+		return
+	}
+	b.showStatus(status)
+}
+
+func (b *commandBox) showStatus(status gxui.Control) {
+	if b.statusTimer != nil {
+		b.statusTimer.Stop()
+	}
+	if status == nil {
 		b.Clear()
 		return
 	}
 	b.clearDisplay()
 	b.clearInput()
+	b.clearStatus()
+	b.status = status
 	b.AddChild(b.status)
 	b.statusTimer = time.AfterFunc(maxStatusAge, func() {
 		b.driver.CallSync(func() {
@@ -360,6 +360,14 @@ func (c *Commander) Execute(e bind.Bindable) {
 	}
 }
 
+// ShowStatus displays status in the command box the same way that
+// a Statuser's status is displayed after it runs.  This allows hooks,
+// which are not run as commands, to display their status.  It must
+// be called on the UI thread.
+func (c *Commander) ShowStatus(status gxui.Control) {
+	c.box.ShowStatus(status)
+}
+
 func (c *Commander) Elements() []interface{} {
 	all := make([]interface{}, 0, len(c.bound))
 	for _, binding := range c.bound {
@@ -4,6 +4,8 @@
 
 package input
 
+import "github.com/nelsam/vidar/piece"
+
 // Editor is the local set of methods that the Editor type passed
 // to InputHandlers are guaranteed to have.  Other methods may be
 // accessed with type assertions, but we pass the Editor type
@@ -22,3 +24,21 @@ type Editor interface {
 	SyntaxLayers() []SyntaxLayer
 	SetSyntaxLayers([]SyntaxLayer)
 }
+
+// Snapshotter is an Editor that keeps an immutable copy of its text.
+// Snapshots are cheap to take and safe to read from any goroutine,
+// so hooks that read an editor's text in the background should use
+// Snapshot instead of Text or Runes.  Reading a snapshot with
+// piece.NewReader or its line methods avoids copying the text.
+type Snapshotter interface {
+	Snapshot() *piece.Table
+}
+
+// Snapshot returns a snapshot of e's text.  The text of editors that
+// aren't Snapshotters is copied.
+func Snapshot(e Editor) *piece.Table {
+	if s, ok := e.(Snapshotter); ok {
+		return s.Snapshot()
+	}
+	return piece.New(e.Runes())
+}
@@ -0,0 +1,23 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package input
+
+// FoldRegion is a region of text that may be folded (hidden) in an
+// editor.  Only the lines after the line containing Start are
+// hidden when a region is folded.
+type FoldRegion struct {
+	Span
+
+	// Level is the nesting level of the region.  Regions that
+	// are not inside of any other region have a Level of 1.
+	Level int
+}
+
+// Move returns r shifted to account for edits.  See Span.Move for
+// details.
+func (r FoldRegion) Move(edits []Edit) FoldRegion {
+	r.Span = r.Span.Move(edits)
+	return r
+}
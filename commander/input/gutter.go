@@ -0,0 +1,34 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package input
+
+import "github.com/nelsam/gxui"
+
+// GutterMark is a mark that is displayed beside a line number in an
+// editor's gutter.
+type GutterMark struct {
+	// Text is usually a single character.  Marks are padded to the
+	// width of the owner's widest mark, so that line numbers stay
+	// aligned.
+	Text  string
+	Color gxui.Color
+
+	// OnClick, if set, is called instead of the gutter's click
+	// callbacks when the mark is clicked.
+	OnClick func()
+}
+
+// Gutter is an editor that displays marks beside its line numbers
+// and reports clicks on them.
+type Gutter interface {
+	// SetGutterMarks replaces the marks that owner displays, keyed
+	// by line index.  Each owner gets its own column in the gutter;
+	// nil marks remove owner's column.
+	SetGutterMarks(owner string, marks map[int]GutterMark)
+
+	// OnGutterClick registers f to be called with the index of a
+	// line when its line number is clicked.
+	OnGutterClick(f func(line int))
+}
@@ -10,7 +10,53 @@ type Span struct {
 	Start, End int
 }
 
+// Move returns s shifted to account for edits, which must be
+// sorted by their At field.  It is intended to keep spans roughly
+// in place while a full update is processed in the background.
+func (s Span) Move(edits []Edit) Span {
+	for _, e := range edits {
+		if e.At > s.End {
+			return s
+		}
+		delta := len(e.New) - len(e.Old)
+		if delta == 0 {
+			continue
+		}
+		s.End += delta
+		if s.End < e.At {
+			s.End = e.At
+		}
+		if e.At > s.Start {
+			continue
+		}
+		s.Start += delta
+		if s.Start < e.At {
+			s.Start = e.At
+		}
+	}
+	return s
+}
+
 type SyntaxLayer struct {
 	Spans     []Span
 	Construct theme.LanguageConstruct
 }
+
+// Move moves every span in l to account for edits.  See
+// Span.Move for details.
+func (l SyntaxLayer) Move(edits []Edit) SyntaxLayer {
+	for i, s := range l.Spans {
+		l.Spans[i] = s.Move(edits)
+	}
+	return l
+}
+
+// Layerer is an editor that keeps the syntax layers of overlays (like
+// a debugger's current line) apart from the layers that highlighters
+// set with SetSyntaxLayers, so that neither replaces the other's.
+type Layerer interface {
+	// SetOwnedLayers replaces the layers that owner displays on top
+	// of the editor's syntax layers.  Nil layers remove owner's
+	// layers.
+	SetOwnedLayers(owner string, layers []SyntaxLayer)
+}
@@ -0,0 +1,279 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package diff finds the differences between versions of text and
+// merges changes that were made to the same text.
+package diff
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nelsam/vidar/commander/input"
+)
+
+// Hunk is a range of lines that differ between two versions of text.
+// Lines are 0-based indexes into the slices returned by Lines.
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+}
+
+// Lines splits text into lines.  A trailing newline results in an
+// empty last line, so that joining the lines with "\n" returns text.
+func Lines(text string) []string {
+	return strings.Split(text, "\n")
+}
+
+// ReadLines is like Lines, but reads the text from r a line at a
+// time.
+func ReadLines(r io.Reader) ([]string, error) {
+	var lines []string
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if err == io.EOF {
+			return append(lines, line), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line[:len(line)-1])
+	}
+}
+
+// Diff returns the hunks that turn old into new, using Myers'
+// algorithm.
+func Diff(old, new []string) []Hunk {
+	hunks, _ := Bounded(old, new, -1)
+	return hunks
+}
+
+// Bounded is like Diff, but gives up if more than maxCost lines
+// would have to be inserted or removed to turn old into new.  In that
+// case, it returns a single hunk covering every line between the
+// lines that old and new start and end with, and false.  A negative
+// maxCost never gives up.
+//
+// The search takes O((N+M)·D) time, where D is the number of lines
+// that differ, so maxCost bounds how long a diff of two very
+// different texts can take.
+func Bounded(old, new []string, maxCost int) ([]Hunk, bool) {
+	// Trimming the common prefix and suffix keeps the search small
+	// for the usual case of a few edits in a large file.
+	prefix := 0
+	for prefix < len(old) && prefix < len(new) && old[prefix] == new[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(new)-prefix && old[len(old)-1-suffix] == new[len(new)-1-suffix] {
+		suffix++
+	}
+	a, b := old[prefix:len(old)-suffix], new[prefix:len(new)-suffix]
+	if len(a) == 0 && len(b) == 0 {
+		return nil, true
+	}
+	m := newMyers(a, b)
+	if !m.compare(0, len(a), 0, len(b), maxCost) {
+		return []Hunk{{OldStart: prefix, OldLines: len(a), NewStart: prefix, NewLines: len(b)}}, false
+	}
+	hunks := m.hunks()
+	for i := range hunks {
+		hunks[i].OldStart += prefix
+		hunks[i].NewStart += prefix
+	}
+	return hunks, true
+}
+
+// myers finds the lines that a and b share using the linear space
+// variant of Myers' algorithm, which splits the texts at the middle
+// of the shortest edit script and recurses on each half.  Only the
+// furthest reaching paths of the current search are stored, so it
+// needs O(N+M) space.
+type myers struct {
+	a, b []string
+
+	// forward and backward hold the furthest reaching paths of
+	// the searches from the start and from the end of the texts,
+	// indexed by diagonal.
+	forward, backward []int
+
+	// shared holds the index in a and b of each line that they
+	// share, in order.
+	shared [][2]int
+}
+
+func newMyers(a, b []string) *myers {
+	size := 2*((len(a)+len(b)+1)/2+1) + 1
+	return &myers{
+		a:        a,
+		b:        b,
+		forward:  make([]int, size),
+		backward: make([]int, size),
+	}
+}
+
+// compare finds the lines that a[aStart:aEnd] and b[bStart:bEnd]
+// share.  It returns false without finding them if more than maxCost
+// lines differ.
+func (m *myers) compare(aStart, aEnd, bStart, bEnd, maxCost int) bool {
+	for aStart < aEnd && bStart < bEnd && m.a[aStart] == m.b[bStart] {
+		m.shared = append(m.shared, [2]int{aStart, bStart})
+		aStart++
+		bStart++
+	}
+	suffix := 0
+	for aStart < aEnd-suffix && bStart < bEnd-suffix && m.a[aEnd-1-suffix] == m.b[bEnd-1-suffix] {
+		suffix++
+	}
+	aEnd, bEnd = aEnd-suffix, bEnd-suffix
+	if aStart == aEnd || bStart == bEnd {
+		if maxCost >= 0 && aEnd-aStart+bEnd-bStart > maxCost {
+			return false
+		}
+	} else {
+		x, y, u, v, ok := m.middle(aStart, aEnd, bStart, bEnd, maxCost)
+		if !ok {
+			return false
+		}
+		// Each half differs by fewer lines than the whole, so
+		// there is no need to bound them again.
+		m.compare(aStart, x, bStart, y, -1)
+		for ; x < u; x, y = x+1, y+1 {
+			m.shared = append(m.shared, [2]int{x, y})
+		}
+		m.compare(u, aEnd, v, bEnd, -1)
+	}
+	for i := 0; i < suffix; i++ {
+		m.shared = append(m.shared, [2]int{aEnd + i, bEnd + i})
+	}
+	return true
+}
+
+// middle finds the snake in the middle of the shortest edit script
+// for a[aStart:aEnd] and b[bStart:bEnd], searching from both ends at
+// once.  The snake runs from (x, y) to (u, v).  The texts must not
+// be empty, and must not start or end with the same line.
+func (m *myers) middle(aStart, aEnd, bStart, bEnd, maxCost int) (x, y, u, v int, ok bool) {
+	n, l := aEnd-aStart, bEnd-bStart
+	delta := n - l
+	odd := delta%2 != 0
+	offset := (n+l+1)/2 + 1
+	fwd, bwd := m.forward, m.backward
+	fwd[offset+1], bwd[offset+1] = 0, 0
+	for d := 0; d <= (n+l+1)/2; d++ {
+		// At least 2d-1 lines differ if the paths haven't met
+		// yet.
+		if maxCost >= 0 && 2*d-1 > maxCost {
+			return 0, 0, 0, 0, false
+		}
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && fwd[offset+k-1] < fwd[offset+k+1]) {
+				x = fwd[offset+k+1]
+			} else {
+				x = fwd[offset+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < l && m.a[aStart+x] == m.b[bStart+y] {
+				x++
+				y++
+			}
+			fwd[offset+k] = x
+			if rk := delta - k; odd && rk >= -(d-1) && rk <= d-1 && x+bwd[offset+rk] >= n {
+				return aStart + startX, bStart + startY, aStart + x, bStart + y, true
+			}
+		}
+		for k := -d; k <= d; k += 2 {
+			// The backward search works on the reversed texts,
+			// so x and y count lines from the end.
+			var x int
+			if k == -d || (k != d && bwd[offset+k-1] < bwd[offset+k+1]) {
+				x = bwd[offset+k+1]
+			} else {
+				x = bwd[offset+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < l && m.a[aEnd-1-x] == m.b[bEnd-1-y] {
+				x++
+				y++
+			}
+			bwd[offset+k] = x
+			if fk := delta - k; !odd && fk >= -d && fk <= d && x+fwd[offset+fk] >= n {
+				if maxCost >= 0 && 2*d > maxCost {
+					return 0, 0, 0, 0, false
+				}
+				return aEnd - x, bEnd - y, aEnd - startX, bEnd - startY, true
+			}
+		}
+	}
+	panic("diff: no path found between texts")
+}
+
+// hunks returns the hunks between the lines that m found to be
+// shared.
+func (m *myers) hunks() []Hunk {
+	n, l := len(m.a), len(m.b)
+	var hunks []Hunk
+	ax, by := 0, 0
+	for i := 0; i <= len(m.shared); i++ {
+		nextA, nextB := n, l
+		if i < len(m.shared) {
+			nextA, nextB = m.shared[i][0], m.shared[i][1]
+		}
+		if nextA > ax || nextB > by {
+			hunks = append(hunks, Hunk{OldStart: ax, OldLines: nextA - ax, NewStart: by, NewLines: nextB - by})
+		}
+		ax, by = nextA+1, nextB+1
+	}
+	return hunks
+}
+
+// Edits returns the edits that turn old into new, one for each hunk
+// of changed lines.  Edits are in ascending order and each edit's At
+// is an offset into old, the way that an input.Handler expects them.
+func Edits(old, new string) []input.Edit {
+	edits, _ := BoundedEdits(old, new, -1)
+	return edits
+}
+
+// BoundedEdits is like Edits, but gives up the way that Bounded does
+// if more than maxCost lines differ, returning nil and false.
+func BoundedEdits(old, new string, maxCost int) ([]input.Edit, bool) {
+	// Keeping the newline on each line means that lines can be
+	// joined back together without worrying about whether a hunk is
+	// at the end of the text.
+	oldLines, newLines := strings.SplitAfter(old, "\n"), strings.SplitAfter(new, "\n")
+	hunks, ok := Bounded(oldLines, newLines, maxCost)
+	if !ok {
+		return nil, false
+	}
+	oldStarts, newStarts := runeStarts(oldLines), runeStarts(newLines)
+	oldRunes, newRunes := []rune(old), []rune(new)
+	var edits []input.Edit
+	for _, h := range hunks {
+		edits = append(edits, input.Edit{
+			At:  oldStarts[h.OldStart],
+			Old: oldRunes[oldStarts[h.OldStart]:oldStarts[h.OldStart+h.OldLines]],
+			New: newRunes[newStarts[h.NewStart]:newStarts[h.NewStart+h.NewLines]],
+		})
+	}
+	return edits, true
+}
+
+// runeStarts returns the rune offset of the start of each line, plus
+// the offset of the end of the last line.
+func runeStarts(lines []string) []int {
+	starts := make([]int, 0, len(lines)+1)
+	offset := 0
+	for _, l := range lines {
+		starts = append(starts, offset)
+		offset += utf8.RuneCountInString(l)
+	}
+	return append(starts, offset)
+}
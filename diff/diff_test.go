@@ -0,0 +1,194 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package diff_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/diff"
+)
+
+// apply applies edits to text the way that an input.Handler does.
+func apply(text string, edits []input.Edit) string {
+	runes := []rune(text)
+	delta := 0
+	for _, e := range edits {
+		at := e.At + delta
+		result := append([]rune(nil), runes[:at]...)
+		result = append(result, e.New...)
+		runes = append(result, runes[at+len(e.Old):]...)
+		delta += len(e.New) - len(e.Old)
+	}
+	return string(runes)
+}
+
+// lcs returns the number of lines that a and b share.
+func lcs(a, b []string) int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] > cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// randomLines returns n lines picked from a small alphabet, so that
+// many of them repeat.
+func randomLines(rng *rand.Rand, n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = string(rune('a' + rng.Intn(4)))
+	}
+	return lines
+}
+
+func TestDiff(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) expect.Expectation {
+		return expect.New(t)
+	})
+
+	o.Spec("it finds the fewest changed lines", func(expect expect.Expectation) {
+		rng := rand.New(rand.NewSource(1))
+		for i := 0; i < 500; i++ {
+			a, b := randomLines(rng, rng.Intn(30)), randomLines(rng, rng.Intn(30))
+			cost, result := 0, []string(nil)
+			pos := 0
+			for _, h := range diff.Diff(a, b) {
+				cost += h.OldLines + h.NewLines
+				result = append(result, a[pos:h.OldStart]...)
+				result = append(result, b[h.NewStart:h.NewStart+h.NewLines]...)
+				pos = h.OldStart + h.OldLines
+			}
+			result = append(result, a[pos:]...)
+			expect(strings.Join(result, "\n")).To(Equal(strings.Join(b, "\n")))
+			expect(cost).To(Equal(len(a) + len(b) - 2*lcs(a, b)))
+		}
+	})
+
+	o.Spec("it gives up on texts that differ too much", func(expect expect.Expectation) {
+		old := diff.Lines("a\nb\nc\nd\ne\nf\n")
+		hunks, ok := diff.Bounded(old, diff.Lines("a\nB\nc\nD\ne\nf\n"), 4)
+		expect(ok).To(BeTrue())
+		expect(hunks).To(HaveLen(2))
+
+		hunks, ok = diff.Bounded(old, diff.Lines("a\nB\nC\nD\ne\nf\n"), 4)
+		expect(ok).To(BeFalse())
+		expect(hunks).To(Equal([]diff.Hunk{{OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3}}))
+	})
+}
+
+func TestEdits(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) expect.Expectation {
+		return expect.New(t)
+	})
+
+	o.Spec("it turns old text into new text", func(expect expect.Expectation) {
+		for _, c := range []struct{ old, new string }{
+			{"a\nb", "a\nb\nc"},
+			{"a\nb\n", "a\nb\nc\n"},
+			{"a\nb\nc\n", "a\nc\n"},
+			{"", "x\ny"},
+			{"x\ny", ""},
+			{"héllo\nwörld\n", "héllo\nthere\nwörld\n"},
+			{"a\nb\nc\nd\n", "A\nb\nc\nD"},
+		} {
+			expect(apply(c.old, diff.Edits(c.old, c.new))).To(Equal(c.new))
+		}
+	})
+
+	o.Spec("it only edits changed lines", func(expect expect.Expectation) {
+		edits := diff.Edits("a\nb\nc\n", "a\nB\nc\n")
+		expect(edits).To(Equal([]input.Edit{
+			{At: 2, Old: []rune("b\n"), New: []rune("B\n")},
+		}))
+		expect(diff.Edits("a\n", "a\n")).To(HaveLen(0))
+	})
+
+	o.Spec("it gives up on edits to texts that differ too much", func(expect expect.Expectation) {
+		edits, ok := diff.BoundedEdits("a\nb\nc\n", "a\nB\nc\n", 2)
+		expect(ok).To(BeTrue())
+		expect(edits).To(HaveLen(1))
+
+		edits, ok = diff.BoundedEdits("a\nb\nc\n", "A\nB\nC\n", 2)
+		expect(ok).To(BeFalse())
+		expect(edits).To(HaveLen(0))
+	})
+
+	o.Spec("it reads the same lines that it splits", func(expect expect.Expectation) {
+		for _, text := range []string{"", "\n", "a", "a\nb", "a\nb\n", "héllo\n\nwörld"} {
+			lines, err := diff.ReadLines(strings.NewReader(text))
+			expect(err).To(Not(HaveOccurred()))
+			expect(lines).To(Equal(diff.Lines(text)))
+		}
+	})
+}
+
+func TestMerge(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) expect.Expectation {
+		return expect.New(t)
+	})
+
+	merge := func(base, ours, theirs string) (string, []diff.Conflict) {
+		merged, conflicts := diff.Merge(diff.Lines(base), diff.Lines(ours), diff.Lines(theirs), "ours", "theirs")
+		return strings.Join(merged, "\n"), conflicts
+	}
+
+	o.Spec("it keeps changes to separate lines", func(expect expect.Expectation) {
+		merged, conflicts := merge("a\nb\nc\nd\ne\n", "A\nb\nc\nd\ne\n", "a\nb\nc\nd\nE\n")
+		expect(merged).To(Equal("A\nb\nc\nd\nE\n"))
+		expect(conflicts).To(HaveLen(0))
+
+		merged, conflicts = merge("a\nb\nc\nd\n", "x\na\nb\nc\nd\n", "a\nb\nc\nd\ny\n")
+		expect(merged).To(Equal("x\na\nb\nc\nd\ny\n"))
+		expect(conflicts).To(HaveLen(0))
+	})
+
+	o.Spec("it keeps identical changes once", func(expect expect.Expectation) {
+		merged, conflicts := merge("a\nb\nc\n", "a\nX\nc\n", "a\nX\nc\n")
+		expect(merged).To(Equal("a\nX\nc\n"))
+		expect(conflicts).To(HaveLen(0))
+	})
+
+	o.Spec("it marks conflicting changes", func(expect expect.Expectation) {
+		merged, conflicts := merge("a\nb\nc\n", "a\nX\nc\n", "a\nY\nc\n")
+		expect(merged).To(Equal("a\n<<<<<<< ours\nX\n=======\nY\n>>>>>>> theirs\nc\n"))
+		expect(conflicts).To(Equal([]diff.Conflict{{Start: 1, Lines: 5}}))
+	})
+
+	o.Spec("it treats every line between unchanged ends as changed when a diff gives up", func(expect expect.Expectation) {
+		base, ours := diff.Lines("a\nb\nc\nd\ne\nf\n"), diff.Lines("a\nB\nc\nD\ne\nf\n")
+		merged, conflicts := diff.BoundedMerge(base, ours, diff.Lines("a\nb\nc\nd\ne\nF\n"), "ours", "theirs", 2)
+		expect(strings.Join(merged, "\n")).To(Equal("a\nB\nc\nD\ne\nF\n"))
+		expect(conflicts).To(HaveLen(0))
+
+		merged, conflicts = diff.BoundedMerge(base, ours, diff.Lines("a\nb\nC\nd\ne\nf\n"), "ours", "theirs", 2)
+		expect(strings.Join(merged, "\n")).To(Equal("a\n<<<<<<< ours\nB\nc\nD\n=======\nb\nC\nd\n>>>>>>> theirs\ne\nf\n"))
+		expect(conflicts).To(HaveLen(1))
+	})
+}
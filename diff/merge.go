@@ -0,0 +1,118 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package diff
+
+const (
+	conflictStart = "<<<<<<< "
+	conflictSep   = "======="
+	conflictEnd   = ">>>>>>> "
+)
+
+// Conflict is a range of lines in merged text where both versions
+// changed the same lines of the base text in different ways.  The
+// range includes the conflict markers.
+type Conflict struct {
+	Start, Lines int
+}
+
+// Merge performs a three-way merge of ours and theirs, which were
+// both changed from base.  Changes that only one version made are
+// kept; lines that both versions changed differently are surrounded
+// by git-style conflict markers, labeled with ourName and theirName.
+func Merge(base, ours, theirs []string, ourName, theirName string) (merged []string, conflicts []Conflict) {
+	return BoundedMerge(base, ours, theirs, ourName, theirName, -1)
+}
+
+// BoundedMerge is like Merge, but bounds the diffs of ours and
+// theirs against base by maxCost the way that Bounded does.  When a
+// diff gives up, every line between the lines that the two texts
+// start and end with is treated as changed, so the merge is still
+// correct but changes are more likely to conflict.
+func BoundedMerge(base, ours, theirs []string, ourName, theirName string, maxCost int) (merged []string, conflicts []Conflict) {
+	a, _ := Bounded(base, ours, maxCost)
+	b, _ := Bounded(base, theirs, maxCost)
+	pos := 0
+	for len(a) > 0 || len(b) > 0 {
+		// Start a group of overlapping hunks with whichever hunk
+		// comes first, then pull in every hunk that touches the
+		// group.  Hunks that touch are treated as overlapping, the
+		// way that git does.
+		var groupA, groupB []Hunk
+		var start, end int
+		if len(b) == 0 || (len(a) > 0 && a[0].OldStart <= b[0].OldStart) {
+			start, end = a[0].OldStart, a[0].OldStart+a[0].OldLines
+			groupA, a = []Hunk{a[0]}, a[1:]
+		} else {
+			start, end = b[0].OldStart, b[0].OldStart+b[0].OldLines
+			groupB, b = []Hunk{b[0]}, b[1:]
+		}
+	group:
+		for {
+			switch {
+			case len(a) > 0 && a[0].OldStart <= end:
+				end = max(end, a[0].OldStart+a[0].OldLines)
+				groupA, a = append(groupA, a[0]), a[1:]
+			case len(b) > 0 && b[0].OldStart <= end:
+				end = max(end, b[0].OldStart+b[0].OldLines)
+				groupB, b = append(groupB, b[0]), b[1:]
+			default:
+				break group
+			}
+		}
+		merged = append(merged, base[pos:start]...)
+		pos = end
+		ourLines := apply(base, ours, groupA, start, end)
+		theirLines := apply(base, theirs, groupB, start, end)
+		switch {
+		case len(groupB) == 0:
+			merged = append(merged, ourLines...)
+		case len(groupA) == 0, equal(ourLines, theirLines):
+			merged = append(merged, theirLines...)
+		default:
+			c := Conflict{Start: len(merged)}
+			merged = append(merged, conflictStart+ourName)
+			merged = append(merged, ourLines...)
+			merged = append(merged, conflictSep)
+			merged = append(merged, theirLines...)
+			merged = append(merged, conflictEnd+theirName)
+			c.Lines = len(merged) - c.Start
+			conflicts = append(conflicts, c)
+		}
+	}
+	merged = append(merged, base[pos:]...)
+	return merged, conflicts
+}
+
+// apply returns the lines that replace base[start:end] in changed,
+// given the hunks from base to changed that fall within that range.
+func apply(base, changed []string, hunks []Hunk, start, end int) []string {
+	var lines []string
+	pos := start
+	for _, h := range hunks {
+		lines = append(lines, base[pos:h.OldStart]...)
+		lines = append(lines, changed[h.NewStart:h.NewStart+h.NewLines]...)
+		pos = h.OldStart + h.OldLines
+	}
+	return append(lines, base[pos:end]...)
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
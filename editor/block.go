@@ -0,0 +1,63 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package editor
+
+import (
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/command/caret"
+	"github.com/nelsam/vidar/commander/bind"
+)
+
+// selectMover is the caret-movement bindable.  Block selections are
+// set through it so that its hooks see them.
+type selectMover interface {
+	Select(...gxui.TextSelection) bind.Bindable
+}
+
+// MouseDown starts a rectangular block selection when the left mouse
+// button is pressed while alt is held.
+func (e *CodeEditor) MouseDown(ev gxui.MouseEvent) {
+	if ev.Button == gxui.MouseButtonLeft && ev.Modifier&gxui.ModAlt != 0 {
+		if pos, ok := e.RuneIndexAt(ev.Point); ok {
+			e.blockAnchor = caret.PointAt(e.Snapshot(), pos, e.TabWidth())
+			e.blockDragging = true
+			e.selectBlock(pos)
+			return
+		}
+	}
+	e.CodeEditor.MouseDown(ev)
+}
+
+func (e *CodeEditor) MouseMove(ev gxui.MouseEvent) {
+	if e.blockDragging {
+		if pos, ok := e.RuneIndexAt(ev.Point); ok {
+			e.selectBlock(pos)
+		}
+	}
+	e.CodeEditor.MouseMove(ev)
+}
+
+func (e *CodeEditor) MouseUp(ev gxui.MouseEvent) {
+	if e.blockDragging && ev.Button == gxui.MouseButtonLeft {
+		e.blockDragging = false
+		return
+	}
+	e.CodeEditor.MouseUp(ev)
+}
+
+// selectBlock selects the block of text between the point where the
+// block selection started and pos.
+func (e *CodeEditor) selectBlock(pos int) {
+	text := e.Snapshot()
+	head := caret.PointAt(text, pos, e.TabWidth())
+	sels := caret.Block(text, e.blockAnchor, head, e.TabWidth())
+	if e.cmdr != nil {
+		if m, ok := e.cmdr.Bindable("caret-movement").(selectMover); ok {
+			e.cmdr.Execute(m.Select(sels...))
+			return
+		}
+	}
+	e.SelectSlice(sels)
+}
@@ -0,0 +1,463 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package editor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/gxui/math"
+	"github.com/nelsam/vidar/command/focus"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/diff"
+	"github.com/nelsam/vidar/piece"
+	"github.com/nelsam/vidar/plugin/status"
+	"github.com/nelsam/vidar/textfile"
+	"github.com/nelsam/vidar/theme"
+)
+
+// diskCheckDelay is how long an editor waits after its file changes
+// before reading it, so that a file which is written in several
+// steps is only read once it's complete.
+const diskCheckDelay = 100 * time.Millisecond
+
+// maxDiffCost bounds the number of changed lines that diffs between
+// an editor's text and its file on disk search for, so that
+// comparing very different texts doesn't take too long.  Reloading a
+// file that differs by more lines replaces the whole text.
+const maxDiffCost = 2000
+
+// diffOwner owns the layers that highlight the lines that differ
+// between an editor's text and its file on disk.
+const diffOwner = "disk-diff"
+
+var diskPromptBackground = gxui.Color{
+	R: 0.3,
+	G: 0.25,
+	B: 0.1,
+	A: 1,
+}
+
+// diskCopies holds the paths of files that the on-disk versions of
+// files were written to for comparison, along with the spans of the
+// lines that differ from the editor's text.  They are opened
+// read-only.
+var diskCopies = struct {
+	sync.Mutex
+	paths map[string][]input.Span
+}{paths: make(map[string][]input.Span)}
+
+func isDiskCopy(path string) bool {
+	diskCopies.Lock()
+	defer diskCopies.Unlock()
+	_, ok := diskCopies.paths[path]
+	return ok
+}
+
+// highlightDiskCopy highlights the lines that differ from the
+// editor's text, if e is showing a copy of a file on disk.  It must
+// be called on the UI goroutine.
+func (e *CodeEditor) highlightDiskCopy() {
+	diskCopies.Lock()
+	spans, ok := diskCopies.paths[e.filepath]
+	diskCopies.Unlock()
+	if !ok {
+		return
+	}
+	e.SetOwnedLayers(diffOwner, []input.SyntaxLayer{{Construct: theme.Changed, Spans: spans}})
+}
+
+// removeDiskCopy removes path, if it is a copy of a file on disk, and
+// the temporary directory that it was written to.
+func removeDiskCopy(path string) {
+	diskCopies.Lock()
+	defer diskCopies.Unlock()
+	if _, ok := diskCopies.paths[path]; !ok {
+		return
+	}
+	delete(diskCopies.paths, path)
+	if err := os.RemoveAll(filepath.Dir(path)); err != nil {
+		log.Printf("Error removing %s: %s", filepath.Dir(path), err)
+	}
+}
+
+// Closed cleans up after e once it has been closed.  If e was
+// showing a copy of a file on disk, the copy is removed.
+func (e *CodeEditor) Closed() {
+	removeDiskCopy(e.filepath)
+}
+
+// handlerOwner is a type that owns the input handler, which edits
+// must be applied through for hooks (like history) to see them.
+type handlerOwner interface {
+	InputHandler() input.Handler
+}
+
+// statusShower is a type that can display a status to the user
+// outside of a command.
+type statusShower interface {
+	ShowStatus(gxui.Control)
+}
+
+// scheduleDiskCheck checks e's file for changes after
+// diskCheckDelay, unless a check is already scheduled.
+func (e *CodeEditor) scheduleDiskCheck() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.diskCheckPending {
+		return
+	}
+	e.diskCheckPending = true
+	time.AfterFunc(diskCheckDelay, func() {
+		e.lock.Lock()
+		e.diskCheckPending = false
+		e.lock.Unlock()
+		e.driver.Call(func() { e.CheckDisk() })
+	})
+}
+
+// readDisk returns the decoded contents, format and modification
+// time of e's file.
+func (e *CodeEditor) readDisk() (string, textfile.Format, time.Time, error) {
+	finfo, err := os.Stat(e.filepath)
+	if err != nil {
+		return "", textfile.Format{}, time.Time{}, err
+	}
+	b, err := ioutil.ReadFile(e.filepath)
+	if err != nil {
+		return "", textfile.Format{}, time.Time{}, err
+	}
+	text, format := textfile.Decode(b)
+	return text, format, finfo.ModTime(), nil
+}
+
+// CheckDisk compares e's file to the text that was last loaded from
+// or saved to it.  If the file changed and e has no changes of its
+// own, e is reloaded; otherwise, the user is asked whether to reload
+// the file, overwrite it, or compare it to e's text.  CheckDisk
+// returns whether the file changed.  It must be called on the UI
+// goroutine.
+func (e *CodeEditor) CheckDisk() (changed bool) {
+	disk, format, mtime, err := e.readDisk()
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error reading file %s: %s", e.filepath, err)
+		}
+		return false
+	}
+	e.lock.Lock()
+	saved := e.saved
+	if disk == saved && format == e.savedFormat {
+		// The file was touched or we saved it ourselves.
+		e.lastModified = mtime
+		e.lock.Unlock()
+		return false
+	}
+	e.lock.Unlock()
+	if !e.HasChanges() {
+		e.reload(disk, format, mtime)
+		return true
+	}
+	e.showDiskPrompt(disk, format, mtime)
+	return true
+}
+
+// reload replaces e's text and format with disk and format, the
+// contents of its file at mtime.  The change is applied as an edit,
+// so that it can be undone and carets stay on the lines they were on.
+func (e *CodeEditor) reload(disk string, format textfile.Format, mtime time.Time) {
+	e.replaceText(disk, func() {
+		e.SetFileFormat(format)
+		e.lock.Lock()
+		defer e.lock.Unlock()
+		e.saved = disk
+		e.savedFormat = format
+		e.savedVersion = e.version
+		e.lastModified = mtime
+	})
+}
+
+// replaceText changes e's text to text using the smallest edits that
+// it can, then calls done.  The edits are found in the background;
+// if e's text changes in the meantime, e's file is checked again
+// instead, since the edits would overwrite the change.  If the texts
+// are too different, the whole text is replaced.  It must be called
+// on the UI goroutine.
+func (e *CodeEditor) replaceText(text string, done func()) {
+	doc := e.Snapshot()
+	go func() {
+		edits, ok := diff.BoundedEdits(doc.String(), text, maxDiffCost)
+		e.driver.Call(func() {
+			if e.Snapshot() != doc {
+				e.CheckDisk()
+				return
+			}
+			e.applyText(text, edits, ok)
+			done()
+		})
+	}()
+}
+
+// applyText applies edits, which turn e's text into text, through
+// the input handler.  If ok is false, the edits couldn't be found and
+// text replaces e's text instead.  It must be called on the UI
+// goroutine.
+func (e *CodeEditor) applyText(text string, edits []input.Edit, ok bool) {
+	if ok && len(edits) == 0 {
+		return
+	}
+	h, isOwner := e.cmdr.(handlerOwner)
+	if !ok || !isOwner || e.ReadOnly() {
+		e.SetText(text)
+		return
+	}
+	carets := e.Carets()
+	for i, c := range carets {
+		carets[i] = moveCaret(c, edits)
+	}
+	h.InputHandler().Apply(e, edits...)
+	e.SetCarets(carets...)
+}
+
+// moveCaret returns where caret ends up after edits are applied.
+// Carets inside of an edit stay the same distance from its start,
+// as long as the new text is long enough.
+func moveCaret(caret int, edits []input.Edit) int {
+	delta := 0
+	for _, edit := range edits {
+		if edit.At > caret {
+			break
+		}
+		if caret < edit.At+len(edit.Old) {
+			offset := caret - edit.At
+			if offset > len(edit.New) {
+				offset = len(edit.New)
+			}
+			return edit.At + delta + offset
+		}
+		delta += len(edit.New) - len(edit.Old)
+	}
+	return caret + delta
+}
+
+// promptAction is a button in a prompt shown by showPrompt.
+type promptAction struct {
+	label string
+	do    func()
+}
+
+// showDiskPrompt asks the user what to do about disk and format, the
+// contents of e's file at mtime, which conflict with changes in e.
+// It must be called on the UI goroutine.
+func (e *CodeEditor) showDiskPrompt(disk string, format textfile.Format, mtime time.Time) {
+	e.showPrompt(fmt.Sprintf("%s changed on disk.", filepath.Base(e.filepath)),
+		promptAction{label: "Reload", do: func() { e.reload(disk, format, mtime) }},
+		promptAction{label: "Overwrite", do: func() { e.overwrite(mtime) }},
+		promptAction{label: "Compare", do: func() { e.compare(disk, mtime) }},
+	)
+}
+
+// showPrompt displays msg at the top of e with a button for each
+// action.  Clicking a button hides the prompt and runs its action.
+// It must be called on the UI goroutine.
+func (e *CodeEditor) showPrompt(msg string, actions ...promptAction) {
+	e.hideDiskPrompt()
+
+	label := e.theme.CreateLabel()
+	label.SetColor(gxui.White)
+	label.SetText(msg)
+
+	prompt := e.theme.CreateLinearLayout()
+	prompt.SetDirection(gxui.LeftToRight)
+	prompt.SetVerticalAlignment(gxui.AlignMiddle)
+	prompt.SetBackgroundBrush(gxui.CreateBrush(diskPromptBackground))
+	prompt.SetBorderPen(gxui.CreatePen(1, gxui.Gray50))
+	prompt.SetPadding(math.CreateSpacing(2))
+	prompt.AddChild(label)
+	for _, a := range actions {
+		a := a
+		button := e.theme.CreateButton()
+		button.SetText(a.label)
+		button.OnClick(func(gxui.MouseEvent) {
+			e.hideDiskPrompt()
+			a.do()
+		})
+		prompt.AddChild(button)
+	}
+
+	bounds := e.Size().Rect().Contract(e.Padding())
+	size := prompt.DesiredSize(math.ZeroSize, bounds.Size())
+	c := e.AddChild(prompt)
+	c.Layout(size.Rect().Offset(bounds.Min).Intersect(bounds))
+	e.diskPrompt = prompt
+	e.Redraw()
+}
+
+// hideDiskPrompt removes the prompt that showDiskPrompt displayed,
+// if there is one.  It must be called on the UI goroutine.
+func (e *CodeEditor) hideDiskPrompt() {
+	if e.diskPrompt == nil {
+		return
+	}
+	if e.Children().Find(e.diskPrompt) != nil {
+		e.RemoveChild(e.diskPrompt)
+	}
+	e.diskPrompt = nil
+}
+
+// overwrite saves e's text over the version of its file at mtime.
+func (e *CodeEditor) overwrite(mtime time.Time) {
+	e.setLastModified(mtime)
+	opener := e.cmdr.Bindable("focus-location").(Opener)
+	e.cmdr.Execute(opener.For(focus.Path(e.filepath)))
+	e.cmdr.Execute(e.cmdr.Bindable("save-current-file"))
+}
+
+// compare shows e's text and disk, the text of e's file at mtime,
+// side by side, with the lines that differ highlighted in both.  The
+// user can then accept a merge of disk into e's text, which uses the
+// text that was last saved as the common base and marks lines that
+// were changed on both sides with conflict markers.  The diffs are
+// run in the background.  It must be called on the UI goroutine.
+func (e *CodeEditor) compare(disk string, mtime time.Time) {
+	e.lock.RLock()
+	saved := e.saved
+	e.lock.RUnlock()
+	doc := e.Snapshot()
+	go func() {
+		ours, theirs := diff.Lines(doc.String()), diff.Lines(disk)
+		hunks, _ := diff.Bounded(ours, theirs, maxDiffCost)
+		merged, conflicts := diff.BoundedMerge(diff.Lines(saved), ours, theirs, "buffer", "disk", maxDiffCost)
+		ourSpans, theirSpans := hunkSpans(ours, hunks, false), hunkSpans(theirs, hunks, true)
+		e.driver.Call(func() {
+			if e.Snapshot() != doc {
+				e.CheckDisk()
+				return
+			}
+			if err := e.openDiskCopy(disk, theirSpans); err != nil {
+				s := status.General{Theme: e.theme, Err: fmt.Sprintf("Could not open the file on disk for comparison: %s", err)}
+				e.showStatus(s)
+				return
+			}
+			e.SetOwnedLayers(diffOwner, []input.SyntaxLayer{{Construct: theme.Changed, Spans: ourSpans}})
+			msg := fmt.Sprintf("Comparing %s with the file on disk (%s).", filepath.Base(e.filepath), countChanges(len(hunks), len(conflicts)))
+			e.showPrompt(msg,
+				promptAction{label: "Accept Merge", do: func() { e.acceptMerge(doc, strings.Join(merged, "\n"), len(conflicts), mtime) }},
+				promptAction{label: "Cancel", do: func() { e.SetOwnedLayers(diffOwner, nil) }},
+			)
+		})
+	}()
+}
+
+// countChanges describes the number of hunks and conflicts in a
+// comparison.
+func countChanges(hunks, conflicts int) string {
+	msg := fmt.Sprintf("%d changes", hunks)
+	if hunks == 1 {
+		msg = "1 change"
+	}
+	switch conflicts {
+	case 0:
+		return msg
+	case 1:
+		return msg + ", 1 conflict"
+	default:
+		return fmt.Sprintf("%s, %d conflicts", msg, conflicts)
+	}
+}
+
+// acceptMerge replaces e's text with merged, the result of merging
+// the text of e's file at mtime into doc.  If e's text is no longer
+// doc, the merge is out of date and e's file is checked again
+// instead.  It must be called on the UI goroutine.
+func (e *CodeEditor) acceptMerge(doc *piece.Table, merged string, conflicts int, mtime time.Time) {
+	e.SetOwnedLayers(diffOwner, nil)
+	if e.Snapshot() != doc {
+		e.CheckDisk()
+		return
+	}
+	e.replaceText(merged, func() {
+		// Our text now includes the changes on disk, so saving it
+		// no longer loses them.
+		e.setLastModified(mtime)
+
+		s := status.General{Theme: e.theme}
+		switch conflicts {
+		case 0:
+			s.Info = fmt.Sprintf("Merged changes from disk into %s", filepath.Base(e.filepath))
+		case 1:
+			s.Warn = "Merged changes from disk with 1 conflict"
+		default:
+			s.Warn = fmt.Sprintf("Merged changes from disk with %d conflicts", conflicts)
+		}
+		e.showStatus(s)
+	})
+}
+
+// showStatus displays s, if e's commander can display statuses.
+func (e *CodeEditor) showStatus(s status.General) {
+	if shower, ok := e.cmdr.(statusShower); ok {
+		shower.ShowStatus(s.Status())
+	}
+}
+
+// hunkSpans returns the spans of the lines in hunks, which are either
+// the old or the new side of each hunk in lines.  Hunks without any
+// lines on that side are skipped.
+func hunkSpans(lines []string, hunks []diff.Hunk, new bool) []input.Span {
+	starts := make([]int, 0, len(lines)+1)
+	offset := 0
+	for _, l := range lines {
+		starts = append(starts, offset)
+		offset += utf8.RuneCountInString(l) + 1
+	}
+	starts = append(starts, offset)
+	var spans []input.Span
+	for _, h := range hunks {
+		start, n := h.OldStart, h.OldLines
+		if new {
+			start, n = h.NewStart, h.NewLines
+		}
+		if n == 0 {
+			continue
+		}
+		// Leave the last line's newline out of the span.
+		spans = append(spans, input.Span{Start: starts[start], End: starts[start+n] - 1})
+	}
+	return spans
+}
+
+// openDiskCopy writes disk to a temporary file and opens it in a
+// split beside e, highlighting spans.
+func (e *CodeEditor) openDiskCopy(disk string, spans []input.Span) error {
+	dir, err := ioutil.TempDir("", "vidar-disk")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, filepath.Base(e.filepath))
+	if err := ioutil.WriteFile(path, []byte(disk), 0444); err != nil {
+		os.RemoveAll(dir)
+		return err
+	}
+	if spans == nil {
+		spans = []input.Span{}
+	}
+	diskCopies.Lock()
+	diskCopies.paths[path] = spans
+	diskCopies.Unlock()
+
+	opener := e.cmdr.Bindable("focus-location").(Opener)
+	e.cmdr.Execute(opener.For(focus.Path(path)))
+	e.cmdr.Execute(e.cmdr.Bindable("split-view-horizontally"))
+	return nil
+}
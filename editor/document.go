@@ -0,0 +1,152 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package editor
+
+import (
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/piece"
+)
+
+// initDocument sets up e's document, which is the buffer that e's
+// text is edited in.  The text box's copy of the text is derived from
+// it.
+func (e *CodeEditor) initDocument() {
+	e.doc = piece.New(nil)
+	e.OnTextChanged(e.controllerEdited)
+}
+
+// SetText replaces e's text with text.
+func (e *CodeEditor) SetText(text string) {
+	e.lock.Lock()
+	e.doc = piece.NewString(text)
+	e.version++
+	e.textStale = false
+	e.lock.Unlock()
+	e.CodeEditor.SetText(text)
+}
+
+// Snapshot returns an immutable copy of e's text.  See
+// input.Snapshotter.
+func (e *CodeEditor) Snapshot() *piece.Table {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	return e.doc
+}
+
+// SetDocument replaces e's text with doc, which input handlers build
+// by editing e's document.  from is the offset of the first rune
+// that the edits changed.
+//
+// The text is copied to the text box the next time that its
+// controller is used, or once the UI goroutine is free, so edits
+// that are applied together only copy it once.  Hooks that only move
+// carets, scroll or fold don't need the copy, so typing doesn't wait
+// for it.  Only the text after
+// the first change is copied.  It must be called on the UI
+// goroutine.
+func (e *CodeEditor) SetDocument(doc *piece.Table, from int) {
+	e.lock.Lock()
+	scheduled := e.textStale
+	if !scheduled || from < e.staleFrom {
+		e.staleFrom = from
+	}
+	e.doc = doc
+	e.version++
+	e.textStale = true
+	e.lock.Unlock()
+	if !scheduled {
+		e.driver.Call(e.syncText)
+	}
+}
+
+// Controller returns the controller of e's text box, after bringing
+// its text up to date with e's document.
+func (e *CodeEditor) Controller() *gxui.TextBoxController {
+	e.syncText()
+	return e.CodeEditor.Controller()
+}
+
+// Runes returns e's text.  It doesn't update the text box, so it may
+// be called off of the UI goroutine.
+func (e *CodeEditor) Runes() []rune {
+	e.lock.RLock()
+	doc, stale := e.doc, e.textStale
+	e.lock.RUnlock()
+	if stale {
+		return doc.Runes()
+	}
+	return e.CodeEditor.Runes()
+}
+
+// Text returns e's text.  Like Runes, it doesn't update the text
+// box.
+func (e *CodeEditor) Text() string {
+	e.lock.RLock()
+	doc, stale := e.doc, e.textStale
+	e.lock.RUnlock()
+	if stale {
+		return doc.String()
+	}
+	return e.CodeEditor.Text()
+}
+
+// lineCount returns the number of lines in e's text, reading it from
+// e's document while the text box hasn't caught up with it.
+func (e *CodeEditor) lineCount() int {
+	e.lock.RLock()
+	doc, stale := e.doc, e.textStale
+	e.lock.RUnlock()
+	if stale {
+		return doc.Lines()
+	}
+	return e.CodeEditor.Controller().LineCount()
+}
+
+// syncText copies e's document to its text box, if the text box
+// hasn't caught up with it.
+func (e *CodeEditor) syncText() {
+	e.lock.Lock()
+	doc, stale, from := e.doc, e.textStale, e.staleFrom
+	e.textStale = false
+	e.lock.Unlock()
+	if !stale {
+		return
+	}
+	c := e.CodeEditor.Controller()
+	text := c.TextRunes()
+	if from > len(text) {
+		from = len(text)
+	}
+	c.SetTextRunesNoEvent(doc.AppendSlice(text[:from], from, doc.Len()))
+}
+
+// controllerEdited applies edits that were made through the text
+// box's controller, like indenting the selection, to e's document.
+// gxui only reports where runes were inserted or removed, so the
+// inserted runes are read back from the controller.
+func (e *CodeEditor) controllerEdited(edits []gxui.TextBoxEdit) {
+	if len(edits) == 0 {
+		return
+	}
+	text := e.CodeEditor.Controller().TextRunes()
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	doc := e.doc
+	for i, edit := range edits {
+		if edit.Delta < 0 {
+			doc = doc.Delete(edit.At, -edit.Delta)
+			continue
+		}
+		at := edit.At
+		for _, later := range edits[i+1:] {
+			if later.At <= at {
+				at += later.Delta
+			}
+		}
+		doc = doc.Insert(edit.At, text[at:at+edit.Delta])
+	}
+	e.doc = doc
+	e.version++
+}
@@ -19,8 +19,11 @@ import (
 	"github.com/nelsam/gxui/math"
 	"github.com/nelsam/gxui/mixins"
 	"github.com/nelsam/gxui/themes/basic"
+	"github.com/nelsam/vidar/command/caret"
 	"github.com/nelsam/vidar/commander/input"
 	"github.com/nelsam/vidar/fsw"
+	"github.com/nelsam/vidar/piece"
+	"github.com/nelsam/vidar/textfile"
 	"github.com/nelsam/vidar/theme"
 )
 
@@ -30,37 +33,74 @@ type CodeEditor struct {
 	theme       *basic.Theme
 	syntaxTheme theme.Theme
 	driver      gxui.Driver
+	cmdr        Commander
 
 	lock         sync.RWMutex
 	lastModified time.Time
-	hasChanges   bool
 	filepath     string
 
+	// saved and savedFormat are the text and format that were last
+	// loaded from or saved to disk, and savedVersion is the version
+	// of doc at the time.
+	saved        string
+	savedFormat  textfile.Format
+	savedVersion uint64
+	format       textfile.Format
+
+	// doc is the text, which edits are applied to before it is
+	// copied to the text box.  version is incremented each time doc
+	// changes.  textStale is set while the text box hasn't caught up
+	// with it, and staleFrom is the offset of the first rune that it
+	// is missing.
+	doc       *piece.Table
+	version   uint64
+	textStale bool
+	staleFrom int
+
+	diskCheckPending bool
+	diskPrompt       gxui.Control
+
 	watcher fsw.Watcher
 
 	selections      []gxui.TextSelection
+	blockAnchor     caret.Point
+	blockDragging   bool
 	scrollPositions math.Point
 	layers          []input.SyntaxLayer
+	ownedLayers     map[string][]input.SyntaxLayer
+
+	folds       *foldAdapter
+	foldRegions []input.FoldRegion
+	folded      []input.FoldRegion
+
+	gutterLock    sync.RWMutex
+	gutterOwners  []string
+	gutterMarks   map[string]map[int]input.GutterMark
+	gutterWidths  map[string]int
+	onGutterClick []func(line int)
+
+	readOnly bool
 
 	renamed  bool
 	onRename func(newPath string)
 }
 
-func (e *CodeEditor) Init(driver gxui.Driver, theme *basic.Theme, syntaxTheme theme.Theme, font gxui.Font, file, headerText string) {
+func (e *CodeEditor) Init(driver gxui.Driver, cmdr Commander, theme *basic.Theme, syntaxTheme theme.Theme, font gxui.Font, file, headerText string) {
 	e.theme = theme
 	e.syntaxTheme = syntaxTheme
 	e.driver = driver
+	e.cmdr = cmdr
 
 	e.CodeEditor.Init(e, driver, theme, font)
+	e.initFolds()
 	e.CodeEditor.SetScrollBarEnabled(true)
 	e.CodeEditor.SetScrollRound(true)
 	e.SetDesiredWidth(math.MaxSize.W)
 	e.watcherSetup()
+	e.initDocument()
 
-	// TODO: move to hooks on the input.Handler
-	e.OnTextChanged(func(changes []gxui.TextBoxEdit) {
-		e.hasChanges = true
-	})
+	e.format = textfile.Default
+	e.savedFormat = textfile.Default
 	e.filepath = file
 	e.open(headerText)
 
@@ -74,20 +114,35 @@ func (e *CodeEditor) DataChanged(recreate bool) {
 	e.List.DataChanged(recreate)
 }
 
+// Carets returns the carets in e.  Selections don't depend on the
+// text box's copy of e's text, so Carets and SetCarets don't wait for
+// it to catch up with edits.
 func (e *CodeEditor) Carets() []int {
-	return e.Controller().Carets()
+	return e.CodeEditor.Controller().Carets()
 }
 
 func (e *CodeEditor) SetCarets(carets ...int) {
 	if len(carets) == 0 {
-		e.Controller().ClearSelections()
+		e.CodeEditor.Controller().ClearSelections()
 		return
 	}
 	var sel []gxui.TextSelection
 	for _, c := range carets {
 		sel = append(sel, gxui.CreateTextSelection(c, c, true))
 	}
-	e.Controller().SetSelections(sel)
+	e.CodeEditor.Controller().SetSelections(sel)
+}
+
+// LineIndex returns the line that the rune at offset i is on.  It
+// reads e's document, so it is up to date during edits.
+func (e *CodeEditor) LineIndex(i int) int {
+	return e.Snapshot().LineOf(i)
+}
+
+// ScrollToRune scrolls e to the line that the rune at offset i is
+// on.
+func (e *CodeEditor) ScrollToRune(i int) {
+	e.ScrollToLine(e.LineIndex(i))
 }
 
 func (e *CodeEditor) OnRename(callback func(newPath string)) {
@@ -95,8 +150,8 @@ func (e *CodeEditor) OnRename(callback func(newPath string)) {
 }
 
 func (e *CodeEditor) open(headerText string) {
-	go e.watch()
 	e.load(headerText)
+	go e.watch()
 }
 
 func (e *CodeEditor) watcherSetup() {
@@ -128,6 +183,8 @@ func (e *CodeEditor) watch() {
 		log.Printf("Error trying to watch %s for changes: %s", e.filepath, err)
 		return
 	}
+	// The file may have been recreated while we were waiting for it.
+	e.scheduleDiskCheck()
 	defer e.watcher.Remove(e.filepath)
 	fileDir := filepath.Dir(e.filepath)
 	err = e.watcher.Add(fileDir)
@@ -153,12 +210,23 @@ func (e *CodeEditor) watch() {
 		}
 		switch ev.Op {
 		case fsw.Write:
-			e.load("")
+			e.scheduleDiskCheck()
+		case fsw.Create:
+			// The file was replaced, rather than written to - which
+			// is how files are saved atomically.
+			e.renamed = false
+			e.watcher.Add(e.filepath)
+			e.scheduleDiskCheck()
 		case fsw.Rename:
 			e.renamed = true
 		case fsw.Remove:
-			e.open("")
-			return
+			// Keep the text, in case the file is being replaced or
+			// the user wants to save it again.  If it has already
+			// been replaced, watch the new file; otherwise, the
+			// directory's watch will tell us when it's created.
+			if err := e.watcher.Add(e.filepath); err == nil {
+				e.scheduleDiskCheck()
+			}
 		}
 	}
 }
@@ -189,6 +257,10 @@ func (e *CodeEditor) load(headerText string) {
 	if os.IsNotExist(err) {
 		e.driver.Call(func() {
 			e.SetText(headerText)
+			if headerText == "" {
+				// There is nothing to save until the text changes.
+				e.setSaved("", e.FileFormat())
+			}
 		})
 		return
 	}
@@ -208,23 +280,31 @@ func (e *CodeEditor) load(headerText string) {
 		log.Printf("Error reading file %s: %s", e.filepath, err)
 		return
 	}
-	newText := string(b)
+	newText, format := textfile.Decode(b)
+	e.setSaved(newText, format)
 	if !strings.HasPrefix(newText, headerText) {
 		log.Printf("%s: header text does not match requested header text", e.filepath)
 	}
 	e.driver.Call(func() {
+		e.SetFileFormat(format)
+		defer e.highlightDiskCopy()
 		if e.Text() == newText {
 			return
 		}
 		e.SetText(newText)
+		e.setSaved(newText, format)
 		if len(e.selections) > 0 {
 			e.restorePositions()
 		}
 	})
 }
 
+// HasChanges returns whether e's text or format has changed since it
+// was last loaded from or saved to disk.
 func (e *CodeEditor) HasChanges() bool {
-	return e.hasChanges
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	return e.version != e.savedVersion || e.format != e.savedFormat
 }
 
 func (e *CodeEditor) LastKnownMTime() time.Time {
@@ -244,22 +324,84 @@ func (e *CodeEditor) Filepath() string {
 }
 
 func (e *CodeEditor) FlushedChanges() {
-	e.hasChanges = false
+	e.setSaved(e.Text(), e.FileFormat())
 	e.setLastModified(time.Now())
 }
 
+func (e *CodeEditor) setSaved(text string, format textfile.Format) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.saved = text
+	e.savedFormat = format
+	e.savedVersion = e.version
+}
+
+// SetReadOnly sets whether e refuses edits.  Read-only editors are
+// for text that isn't meant to be saved, like generated views of
+// other files.
+func (e *CodeEditor) SetReadOnly(readOnly bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.readOnly = readOnly
+}
+
+// ReadOnly returns whether e refuses edits.
+func (e *CodeEditor) ReadOnly() bool {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	return e.readOnly
+}
+
+// Elements returns the controller of e's text box.  Its text isn't
+// brought up to date first, because hooks that run while edits are
+// applied execute commands; it catches up before the next UI event.
 func (e *CodeEditor) Elements() []interface{} {
 	return []interface{}{
-		e.Controller(),
+		e.CodeEditor.Controller(),
 	}
 }
 
+// SetSyntaxLayers replaces e's syntax layers.  Layers set with
+// SetOwnedLayers are kept.
 func (e *CodeEditor) SetSyntaxLayers(layers []input.SyntaxLayer) {
+	e.layers = layers
+	e.paintLayers()
+}
+
+func (e *CodeEditor) SyntaxLayers() []input.SyntaxLayer {
+	return e.layers
+}
+
+// SetOwnedLayers replaces the layers that owner displays on top of
+// e's syntax layers.  Nil layers remove owner's layers.  It must be
+// called on the UI goroutine.
+func (e *CodeEditor) SetOwnedLayers(owner string, layers []input.SyntaxLayer) {
+	if e.ownedLayers == nil {
+		e.ownedLayers = make(map[string][]input.SyntaxLayer)
+	}
+	if layers == nil {
+		delete(e.ownedLayers, owner)
+	} else {
+		e.ownedLayers[owner] = layers
+	}
+	e.paintLayers()
+}
+
+// paintLayers passes e's syntax layers and owned layers to gxui.
+func (e *CodeEditor) paintLayers() {
 	defer e.syntaxTheme.Rainbow.Reset()
-	sort.Slice(layers, func(i, j int) bool {
+	owners := make([]string, 0, len(e.ownedLayers))
+	for owner := range e.ownedLayers {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+	layers := append([]input.SyntaxLayer(nil), e.layers...)
+	for _, owner := range owners {
+		layers = append(layers, e.ownedLayers[owner]...)
+	}
+	sort.SliceStable(layers, func(i, j int) bool {
 		return layers[i].Construct < layers[j].Construct
 	})
-	e.layers = layers
 	gLayers := make(gxui.CodeSyntaxLayers, 0, len(layers))
 	for _, l := range layers {
 		highlight, found := e.syntaxTheme.Constructs[l.Construct]
@@ -277,12 +419,9 @@ func (e *CodeEditor) SetSyntaxLayers(layers []input.SyntaxLayer) {
 	e.CodeEditor.SetSyntaxLayers(gLayers)
 }
 
-func (e *CodeEditor) SyntaxLayers() []input.SyntaxLayer {
-	return e.layers
-}
-
 func (e *CodeEditor) Paint(c gxui.Canvas) {
 	e.CodeEditor.Paint(c)
+	e.paintFormat(c)
 
 	if e.HasFocus() {
 		r := e.Size().Rect()
@@ -316,14 +455,9 @@ func (e *CodeEditor) KeyPress(event gxui.KeyboardEvent) bool {
 		// These are all bindings that the TextBox handles fine.
 		return e.TextBox.KeyPress(event)
 	case gxui.KeyTab:
-		// TODO: Gain knowledge about scope, so we know how much to indent.
-		switch {
-		case event.Modifier.Shift():
-			e.Controller().UnindentSelection()
-		default:
-			e.Controller().IndentSelection()
-		}
-		return true
+		// The input handler indents the selection, unless one of its
+		// hooks (like a snippet's tab stops) consumes the tab.
+		return false
 	case gxui.KeyEscape:
 		// TODO: Keep track of some sort of concept of a "focused" caret and
 		// focus that.
@@ -338,14 +472,20 @@ func (e *CodeEditor) KeyStroke(event gxui.KeyStrokeEvent) (consume bool) {
 
 func (e *CodeEditor) CreateLine(theme gxui.Theme, index int) (mixins.TextBoxLine, gxui.Control) {
 	lineNumber := theme.CreateLabel()
-	lineNumber.SetText(fmt.Sprintf("%4d", index+1))
+	lineNumber.SetText(fmt.Sprintf("%4d%s", index+1, e.foldMarker(index)))
 	lineNumber.SetMargin(math.Spacing{L: 0, T: 0, R: 3, B: 0})
+	lineNumber.OnClick(func(gxui.MouseEvent) {
+		e.gutterClicked(index)
+	})
 
 	line := &mixins.CodeEditorLine{}
 	line.Init(line, theme, &e.CodeEditor, index)
 
 	layout := theme.CreateLinearLayout()
 	layout.SetDirection(gxui.LeftToRight)
+	for _, mark := range e.gutterLabels(theme, index) {
+		layout.AddChild(mark)
+	}
 	layout.AddChild(lineNumber)
 	layout.AddChild(line)
 
@@ -0,0 +1,198 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package editor
+
+import (
+	"sort"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/input"
+)
+
+const (
+	foldableMarker = "▾"
+	foldedMarker   = "▸"
+	noMarker       = " "
+)
+
+// lineRange is an inclusive range of line indexes.
+type lineRange struct {
+	start, end int
+}
+
+// foldAdapter wraps the gxui.ListAdapter that displays lines in the
+// editor, skipping over lines that are hidden by folded regions.
+// Items are still the lines' items, so only visible indexes need to
+// be translated.
+type foldAdapter struct {
+	gxui.ListAdapter
+
+	// lines returns the number of lines in the editor, which may be
+	// ahead of the wrapped adapter's count while the text box
+	// catches up with edits.
+	lines func() int
+
+	// hidden is a sorted list of non-overlapping line ranges that
+	// are hidden.
+	hidden []lineRange
+}
+
+func (a *foldAdapter) Count() int {
+	n := a.lines()
+	for _, r := range a.hidden {
+		n -= r.end - r.start + 1
+	}
+	return n
+}
+
+// line returns the line index displayed at the visible index.
+func (a *foldAdapter) line(index int) int {
+	for _, r := range a.hidden {
+		if r.start > index {
+			break
+		}
+		index += r.end - r.start + 1
+	}
+	return index
+}
+
+// index returns the visible index that line is displayed at.
+// Hidden lines return the index of the line that their folded
+// region starts on.
+func (a *foldAdapter) index(line int) int {
+	hidden := 0
+	for _, r := range a.hidden {
+		if r.start > line {
+			break
+		}
+		if line <= r.end {
+			return r.start - 1 - hidden
+		}
+		hidden += r.end - r.start + 1
+	}
+	return line - hidden
+}
+
+func (a *foldAdapter) ItemAt(index int) gxui.AdapterItem {
+	return a.ListAdapter.ItemAt(a.line(index))
+}
+
+func (a *foldAdapter) ItemIndex(item gxui.AdapterItem) int {
+	return a.index(a.ListAdapter.ItemIndex(item))
+}
+
+func (a *foldAdapter) Create(theme gxui.Theme, index int) gxui.Control {
+	return a.ListAdapter.Create(theme, a.line(index))
+}
+
+func (e *CodeEditor) initFolds() {
+	e.folds = &foldAdapter{
+		ListAdapter: e.Adapter(),
+		lines:       e.lineCount,
+	}
+	e.SetAdapter(e.folds)
+}
+
+// FoldRegions returns the regions of e that may be folded.
+func (e *CodeEditor) FoldRegions() []input.FoldRegion {
+	return append([]input.FoldRegion(nil), e.foldRegions...)
+}
+
+// SetFoldRegions sets the regions of e that may be folded.  It is
+// up to language plugins to set them.
+func (e *CodeEditor) SetFoldRegions(regions []input.FoldRegion) {
+	oldStarts := e.foldStarts(e.foldRegions)
+	e.foldRegions = regions
+	newStarts := e.foldStarts(regions)
+	if len(oldStarts) == len(newStarts) {
+		same := true
+		for l := range newStarts {
+			if !oldStarts[l] {
+				same = false
+				break
+			}
+		}
+		if same {
+			return
+		}
+	}
+	e.DataChanged(true)
+}
+
+// Folded returns the regions of e that are currently folded.
+func (e *CodeEditor) Folded() []input.FoldRegion {
+	return append([]input.FoldRegion(nil), e.folded...)
+}
+
+// SetFolded sets the regions of e that are folded, hiding every line
+// in each region after the line that it starts on.
+func (e *CodeEditor) SetFolded(folded []input.FoldRegion) {
+	e.folded = folded
+	var hidden []lineRange
+	for _, r := range folded {
+		l := lineRange{
+			start: e.LineIndex(r.Start) + 1,
+			end:   e.LineIndex(r.End),
+		}
+		if l.end < l.start {
+			continue
+		}
+		hidden = append(hidden, l)
+	}
+	sort.Slice(hidden, func(i, j int) bool {
+		return hidden[i].start < hidden[j].start
+	})
+	merged := hidden[:0]
+	for _, l := range hidden {
+		if len(merged) > 0 && l.start <= merged[len(merged)-1].end+1 {
+			if l.end > merged[len(merged)-1].end {
+				merged[len(merged)-1].end = l.end
+			}
+			continue
+		}
+		merged = append(merged, l)
+	}
+	if sameRanges(e.folds.hidden, merged) {
+		return
+	}
+	e.folds.hidden = merged
+	e.DataChanged(true)
+}
+
+func sameRanges(a, b []lineRange) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *CodeEditor) foldStarts(regions []input.FoldRegion) map[int]bool {
+	starts := make(map[int]bool, len(regions))
+	for _, r := range regions {
+		starts[e.LineIndex(r.Start)] = true
+	}
+	return starts
+}
+
+// foldMarker returns the marker to display in the gutter for the
+// line at index.
+func (e *CodeEditor) foldMarker(index int) string {
+	for _, r := range e.folded {
+		if e.LineIndex(r.Start) == index {
+			return foldedMarker
+		}
+	}
+	for _, r := range e.foldRegions {
+		if e.LineIndex(r.Start) == index {
+			return foldableMarker
+		}
+	}
+	return noMarker
+}
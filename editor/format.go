@@ -0,0 +1,58 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package editor
+
+import (
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/gxui/math"
+	"github.com/nelsam/vidar/textfile"
+)
+
+// formatMargin is the space left between the file format indicator
+// and the right edge of the editor, so that it stays clear of the
+// scroll bar.
+const formatMargin = 16
+
+var formatBackground = gxui.Color{
+	R: 0.1,
+	G: 0.1,
+	B: 0.1,
+	A: 0.8,
+}
+
+// FileFormat returns the encoding and line endings that e's file is
+// saved with.
+func (e *CodeEditor) FileFormat() textfile.Format {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	return e.format
+}
+
+// SetFileFormat changes the encoding and line endings that e's file
+// is saved with.  The file is converted the next time it is saved.
+// It must be called on the UI goroutine.
+func (e *CodeEditor) SetFileFormat(f textfile.Format) {
+	e.lock.Lock()
+	e.format = f
+	e.lock.Unlock()
+	e.Redraw()
+}
+
+// paintFormat draws the file format in the bottom right corner of e.
+func (e *CodeEditor) paintFormat(c gxui.Canvas) {
+	runes := []rune(e.FileFormat().String())
+	font := e.Font()
+	bounds := e.Size().Rect().Contract(e.Padding())
+	size := font.Measure(&gxui.TextBlock{Runes: runes})
+	rect := math.CreateRect(bounds.Max.X-formatMargin-size.W, bounds.Max.Y-size.H, bounds.Max.X-formatMargin, bounds.Max.Y)
+	c.DrawRect(rect.Expand(math.CreateSpacing(2)), gxui.CreateBrush(formatBackground))
+	offsets := font.Layout(&gxui.TextBlock{
+		Runes:     runes,
+		AlignRect: rect,
+		H:         gxui.AlignLeft,
+		V:         gxui.AlignMiddle,
+	})
+	c.DrawRunes(font, runes, offsets, gxui.Gray50)
+}
@@ -0,0 +1,100 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package editor
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/gxui/math"
+	"github.com/nelsam/vidar/commander/input"
+)
+
+// SetGutterMarks replaces the marks that owner displays in e's
+// gutter.  Nil marks remove owner's column.  It must be called on
+// the UI goroutine.
+func (e *CodeEditor) SetGutterMarks(owner string, marks map[int]input.GutterMark) {
+	e.gutterLock.Lock()
+	if e.gutterMarks == nil {
+		e.gutterMarks = make(map[string]map[int]input.GutterMark)
+		e.gutterWidths = make(map[string]int)
+	}
+	_, existed := e.gutterMarks[owner]
+	switch {
+	case marks == nil:
+		delete(e.gutterMarks, owner)
+		delete(e.gutterWidths, owner)
+		if existed {
+			i := sort.SearchStrings(e.gutterOwners, owner)
+			e.gutterOwners = append(e.gutterOwners[:i], e.gutterOwners[i+1:]...)
+		}
+	default:
+		e.gutterMarks[owner] = marks
+		width := 1
+		for _, m := range marks {
+			if w := utf8.RuneCountInString(m.Text); w > width {
+				width = w
+			}
+		}
+		e.gutterWidths[owner] = width
+		if !existed {
+			e.gutterOwners = append(e.gutterOwners, owner)
+			sort.Strings(e.gutterOwners)
+		}
+	}
+	e.gutterLock.Unlock()
+	e.DataChanged(true)
+}
+
+// OnGutterClick registers f to be called when a line number in e's
+// gutter is clicked.
+func (e *CodeEditor) OnGutterClick(f func(line int)) {
+	e.gutterLock.Lock()
+	defer e.gutterLock.Unlock()
+	e.onGutterClick = append(e.onGutterClick, f)
+}
+
+func (e *CodeEditor) gutterClicked(line int) {
+	e.gutterLock.RLock()
+	callbacks := append([]func(int){}, e.onGutterClick...)
+	e.gutterLock.RUnlock()
+	for _, f := range callbacks {
+		f(line)
+	}
+}
+
+// gutterLabels returns a label for each owner's mark on the line at
+// index, in a stable order.  Clicking a mark calls its OnClick, or
+// counts as a click in the gutter if it has none.  Owners without a
+// mark on the line get a blank label, so that line numbers stay
+// aligned.
+func (e *CodeEditor) gutterLabels(theme gxui.Theme, index int) []gxui.Control {
+	e.gutterLock.RLock()
+	defer e.gutterLock.RUnlock()
+	labels := make([]gxui.Control, 0, len(e.gutterOwners))
+	for _, owner := range e.gutterOwners {
+		width := e.gutterWidths[owner]
+		l := theme.CreateLabel()
+		l.SetText(strings.Repeat(" ", width))
+		m, ok := e.gutterMarks[owner][index]
+		if ok {
+			pad := width - utf8.RuneCountInString(m.Text)
+			l.SetText(m.Text + strings.Repeat(" ", pad))
+			l.SetColor(m.Color)
+		}
+		l.SetMargin(math.Spacing{R: 1})
+		l.OnClick(func(gxui.MouseEvent) {
+			if m.OnClick != nil {
+				m.OnClick()
+				return
+			}
+			e.gutterClicked(index)
+		})
+		labels = append(labels, l)
+	}
+	return labels
+}
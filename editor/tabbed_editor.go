@@ -6,7 +6,6 @@ package editor
 
 import (
 	"log"
-	"os"
 	"path/filepath"
 	"strings"
 
@@ -16,6 +15,9 @@ import (
 	"github.com/nelsam/gxui/themes/basic"
 	"github.com/nelsam/vidar/command/focus"
 	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/safefile"
+	"github.com/nelsam/vidar/setting"
+	"github.com/nelsam/vidar/textfile"
 	"github.com/nelsam/vidar/theme"
 )
 
@@ -85,8 +87,11 @@ func (e *TabbedEditor) Open(hiddenPrefix, path, headerText string, environ []str
 			gxui.SetFocus(focused.(gxui.Focusable))
 		})
 	})
-	ce.Init(e.driver, e.theme, e.syntaxTheme, e.font, path, headerText)
+	ce.Init(e.driver, e.cmdr, e.theme, e.syntaxTheme, e.font, path, headerText)
 	ce.SetTabWidth(4)
+	if isDiskCopy(path) {
+		ce.SetReadOnly(true)
+	}
 	e.Add(name, editor)
 	return editor, false
 }
@@ -202,14 +207,30 @@ func (e *TabbedEditor) CloseCurrentEditor() (name string, editor input.Editor) {
 }
 
 func (e *TabbedEditor) SaveAll() {
-	for name, editor := range e.editors {
-		f, err := os.Create(name)
+	backup := setting.Backups()
+	for _, editor := range e.editors {
+		path := editor.Filepath()
+		format := textfile.Default
+		ce, isCode := editor.(*CodeEditor)
+		if isCode {
+			format = ce.FileFormat()
+		}
+		data, err := textfile.Encode(editor.Text(), format)
 		if err != nil {
-			log.Printf("Could not save %s : %s", name, err)
+			log.Printf("Could not save %s: %s", path, err)
+			continue
+		}
+		if backup.Dir != "" {
+			if err := safefile.Backup(path, backup.Dir, backup.Count); err != nil {
+				log.Printf("Could not back up %s: %s", path, err)
+			}
+		}
+		if err := safefile.Write(path, data); err != nil {
+			log.Printf("Could not write to file %s: %s", path, err)
+			continue
 		}
-		defer f.Close()
-		if _, err := f.WriteString(editor.Text()); err != nil {
-			log.Printf("Could not write to file %s: %s", name, err)
+		if isCode {
+			ce.FlushedChanges()
 		}
 	}
 }
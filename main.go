@@ -83,6 +83,11 @@ func uiMain(driver gxui.Driver) {
 	controller.SetEditor(editor)
 
 	projTree := navigator.NewProjectTree(cmdr, driver, window, gTheme)
+	for _, b := range bindings {
+		if h, ok := b.(navigator.TreeHook); ok {
+			projTree.AddHook(h)
+		}
+	}
 	projects := navigator.NewProjectsPane(cmdr, driver, gTheme, projTree.Frame())
 
 	nav.Add(projects)
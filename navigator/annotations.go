@@ -0,0 +1,62 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package navigator
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/nelsam/gxui"
+)
+
+// Annotation is a short note displayed after the name of a file or
+// directory in the project tree, e.g. its test coverage.
+type Annotation struct {
+	Text  string
+	Color gxui.Color
+}
+
+// annotations holds the annotations that each owner has set, keyed
+// by path.
+type annotations struct {
+	mu      sync.RWMutex
+	owners  []string
+	byOwner map[string]map[string]Annotation
+}
+
+func (a *annotations) set(owner string, notes map[string]Annotation) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.byOwner == nil {
+		a.byOwner = make(map[string]map[string]Annotation)
+	}
+	if len(notes) == 0 {
+		delete(a.byOwner, owner)
+	} else {
+		a.byOwner[owner] = notes
+	}
+	a.owners = a.owners[:0]
+	for o := range a.byOwner {
+		a.owners = append(a.owners, o)
+	}
+	sort.Strings(a.owners)
+}
+
+// For returns the annotations for path, sorted by owner so that
+// they are displayed in a stable order.
+func (a *annotations) For(path string) []Annotation {
+	if a == nil {
+		return nil
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	var notes []Annotation
+	for _, o := range a.owners {
+		if n, ok := a.byOwner[o][path]; ok {
+			notes = append(notes, n)
+		}
+	}
+	return notes
+}
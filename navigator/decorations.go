@@ -0,0 +1,120 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package navigator
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/nelsam/gxui"
+)
+
+// Colorer returns the color that the name of the file or directory
+// at path should be displayed in, if it should be changed.
+type Colorer func(path string) (gxui.Color, bool)
+
+// Filter returns whether the file or directory at path should be
+// displayed in the project tree.
+type Filter func(path string, dir bool) bool
+
+// Decoratable is a tree of files that can be decorated, like the
+// project tree.
+type Decoratable interface {
+	SetAnnotations(owner string, annotations map[string]Annotation)
+	SetColors(owner string, colors Colorer)
+	SetFilter(owner string, filter Filter)
+}
+
+// A TreeHook is told about changes to the files in the project tree,
+// so that it can decorate them.
+type TreeHook interface {
+	// RootChanged is called when the root directory of tree
+	// changes, including when the hook is added.
+	RootChanged(tree Decoratable, root string)
+
+	// Changed is called with the path of each file or directory that
+	// the tree's watcher reports a change to.
+	Changed(path string)
+}
+
+// decorations holds the colors and filters that each owner has set.
+type decorations struct {
+	mu      sync.RWMutex
+	owners  []string
+	colors  map[string]Colorer
+	filters map[string]Filter
+}
+
+func (d *decorations) setColors(owner string, c Colorer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.colors == nil {
+		d.colors = make(map[string]Colorer)
+	}
+	if c == nil {
+		delete(d.colors, owner)
+	} else {
+		d.colors[owner] = c
+	}
+	d.owners = d.owners[:0]
+	for o := range d.colors {
+		d.owners = append(d.owners, o)
+	}
+	sort.Strings(d.owners)
+}
+
+func (d *decorations) setFilter(owner string, f Filter) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.filters == nil {
+		d.filters = make(map[string]Filter)
+	}
+	if f == nil {
+		delete(d.filters, owner)
+		return
+	}
+	d.filters[owner] = f
+}
+
+// Color returns the color for path.  When more than one owner colors
+// path, the first owner in sorted order wins.
+func (d *decorations) Color(path string) (gxui.Color, bool) {
+	if d == nil {
+		return gxui.Color{}, false
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, o := range d.owners {
+		if c, ok := d.colors[o](path); ok {
+			return c, true
+		}
+	}
+	return gxui.Color{}, false
+}
+
+// Show returns whether path passes every owner's filter.
+func (d *decorations) Show(path string, dir bool) bool {
+	if d == nil {
+		return true
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, f := range d.filters {
+		if !f(path, dir) {
+			return false
+		}
+	}
+	return true
+}
+
+// decorate sets the color of b's name for path.
+func (d *decorations) decorate(b *treeButton, path string) {
+	c, ok := d.Color(path)
+	if !ok {
+		b.SetDecoration(nil)
+		return
+	}
+	b.SetDecoration(&c)
+}
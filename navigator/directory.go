@@ -43,6 +43,8 @@ func newDirectory(projTree *ProjectTree, path string, watcher Watcher) *director
 	theme := projTree.theme
 
 	button := newTreeButton(driver, theme, filepath.Base(path))
+	button.SetAnnotations(projTree.notes.For(path))
+	projTree.decs.decorate(button, path)
 	tree := newDirTree(projTree, path)
 	tree.SetMargin(math.Spacing{L: 10})
 	d := &directory{
@@ -57,7 +59,7 @@ func newDirectory(projTree *ProjectTree, path string, watcher Watcher) *director
 		if projTree.tocCtl != nil {
 			projTree.layout.RemoveChild(projTree.tocCtl)
 		}
-		toc := NewTOC(projTree.cmdr, projTree.driver, projTree.theme, path)
+		toc := newTOC(projTree.cmdr, projTree.driver, projTree.theme, path, projTree.notes, projTree.decs)
 		projTree.SetTOC(toc)
 		scrollable := theme.CreateScrollLayout()
 		// Disable horiz scrolling until we can figure out an accurate
@@ -76,14 +78,19 @@ func newDirectory(projTree *ProjectTree, path string, watcher Watcher) *director
 			d.RemoveChild(d.tree)
 			return
 		}
-		d.tree.Load(watcher)
-		d.button.Expand()
-		d.AddChild(d.tree)
+		d.expand()
 	})
 	d.reload()
 	return d
 }
 
+// expand loads d's children and displays them.
+func (d *directory) expand() {
+	d.tree.Load(d.watcher)
+	d.button.Expand()
+	d.AddChild(d.tree)
+}
+
 func (d *directory) update(path string) {
 	if !strings.HasPrefix(path, d.tree.path) {
 		return
@@ -97,6 +104,51 @@ func (d *directory) update(path string) {
 	}
 }
 
+// annotate updates the annotations on d and any of its loaded
+// children.
+func (d *directory) annotate(notes *annotations) {
+	d.button.SetAnnotations(notes.For(d.tree.path))
+	if !d.tree.Attached() {
+		return
+	}
+	for _, child := range d.tree.Dirs() {
+		child.annotate(notes)
+	}
+}
+
+// decorate updates the decorations on d and any of its loaded
+// children.
+func (d *directory) decorate(decs *decorations) {
+	decs.decorate(d.button, d.tree.path)
+	if !d.tree.Attached() {
+		return
+	}
+	for _, child := range d.tree.Dirs() {
+		child.decorate(decs)
+	}
+}
+
+// refilter reloads d and any of its loaded children, so that they
+// match the tree's filters.
+func (d *directory) refilter() {
+	if !d.tree.Attached() {
+		return
+	}
+	expanded := make(map[string]bool)
+	for _, child := range d.tree.Dirs() {
+		if child.tree.Attached() {
+			expanded[child.tree.path] = true
+		}
+	}
+	d.reload()
+	for _, child := range d.tree.Dirs() {
+		if expanded[child.tree.path] {
+			child.expand()
+			child.refilter()
+		}
+	}
+}
+
 func (d *directory) ExpandTo(dir string) {
 	if !strings.HasPrefix(dir, d.tree.path) {
 		return
@@ -211,6 +263,9 @@ func (d *dirTree) parse(finfos []os.FileInfo, w Watcher) {
 			continue
 		}
 		fullPath := filepath.Join(d.path, finfo.Name())
+		if !d.projTree.decs.Show(fullPath, true) {
+			continue
+		}
 		dir := newDirectory(d.projTree, fullPath, w)
 		d.AddChild(dir)
 	}
@@ -70,6 +70,13 @@ type ProjectTree struct {
 	watcher    fsw.Watcher
 	reloadLock chan struct{}
 
+	notes *annotations
+	decs  *decorations
+
+	root      string
+	hooks     []TreeHook
+	hooksLock sync.RWMutex
+
 	layout *splitterLayout
 }
 
@@ -79,6 +86,8 @@ func NewProjectTree(cmdr Commander, driver gxui.Driver, window gxui.Window, them
 		driver:     driver,
 		theme:      theme,
 		reloadLock: make(chan struct{}, 1),
+		notes:      &annotations{},
+		decs:       &decorations{},
 		button:     createIconButton(driver, theme, "folder.png"),
 		layout:     newSplitterLayout(window, theme),
 	}
@@ -117,6 +126,13 @@ func (p *ProjectTree) Button() gxui.Button {
 }
 
 func (p *ProjectTree) SetRoot(path string) {
+	p.hooksLock.Lock()
+	p.root = path
+	hooks := append([]TreeHook(nil), p.hooks...)
+	p.hooksLock.Unlock()
+	for _, h := range hooks {
+		h.RootChanged(p, path)
+	}
 	p.layout.RemoveAll()
 	p.SetTOC(nil)
 	p.tocCtl = nil
@@ -164,6 +180,11 @@ func (p *ProjectTree) watch() {
 		switch e.Op {
 		case fsw.Write, fsw.Create, fsw.Remove, fsw.Rename:
 			go p.update(e.Path)
+			p.hooksLock.RLock()
+			for _, h := range p.hooks {
+				go h.Changed(e.Path)
+			}
+			p.hooksLock.RUnlock()
 		}
 	}
 }
@@ -203,6 +224,59 @@ func (p *ProjectTree) update(path string) {
 	}
 }
 
+// SetAnnotations replaces the annotations that owner displays beside
+// files and directories in the tree.  annotations is keyed by path;
+// a nil map removes all of owner's annotations.
+func (p *ProjectTree) SetAnnotations(owner string, annotations map[string]Annotation) {
+	p.notes.set(owner, annotations)
+	p.driver.Call(func() {
+		if p.dirs != nil {
+			p.dirs.annotate(p.notes)
+		}
+		if toc := p.TOC(); toc != nil {
+			toc.annotate(p.notes)
+		}
+	})
+}
+
+// SetColors replaces the colors that owner displays the names of
+// files and directories in.  A nil colors removes owner's colors.
+func (p *ProjectTree) SetColors(owner string, colors Colorer) {
+	p.decs.setColors(owner, colors)
+	p.driver.Call(func() {
+		if p.dirs != nil {
+			p.dirs.decorate(p.decs)
+		}
+		if toc := p.TOC(); toc != nil {
+			toc.decorate(p.decs)
+		}
+	})
+}
+
+// SetFilter replaces the filter that owner uses to hide files and
+// directories in the tree.  A nil filter removes owner's filter.
+func (p *ProjectTree) SetFilter(owner string, filter Filter) {
+	p.decs.setFilter(owner, filter)
+	p.driver.Call(func() {
+		if p.dirs != nil {
+			p.dirs.refilter()
+		}
+		if toc := p.TOC(); toc != nil {
+			toc.Reload()
+		}
+	})
+}
+
+// AddHook adds h to the hooks that are told about changes to p's
+// files.  h is told about p's current root immediately.
+func (p *ProjectTree) AddHook(h TreeHook) {
+	p.hooksLock.Lock()
+	p.hooks = append(p.hooks, h)
+	root := p.root
+	p.hooksLock.Unlock()
+	h.RootChanged(p, root)
+}
+
 func (p *ProjectTree) SetProject(project setting.Project) {
 	// Ensure that the project tree is the current pane before
 	// the UI goroutine does our relayout/redraw logic.
@@ -9,6 +9,7 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"io/ioutil"
 	"log"
 	"os"
@@ -183,18 +184,31 @@ func (p *packageNode) addTypes(types ...*Name) {
 		// Since we can't guarantee that we parsed the type declaration before
 		// any method declarations, we have to check to see if the type already
 		// exists.
-		existingType, ok := p.typeMap[typ.button.Text()]
+		key := typeKey(typ.button.Text())
+		existingType, ok := p.typeMap[key]
 		if !ok {
-			p.typeMap[typ.button.Text()] = typ
+			p.typeMap[key] = typ
 			p.types.AddChild(typ)
 			continue
 		}
+		existingType.button.SetText(typ.button.Text())
 		existingType.button.Label().SetColor(typ.button.Label().Color())
 		existingType.filepath = typ.filepath
 		existingType.position = typ.position
 	}
 }
 
+// typeKey returns the key that a type is stored under in a
+// packageNode's typeMap.  Type parameter lists are stripped so that
+// methods (whose receivers only name the type) can be matched to
+// generic types.
+func typeKey(name string) string {
+	if i := strings.Index(name, "["); i >= 0 {
+		return name[:i]
+	}
+	return name
+}
+
 func (p *packageNode) addFuncs(funcs ...*Name) {
 	for _, f := range funcs {
 		p.funcs.AddChild(f)
@@ -251,16 +265,25 @@ type TOC struct {
 	dir        string
 	fileSet    *token.FileSet
 	packageMap map[string]*packageNode
+	notes      *annotations
+	decs       *decorations
+	files      []*Name
 
 	lock sync.Mutex
 }
 
 func NewTOC(cmdr Commander, driver gxui.Driver, theme gxui.Theme, dir string) *TOC {
+	return newTOC(cmdr, driver, theme, dir, nil, nil)
+}
+
+func newTOC(cmdr Commander, driver gxui.Driver, theme gxui.Theme, dir string, notes *annotations, decs *decorations) *TOC {
 	toc := &TOC{
 		cmdr:   cmdr,
 		driver: driver,
 		theme:  theme,
 		dir:    dir,
+		notes:  notes,
+		decs:   decs,
 	}
 	toc.Init(toc, theme)
 	toc.Reload()
@@ -274,6 +297,7 @@ func (t *TOC) Reload() {
 	t.fileSet = token.NewFileSet()
 	t.RemoveAll()
 	t.packageMap = make(map[string]*packageNode)
+	t.files = nil
 	allFiles, err := ioutil.ReadDir(t.dir)
 	if err != nil {
 		log.Printf("Received error reading directory %s: %s", t.dir, err)
@@ -301,12 +325,37 @@ func (t *TOC) parseFiles(dir string, files ...os.FileInfo) {
 		if file.IsDir() {
 			continue
 		}
+		path := filepath.Join(dir, file.Name())
+		if !t.decs.Show(path, false) {
+			continue
+		}
 		fileNode := t.parseFile(dir, file)
-		fileNode.filepath = filepath.Join(dir, file.Name())
+		fileNode.filepath = path
+		fileNode.button.SetAnnotations(t.notes.For(fileNode.filepath))
+		t.decs.decorate(fileNode.button, fileNode.filepath)
+		t.files = append(t.files, fileNode)
 		filesNode.AddChild(fileNode)
 	}
 }
 
+// annotate updates the annotations on t's files.
+func (t *TOC) annotate(notes *annotations) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for _, f := range t.files {
+		f.button.SetAnnotations(notes.For(f.filepath))
+	}
+}
+
+// decorate updates the decorations on t's files.
+func (t *TOC) decorate(decs *decorations) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for _, f := range t.files {
+		decs.decorate(f.button, f.filepath)
+	}
+}
+
 func (t *TOC) parseFile(dir string, file os.FileInfo) *Name {
 	if !strings.HasSuffix(file.Name(), ".go") {
 		return newName(t.cmdr, t.driver, t.theme, file.Name(), nonGoColor)
@@ -341,7 +390,7 @@ func (t *TOC) parseAstFile(filepath string, file *ast.File) *packageNode {
 				// doesn't really help us in the TOC.
 				continue
 			}
-			text := src.Name.String()
+			text := src.Name.String() + typeParams(src.Type.TypeParams)
 			if buildTagLine != "" {
 				text = fmt.Sprintf("%s (%s)", text, buildTagLine)
 			}
@@ -356,11 +405,11 @@ func (t *TOC) parseAstFile(filepath string, file *ast.File) *packageNode {
 				log.Printf("Incorrect definition for %s function\n", text)
 				continue
 			}
-			recvTyp := src.Recv.List[0].Type
-			if starExpr, ok := recvTyp.(*ast.StarExpr); ok {
-				recvTyp = starExpr.X
+			recvTypeName, ok := recvName(src.Recv.List[0].Type)
+			if !ok {
+				log.Printf("Could not find receiver type name for %s method\n", text)
+				continue
 			}
-			recvTypeName := recvTyp.(*ast.Ident).String()
 			pkgNode.addMethod(recvTypeName, name)
 		}
 	}
@@ -376,7 +425,7 @@ func (t *TOC) parseGenDecl(pkgNode *packageNode, decl *ast.GenDecl, filepath, bu
 	case "type":
 		// I have yet to see a case where a type declaration has len(Specs) != 0.
 		typeSpec := decl.Specs[0].(*ast.TypeSpec)
-		name := typeSpec.Name.String()
+		name := typeSpec.Name.String() + typeParams(typeSpec.TypeParams)
 		if buildTags != "" {
 			name = fmt.Sprintf("%s (%s)", name, buildTags)
 		}
@@ -387,6 +436,46 @@ func (t *TOC) parseGenDecl(pkgNode *packageNode, decl *ast.GenDecl, filepath, bu
 	}
 }
 
+// recvName returns the name of the type in a method receiver,
+// stripping any pointer and type parameters (e.g. *List[T] returns
+// List).
+func recvName(recv ast.Expr) (string, bool) {
+	for {
+		switch src := recv.(type) {
+		case *ast.Ident:
+			return src.String(), true
+		case *ast.StarExpr:
+			recv = src.X
+		case *ast.ParenExpr:
+			recv = src.X
+		case *ast.IndexExpr:
+			recv = src.X
+		case *ast.IndexListExpr:
+			recv = src.X
+		default:
+			return "", false
+		}
+	}
+}
+
+// typeParams returns the text of a type parameter list (e.g.
+// "[K comparable, V any]"), or an empty string if there are no
+// type parameters.
+func typeParams(params *ast.FieldList) string {
+	if params == nil || len(params.List) == 0 {
+		return ""
+	}
+	fields := make([]string, 0, len(params.List))
+	for _, f := range params.List {
+		names := make([]string, 0, len(f.Names))
+		for _, n := range f.Names {
+			names = append(names, n.String())
+		}
+		fields = append(fields, strings.Join(names, ", ")+" "+types.ExprString(f.Type))
+	}
+	return "[" + strings.Join(fields, ", ") + "]"
+}
+
 func (t *TOC) valueNamesFrom(filepath, buildTags string, specs []ast.Spec) (names []*Name) {
 	for _, spec := range specs {
 		valSpec, ok := spec.(*ast.ValueSpec)
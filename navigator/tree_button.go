@@ -42,6 +42,11 @@ type treeButton struct {
 	driver gxui.Driver
 	theme  *basic.Theme
 	drop   *mixins.Label
+	notes  []*mixins.Label
+
+	// undecorated is the color of the button's name before it was
+	// decorated, or nil if it isn't decorated.
+	undecorated *gxui.Color
 
 	dropSet dropdownCharSet
 }
@@ -104,6 +109,44 @@ func (d *treeButton) SetExpandable(expandable bool) {
 	d.drop.SetText(text)
 }
 
+// SetAnnotations replaces the annotations displayed between the
+// button's name and its dropdown character.
+func (d *treeButton) SetAnnotations(notes []Annotation) {
+	for _, l := range d.notes {
+		d.RemoveChild(l)
+	}
+	d.notes = d.notes[:0]
+	if len(notes) == 0 {
+		return
+	}
+	d.RemoveChild(d.drop)
+	for _, n := range notes {
+		l := &mixins.Label{}
+		l.Init(l, d.theme, d.theme.DefaultMonospaceFont(), n.Color)
+		l.SetText(" " + n.Text)
+		d.AddChild(l)
+		d.notes = append(d.notes, l)
+	}
+	d.AddChild(d.drop)
+}
+
+// SetDecoration changes the color of the button's name to color, or
+// restores its original color if color is nil.
+func (d *treeButton) SetDecoration(color *gxui.Color) {
+	if color == nil {
+		if d.undecorated != nil {
+			d.Label().SetColor(*d.undecorated)
+			d.undecorated = nil
+		}
+		return
+	}
+	if d.undecorated == nil {
+		orig := d.Label().Color()
+		d.undecorated = &orig
+	}
+	d.Label().SetColor(*color)
+}
+
 func (d *treeButton) Expanded() bool {
 	return d.Expandable() && d.drop.Text() == fmt.Sprintf(" %c", d.dropSet.expanded)
 }
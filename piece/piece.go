@@ -0,0 +1,414 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package piece implements a piece table: an immutable text buffer
+// that is edited by building a new table which shares almost all of
+// its memory with the old one.
+//
+// The pieces are held in a balanced tree, so edits take O(log n)
+// time in the number of pieces, and keeping an old version of the
+// text around (a snapshot) costs nothing more than a pointer.
+package piece
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// maxPiece is the most runes that a single piece holds.  It bounds
+// the time spent splitting pieces and counting the lines in them.
+const maxPiece = 1024
+
+// buffer is the append-only buffer that inserted text is copied to.
+// It is shared by every table that was derived from the same table,
+// which lets runes typed one at a time be stored in a single piece.
+type buffer struct {
+	runes []rune
+}
+
+// Table is an immutable sequence of runes.  A nil or zero Table is
+// empty.
+//
+// Tables may be read from any number of goroutines, but edits to
+// tables that were derived from the same table must not be made
+// concurrently, since they share a buffer.
+type Table struct {
+	root *node
+	add  *buffer
+}
+
+// New returns a table holding a copy of runes.
+func New(runes []rune) *Table {
+	t := &Table{add: &buffer{}}
+	t.root = t.pieces(runes)
+	return t
+}
+
+// NewString returns a table holding s.
+func NewString(s string) *Table {
+	return New([]rune(s))
+}
+
+// Len returns the number of runes in t.
+func (t *Table) Len() int {
+	if t == nil {
+		return 0
+	}
+	return t.root.len()
+}
+
+// Lines returns the number of lines in t, which is one more than the
+// number of newlines in it.
+func (t *Table) Lines() int {
+	if t == nil {
+		return 1
+	}
+	return t.root.lines() + 1
+}
+
+// RuneAt returns the rune at offset i, which must be in the range
+// [0, t.Len()).
+func (t *Table) RuneAt(i int) rune {
+	n := t.root
+	for {
+		left := n.left.len()
+		switch {
+		case i < left:
+			n = n.left
+		case i < left+len(n.runes):
+			return n.runes[i-left]
+		default:
+			i -= left + len(n.runes)
+			n = n.right
+		}
+	}
+}
+
+// Slice returns a copy of the runes in the range [start, end).
+func (t *Table) Slice(start, end int) []rune {
+	if t == nil || start >= end {
+		return nil
+	}
+	return t.AppendSlice(make([]rune, 0, end-start), start, end)
+}
+
+// AppendSlice appends the runes in the range [start, end) to dst and
+// returns the extended slice, which lets a buffer be reused when
+// only part of it has changed.
+func (t *Table) AppendSlice(dst []rune, start, end int) []rune {
+	if t == nil {
+		return dst
+	}
+	t.root.walk(start, end, func(piece []rune) {
+		dst = append(dst, piece...)
+	})
+	return dst
+}
+
+// Runes returns a copy of every rune in t.
+func (t *Table) Runes() []rune {
+	return t.Slice(0, t.Len())
+}
+
+// String returns the text of t.
+func (t *Table) String() string {
+	if t == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.Grow(t.Len())
+	t.root.walk(0, t.Len(), func(piece []rune) {
+		for _, r := range piece {
+			b.WriteRune(r)
+		}
+	})
+	return b.String()
+}
+
+// LineStart returns the offset of the first rune on line, counting
+// from 0.  It returns false if t doesn't have that many lines.
+func (t *Table) LineStart(line int) (int, bool) {
+	if line == 0 {
+		return 0, true
+	}
+	if line < 0 || line >= t.Lines() {
+		return 0, false
+	}
+	n, k, offset := t.root, line, 0
+	for {
+		left := n.left.lines()
+		if k <= left {
+			n = n.left
+			continue
+		}
+		k -= left
+		offset += n.left.len()
+		if k <= n.newlines {
+			for i, r := range n.runes {
+				if r != '\n' {
+					continue
+				}
+				k--
+				if k == 0 {
+					return offset + i + 1, true
+				}
+			}
+		}
+		k -= n.newlines
+		offset += len(n.runes)
+		n = n.right
+	}
+}
+
+// LineOf returns the line that offset is on, counting from 0.
+func (t *Table) LineOf(offset int) int {
+	if t == nil {
+		return 0
+	}
+	line := 0
+	for n := t.root; n != nil; {
+		left := n.left.len()
+		if offset < left {
+			n = n.left
+			continue
+		}
+		line += n.left.lines()
+		offset -= left
+		if offset < len(n.runes) {
+			return line + count(n.runes[:offset])
+		}
+		line += n.newlines
+		offset -= len(n.runes)
+		n = n.right
+	}
+	return line
+}
+
+// Insert returns a table with runes inserted at offset at.
+func (t *Table) Insert(at int, runes []rune) *Table {
+	return t.Replace(at, 0, runes)
+}
+
+// Delete returns a table with the n runes starting at offset at
+// removed.
+func (t *Table) Delete(at, n int) *Table {
+	return t.Replace(at, n, nil)
+}
+
+// Replace returns a table with the n runes starting at offset at
+// replaced with runes.
+func (t *Table) Replace(at, n int, runes []rune) *Table {
+	if t == nil {
+		t = &Table{}
+	}
+	if t.add == nil {
+		t = &Table{root: t.root, add: &buffer{}}
+	}
+	if n == 0 && len(runes) == 0 {
+		return t
+	}
+	left, rest := split(t.root, at)
+	_, right := split(rest, n)
+	if len(runes) > 0 {
+		var ok bool
+		if left, ok = t.extend(left, runes); !ok {
+			left = merge(left, t.pieces(runes))
+		}
+	}
+	return &Table{root: merge(left, right), add: t.add}
+}
+
+// pieces copies runes to t's buffer and returns a tree of pieces
+// holding them.
+func (t *Table) pieces(runes []rune) *node {
+	var root *node
+	for stored := t.store(runes); len(stored) > 0; {
+		size := len(stored)
+		if size > maxPiece {
+			size = maxPiece
+		}
+		root = merge(root, newNode(stored[:size:size]))
+		stored = stored[size:]
+	}
+	return root
+}
+
+// store copies runes to the end of t's buffer and returns the copy.
+// Runes that don't fit in the buffer's capacity start a new buffer,
+// so that the copies returned earlier are never moved.
+func (t *Table) store(runes []rune) []rune {
+	add := t.add
+	if cap(add.runes)-len(add.runes) < len(runes) {
+		size := 4 * maxPiece
+		if len(runes) > size {
+			size = len(runes)
+		}
+		add.runes = make([]rune, 0, size)
+	}
+	start := len(add.runes)
+	add.runes = append(add.runes, runes...)
+	return add.runes[start:len(add.runes):len(add.runes)]
+}
+
+// extend appends runes to the last piece in n, if that piece was the
+// last thing stored in t's buffer and there is room for them.  This
+// keeps text that is typed one rune at a time in a single piece.
+func (t *Table) extend(n *node, runes []rune) (*node, bool) {
+	last := n.last()
+	if last == nil || len(last.runes)+len(runes) > maxPiece {
+		return n, false
+	}
+	add := t.add
+	end := len(add.runes)
+	if end == 0 || cap(add.runes)-end < len(runes) || &last.runes[len(last.runes)-1] != &add.runes[end-1] {
+		return n, false
+	}
+	start := end - len(last.runes)
+	add.runes = append(add.runes, runes...)
+	return n.withLast(add.runes[start:len(add.runes):len(add.runes)]), true
+}
+
+// node is a piece of text in a treap ordered by offset.
+type node struct {
+	runes       []rune
+	newlines    int
+	left, right *node
+	priority    uint32
+
+	// size and totalLines are the number of runes and newlines in
+	// the subtree rooted at this node.
+	size, totalLines int
+}
+
+func newNode(runes []rune) *node {
+	return update(&node{
+		runes:    runes,
+		newlines: count(runes),
+		priority: rand.Uint32(),
+	})
+}
+
+func (n *node) len() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *node) lines() int {
+	if n == nil {
+		return 0
+	}
+	return n.totalLines
+}
+
+func (n *node) last() *node {
+	if n == nil {
+		return nil
+	}
+	for n.right != nil {
+		n = n.right
+	}
+	return n
+}
+
+// with returns a copy of n with new children.
+func (n *node) with(left, right *node) *node {
+	c := *n
+	c.left, c.right = left, right
+	return update(&c)
+}
+
+// withPiece returns a copy of n holding runes.
+func (n *node) withPiece(runes []rune) *node {
+	c := *n
+	c.runes = runes
+	c.newlines = count(runes)
+	return update(&c)
+}
+
+// withLast returns a copy of n with its last piece replaced by runes.
+func (n *node) withLast(runes []rune) *node {
+	if n.right == nil {
+		return n.withPiece(runes)
+	}
+	return n.with(n.left, n.right.withLast(runes))
+}
+
+// walk calls f with the parts of each piece in the range [start, end)
+// of n, in order.
+func (n *node) walk(start, end int, f func([]rune)) {
+	if n == nil || start >= end {
+		return
+	}
+	left := n.left.len()
+	if start < left {
+		n.left.walk(start, end, f)
+	}
+	pStart, pEnd := start-left, end-left
+	if pStart < 0 {
+		pStart = 0
+	}
+	if pEnd > len(n.runes) {
+		pEnd = len(n.runes)
+	}
+	if pStart < pEnd {
+		f(n.runes[pStart:pEnd])
+	}
+	right := left + len(n.runes)
+	if end > right {
+		n.right.walk(start-right, end-right, f)
+	}
+}
+
+func update(n *node) *node {
+	n.size = n.left.len() + len(n.runes) + n.right.len()
+	n.totalLines = n.left.lines() + n.newlines + n.right.lines()
+	return n
+}
+
+// split returns the nodes holding the runes before and after offset
+// at in n, splitting the piece that at falls in.
+func split(n *node, at int) (*node, *node) {
+	if n == nil {
+		return nil, nil
+	}
+	left := n.left.len()
+	switch {
+	case at <= left:
+		l, r := split(n.left, at)
+		return l, n.with(r, n.right)
+	case at >= left+len(n.runes):
+		l, r := split(n.right, at-left-len(n.runes))
+		return n.with(n.left, l), r
+	default:
+		off := at - left
+		l := n.withPiece(n.runes[:off:off]).with(n.left, nil)
+		return l, merge(newNode(n.runes[off:]), n.right)
+	}
+}
+
+// merge returns a node holding the runes in a followed by those in b.
+func merge(a, b *node) *node {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.priority > b.priority:
+		return a.with(a.left, merge(a.right, b))
+	default:
+		return b.with(merge(a, b.left), b.right)
+	}
+}
+
+func count(runes []rune) int {
+	lines := 0
+	for _, r := range runes {
+		if r == '\n' {
+			lines++
+		}
+	}
+	return lines
+}
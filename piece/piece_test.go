@@ -0,0 +1,242 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package piece_test
+
+import (
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/piece"
+)
+
+// splice replaces the n runes at offset at in text with runes the
+// way that the input handler used to edit an editor's text.
+func splice(text []rune, at, n int, runes []rune) []rune {
+	oldE, newE := at+n, at+len(runes)
+	if newE > oldE {
+		text = append(text, make([]rune, newE-oldE)...)
+	}
+	if newE < len(text) {
+		copy(text[newE:], text[oldE:])
+	}
+	if oldE > newE {
+		text = text[:len(text)-(oldE-newE)]
+	}
+	copy(text[at:newE], runes)
+	return text
+}
+
+func TestTable(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) expect.Expectation {
+		return expect.New(t)
+	})
+
+	o.Spec("it holds a copy of its text", func(expect expect.Expectation) {
+		runes := []rune("héllo\nwörld")
+		table := piece.New(runes)
+		runes[0] = 'j'
+		expect(table.String()).To(Equal("héllo\nwörld"))
+		expect(table.Len()).To(Equal(11))
+		expect(table.Lines()).To(Equal(2))
+		expect(table.RuneAt(6)).To(Equal('w'))
+		expect(string(table.Slice(3, 8))).To(Equal("lo\nwö"))
+		expect(string(table.AppendSlice([]rune("> "), 6, 11))).To(Equal("> wörld"))
+	})
+
+	o.Spec("it treats nil tables as empty", func(expect expect.Expectation) {
+		var table *piece.Table
+		expect(table.Len()).To(Equal(0))
+		expect(table.String()).To(Equal(""))
+		expect(table.Insert(0, []rune("a")).String()).To(Equal("a"))
+	})
+
+	o.Spec("it leaves snapshots unchanged by edits", func(expect expect.Expectation) {
+		snapshot := piece.NewString("foo\nbar\n")
+		table := snapshot
+		for _, r := range "baz\n" {
+			table = table.Insert(table.Len(), []rune{r})
+		}
+		table = table.Delete(0, 4)
+		expect(table.String()).To(Equal("bar\nbaz\n"))
+		expect(snapshot.String()).To(Equal("foo\nbar\n"))
+
+		other := snapshot.Insert(snapshot.Len(), []rune("qux"))
+		expect(other.String()).To(Equal("foo\nbar\nqux"))
+		expect(table.String()).To(Equal("bar\nbaz\n"))
+	})
+
+	o.Spec("it finds lines", func(expect expect.Expectation) {
+		table := piece.NewString("a\nbc\n\nd")
+		for line, start := range []int{0, 2, 5, 6} {
+			s, ok := table.LineStart(line)
+			expect(ok).To(BeTrue())
+			expect(s).To(Equal(start))
+			expect(table.LineOf(start)).To(Equal(line))
+		}
+		_, ok := table.LineStart(4)
+		expect(ok).To(BeFalse())
+		expect(table.LineOf(table.Len())).To(Equal(3))
+	})
+
+	o.Spec("it can be read through a Reader", func(expect expect.Expectation) {
+		table := piece.NewString(strings.Repeat("héllo, 世界\n", 500))
+		table = table.Insert(7, []rune("wörld"))
+		for _, size := range []int{1, 2, 5, 4096} {
+			r := piece.NewReader(table)
+			var b strings.Builder
+			buf := make([]byte, size)
+			for {
+				n, err := r.Read(buf)
+				b.Write(buf[:n])
+				if err == io.EOF {
+					break
+				}
+				expect(err).To(Not(HaveOccurred()))
+			}
+			expect(b.String()).To(Equal(table.String()))
+		}
+
+		n, err := piece.NewReader(nil).Read(make([]byte, 1))
+		expect(n).To(Equal(0))
+		expect(err).To(Equal(io.EOF))
+	})
+
+	o.Spec("it matches a rune slice through random edits", func(expect expect.Expectation) {
+		rng := rand.New(rand.NewSource(1))
+		alphabet := []rune("ab\nçd")
+		text := []rune(strings.Repeat("line of text\n", 300))
+		table := piece.New(text)
+		for i := 0; i < 2000; i++ {
+			at := rng.Intn(len(text) + 1)
+			n := 0
+			if at < len(text) && rng.Intn(3) == 0 {
+				n = rng.Intn(len(text)-at) % 50
+			}
+			runes := make([]rune, rng.Intn(4))
+			if rng.Intn(20) == 0 {
+				runes = make([]rune, rng.Intn(3000))
+			}
+			for j := range runes {
+				runes[j] = alphabet[rng.Intn(len(alphabet))]
+			}
+			text = splice(text, at, n, runes)
+			table = table.Replace(at, n, runes)
+		}
+		expect(table.String()).To(Equal(string(text)))
+		expect(table.Len()).To(Equal(len(text)))
+		expect(table.Lines()).To(Equal(strings.Count(string(text), "\n") + 1))
+		for i := 0; i < 100; i++ {
+			at := rng.Intn(len(text))
+			expect(table.RuneAt(at)).To(Equal(text[at]))
+			line := table.LineOf(at)
+			expect(line).To(Equal(strings.Count(string(text[:at]), "\n")))
+			start, ok := table.LineStart(line)
+			expect(ok).To(BeTrue())
+			expect(start <= at).To(BeTrue())
+			expect(strings.ContainsRune(string(text[start:at]), '\n')).To(BeFalse())
+		}
+	})
+}
+
+// benchText returns a generated file of roughly 4MB.
+func benchText() []rune {
+	return []rune(strings.Repeat("\tfoo := bar(baz, \"qux\") // some generated code\n", 80000))
+}
+
+// textBox stands in for gxui's TextBoxController, which finds the
+// start and end of every line whenever its text is set.
+type textBox struct {
+	text                 []rune
+	lineStarts, lineEnds []int
+}
+
+func (t *textBox) setTextRunesNoEvent(text []rune) {
+	t.text = text
+	t.lineStarts = append(t.lineStarts[:0], 0)
+	t.lineEnds = t.lineEnds[:0]
+	for i, r := range text {
+		if r == '\n' {
+			t.lineEnds = append(t.lineEnds, i)
+			t.lineStarts = append(t.lineStarts, i+1)
+		}
+	}
+	t.lineEnds = append(t.lineEnds, len(text))
+}
+
+// BenchmarkApplySplice measures typing a rune the way that the input
+// handler used to apply it: splicing it into the text box's text and
+// setting the text again.
+func BenchmarkApplySplice(b *testing.B) {
+	box := &textBox{}
+	box.setTextRunesNoEvent(benchText())
+	at := len(box.text) / 2
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		box.setTextRunesNoEvent(splice(box.text, at+i%1000, 0, []rune{'x'}))
+	}
+}
+
+// BenchmarkApplyTable measures typing a rune into an editor's
+// document, which is all that the input handler does before running
+// its hooks.
+func BenchmarkApplyTable(b *testing.B) {
+	table := piece.New(benchText())
+	at := table.Len() / 2
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		table = table.Insert(at+i%1000, []rune{'x'})
+	}
+}
+
+// BenchmarkApplyTableSync measures typing a rune into an editor's
+// document and bringing the text box up to date with it, which
+// happens after every edit if something reads the text box in
+// between.
+func BenchmarkApplyTableSync(b *testing.B) {
+	table := piece.New(benchText())
+	box := &textBox{}
+	box.setTextRunesNoEvent(table.Runes())
+	at := table.Len() / 2
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		from := at + i%1000
+		table = table.Insert(from, []rune{'x'})
+		box.setTextRunesNoEvent(table.AppendSlice(box.text[:from], from, table.Len()))
+	}
+}
+
+func BenchmarkSnapshotTable(b *testing.B) {
+	table := piece.New(benchText())
+	at := table.Len() / 2
+	var snapshot *piece.Table
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		table = table.Insert(at, []rune{'x'})
+		snapshot = table
+	}
+	_ = snapshot
+}
+
+// BenchmarkSnapshotText measures keeping a copy of the text for a
+// hook after every edit, the way that hooks calling Text do.
+func BenchmarkSnapshotText(b *testing.B) {
+	text := benchText()
+	at := len(text) / 2
+	var snapshot string
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		text = splice(text, at, 0, []rune{'x'})
+		snapshot = string(text)
+	}
+	_ = snapshot
+}
@@ -0,0 +1,78 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package piece
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// Reader reads the text of a table as UTF-8, a piece at a time, so
+// that the text never has to be copied into a single string or slice.
+// It implements io.Reader.
+type Reader struct {
+	// stack holds the nodes whose pieces haven't been read yet,
+	// with the next one on top.
+	stack []*node
+	piece []rune
+
+	buf     [utf8.UTFMax]byte
+	pending []byte
+}
+
+// NewReader returns a Reader that reads the text of t.
+func NewReader(t *Table) *Reader {
+	r := &Reader{}
+	if t != nil {
+		r.push(t.root)
+	}
+	return r
+}
+
+// Read reads the next len(p) bytes of text into p.  A rune that
+// doesn't fit in p is split across calls.
+func (r *Reader) Read(p []byte) (int, error) {
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	for n < len(p) && r.next() {
+		c := r.piece[0]
+		r.piece = r.piece[1:]
+		if c < utf8.RuneSelf {
+			p[n] = byte(c)
+			n++
+			continue
+		}
+		size := utf8.EncodeRune(r.buf[:], c)
+		copied := copy(p[n:], r.buf[:size])
+		n += copied
+		r.pending = r.buf[copied:size]
+	}
+	if n == 0 && len(p) > 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// next moves r to the next piece once the current one has been read,
+// returning false at the end of the text.
+func (r *Reader) next() bool {
+	for len(r.piece) == 0 {
+		if len(r.stack) == 0 {
+			return false
+		}
+		n := r.stack[len(r.stack)-1]
+		r.stack = r.stack[:len(r.stack)-1]
+		r.push(n.right)
+		r.piece = n.runes
+	}
+	return true
+}
+
+// push adds n and the left spine of its subtree to r's stack.
+func (r *Reader) push(n *node) {
+	for ; n != nil; n = n.left {
+		r.stack = append(r.stack, n)
+	}
+}
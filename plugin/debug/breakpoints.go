@@ -0,0 +1,232 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package debug
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/piece"
+	"github.com/nelsam/vidar/setting"
+)
+
+// BreakpointsFilename is the name of the file, in vidar's data
+// directory, that breakpoints are saved to.
+const BreakpointsFilename = "breakpoints.json"
+
+// Breakpoints is the set of breakpoints in each project, keyed by
+// file.  Lines are 1-based, the way Delve expects them.  Every change
+// is saved to disk so that breakpoints survive restarts.
+type Breakpoints struct {
+	path string
+
+	mu       sync.Mutex
+	projects map[string]map[string][]int
+	onChange []func(file string)
+}
+
+// LoadBreakpoints loads breakpoints from the file at path.  A missing
+// file is treated as an empty set of breakpoints.
+func LoadBreakpoints(path string) *Breakpoints {
+	b := &Breakpoints{path: path, projects: make(map[string]map[string][]int)}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return b
+	}
+	if err != nil {
+		log.Printf("Error reading breakpoints from %s: %s", path, err)
+		return b
+	}
+	if err := json.Unmarshal(data, &b.projects); err != nil {
+		log.Printf("Error parsing breakpoints in %s: %s", path, err)
+	}
+	return b
+}
+
+// DefaultBreakpoints loads breakpoints from BreakpointsFilename in
+// vidar's data directory.
+func DefaultBreakpoints() *Breakpoints {
+	return LoadBreakpoints(filepath.Join(setting.App.DataHome(), BreakpointsFilename))
+}
+
+// ProjectFor returns the name of the project that file belongs to,
+// which is the project with the longest path containing file.
+func ProjectFor(file string) string {
+	name, longest := setting.DefaultProject.Name, 0
+	for _, p := range setting.Projects() {
+		if p.Path == "" || len(p.Path) <= longest {
+			continue
+		}
+		rel, err := filepath.Rel(p.Path, file)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		name, longest = p.Name, len(p.Path)
+	}
+	return name
+}
+
+// OnChange registers f to be called with the file whose breakpoints
+// changed.
+func (b *Breakpoints) OnChange(f func(file string)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onChange = append(b.onChange, f)
+}
+
+// Lines returns the lines in file that have breakpoints.
+func (b *Breakpoints) Lines(file string) []int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]int(nil), b.projects[ProjectFor(file)][file]...)
+}
+
+// All returns every breakpoint in project, keyed by file.
+func (b *Breakpoints) All(project string) map[string][]int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	all := make(map[string][]int, len(b.projects[project]))
+	for file, lines := range b.projects[project] {
+		all[file] = append([]int(nil), lines...)
+	}
+	return all
+}
+
+// Toggle adds a breakpoint on line of file, or removes it if there
+// already is one.  It returns whether the line has a breakpoint
+// after the toggle.
+func (b *Breakpoints) Toggle(file string, line int) bool {
+	b.mu.Lock()
+	lines := b.lines(file)
+	i := sort.SearchInts(lines, line)
+	set := i == len(lines) || lines[i] != line
+	if set {
+		lines = append(lines, 0)
+		copy(lines[i+1:], lines[i:])
+		lines[i] = line
+	} else {
+		lines = append(lines[:i], lines[i+1:]...)
+	}
+	b.setLines(file, lines)
+	b.mu.Unlock()
+	b.changed(file)
+	return set
+}
+
+// Edited moves the breakpoints in file to account for edits.  text
+// is the text of the file after the edits were applied.  Lines
+// inserted at the start of a breakpoint's line push it down, and
+// breakpoints on lines that were removed are moved to the line that
+// the edit left behind.
+func (b *Breakpoints) Edited(file string, text *piece.Table, edits []input.Edit) {
+	b.mu.Lock()
+	lines := b.lines(file)
+	if len(lines) == 0 {
+		b.mu.Unlock()
+		return
+	}
+	moved := make([]int, 0, len(lines))
+	for _, l := range lines {
+		for _, e := range edits {
+			at := e.At
+			if at > text.Len() {
+				at = text.Len()
+			}
+			start := 1 + text.LineOf(at)
+			removed := count(e.Old, '\n')
+			lineStart := at == 0 || text.RuneAt(at-1) == '\n'
+			switch {
+			case l < start, l == start && (removed > 0 || !lineStart):
+			case removed > 0 && l <= start+removed:
+				l = start
+			default:
+				l += count(e.New, '\n') - removed
+			}
+		}
+		if len(moved) > 0 && moved[len(moved)-1] == l {
+			continue
+		}
+		moved = append(moved, l)
+	}
+	changed := len(moved) != len(lines)
+	for i := range moved {
+		changed = changed || moved[i] != lines[i]
+	}
+	if !changed {
+		b.mu.Unlock()
+		return
+	}
+	b.setLines(file, moved)
+	b.mu.Unlock()
+	b.changed(file)
+}
+
+func count(runes []rune, r rune) int {
+	n := 0
+	for _, c := range runes {
+		if c == r {
+			n++
+		}
+	}
+	return n
+}
+
+// lines returns a copy of the lines in file.  b.mu must be locked by
+// the caller.
+func (b *Breakpoints) lines(file string) []int {
+	return append([]int(nil), b.projects[ProjectFor(file)][file]...)
+}
+
+// setLines replaces the lines in file and saves the result.  b.mu
+// must be locked by the caller.
+func (b *Breakpoints) setLines(file string, lines []int) {
+	project := ProjectFor(file)
+	files := b.projects[project]
+	if files == nil {
+		files = make(map[string][]int)
+		b.projects[project] = files
+	}
+	if len(lines) == 0 {
+		delete(files, file)
+	} else {
+		files[file] = lines
+	}
+	if len(files) == 0 {
+		delete(b.projects, project)
+	}
+	if err := b.save(); err != nil {
+		log.Printf("Error saving breakpoints to %s: %s", b.path, err)
+	}
+}
+
+func (b *Breakpoints) save() error {
+	if b.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(b.projects, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(b.path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(b.path, data, 0600)
+}
+
+func (b *Breakpoints) changed(file string) {
+	b.mu.Lock()
+	callbacks := append([]func(string){}, b.onChange...)
+	b.mu.Unlock()
+	for _, f := range callbacks {
+		f(file)
+	}
+}
@@ -0,0 +1,64 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package debug_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/piece"
+	"github.com/nelsam/vidar/plugin/debug"
+	"github.com/nelsam/vidar/setting"
+)
+
+func TestBreakpoints(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, string, *debug.Breakpoints) {
+		dir, err := ioutil.TempDir("", "breakpoints")
+		if err != nil {
+			t.Fatalf("could not create temp dir: %s", err)
+		}
+		t.Cleanup(func() { os.RemoveAll(dir) })
+		path := filepath.Join(dir, debug.BreakpointsFilename)
+		return expect.New(t), path, debug.LoadBreakpoints(path)
+	})
+
+	o.Spec("it toggles breakpoints and saves them", func(expect expect.Expectation, path string, b *debug.Breakpoints) {
+		var changed []string
+		b.OnChange(func(file string) { changed = append(changed, file) })
+
+		expect(b.Toggle("/src/main.go", 10)).To(BeTrue())
+		expect(b.Toggle("/src/main.go", 4)).To(BeTrue())
+		expect(b.Lines("/src/main.go")).To(Equal([]int{4, 10}))
+		expect(changed).To(Equal([]string{"/src/main.go", "/src/main.go"}))
+
+		loaded := debug.LoadBreakpoints(path)
+		expect(loaded.All(setting.DefaultProject.Name)).To(Equal(map[string][]int{"/src/main.go": {4, 10}}))
+
+		expect(b.Toggle("/src/main.go", 10)).To(BeFalse())
+		expect(b.Lines("/src/main.go")).To(Equal([]int{4}))
+	})
+
+	o.Spec("it moves breakpoints when lines are added or removed", func(expect expect.Expectation, path string, b *debug.Breakpoints) {
+		b.Toggle("/src/main.go", 2)
+		b.Toggle("/src/main.go", 5)
+
+		text := piece.NewString("a\nnew\nb\nc\nd\ne\n")
+		b.Edited("/src/main.go", text, []input.Edit{{At: 2, New: []rune("new\n")}})
+		expect(b.Lines("/src/main.go")).To(Equal([]int{3, 6}))
+
+		text = piece.NewString("a\ne\n")
+		b.Edited("/src/main.go", text, []input.Edit{{At: 2, Old: []rune("new\nb\nc\nd\n")}})
+		expect(b.Lines("/src/main.go")).To(Equal([]int{2}))
+	})
+}
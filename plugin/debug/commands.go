@@ -0,0 +1,269 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package debug
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/plugin/debug/dlv"
+	"github.com/nelsam/vidar/plugin/status"
+	"github.com/nelsam/vidar/setting"
+)
+
+type Projecter interface {
+	Project() setting.Project
+}
+
+type Editor interface {
+	Filepath() string
+	Runes() []rune
+}
+
+type CursorController interface {
+	LastCaret() int
+}
+
+// Navigator is a type that can display a pane's frame.
+type Navigator interface {
+	ShowNavPane(gxui.Control)
+}
+
+// Continue is a command that continues the program being debugged
+// until it reaches a breakpoint.  When there is no debug session, it
+// starts one for the current file's package: `dlv test` for test
+// files and `dlv debug` for everything else.
+type Continue struct {
+	status.General
+
+	debugger *Debugger
+	proj     Projecter
+	editor   Editor
+	nav      Navigator
+}
+
+// NewContinue returns a *Continue that controls d.
+func NewContinue(theme gxui.Theme, d *Debugger) *Continue {
+	c := &Continue{debugger: d}
+	c.Theme = theme
+	return c
+}
+
+func (c *Continue) Name() string {
+	return "debug-continue"
+}
+
+func (c *Continue) Menu() string {
+	return "Debug"
+}
+
+func (c *Continue) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Key: gxui.KeyF5,
+	}}
+}
+
+func (c *Continue) Reset() {
+	c.proj = nil
+	c.editor = nil
+	c.nav = nil
+}
+
+func (c *Continue) Store(target interface{}) bind.Status {
+	switch src := target.(type) {
+	case Projecter:
+		c.proj = src
+	case Editor:
+		c.editor = src
+	case Navigator:
+		c.nav = src
+	}
+	if c.proj != nil && c.editor != nil && c.nav != nil {
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+func (c *Continue) Exec() error {
+	c.nav.ShowNavPane(c.debugger.Pane().Frame())
+	if c.debugger.Active() {
+		if err := c.debugger.Command(dlv.Continue); err != nil {
+			c.Warn = err.Error()
+		}
+		return nil
+	}
+	path := c.editor.Filepath()
+	if path == "" {
+		c.Warn = "No file is open"
+		return nil
+	}
+	mode := "debug"
+	if strings.HasSuffix(path, "_test.go") {
+		mode = "test"
+	}
+	dir := filepath.Dir(path)
+	if err := c.debugger.Start(c.proj.Project().Environ(), dir, ProjectFor(path), mode); err != nil {
+		c.Err = err.Error()
+		return nil
+	}
+	c.Info = fmt.Sprintf("Starting dlv %s in %s", mode, filepath.Base(dir))
+	return nil
+}
+
+// Control is a command that sends an execution command (e.g. next or
+// step) to the program being debugged, or stops it.
+type Control struct {
+	status.General
+
+	debugger *Debugger
+	name     string
+	key      gxui.KeyboardEvent
+	exec     func(*Debugger) error
+	nav      Navigator
+}
+
+func newControl(theme gxui.Theme, d *Debugger, name string, key gxui.KeyboardEvent, exec func(*Debugger) error) *Control {
+	c := &Control{debugger: d, name: name, key: key, exec: exec}
+	c.Theme = theme
+	return c
+}
+
+func command(name string) func(*Debugger) error {
+	return func(d *Debugger) error {
+		return d.Command(name)
+	}
+}
+
+// NewNext returns a *Control that steps d over the current line.
+func NewNext(theme gxui.Theme, d *Debugger) *Control {
+	return newControl(theme, d, "debug-next", gxui.KeyboardEvent{Key: gxui.KeyF10}, command(dlv.Next))
+}
+
+// NewStep returns a *Control that steps d into the current line.
+func NewStep(theme gxui.Theme, d *Debugger) *Control {
+	return newControl(theme, d, "debug-step", gxui.KeyboardEvent{Key: gxui.KeyF7}, command(dlv.Step))
+}
+
+// NewStepOut returns a *Control that steps d out of the current
+// function.
+func NewStepOut(theme gxui.Theme, d *Debugger) *Control {
+	key := gxui.KeyboardEvent{Modifier: gxui.ModShift, Key: gxui.KeyF7}
+	return newControl(theme, d, "debug-step-out", key, command(dlv.StepOut))
+}
+
+// NewStop returns a *Control that ends d's debug session.
+func NewStop(theme gxui.Theme, d *Debugger) *Control {
+	key := gxui.KeyboardEvent{Modifier: gxui.ModShift, Key: gxui.KeyF5}
+	return newControl(theme, d, "debug-stop", key, (*Debugger).Stop)
+}
+
+func (c *Control) Name() string {
+	return c.name
+}
+
+func (c *Control) Menu() string {
+	return "Debug"
+}
+
+func (c *Control) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{c.key}
+}
+
+func (c *Control) Reset() {
+	c.nav = nil
+}
+
+func (c *Control) Store(target interface{}) bind.Status {
+	if nav, ok := target.(Navigator); ok {
+		c.nav = nav
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+func (c *Control) Exec() error {
+	if err := c.exec(c.debugger); err != nil {
+		c.Warn = err.Error()
+		return nil
+	}
+	c.nav.ShowNavPane(c.debugger.Pane().Frame())
+	return nil
+}
+
+// ToggleBreakpoint is a command that adds or removes a breakpoint on
+// the caret's line.
+type ToggleBreakpoint struct {
+	status.General
+
+	breakpoints *Breakpoints
+	editor      Editor
+	ctrl        CursorController
+}
+
+// NewToggleBreakpoint returns a *ToggleBreakpoint that stores
+// breakpoints in b.
+func NewToggleBreakpoint(theme gxui.Theme, b *Breakpoints) *ToggleBreakpoint {
+	t := &ToggleBreakpoint{breakpoints: b}
+	t.Theme = theme
+	return t
+}
+
+func (t *ToggleBreakpoint) Name() string {
+	return "debug-toggle-breakpoint"
+}
+
+func (t *ToggleBreakpoint) Menu() string {
+	return "Debug"
+}
+
+func (t *ToggleBreakpoint) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Key: gxui.KeyF9,
+	}}
+}
+
+func (t *ToggleBreakpoint) Reset() {
+	t.editor = nil
+	t.ctrl = nil
+}
+
+func (t *ToggleBreakpoint) Store(target interface{}) bind.Status {
+	switch src := target.(type) {
+	case Editor:
+		t.editor = src
+		if ctrl, ok := target.(CursorController); ok {
+			t.ctrl = ctrl
+		}
+	case CursorController:
+		t.ctrl = src
+	}
+	if t.editor != nil && t.ctrl != nil {
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+func (t *ToggleBreakpoint) Exec() error {
+	path := t.editor.Filepath()
+	if path == "" {
+		t.Warn = "No file is open"
+		return nil
+	}
+	runes := t.editor.Runes()
+	caret := t.ctrl.LastCaret()
+	if caret > len(runes) {
+		caret = len(runes)
+	}
+	line := 1 + count(runes[:caret], '\n')
+	if t.breakpoints.Toggle(path, line) {
+		t.Info = fmt.Sprintf("Set breakpoint at %s:%d", filepath.Base(path), line)
+		return nil
+	}
+	t.Info = fmt.Sprintf("Cleared breakpoint at %s:%d", filepath.Base(path), line)
+	return nil
+}
@@ -0,0 +1,473 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package debug contains commands for debugging go programs and
+// tests with Delve.
+package debug
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/command/focus"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/piece"
+	"github.com/nelsam/vidar/plugin/debug/dlv"
+	"github.com/nelsam/vidar/plugin/status"
+	"github.com/nelsam/vidar/theme"
+)
+
+// stackDepth is the number of frames loaded for the call stack.
+const stackDepth = 50
+
+// lineOwner owns the layer that highlights the line that the program
+// is stopped on.
+const lineOwner = "debug-line"
+
+// errNotDebugging is returned by commands that need a debug session
+// when there isn't one.
+var errNotDebugging = errors.New("not debugging")
+
+// Commander is a type that can look up and execute bindables.
+type Commander interface {
+	Bindable(name string) bind.Bindable
+	Execute(bind.Bindable)
+}
+
+// Opener is a type that can open locations in the code.
+type Opener interface {
+	For(...focus.Opt) bind.Bindable
+}
+
+// Debugger manages a single debug session at a time, displaying the
+// session's state in a Pane and highlighting the line that the
+// program is stopped on.
+type Debugger struct {
+	cmdr        Commander
+	driver      gxui.Driver
+	breakpoints *Breakpoints
+	pane        *Pane
+
+	mu        sync.Mutex
+	editors   map[string]input.Editor
+	proc      *dlv.Process
+	client    *dlv.Client
+	output    *bytes.Buffer
+	ids       map[string]map[int]int
+	project   string
+	busy      bool
+	goroutine int64
+	stopped   dlv.Location
+}
+
+// New returns a *Debugger that sets breakpoints from b.
+func New(cmdr Commander, driver gxui.Driver, theme gxui.Theme, b *Breakpoints) *Debugger {
+	d := &Debugger{
+		cmdr:        cmdr,
+		driver:      driver,
+		breakpoints: b,
+		editors:     make(map[string]input.Editor),
+	}
+	d.pane = newPane(d, driver, theme)
+	b.OnChange(d.breakpointsChanged)
+	return d
+}
+
+// Pane returns the pane that displays d's call stack and variables.
+func (d *Debugger) Pane() *Pane {
+	return d.pane
+}
+
+// Active returns whether d has a debug session.
+func (d *Debugger) Active() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.client != nil
+}
+
+// Start launches dlv with args (e.g. "debug" or "test") in dir,
+// sets the breakpoints in project, and continues to the first
+// breakpoint.  It returns once dlv has been started; the rest of the
+// session runs in the background.
+func (d *Debugger) Start(env []string, dir, project string, args ...string) error {
+	d.mu.Lock()
+	if d.client != nil || d.busy {
+		d.mu.Unlock()
+		return errors.New("a debug session is already running")
+	}
+	d.busy = true
+	d.project = project
+	d.output = &bytes.Buffer{}
+	output := d.output
+	d.mu.Unlock()
+
+	d.pane.setStatus("Starting dlv")
+	go func() {
+		proc, err := dlv.Launch(env, dir, &syncWriter{mu: &d.mu, w: output}, args...)
+		if err != nil {
+			d.end(fmt.Sprintf("Could not start dlv: %s", err))
+			return
+		}
+		client, err := dlv.Dial(proc.Addr)
+		if err != nil {
+			proc.Kill()
+			d.end(fmt.Sprintf("Could not connect to dlv: %s", err))
+			return
+		}
+		d.mu.Lock()
+		d.proc = proc
+		d.client = client
+		d.ids = make(map[string]map[int]int)
+		d.mu.Unlock()
+
+		for file, lines := range d.breakpoints.All(project) {
+			for _, l := range lines {
+				d.create(client, file, l)
+			}
+		}
+		go func() {
+			<-proc.Done()
+			d.end("dlv exited")
+		}()
+		d.mu.Lock()
+		d.busy = false
+		d.mu.Unlock()
+		d.Command(dlv.Continue)
+	}()
+	return nil
+}
+
+// Command runs one of Delve's execution commands in the background,
+// updating the pane and highlighted line when the program stops.
+func (d *Debugger) Command(name string) error {
+	d.mu.Lock()
+	client := d.client
+	if client == nil {
+		d.mu.Unlock()
+		return errNotDebugging
+	}
+	if d.busy && name != dlv.Halt {
+		d.mu.Unlock()
+		return errors.New("the program is running")
+	}
+	d.busy = true
+	d.mu.Unlock()
+
+	d.pane.setStatus("Running")
+	d.highlight(dlv.Location{})
+	go func() {
+		state, err := client.Command(name)
+		d.mu.Lock()
+		d.busy = false
+		d.mu.Unlock()
+		if err != nil {
+			d.pane.setStatus(fmt.Sprintf("%s failed: %s", name, err))
+			return
+		}
+		d.stoppedAt(client, state)
+	}()
+	return nil
+}
+
+// Stop ends the debug session in the background, killing the
+// debugged program.
+func (d *Debugger) Stop() error {
+	if !d.Active() {
+		return errNotDebugging
+	}
+	go d.stop("Stopped")
+	return nil
+}
+
+func (d *Debugger) stop(msg string) {
+	d.mu.Lock()
+	client, proc := d.client, d.proc
+	d.mu.Unlock()
+	if client == nil {
+		return
+	}
+	if err := client.Detach(true); err != nil {
+		log.Printf("Error detaching from dlv: %s", err)
+	}
+	proc.Kill()
+	d.end(msg)
+}
+
+// Output returns everything that the debugged program has written
+// to stdout and stderr.
+func (d *Debugger) Output() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.output == nil {
+		return ""
+	}
+	return d.output.String()
+}
+
+// SelectFrame loads the variables for frame of the goroutine that
+// the program is stopped in, and opens the frame's location.
+func (d *Debugger) SelectFrame(frame int, loc dlv.Location) {
+	d.mu.Lock()
+	client, goroutine := d.client, d.goroutine
+	d.mu.Unlock()
+	if client == nil {
+		return
+	}
+	d.open(loc)
+	go func() {
+		vars, err := client.Locals(dlv.EvalScope{GoroutineID: goroutine, Frame: frame})
+		if err != nil {
+			d.pane.setStatus(fmt.Sprintf("Could not load variables: %s", err))
+			return
+		}
+		d.pane.setVariables(frame, vars)
+	}()
+}
+
+func (d *Debugger) stoppedAt(client *dlv.Client, state dlv.State) {
+	if state.Exited {
+		d.stop(fmt.Sprintf("Exited with status %d", state.ExitStatus))
+		return
+	}
+	var loc dlv.Location
+	var goroutine int64
+	if t := state.CurrentThread; t != nil {
+		loc = dlv.Location{PC: t.PC, File: t.File, Line: t.Line, Function: t.Function}
+		goroutine = t.GoroutineID
+	}
+	if g := state.SelectedGoroutine; g != nil {
+		goroutine = g.ID
+	}
+	d.mu.Lock()
+	d.goroutine = goroutine
+	d.mu.Unlock()
+
+	frames, err := client.Stacktrace(goroutine, stackDepth)
+	if err != nil {
+		d.pane.setStatus(fmt.Sprintf("Could not load the call stack: %s", err))
+	}
+	vars, err := client.Locals(dlv.EvalScope{GoroutineID: goroutine})
+	if err != nil {
+		d.pane.setStatus(fmt.Sprintf("Could not load variables: %s", err))
+	}
+	d.pane.setStopped(fmt.Sprintf("Stopped at %s:%d", loc.File, loc.Line), frames, vars)
+	d.open(loc)
+}
+
+// end clears the session, displaying msg.  It does nothing if the
+// session has already ended.
+func (d *Debugger) end(msg string) {
+	d.mu.Lock()
+	if d.client == nil && !d.busy {
+		d.mu.Unlock()
+		return
+	}
+	client := d.client
+	d.client = nil
+	d.proc = nil
+	d.ids = nil
+	d.busy = false
+	d.mu.Unlock()
+	if client != nil {
+		client.Close()
+	}
+	d.highlight(dlv.Location{})
+	d.pane.setStopped(msg, nil, nil)
+}
+
+// create sets a breakpoint in the running session.
+func (d *Debugger) create(client *dlv.Client, file string, line int) {
+	bp, err := client.CreateBreakpoint(file, line)
+	if err != nil {
+		log.Printf("Error setting breakpoint at %s:%d: %s", file, line, err)
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.ids == nil {
+		return
+	}
+	if d.ids[file] == nil {
+		d.ids[file] = make(map[int]int)
+	}
+	d.ids[file][line] = bp.ID
+}
+
+// breakpointsChanged updates the gutter for file and, if there is a
+// session, the breakpoints that are set in it.
+func (d *Debugger) breakpointsChanged(file string) {
+	lines := d.breakpoints.Lines(file)
+	d.mark(file, lines)
+
+	d.mu.Lock()
+	client, project := d.client, d.project
+	if client == nil || ProjectFor(file) != project {
+		d.mu.Unlock()
+		return
+	}
+	set := make(map[int]bool, len(lines))
+	for _, l := range lines {
+		set[l] = true
+	}
+	var clear []int
+	for l, id := range d.ids[file] {
+		if !set[l] {
+			clear = append(clear, id)
+			delete(d.ids[file], l)
+		}
+	}
+	var create []int
+	for _, l := range lines {
+		if _, ok := d.ids[file][l]; !ok {
+			create = append(create, l)
+		}
+	}
+	d.mu.Unlock()
+
+	go func() {
+		for _, id := range clear {
+			if err := client.ClearBreakpoint(id); err != nil {
+				log.Printf("Error clearing breakpoint %d: %s", id, err)
+			}
+		}
+		for _, l := range create {
+			d.create(client, file, l)
+		}
+	}()
+}
+
+// addEditor registers e so that its gutter and highlighted line can
+// be updated.
+func (d *Debugger) addEditor(e input.Editor) {
+	path := e.Filepath()
+	d.mu.Lock()
+	_, existed := d.editors[path]
+	d.editors[path] = e
+	d.mu.Unlock()
+	if g, ok := e.(input.Gutter); ok && !existed {
+		g.OnGutterClick(func(line int) {
+			d.breakpoints.Toggle(path, line+1)
+		})
+	}
+	d.mark(path, d.breakpoints.Lines(path))
+}
+
+func (d *Debugger) editor(path string) (input.Editor, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	e, ok := d.editors[path]
+	return e, ok
+}
+
+// mark displays a breakpoint mark beside each of lines in the editor
+// for file.
+func (d *Debugger) mark(file string, lines []int) {
+	e, ok := d.editor(file)
+	if !ok {
+		return
+	}
+	g, ok := e.(input.Gutter)
+	if !ok {
+		return
+	}
+	marks := make(map[int]input.GutterMark, len(lines))
+	for _, l := range lines {
+		marks[l-1] = input.GutterMark{Text: "●", Color: status.ColorErr}
+	}
+	d.driver.Call(func() {
+		g.SetGutterMarks("breakpoints", marks)
+	})
+}
+
+// highlight moves the theme.DebugLine layer to loc.  An empty loc
+// clears the highlight.
+func (d *Debugger) highlight(loc dlv.Location) {
+	d.mu.Lock()
+	old := d.stopped
+	d.stopped = loc
+	d.mu.Unlock()
+	d.driver.Call(func() {
+		if e, ok := d.editor(old.File); ok && old.File != loc.File {
+			if l, ok := e.(input.Layerer); ok {
+				l.SetOwnedLayers(lineOwner, nil)
+			}
+		}
+		if e, ok := d.editor(loc.File); ok {
+			d.setLine(e, loc)
+		}
+	})
+}
+
+// setLine replaces the theme.DebugLine layer in e with one for loc's
+// line.  It must be called on the UI goroutine.
+func (d *Debugger) setLine(e input.Editor, loc dlv.Location) {
+	l, ok := e.(input.Layerer)
+	if !ok {
+		return
+	}
+	var layers []input.SyntaxLayer
+	if span, ok := lineSpan(input.Snapshot(e), loc.Line); ok && e.Filepath() == loc.File {
+		layers = []input.SyntaxLayer{{Construct: theme.DebugLine, Spans: []input.Span{span}}}
+	}
+	l.SetOwnedLayers(lineOwner, layers)
+}
+
+// restoreLine moves the highlight in e back to the line that the
+// program is stopped on, if it is stopped in e's file, since edits
+// above the line move it.
+func (d *Debugger) restoreLine(e input.Editor) {
+	d.mu.Lock()
+	loc := d.stopped
+	d.mu.Unlock()
+	if loc.File == "" || e.Filepath() != loc.File {
+		return
+	}
+	d.setLine(e, loc)
+}
+
+// open opens loc in the editor and highlights it.
+func (d *Debugger) open(loc dlv.Location) {
+	if loc.File == "" {
+		return
+	}
+	d.driver.Call(func() {
+		opener := d.cmdr.Bindable("focus-location").(Opener)
+		d.cmdr.Execute(opener.For(focus.Path(loc.File), focus.Line(loc.Line-1)))
+		d.highlight(loc)
+	})
+}
+
+// lineSpan returns the span of line (1-based) in text, not including
+// its newline.
+func lineSpan(text *piece.Table, line int) (input.Span, bool) {
+	if line < 1 {
+		return input.Span{}, false
+	}
+	start, ok := text.LineStart(line - 1)
+	if !ok {
+		return input.Span{}, false
+	}
+	end := text.Len()
+	if next, ok := text.LineStart(line); ok {
+		end = next - 1
+	}
+	return input.Span{Start: start, End: end}, true
+}
+
+// syncWriter locks mu around writes to w.
+type syncWriter struct {
+	mu *sync.Mutex
+	w  *bytes.Buffer
+}
+
+func (s *syncWriter) Write(b []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(b)
+}
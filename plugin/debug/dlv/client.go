@@ -0,0 +1,106 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package dlv is a client for the JSON-RPC API (version 2) of the
+// Delve debugger's headless server.
+package dlv
+
+import (
+	"io"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+)
+
+const service = "RPCServer."
+
+// Client makes calls to a headless Delve server.
+type Client struct {
+	rpc *rpc.Client
+}
+
+// Dial connects to the Delve server listening at addr.
+func Dial(addr string) (*Client, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn), nil
+}
+
+// NewClient returns a *Client that talks to Delve over conn.
+func NewClient(conn io.ReadWriteCloser) *Client {
+	return &Client{rpc: jsonrpc.NewClient(conn)}
+}
+
+func (c *Client) call(method string, args, reply interface{}) error {
+	return c.rpc.Call(service+method, args, reply)
+}
+
+// Close closes the connection to the server without stopping it.
+func (c *Client) Close() error {
+	return c.rpc.Close()
+}
+
+// Command runs one of the execution commands (Continue, Next, Step,
+// StepOut or Halt), returning the state that the program stopped
+// in.  Continue blocks until the program stops.
+func (c *Client) Command(name string) (State, error) {
+	var out commandOut
+	err := c.call("Command", debuggerCommand{Name: name}, &out)
+	return out.State, err
+}
+
+// State returns the current state of the debugger.
+func (c *Client) State() (State, error) {
+	var out stateOut
+	if err := c.call("State", stateIn{NonBlocking: true}, &out); err != nil {
+		return State{}, err
+	}
+	if out.State == nil {
+		return State{}, nil
+	}
+	return *out.State, nil
+}
+
+// CreateBreakpoint sets a breakpoint at line (1-based) of file.
+func (c *Client) CreateBreakpoint(file string, line int) (Breakpoint, error) {
+	var out createBreakpointOut
+	err := c.call("CreateBreakpoint", createBreakpointIn{Breakpoint: Breakpoint{File: file, Line: line}}, &out)
+	return out.Breakpoint, err
+}
+
+// ClearBreakpoint removes the breakpoint with id.
+func (c *Client) ClearBreakpoint(id int) error {
+	var out clearBreakpointOut
+	return c.call("ClearBreakpoint", clearBreakpointIn{Id: id}, &out)
+}
+
+// Stacktrace returns up to depth frames of goroutine's call stack.
+func (c *Client) Stacktrace(goroutine int64, depth int) ([]Stackframe, error) {
+	var out stacktraceOut
+	err := c.call("Stacktrace", stacktraceIn{Id: goroutine, Depth: depth}, &out)
+	return out.Locations, err
+}
+
+// Locals returns the arguments and local variables in scope, with
+// arguments first.
+func (c *Client) Locals(scope EvalScope) ([]Variable, error) {
+	var args listFunctionArgsOut
+	if err := c.call("ListFunctionArgs", listFunctionArgsIn{Scope: scope, Cfg: DefaultLoadConfig}, &args); err != nil {
+		return nil, err
+	}
+	var locals listLocalVarsOut
+	if err := c.call("ListLocalVars", listLocalVarsIn{Scope: scope, Cfg: DefaultLoadConfig}, &locals); err != nil {
+		return nil, err
+	}
+	return append(args.Args, locals.Variables...), nil
+}
+
+// Detach disconnects from the server, stopping it.  If kill is true,
+// the debugged program is killed as well.
+func (c *Client) Detach(kill bool) error {
+	var out detachOut
+	return c.call("Detach", detachIn{Kill: kill}, &out)
+}
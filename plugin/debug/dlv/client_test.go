@@ -0,0 +1,274 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package dlv_test
+
+import (
+	"errors"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/plugin/debug/dlv"
+)
+
+// The types below are written the way Delve's rpc2 package defines
+// them, so that the tests fail if the client stops sending or
+// reading the JSON that Delve uses.
+
+type DebuggerCommand struct {
+	Name string `json:"name"`
+}
+
+type Thread struct {
+	ID   int    `json:"id"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+type DebuggerState struct {
+	Running       bool
+	CurrentThread *Thread
+	Exited        bool
+	ExitStatus    int
+}
+
+type CommandOut struct {
+	State DebuggerState
+}
+
+type Breakpoint struct {
+	ID   int    `json:"id"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+type CreateBreakpointIn struct {
+	Breakpoint Breakpoint
+}
+
+type CreateBreakpointOut struct {
+	Breakpoint Breakpoint
+}
+
+type ClearBreakpointIn struct {
+	Id int
+}
+
+type ClearBreakpointOut struct {
+	Breakpoint *Breakpoint
+}
+
+type Function struct {
+	Name string `json:"name"`
+}
+
+type Stackframe struct {
+	File     string    `json:"file"`
+	Line     int       `json:"line"`
+	Function *Function `json:"function,omitempty"`
+}
+
+type StacktraceIn struct {
+	Id    int64
+	Depth int
+}
+
+type StacktraceOut struct {
+	Locations []Stackframe
+}
+
+type EvalScope struct {
+	GoroutineID int64
+	Frame       int
+}
+
+type Variable struct {
+	Name     string     `json:"name"`
+	Type     string     `json:"type"`
+	Value    string     `json:"value"`
+	Children []Variable `json:"children"`
+}
+
+type ListLocalVarsIn struct {
+	Scope EvalScope
+}
+
+type ListLocalVarsOut struct {
+	Variables []Variable
+}
+
+type ListFunctionArgsIn struct {
+	Scope EvalScope
+}
+
+type ListFunctionArgsOut struct {
+	Args []Variable
+}
+
+type DetachIn struct {
+	Kill bool
+}
+
+type DetachOut struct{}
+
+// RPCServer is a fake Delve server that records the calls made to
+// it.
+type RPCServer struct {
+	commands    chan string
+	breakpoints map[int]Breakpoint
+	scopes      chan EvalScope
+	killed      chan bool
+}
+
+func (s *RPCServer) Command(in DebuggerCommand, out *CommandOut) error {
+	s.commands <- in.Name
+	if in.Name == "bad" {
+		return errors.New("unknown command bad")
+	}
+	out.State = DebuggerState{CurrentThread: &Thread{ID: 1, File: "/src/main.go", Line: 12}}
+	return nil
+}
+
+func (s *RPCServer) CreateBreakpoint(in CreateBreakpointIn, out *CreateBreakpointOut) error {
+	bp := in.Breakpoint
+	bp.ID = len(s.breakpoints) + 1
+	s.breakpoints[bp.ID] = bp
+	out.Breakpoint = bp
+	return nil
+}
+
+func (s *RPCServer) ClearBreakpoint(in ClearBreakpointIn, out *ClearBreakpointOut) error {
+	bp, ok := s.breakpoints[in.Id]
+	if !ok {
+		return errors.New("no such breakpoint")
+	}
+	delete(s.breakpoints, in.Id)
+	out.Breakpoint = &bp
+	return nil
+}
+
+func (s *RPCServer) Stacktrace(in StacktraceIn, out *StacktraceOut) error {
+	out.Locations = []Stackframe{
+		{File: "/src/main.go", Line: 12, Function: &Function{Name: "main.foo"}},
+		{File: "/src/main.go", Line: 4, Function: &Function{Name: "main.main"}},
+	}
+	return nil
+}
+
+func (s *RPCServer) ListFunctionArgs(in ListFunctionArgsIn, out *ListFunctionArgsOut) error {
+	s.scopes <- in.Scope
+	out.Args = []Variable{{Name: "x", Type: "int", Value: "1"}}
+	return nil
+}
+
+func (s *RPCServer) ListLocalVars(in ListLocalVarsIn, out *ListLocalVarsOut) error {
+	out.Variables = []Variable{{
+		Name: "p",
+		Type: "main.point",
+		Children: []Variable{
+			{Name: "X", Type: "int", Value: "2"},
+		},
+	}}
+	return nil
+}
+
+func (s *RPCServer) Detach(in DetachIn, out *DetachOut) error {
+	s.killed <- in.Kill
+	return nil
+}
+
+func serve(t *testing.T) (*RPCServer, *dlv.Client) {
+	fake := &RPCServer{
+		commands:    make(chan string, 10),
+		breakpoints: make(map[int]Breakpoint),
+		scopes:      make(chan EvalScope, 10),
+		killed:      make(chan bool, 10),
+	}
+	server := rpc.NewServer()
+	if err := server.Register(fake); err != nil {
+		t.Fatalf("could not register fake server: %s", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %s", err)
+	}
+	go func() {
+		conn, err := l.Accept()
+		l.Close()
+		if err != nil {
+			return
+		}
+		server.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}()
+	c, err := dlv.Dial(l.Addr().String())
+	if err != nil {
+		t.Fatalf("could not dial fake server: %s", err)
+	}
+	return fake, c
+}
+
+func TestClient(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, *RPCServer, *dlv.Client) {
+		fake, c := serve(t)
+		t.Cleanup(func() { c.Close() })
+		return expect.New(t), fake, c
+	})
+
+	o.Spec("it runs commands and returns where the program stopped", func(expect expect.Expectation, fake *RPCServer, c *dlv.Client) {
+		state, err := c.Command(dlv.Next)
+		expect(err).To(Not(HaveOccurred()))
+		expect(<-fake.commands).To(Equal("next"))
+		expect(state.CurrentThread).To(Not(BeNil()))
+		expect(state.CurrentThread.File).To(Equal("/src/main.go"))
+		expect(state.CurrentThread.Line).To(Equal(12))
+	})
+
+	o.Spec("it returns errors from the server", func(expect expect.Expectation, fake *RPCServer, c *dlv.Client) {
+		_, err := c.Command("bad")
+		expect(err).To(HaveOccurred())
+		expect(err.Error()).To(Equal("unknown command bad"))
+	})
+
+	o.Spec("it creates and clears breakpoints", func(expect expect.Expectation, fake *RPCServer, c *dlv.Client) {
+		bp, err := c.CreateBreakpoint("/src/main.go", 7)
+		expect(err).To(Not(HaveOccurred()))
+		expect(bp.ID).To(Equal(1))
+		expect(fake.breakpoints[1]).To(Equal(Breakpoint{ID: 1, File: "/src/main.go", Line: 7}))
+
+		expect(c.ClearBreakpoint(bp.ID)).To(Not(HaveOccurred()))
+		expect(fake.breakpoints).To(HaveLen(0))
+		expect(c.ClearBreakpoint(bp.ID)).To(HaveOccurred())
+	})
+
+	o.Spec("it reads the call stack", func(expect expect.Expectation, fake *RPCServer, c *dlv.Client) {
+		frames, err := c.Stacktrace(1, 20)
+		expect(err).To(Not(HaveOccurred()))
+		expect(frames).To(HaveLen(2))
+		expect(frames[0].FunctionName()).To(Equal("main.foo"))
+		expect(frames[1].Line).To(Equal(4))
+	})
+
+	o.Spec("it reads arguments before locals", func(expect expect.Expectation, fake *RPCServer, c *dlv.Client) {
+		vars, err := c.Locals(dlv.EvalScope{GoroutineID: 3, Frame: 1})
+		expect(err).To(Not(HaveOccurred()))
+		expect(<-fake.scopes).To(Equal(EvalScope{GoroutineID: 3, Frame: 1}))
+		expect(vars).To(HaveLen(2))
+		expect(vars[0].Name).To(Equal("x"))
+		expect(vars[1].Children).To(HaveLen(1))
+		expect(vars[1].Children[0].Value).To(Equal("2"))
+	})
+
+	o.Spec("it detaches from the server", func(expect expect.Expectation, fake *RPCServer, c *dlv.Client) {
+		expect(c.Detach(true)).To(Not(HaveOccurred()))
+		expect(<-fake.killed).To(BeTrue())
+	})
+}
@@ -0,0 +1,91 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package dlv
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+const listening = "API server listening at: "
+
+// Process is a headless Delve server started by Launch.
+type Process struct {
+	// Addr is the address that the server is listening on.
+	Addr string
+
+	cmd  *exec.Cmd
+	done chan struct{}
+}
+
+// Launch starts `dlv` in headless mode in dir, passing it args (e.g.
+// "debug" or "test"), and waits for its API server to start.  Output
+// from the debugged program is copied to output.
+func Launch(env []string, dir string, output io.Writer, args ...string) (*Process, error) {
+	flags := []string{"--headless", "--api-version=2", "--listen=127.0.0.1:0"}
+	cmd := exec.Command("dlv", append(flags, args...)...)
+	cmd.Dir = dir
+	cmd.Env = env
+	errBuffer := &bytes.Buffer{}
+	cmd.Stderr = io.MultiWriter(errBuffer, output)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	p := &Process{cmd: cmd, done: make(chan struct{})}
+
+	addr := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(out)
+		found := false
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !found && strings.HasPrefix(line, listening) {
+				found = true
+				addr <- strings.TrimSpace(strings.TrimPrefix(line, listening))
+				continue
+			}
+			fmt.Fprintln(output, line)
+		}
+		close(addr)
+		cmd.Wait()
+		close(p.done)
+	}()
+
+	a, ok := <-addr
+	if !ok {
+		<-p.done
+		msg := strings.TrimSpace(errBuffer.String())
+		if msg == "" {
+			msg = "dlv exited before its API server started"
+		}
+		return nil, errors.New(msg)
+	}
+	p.Addr = a
+	return p, nil
+}
+
+// Done returns a channel that is closed when p exits.
+func (p *Process) Done() <-chan struct{} {
+	return p.done
+}
+
+// Kill stops p if it is still running.
+func (p *Process) Kill() error {
+	select {
+	case <-p.done:
+		return nil
+	default:
+	}
+	return p.cmd.Process.Kill()
+}
@@ -0,0 +1,188 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package dlv
+
+// The types in this file mirror the parts of Delve's service/api and
+// service/rpc2 packages that vidar uses.  Delve's own packages pull
+// in most of the debugger, so only the JSON shapes are copied here.
+
+// Function is a function in the debugged program.
+type Function struct {
+	Name string `json:"name"`
+}
+
+// Location is a position in the debugged program's source.
+type Location struct {
+	PC       uint64    `json:"pc"`
+	File     string    `json:"file"`
+	Line     int       `json:"line"`
+	Function *Function `json:"function,omitempty"`
+}
+
+// FunctionName returns the name of l's function, or an empty string
+// if it is unknown.
+func (l Location) FunctionName() string {
+	if l.Function == nil {
+		return ""
+	}
+	return l.Function.Name
+}
+
+// Thread is a thread in the debugged program.
+type Thread struct {
+	ID          int       `json:"id"`
+	PC          uint64    `json:"pc"`
+	File        string    `json:"file"`
+	Line        int       `json:"line"`
+	Function    *Function `json:"function,omitempty"`
+	GoroutineID int64     `json:"goroutineID"`
+}
+
+// Goroutine is a goroutine in the debugged program.
+type Goroutine struct {
+	ID         int64    `json:"id"`
+	CurrentLoc Location `json:"currentLoc"`
+}
+
+// State is the state of the debugger after a command.
+type State struct {
+	Running           bool
+	CurrentThread     *Thread
+	SelectedGoroutine *Goroutine
+	Exited            bool
+	ExitStatus        int
+}
+
+// Breakpoint is a breakpoint set in the debugged program.
+type Breakpoint struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	File         string `json:"file"`
+	Line         int    `json:"line"`
+	FunctionName string `json:"functionName,omitempty"`
+}
+
+// Variable is a variable in the debugged program.  Children holds
+// struct fields, slice and map elements, and pointer targets.
+type Variable struct {
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	Kind       int        `json:"kind"`
+	Value      string     `json:"value"`
+	Len        int64      `json:"len"`
+	Cap        int64      `json:"cap"`
+	Children   []Variable `json:"children"`
+	Unreadable string     `json:"unreadable"`
+}
+
+// Stackframe is a frame in a goroutine's call stack.
+type Stackframe struct {
+	Location
+	Locals    []Variable `json:"Locals,omitempty"`
+	Arguments []Variable `json:"Arguments,omitempty"`
+	Err       string     `json:"Err,omitempty"`
+}
+
+// LoadConfig controls how much of each variable Delve loads.
+type LoadConfig struct {
+	FollowPointers     bool
+	MaxVariableRecurse int
+	MaxStringLen       int
+	MaxArrayValues     int
+	MaxStructFields    int
+}
+
+// DefaultLoadConfig loads enough of each variable to display it in
+// a tree without loading entire large values.
+var DefaultLoadConfig = LoadConfig{
+	FollowPointers:     true,
+	MaxVariableRecurse: 1,
+	MaxStringLen:       256,
+	MaxArrayValues:     64,
+	MaxStructFields:    -1,
+}
+
+// EvalScope is the goroutine and frame that variables are read from.
+type EvalScope struct {
+	GoroutineID int64
+	Frame       int
+}
+
+// The names of the commands that Delve accepts for Command.
+const (
+	Continue = "continue"
+	Next     = "next"
+	Step     = "step"
+	StepOut  = "stepOut"
+	Halt     = "halt"
+)
+
+type debuggerCommand struct {
+	Name        string `json:"name"`
+	GoroutineID int64  `json:"goroutineID,omitempty"`
+}
+
+type commandOut struct {
+	State State
+}
+
+type stateIn struct {
+	NonBlocking bool
+}
+
+type stateOut struct {
+	State *State
+}
+
+type createBreakpointIn struct {
+	Breakpoint Breakpoint
+}
+
+type createBreakpointOut struct {
+	Breakpoint Breakpoint
+}
+
+type clearBreakpointIn struct {
+	Id int
+}
+
+type clearBreakpointOut struct {
+	Breakpoint *Breakpoint
+}
+
+type stacktraceIn struct {
+	Id    int64
+	Depth int
+	Full  bool
+	Cfg   *LoadConfig
+}
+
+type stacktraceOut struct {
+	Locations []Stackframe
+}
+
+type listLocalVarsIn struct {
+	Scope EvalScope
+	Cfg   LoadConfig
+}
+
+type listLocalVarsOut struct {
+	Variables []Variable
+}
+
+type listFunctionArgsIn struct {
+	Scope EvalScope
+	Cfg   LoadConfig
+}
+
+type listFunctionArgsOut struct {
+	Args []Variable
+}
+
+type detachIn struct {
+	Kill bool
+}
+
+type detachOut struct{}
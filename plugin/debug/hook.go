@@ -0,0 +1,39 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package debug
+
+import "github.com/nelsam/vidar/commander/input"
+
+// Hook connects editors to a Debugger: clicking an editor's gutter
+// toggles a breakpoint, breakpoints follow the lines they were set on
+// as text is edited, and the line that the program is stopped on
+// stays highlighted.
+type Hook struct {
+	Debugger *Debugger
+}
+
+func (h Hook) Name() string {
+	return "debug-breakpoints"
+}
+
+func (h Hook) OpName() string {
+	return "input-handler"
+}
+
+func (h Hook) Init(e input.Editor, _ []rune) {
+	h.Debugger.addEditor(e)
+}
+
+func (h Hook) TextChanged(input.Editor, input.Edit) {
+}
+
+func (h Hook) Apply(e input.Editor) error {
+	h.Debugger.restoreLine(e)
+	return nil
+}
+
+func (h Hook) Applied(e input.Editor, edits []input.Edit) {
+	h.Debugger.breakpoints.Edited(e.Filepath(), input.Snapshot(e), edits)
+}
@@ -0,0 +1,27 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package main
+
+import (
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/plugin/command"
+	"github.com/nelsam/vidar/plugin/debug"
+)
+
+// Bindables is the main entry point to the command.
+func Bindables(cmdr command.Commander, driver gxui.Driver, theme gxui.Theme) []bind.Bindable {
+	breakpoints := debug.DefaultBreakpoints()
+	d := debug.New(cmdr, driver, theme, breakpoints)
+	return []bind.Bindable{
+		debug.NewContinue(theme, d),
+		debug.NewNext(theme, d),
+		debug.NewStep(theme, d),
+		debug.NewStepOut(theme, d),
+		debug.NewStop(theme, d),
+		debug.NewToggleBreakpoint(theme, breakpoints),
+		debug.Hook{Debugger: d},
+	}
+}
@@ -0,0 +1,245 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package debug
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/gxui/math"
+	"github.com/nelsam/gxui/mixins"
+	"github.com/nelsam/gxui/themes/basic"
+	"github.com/nelsam/vidar/plugin/debug/dlv"
+	"github.com/nelsam/vidar/plugin/status"
+)
+
+var selectedColor = gxui.Color{
+	R: 0.6,
+	G: 0.8,
+	B: 1,
+	A: 1,
+}
+
+// outputLines is the number of lines of program output displayed
+// below the variables.
+const outputLines = 10
+
+// row is a clickable line in the pane.
+type row struct {
+	mixins.Button
+}
+
+func newRow(theme *basic.Theme, depth int) *row {
+	r := &row{}
+	r.Init(r, theme)
+	r.SetMargin(math.Spacing{L: 3 + depth*10})
+	r.SetPadding(math.Spacing{L: 1, R: 1, T: 1, B: 1})
+	return r
+}
+
+// Pane displays the call stack of the goroutine that the debugged
+// program is stopped in, and a tree of the variables in the selected
+// frame.  Clicking a frame opens its location and loads its
+// variables; clicking a variable expands or collapses it.
+type Pane struct {
+	debugger *Debugger
+	driver   gxui.Driver
+	theme    *basic.Theme
+
+	frame  gxui.LinearLayout
+	header gxui.Label
+	rows   gxui.LinearLayout
+
+	mu       sync.Mutex
+	status   string
+	frames   []dlv.Stackframe
+	selected int
+	vars     []dlv.Variable
+	expanded map[string]bool
+	pending  bool
+}
+
+func newPane(d *Debugger, driver gxui.Driver, theme gxui.Theme) *Pane {
+	p := &Pane{
+		debugger: d,
+		driver:   driver,
+		theme:    theme.(*basic.Theme),
+		frame:    theme.CreateLinearLayout(),
+		header:   theme.CreateLabel(),
+		rows:     theme.CreateLinearLayout(),
+		status:   "Not debugging",
+		expanded: make(map[string]bool),
+	}
+	p.frame.SetDirection(gxui.TopToBottom)
+	p.rows.SetDirection(gxui.TopToBottom)
+	p.header.SetText(p.status)
+	p.frame.AddChild(p.header)
+	scrollable := theme.CreateScrollLayout()
+	scrollable.SetScrollAxis(false, true)
+	scrollable.SetChild(p.rows)
+	p.frame.AddChild(scrollable)
+	p.frame.SetChildWeight(scrollable, 1)
+	return p
+}
+
+// Frame returns the control that displays p's call stack and
+// variables.
+func (p *Pane) Frame() gxui.Control {
+	return p.frame
+}
+
+func (p *Pane) setStatus(s string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status = s
+	p.refreshLocked()
+}
+
+// setStopped replaces everything that p displays.
+func (p *Pane) setStopped(s string, frames []dlv.Stackframe, vars []dlv.Variable) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status = s
+	p.frames = frames
+	p.selected = 0
+	p.vars = vars
+	p.expanded = make(map[string]bool)
+	p.refreshLocked()
+}
+
+func (p *Pane) setVariables(frame int, vars []dlv.Variable) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.selected = frame
+	p.vars = vars
+	p.expanded = make(map[string]bool)
+	p.refreshLocked()
+}
+
+// refreshLocked schedules a redraw, unless one is already scheduled.
+// p.mu must be locked by the caller.
+func (p *Pane) refreshLocked() {
+	if p.pending {
+		return
+	}
+	p.pending = true
+	p.driver.Call(func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.pending = false
+		p.redraw()
+	})
+}
+
+// redraw rebuilds the rows.  It must be called on the UI goroutine
+// with p.mu locked.
+func (p *Pane) redraw() {
+	p.header.SetText(p.status)
+	p.rows.RemoveAll()
+	if len(p.frames) > 0 {
+		p.addHeading("Call Stack")
+		for i, f := range p.frames {
+			p.addFrame(i, f)
+		}
+	}
+	if len(p.vars) > 0 {
+		p.addHeading("Variables")
+		for _, v := range p.vars {
+			p.addVariable(v, v.Name, 0)
+		}
+	}
+	if output := lastLines(p.debugger.Output(), outputLines); len(output) > 0 {
+		p.addHeading("Output")
+		for _, line := range output {
+			l := p.theme.CreateLabel()
+			l.SetText(line)
+			l.SetMargin(math.Spacing{L: 3})
+			p.rows.AddChild(l)
+		}
+	}
+}
+
+func (p *Pane) addHeading(text string) {
+	l := p.theme.CreateLabel()
+	l.SetText(text)
+	l.SetMargin(math.Spacing{T: 5})
+	p.rows.AddChild(l)
+}
+
+func (p *Pane) addFrame(i int, f dlv.Stackframe) {
+	r := newRow(p.theme, 0)
+	r.SetText(fmt.Sprintf("%s %s:%d", f.FunctionName(), filepath.Base(f.File), f.Line))
+	if i == p.selected {
+		r.Label().SetColor(selectedColor)
+	}
+	loc := f.Location
+	r.OnClick(func(gxui.MouseEvent) {
+		p.debugger.SelectFrame(i, loc)
+	})
+	p.rows.AddChild(r)
+}
+
+// addVariable adds a row for v and, if v is expanded, its children.
+// path identifies v in p.expanded.
+func (p *Pane) addVariable(v dlv.Variable, path string, depth int) {
+	r := newRow(p.theme, depth)
+	r.SetText(variableText(v, len(v.Children) > 0, p.expanded[path]))
+	if v.Unreadable != "" {
+		r.Label().SetColor(status.ColorErr)
+	}
+	if len(v.Children) > 0 {
+		r.OnClick(func(gxui.MouseEvent) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.expanded[path] = !p.expanded[path]
+			p.redraw()
+		})
+	}
+	p.rows.AddChild(r)
+	if !p.expanded[path] {
+		return
+	}
+	for i, c := range v.Children {
+		name := c.Name
+		if name == "" {
+			name = fmt.Sprintf("[%d]", i)
+			c.Name = name
+		}
+		p.addVariable(c, path+"."+name, depth+1)
+	}
+}
+
+func variableText(v dlv.Variable, parent, expanded bool) string {
+	prefix := "  "
+	if parent {
+		prefix = "▸ "
+		if expanded {
+			prefix = "▾ "
+		}
+	}
+	value := v.Value
+	if v.Unreadable != "" {
+		value = "unreadable: " + v.Unreadable
+	}
+	if value == "" {
+		return fmt.Sprintf("%s%s %s", prefix, v.Name, v.Type)
+	}
+	return fmt.Sprintf("%s%s %s = %s", prefix, v.Name, v.Type, value)
+}
+
+func lastLines(s string, n int) []string {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
@@ -0,0 +1,126 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package format runs the formatters configured for a project on
+// files before they are saved.
+package format
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/nelsam/vidar/setting"
+)
+
+// Error is an error from a single formatter.
+type Error struct {
+	Formatter string
+	Msg       string
+	Block     bool
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Formatter, e.Msg)
+}
+
+// Blocking returns whether the error should prevent the file from
+// being saved.
+func (e Error) Blocking() bool {
+	return e.Block
+}
+
+// OnSave is a hook that runs a project's formatters on files before
+// they are saved.
+type OnSave struct{}
+
+func (OnSave) Name() string {
+	return "format-on-save"
+}
+
+func (OnSave) OpName() string {
+	return "save-current-file"
+}
+
+// BeforeSave runs each formatter for path in order, passing the
+// output of each formatter to the next.  If a non-blocking formatter
+// fails, its output is skipped and the rest of the chain still runs,
+// so the text is returned along with an error listing the failures.
+func (OnSave) BeforeSave(proj setting.Project, path, text string) (newText string, err error) {
+	var errs []string
+	for _, f := range proj.Formatters(path) {
+		formatted, err := Run(proj, f, path, text)
+		if err != nil {
+			if err.Block {
+				return "", err
+			}
+			errs = append(errs, err.Error())
+			continue
+		}
+		text = formatted
+	}
+	if len(errs) > 0 {
+		return text, errors.New(strings.Join(errs, "; "))
+	}
+	return text, nil
+}
+
+// Run runs f on text, returning the formatted text.
+func Run(proj setting.Project, f setting.Formatter, path, text string) (string, *Error) {
+	fail := func(msg string) *Error {
+		return &Error{Formatter: f.String(), Msg: msg, Block: f.Blocking}
+	}
+	if f.Command == "" {
+		return "", fail("no command configured")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), f.Duration())
+	defer cancel()
+
+	env := f.Environ(proj)
+	lookup := func(name string) string {
+		if name == "FILE" {
+			return path
+		}
+		prefix := name + "="
+		for _, v := range env {
+			if strings.HasPrefix(v, prefix) {
+				return strings.TrimPrefix(v, prefix)
+			}
+		}
+		return ""
+	}
+	var args []string
+	for _, a := range f.Args {
+		args = append(args, os.Expand(a, lookup))
+	}
+
+	cmd := exec.CommandContext(ctx, f.Command, args...)
+	cmd.Stdin = strings.NewReader(text)
+	errBuffer := &bytes.Buffer{}
+	cmd.Stderr = errBuffer
+	cmd.Env = env
+	cmd.Dir = filepath.Dir(path)
+	formatted, err := cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fail(fmt.Sprintf("timed out after %s", f.Duration()))
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(errBuffer.String()); msg != "" {
+			return "", fail(msg)
+		}
+		return "", fail(err.Error())
+	}
+	if len(formatted) == 0 && text != "" {
+		// A formatter that exits successfully without writing
+		// anything has most likely been configured to format the
+		// file in place; using its output would empty the file.
+		return "", fail("no output for non-empty input")
+	}
+	return string(formatted), nil
+}
@@ -0,0 +1,117 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package format_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/plugin/format"
+	"github.com/nelsam/vidar/setting"
+)
+
+const helperEnv = "VIDAR_FORMAT_HELPER"
+
+// TestMain allows the test binary to act as a formatter, so that the
+// tests don't depend on any formatters being installed.
+func TestMain(m *testing.M) {
+	switch os.Getenv(helperEnv) {
+	case "":
+		os.Exit(m.Run())
+	case "upper":
+		b, _ := ioutil.ReadAll(os.Stdin)
+		fmt.Print(strings.ToUpper(string(b)))
+	case "suffix":
+		b, _ := ioutil.ReadAll(os.Stdin)
+		fmt.Print(string(b) + strings.Join(os.Args[1:], " "))
+	case "fail":
+		fmt.Fprint(os.Stderr, "bad input")
+		os.Exit(1)
+	case "silent":
+		ioutil.ReadAll(os.Stdin)
+	case "sleep":
+		time.Sleep(5 * time.Second)
+	}
+	os.Exit(0)
+}
+
+func helper(name, mode string, args ...string) setting.Formatter {
+	return setting.Formatter{
+		Name:    name,
+		Command: os.Args[0],
+		Args:    args,
+		Env:     map[string]string{helperEnv: "=" + mode},
+	}
+}
+
+func path(p setting.Project) string {
+	return filepath.Join(p.Path, "bar.go")
+}
+
+func TestOnSave(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, setting.Project) {
+		return expect.New(t), setting.Project{Name: "test", Path: os.TempDir()}
+	})
+
+	o.Spec("it runs each matching formatter in order", func(expect expect.Expectation, p setting.Project) {
+		p.Format = []setting.FormatChain{
+			{Glob: "*.go", Formatters: []setting.Formatter{helper("upper", "upper"), helper("suffix", "suffix", "-x", "$FILE")}},
+			{Glob: "*.md", Formatters: []setting.Formatter{helper("fail", "fail")}},
+		}
+		text, err := format.OnSave{}.BeforeSave(p, path(p), "foo\n")
+		expect(err).To(Not(HaveOccurred()))
+		expect(text).To(Equal("FOO\n-x " + path(p)))
+	})
+
+	o.Spec("it skips failed formatters and reports them by name", func(expect expect.Expectation, p setting.Project) {
+		p.Format = []setting.FormatChain{
+			{Glob: "*.go", Formatters: []setting.Formatter{helper("broken", "fail"), helper("upper", "upper")}},
+		}
+		text, err := format.OnSave{}.BeforeSave(p, path(p), "foo")
+		expect(err).To(HaveOccurred())
+		expect(err.Error()).To(Equal("broken: bad input"))
+		expect(text).To(Equal("FOO"))
+	})
+
+	o.Spec("it blocks the save when a blocking formatter fails", func(expect expect.Expectation, p setting.Project) {
+		f := helper("broken", "fail")
+		f.Blocking = true
+		p.Format = []setting.FormatChain{{Glob: "*.go", Formatters: []setting.Formatter{f}}}
+		_, err := format.OnSave{}.BeforeSave(p, path(p), "foo")
+		expect(err).To(HaveOccurred())
+		blocker, ok := err.(interface{ Blocking() bool })
+		expect(ok).To(BeTrue())
+		expect(blocker.Blocking()).To(BeTrue())
+	})
+
+	o.Spec("it treats empty output for non-empty input as a failure", func(expect expect.Expectation, p setting.Project) {
+		_, err := format.Run(p, helper("quiet", "silent"), path(p), "foo")
+		expect(err).To(Not(BeNil()))
+		expect(err.Error()).To(Equal("quiet: no output for non-empty input"))
+
+		text, err := format.Run(p, helper("quiet", "silent"), path(p), "")
+		expect(err).To(BeNil())
+		expect(text).To(Equal(""))
+	})
+
+	o.Spec("it stops formatters that run past their timeout", func(expect expect.Expectation, p setting.Project) {
+		f := helper("slow", "sleep")
+		f.Timeout = "50ms"
+		_, err := format.Run(p, f, path(p), "foo")
+		expect(err).To(Not(BeNil()))
+		expect(err.Error()).To(Equal("slow: timed out after 50ms"))
+	})
+}
@@ -0,0 +1,162 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/piece"
+)
+
+// Commit is the commit that blame attributes a line to.
+type Commit struct {
+	Hash    string
+	Author  string
+	Time    time.Time
+	Summary string
+}
+
+// Uncommitted returns whether c stands for lines that haven't been
+// committed.  A nil *Commit is uncommitted.
+func (c *Commit) Uncommitted() bool {
+	return c == nil || strings.Trim(c.Hash, "0") == ""
+}
+
+// Short returns the abbreviated hash of c.
+func (c *Commit) Short() string {
+	if len(c.Hash) < 7 {
+		return c.Hash
+	}
+	return c.Hash[:7]
+}
+
+// Blame returns the commit that last changed each line of contents,
+// which is the current text of path.  Lines that differ from HEAD are
+// uncommitted.
+func Blame(path, contents string) ([]*Commit, error) {
+	root, relPath, err := rel(path)
+	if err != nil {
+		return nil, err
+	}
+	out, err := runInput(root, strings.NewReader(contents), "blame", "--porcelain", "--contents", "-", "--", relPath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBlame(strings.NewReader(out))
+}
+
+// ParseBlame parses the output of `git blame --porcelain`.
+func ParseBlame(r io.Reader) ([]*Commit, error) {
+	var lines []*Commit
+	commits := make(map[string]*Commit)
+	var current *Commit
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		if current == nil {
+			fields := strings.Fields(text)
+			if len(fields) < 3 || len(fields[0]) != 40 {
+				return nil, fmt.Errorf("malformed blame header %q", text)
+			}
+			c, ok := commits[fields[0]]
+			if !ok {
+				c = &Commit{Hash: fields[0]}
+				commits[c.Hash] = c
+			}
+			current = c
+			continue
+		}
+		if strings.HasPrefix(text, "\t") {
+			lines = append(lines, current)
+			current = nil
+			continue
+		}
+		key, value := text, ""
+		if space := strings.Index(text, " "); space >= 0 {
+			key, value = text[:space], text[space+1:]
+		}
+		switch key {
+		case "author":
+			current.Author = value
+		case "author-time":
+			secs, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("malformed author-time %q", value)
+			}
+			current.Time = time.Unix(secs, 0)
+		case "summary":
+			current.Summary = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// ShowCommit returns the message and diff of the commit with hash in
+// the repository that contains path.
+func ShowCommit(path, hash string) (string, error) {
+	root, _, err := rel(path)
+	if err != nil {
+		return "", err
+	}
+	return run(root, "show", "--format=fuller", hash)
+}
+
+// Edited returns lines updated for edits, which have been applied to
+// text.  Edits must be in the order they were applied.  Lines that an
+// edit touches are uncommitted (nil) in the result.
+func Edited(lines []*Commit, text *piece.Table, edits []input.Edit) []*Commit {
+	for _, e := range edits {
+		at := e.At
+		if at > text.Len() {
+			at = text.Len()
+		}
+		start := text.LineOf(at)
+		end := start + count(e.Old, '\n') + 1
+		added := count(e.New, '\n') + 1
+		lineStart := at == 0 || text.RuneAt(at-1) == '\n'
+		if lineStart && endsLine(e.Old) && endsLine(e.New) {
+			// Whole lines were replaced, so the line after them is
+			// untouched.
+			end--
+			added--
+		}
+		if start > len(lines) {
+			start = len(lines)
+		}
+		if end > len(lines) {
+			end = len(lines)
+		}
+		updated := make([]*Commit, 0, len(lines)-(end-start)+added)
+		updated = append(updated, lines[:start]...)
+		updated = append(updated, make([]*Commit, added)...)
+		lines = append(updated, lines[end:]...)
+	}
+	return lines
+}
+
+// endsLine returns whether r is empty or ends with a newline.
+func endsLine(r []rune) bool {
+	return len(r) == 0 || r[len(r)-1] == '\n'
+}
+
+func count(runes []rune, r rune) int {
+	n := 0
+	for _, c := range runes {
+		if c == r {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,121 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package git_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/piece"
+	"github.com/nelsam/vidar/plugin/git"
+)
+
+func TestBlameEdits(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	a, b, c := &git.Commit{Hash: "a"}, &git.Commit{Hash: "b"}, &git.Commit{Hash: "c"}
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, []*git.Commit) {
+		return expect.New(t), []*git.Commit{a, b, c}
+	})
+
+	o.Spec("it marks edited lines as uncommitted", func(expect expect.Expectation, lines []*git.Commit) {
+		edits := []input.Edit{{At: 3, New: []rune("x")}}
+		lines = git.Edited(lines, piece.NewString("a\nbx\nc"), edits)
+		expect(lines).To(Equal([]*git.Commit{a, nil, c}))
+	})
+
+	o.Spec("it inserts uncommitted lines", func(expect expect.Expectation, lines []*git.Commit) {
+		edits := []input.Edit{{At: 2, New: []rune("y\n")}}
+		lines = git.Edited(lines, piece.NewString("a\ny\nb\nc"), edits)
+		expect(lines).To(Equal([]*git.Commit{a, nil, b, c}))
+
+		edits = []input.Edit{{At: 1, New: []rune("\n")}}
+		lines = git.Edited(lines, piece.NewString("a\n\ny\nb\nc"), edits)
+		expect(lines).To(Equal([]*git.Commit{nil, nil, nil, b, c}))
+	})
+
+	o.Spec("it removes deleted lines", func(expect expect.Expectation, lines []*git.Commit) {
+		edits := []input.Edit{{At: 2, Old: []rune("b\n")}}
+		lines = git.Edited(lines, piece.NewString("a\nc"), edits)
+		expect(lines).To(Equal([]*git.Commit{a, c}))
+	})
+
+	o.Spec("it applies edits in order", func(expect expect.Expectation, lines []*git.Commit) {
+		edits := []input.Edit{
+			{At: 0, Old: []rune("a\n")},
+			{At: 2, Old: []rune("c"), New: []rune("z")},
+		}
+		lines = git.Edited(lines, piece.NewString("b\nz"), edits)
+		expect(lines).To(Equal([]*git.Commit{b, nil}))
+	})
+}
+
+func TestBlame(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, string) {
+		expect := expect.New(t)
+		dir, err := ioutil.TempDir("", "vidar-git")
+		expect(err).To(Not(HaveOccurred()))
+		run := func(args ...string) {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = dir
+			out, err := cmd.CombinedOutput()
+			expect(err).To(Not(HaveOccurred()))
+			if err != nil {
+				t.Log(string(out))
+			}
+		}
+		run("init", "-q")
+		run("config", "user.email", "test@example.com")
+		run("config", "user.name", "Test")
+		write(expect, filepath.Join(dir, "foo.txt"), "one\ntwo\n")
+		run("add", "foo.txt")
+		run("commit", "-q", "-m", "initial")
+		return expect, dir
+	})
+
+	o.AfterEach(func(_ expect.Expectation, dir string) {
+		os.RemoveAll(dir)
+	})
+
+	o.Spec("it blames the text of a buffer", func(expect expect.Expectation, dir string) {
+		path := filepath.Join(dir, "foo.txt")
+		lines, err := git.Blame(path, "one\nTWO\nthree\n")
+		expect(err).To(Not(HaveOccurred()))
+		expect(lines).To(HaveLen(3))
+
+		expect(lines[0].Uncommitted()).To(BeFalse())
+		expect(lines[0].Author).To(Equal("Test"))
+		expect(lines[0].Summary).To(Equal("initial"))
+		expect(lines[1].Uncommitted()).To(BeTrue())
+		expect(lines[2].Uncommitted()).To(BeTrue())
+
+		show, err := git.ShowCommit(path, lines[0].Hash)
+		expect(err).To(Not(HaveOccurred()))
+		expect(show).To(ContainSubstring("initial"))
+		expect(show).To(ContainSubstring("+two"))
+	})
+
+	o.Spec("it reports malformed output", func(expect expect.Expectation, _ string) {
+		_, err := git.ParseBlame(strings.NewReader("not blame output\n"))
+		expect(err).To(HaveOccurred())
+	})
+}
@@ -0,0 +1,282 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package git
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/command/focus"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/piece"
+	"github.com/nelsam/vidar/plugin/status"
+	"github.com/nelsam/vidar/setting"
+)
+
+// blameOwner is the name that blame entries are set under in the
+// gutter.
+const blameOwner = "blame"
+
+// authorWidth is the number of characters of each author's name that
+// are displayed.
+const authorWidth = 12
+
+var blameColor = gxui.Color{
+	R: 0.6,
+	G: 0.6,
+	B: 0.6,
+	A: 1,
+}
+
+// Commander is a type that can look up and execute bindables.
+type Commander interface {
+	Bindable(name string) bind.Bindable
+	Execute(bind.Bindable)
+}
+
+// Opener is a type that can open locations in the code.
+type Opener interface {
+	For(...focus.Opt) bind.Bindable
+}
+
+// StatusShower is a type that can display a status to the user
+// outside of a command.
+type StatusShower interface {
+	ShowStatus(gxui.Control)
+}
+
+// ReadOnlySetter is an editor that can refuse edits.
+type ReadOnlySetter interface {
+	SetReadOnly(bool)
+}
+
+// BlameView displays blame in a column beside the line numbers of
+// editors.  Clicking an entry opens its commit in a read-only editor.
+type BlameView struct {
+	cmdr   Commander
+	driver gxui.Driver
+	theme  gxui.Theme
+	status StatusShower
+
+	mu      sync.Mutex
+	editors map[string]input.Editor
+	shown   map[string][]*Commit
+
+	// commits are the paths of files that commits were written to,
+	// which are opened read-only.
+	commits map[string]bool
+}
+
+// NewBlameView returns a *BlameView that opens commits using cmdr.
+func NewBlameView(cmdr Commander, driver gxui.Driver, theme gxui.Theme) *BlameView {
+	status, _ := cmdr.(StatusShower)
+	return &BlameView{
+		cmdr:    cmdr,
+		driver:  driver,
+		theme:   theme,
+		status:  status,
+		editors: make(map[string]input.Editor),
+		shown:   make(map[string][]*Commit),
+		commits: make(map[string]bool),
+	}
+}
+
+// Toggle shows or hides blame for e, returning whether it is shown.
+// It must be called on the UI goroutine.
+func (v *BlameView) Toggle(e input.Editor) bool {
+	path := e.Filepath()
+	v.mu.Lock()
+	if _, ok := v.shown[path]; ok {
+		delete(v.shown, path)
+		v.mu.Unlock()
+		if g, ok := e.(input.Gutter); ok {
+			g.SetGutterMarks(blameOwner, nil)
+		}
+		return false
+	}
+	v.shown[path] = nil
+	v.editors[path] = e
+	v.mu.Unlock()
+	go v.load(path, e.Text())
+	return true
+}
+
+// load blames contents, the text of path, and displays the result.
+func (v *BlameView) load(path, contents string) {
+	lines, err := Blame(path, contents)
+	if err != nil {
+		v.report(fmt.Sprintf("git blame: %s", err))
+		v.mu.Lock()
+		delete(v.shown, path)
+		v.mu.Unlock()
+		return
+	}
+	v.mu.Lock()
+	if _, ok := v.shown[path]; !ok {
+		v.mu.Unlock()
+		return
+	}
+	v.shown[path] = lines
+	v.mu.Unlock()
+	v.driver.Call(func() { v.mark(path) })
+}
+
+// edited updates the blame for path after edits were applied to text.
+func (v *BlameView) edited(path string, text *piece.Table, edits []input.Edit) {
+	v.mu.Lock()
+	lines, ok := v.shown[path]
+	if !ok || lines == nil {
+		v.mu.Unlock()
+		return
+	}
+	v.shown[path] = Edited(lines, text, edits)
+	v.mu.Unlock()
+	v.mark(path)
+}
+
+// mark sets the gutter marks for path.  It must be called on the UI
+// goroutine.
+func (v *BlameView) mark(path string) {
+	v.mu.Lock()
+	lines, ok := v.shown[path]
+	e := v.editors[path]
+	v.mu.Unlock()
+	g, isGutter := e.(input.Gutter)
+	if !ok || !isGutter {
+		return
+	}
+	marks := make(map[int]input.GutterMark, len(lines))
+	for i, c := range lines {
+		if c.Uncommitted() {
+			marks[i] = input.GutterMark{
+				Text:  "uncommitted",
+				Color: status.ColorWarn,
+				// Clicking an uncommitted line shouldn't count as a
+				// click in the gutter, like clicking a commit doesn't.
+				OnClick: func() {},
+			}
+			continue
+		}
+		c := c
+		marks[i] = input.GutterMark{
+			Text:    blameText(c),
+			Color:   blameColor,
+			OnClick: func() { go v.open(path, c) },
+		}
+	}
+	g.SetGutterMarks(blameOwner, marks)
+}
+
+// blameText returns the text of c's entry in the blame column.
+func blameText(c *Commit) string {
+	author := []rune(c.Author)
+	if len(author) > authorWidth {
+		author = author[:authorWidth]
+	}
+	return fmt.Sprintf("%s %-*s %s", c.Short(), authorWidth, string(author), c.Time.Format("2006-01-02"))
+}
+
+// open writes the message and diff of c to a file and opens it in a
+// read-only editor.
+func (v *BlameView) open(path string, c *Commit) {
+	text, err := ShowCommit(path, c.Hash)
+	if err != nil {
+		v.report(fmt.Sprintf("git show: %s", err))
+		return
+	}
+	dir := filepath.Join(os.TempDir(), "vidar-git")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		v.report(fmt.Sprintf("Could not create %s: %s", dir, err))
+		return
+	}
+	commitPath := filepath.Join(dir, c.Short()+".diff")
+	if err := ioutil.WriteFile(commitPath, []byte(text), 0644); err != nil {
+		v.report(fmt.Sprintf("Could not write commit %s: %s", c.Short(), err))
+		return
+	}
+	v.mu.Lock()
+	v.commits[commitPath] = true
+	v.mu.Unlock()
+	v.driver.Call(func() {
+		opener := v.cmdr.Bindable("focus-location").(Opener)
+		v.cmdr.Execute(opener.For(focus.Path(commitPath)))
+	})
+}
+
+// report displays msg as an error, if v has a StatusShower.
+func (v *BlameView) report(msg string) {
+	if v.status == nil {
+		return
+	}
+	v.driver.Call(func() {
+		s := status.General{Theme: v.theme, Err: msg}
+		v.status.ShowStatus(s.Status())
+	})
+}
+
+// BlameHook keeps blame up to date as files are edited, and makes
+// editors for commits read-only.
+type BlameHook struct {
+	View *BlameView
+}
+
+func (h BlameHook) Name() string {
+	return "git-blame"
+}
+
+func (h BlameHook) OpName() string {
+	return "input-handler"
+}
+
+func (h BlameHook) Init(e input.Editor, _ []rune) {
+	v := h.View
+	v.mu.Lock()
+	readOnly := v.commits[e.Filepath()]
+	v.mu.Unlock()
+	if s, ok := e.(ReadOnlySetter); ok && readOnly {
+		s.SetReadOnly(true)
+	}
+}
+
+func (h BlameHook) TextChanged(input.Editor, input.Edit) {
+}
+
+func (h BlameHook) Apply(input.Editor) error {
+	return nil
+}
+
+func (h BlameHook) Applied(e input.Editor, edits []input.Edit) {
+	h.View.edited(e.Filepath(), input.Snapshot(e), edits)
+}
+
+// BlameOnSave reblames files when they are saved, replacing the
+// blame that was tracked through edits.
+type BlameOnSave struct {
+	View *BlameView
+}
+
+func (o BlameOnSave) Name() string {
+	return "git-blame-on-save"
+}
+
+func (o BlameOnSave) OpName() string {
+	return "save-current-file"
+}
+
+func (o BlameOnSave) AfterSave(_ setting.Project, path, contents string) error {
+	v := o.View
+	v.mu.Lock()
+	_, ok := v.shown[path]
+	v.mu.Unlock()
+	if ok {
+		go v.load(path, contents)
+	}
+	return nil
+}
@@ -0,0 +1,462 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package git
+
+import (
+	"fmt"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/plugin/status"
+)
+
+type Editor interface {
+	Filepath() string
+	Runes() []rune
+}
+
+type Scroller interface {
+	LineStart(int) int
+	ScrollToLine(int)
+}
+
+type CursorController interface {
+	LastCaret() int
+}
+
+type CaretSetter interface {
+	SetCaret(int)
+}
+
+type Applier interface {
+	Apply(input.Editor, ...input.Edit)
+}
+
+// caretLine returns the 0-based line that caret is on in text.
+func caretLine(text []rune, caret int) int {
+	if caret > len(text) {
+		caret = len(text)
+	}
+	line := 0
+	for _, r := range text[:caret] {
+		if r == '\n' {
+			line++
+		}
+	}
+	return line
+}
+
+// hunkAt returns the hunk that contains line.
+func hunkAt(hunks []Hunk, line int) (Hunk, bool) {
+	for _, h := range hunks {
+		if h.Contains(line) {
+			return h, true
+		}
+	}
+	return Hunk{}, false
+}
+
+// firstLine returns the line that the caret is moved to when jumping
+// to h.
+func (h Hunk) firstLine() int {
+	if h.NewLines == 0 {
+		return h.markLine()
+	}
+	return h.NewStart
+}
+
+// Jump is a command that moves the caret to the next or previous
+// hunk, wrapping around at the end of the file.
+type Jump struct {
+	status.General
+
+	tracker *Tracker
+	name    string
+	key     gxui.KeyboardEvent
+	forward bool
+
+	editor   Editor
+	scroller Scroller
+	ctrl     CursorController
+	setter   CaretSetter
+}
+
+func newJump(theme gxui.Theme, t *Tracker, name string, key gxui.KeyboardEvent, forward bool) *Jump {
+	j := &Jump{tracker: t, name: name, key: key, forward: forward}
+	j.Theme = theme
+	return j
+}
+
+// NewNextHunk returns a *Jump that moves to the next hunk.
+func NewNextHunk(theme gxui.Theme, t *Tracker) *Jump {
+	key := gxui.KeyboardEvent{Modifier: gxui.ModControl | gxui.ModAlt, Key: gxui.KeyDown}
+	return newJump(theme, t, "next-git-hunk", key, true)
+}
+
+// NewPrevHunk returns a *Jump that moves to the previous hunk.
+func NewPrevHunk(theme gxui.Theme, t *Tracker) *Jump {
+	key := gxui.KeyboardEvent{Modifier: gxui.ModControl | gxui.ModAlt, Key: gxui.KeyUp}
+	return newJump(theme, t, "previous-git-hunk", key, false)
+}
+
+func (j *Jump) Name() string {
+	return j.name
+}
+
+func (j *Jump) Menu() string {
+	return "Git"
+}
+
+func (j *Jump) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{j.key}
+}
+
+func (j *Jump) Reset() {
+	j.editor = nil
+	j.scroller = nil
+	j.ctrl = nil
+	j.setter = nil
+}
+
+func (j *Jump) Store(target interface{}) bind.Status {
+	if e, ok := target.(Editor); ok {
+		j.editor = e
+	}
+	if s, ok := target.(Scroller); ok {
+		j.scroller = s
+	}
+	if c, ok := target.(CursorController); ok {
+		j.ctrl = c
+	}
+	if s, ok := target.(CaretSetter); ok {
+		j.setter = s
+	}
+	if j.editor != nil && j.scroller != nil && j.ctrl != nil && j.setter != nil {
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+func (j *Jump) Exec() error {
+	hunks, _ := j.tracker.Hunks(j.editor.Filepath())
+	if len(hunks) == 0 {
+		j.Info = "No changes"
+		return nil
+	}
+	line := caretLine(j.editor.Runes(), j.ctrl.LastCaret())
+	target, wrapped := hunks[0], true
+	if j.forward {
+		for _, h := range hunks {
+			if h.firstLine() > line {
+				target, wrapped = h, false
+				break
+			}
+		}
+	} else {
+		target = hunks[len(hunks)-1]
+		for i := len(hunks) - 1; i >= 0; i-- {
+			if hunks[i].firstLine() < line {
+				target, wrapped = hunks[i], false
+				break
+			}
+		}
+	}
+	if wrapped && len(hunks) > 1 {
+		j.Info = "Wrapped around the file"
+	}
+	l := target.firstLine()
+	j.setter.SetCaret(j.scroller.LineStart(l))
+	j.scroller.ScrollToLine(l)
+	return nil
+}
+
+// RevertHunk is a command that replaces the hunk at the caret with the
+// base version of its lines.
+type RevertHunk struct {
+	status.General
+
+	tracker *Tracker
+
+	editor  input.Editor
+	ctrl    CursorController
+	applier Applier
+}
+
+// NewRevertHunk returns a *RevertHunk that reverts hunks found by t.
+func NewRevertHunk(theme gxui.Theme, t *Tracker) *RevertHunk {
+	r := &RevertHunk{tracker: t}
+	r.Theme = theme
+	return r
+}
+
+func (r *RevertHunk) Name() string {
+	return "revert-git-hunk"
+}
+
+func (r *RevertHunk) Menu() string {
+	return "Git"
+}
+
+func (r *RevertHunk) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl | gxui.ModAlt,
+		Key:      gxui.KeyZ,
+	}}
+}
+
+func (r *RevertHunk) Reset() {
+	r.editor = nil
+	r.ctrl = nil
+	r.applier = nil
+}
+
+func (r *RevertHunk) Store(target interface{}) bind.Status {
+	if e, ok := target.(input.Editor); ok {
+		r.editor = e
+	}
+	if c, ok := target.(CursorController); ok {
+		r.ctrl = c
+	}
+	if a, ok := target.(Applier); ok {
+		r.applier = a
+	}
+	if r.editor != nil && r.ctrl != nil && r.applier != nil {
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+func (r *RevertHunk) Exec() error {
+	hunks, base := r.tracker.Hunks(r.editor.Filepath())
+	if base == nil {
+		r.Warn = fmt.Sprintf("File is not tracked in %s", r.tracker.Base())
+		return nil
+	}
+	text := r.editor.Runes()
+	line := caretLine(text, r.ctrl.LastCaret())
+	h, ok := hunkAt(hunks, line)
+	if !ok {
+		r.Warn = "There is no change on this line"
+		return nil
+	}
+	r.applier.Apply(r.editor, Revert(text, base, h))
+	r.Info = fmt.Sprintf("Reverted %d line(s) to %s", h.NewLines, r.tracker.Base())
+	return nil
+}
+
+// ShowHunk is a command that shows the base version of the hunk at the
+// caret below it, or hides it if it is already shown.
+type ShowHunk struct {
+	status.General
+
+	tracker *Tracker
+
+	editor PopupEditor
+	ctrl   CursorController
+}
+
+// NewShowHunk returns a *ShowHunk that shows hunks found by t.
+func NewShowHunk(theme gxui.Theme, t *Tracker) *ShowHunk {
+	s := &ShowHunk{tracker: t}
+	s.Theme = theme
+	return s
+}
+
+func (s *ShowHunk) Name() string {
+	return "show-git-hunk"
+}
+
+func (s *ShowHunk) Menu() string {
+	return "Git"
+}
+
+func (s *ShowHunk) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl | gxui.ModAlt,
+		Key:      gxui.KeyH,
+	}}
+}
+
+func (s *ShowHunk) Reset() {
+	s.editor = nil
+	s.ctrl = nil
+}
+
+func (s *ShowHunk) Store(target interface{}) bind.Status {
+	if e, ok := target.(PopupEditor); ok {
+		s.editor = e
+	}
+	if c, ok := target.(CursorController); ok {
+		s.ctrl = c
+	}
+	if s.editor != nil && s.ctrl != nil {
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+func (s *ShowHunk) Exec() error {
+	path := s.editor.Filepath()
+	if hidePopup(s.tracker, path) {
+		return nil
+	}
+	hunks, base := s.tracker.Hunks(path)
+	if base == nil {
+		s.Warn = fmt.Sprintf("File is not tracked in %s", s.tracker.Base())
+		return nil
+	}
+	h, ok := hunkAt(hunks, caretLine(s.editor.Runes(), s.ctrl.LastCaret()))
+	if !ok {
+		s.Warn = "There is no change on this line"
+		return nil
+	}
+	showPopup(s.tracker, s.Theme, s.editor, h, base)
+	return nil
+}
+
+// ToggleBase is a command that switches between comparing files
+// against HEAD and against the index.
+type ToggleBase struct {
+	status.General
+
+	tracker *Tracker
+}
+
+// NewToggleBase returns a *ToggleBase that changes t's base.
+func NewToggleBase(theme gxui.Theme, t *Tracker) *ToggleBase {
+	b := &ToggleBase{tracker: t}
+	b.Theme = theme
+	return b
+}
+
+func (b *ToggleBase) Name() string {
+	return "toggle-git-diff-base"
+}
+
+func (b *ToggleBase) Menu() string {
+	return "Git"
+}
+
+func (b *ToggleBase) Defaults() []fmt.Stringer {
+	return nil
+}
+
+func (b *ToggleBase) Reset() {
+}
+
+func (b *ToggleBase) Store(interface{}) bind.Status {
+	return bind.Done
+}
+
+func (b *ToggleBase) Exec() error {
+	base := HEAD
+	if b.tracker.Base() == HEAD {
+		base = Index
+	}
+	b.tracker.SetBase(base)
+	b.Info = fmt.Sprintf("Comparing changes against %s", base)
+	return nil
+}
+
+// ToggleBlame is a command that shows or hides blame for the current
+// file.
+type ToggleBlame struct {
+	status.General
+
+	view   *BlameView
+	editor input.Editor
+}
+
+// NewToggleBlame returns a *ToggleBlame that shows blame in v.
+func NewToggleBlame(theme gxui.Theme, v *BlameView) *ToggleBlame {
+	b := &ToggleBlame{view: v}
+	b.Theme = theme
+	return b
+}
+
+func (b *ToggleBlame) Name() string {
+	return "toggle-git-blame"
+}
+
+func (b *ToggleBlame) Menu() string {
+	return "Git"
+}
+
+func (b *ToggleBlame) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl | gxui.ModAlt,
+		Key:      gxui.KeyB,
+	}}
+}
+
+func (b *ToggleBlame) Reset() {
+	b.editor = nil
+}
+
+func (b *ToggleBlame) Store(target interface{}) bind.Status {
+	if e, ok := target.(input.Editor); ok {
+		b.editor = e
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+func (b *ToggleBlame) Exec() error {
+	if b.editor.Filepath() == "" {
+		b.Warn = "No file is open"
+		return nil
+	}
+	if b.view.Toggle(b.editor) {
+		b.Info = "Loading blame"
+		return nil
+	}
+	b.Info = "Hiding blame"
+	return nil
+}
+
+// ToggleChangedFilter is a command that shows only changed files in
+// the project tree, or shows every file again.
+type ToggleChangedFilter struct {
+	status.General
+
+	tree *TreeStatus
+}
+
+// NewToggleChangedFilter returns a *ToggleChangedFilter that filters
+// the tree that t decorates.
+func NewToggleChangedFilter(theme gxui.Theme, t *TreeStatus) *ToggleChangedFilter {
+	f := &ToggleChangedFilter{tree: t}
+	f.Theme = theme
+	return f
+}
+
+func (f *ToggleChangedFilter) Name() string {
+	return "toggle-git-changed-filter"
+}
+
+func (f *ToggleChangedFilter) Menu() string {
+	return "Git"
+}
+
+func (f *ToggleChangedFilter) Defaults() []fmt.Stringer {
+	return nil
+}
+
+func (f *ToggleChangedFilter) Reset() {
+}
+
+func (f *ToggleChangedFilter) Store(interface{}) bind.Status {
+	return bind.Done
+}
+
+func (f *ToggleChangedFilter) Exec() error {
+	if f.tree.ToggleFilter() {
+		f.Info = "Showing only changed files"
+		return nil
+	}
+	f.Info = "Showing all files"
+	return nil
+}
@@ -0,0 +1,112 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package git
+
+import (
+	"strings"
+
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/diff"
+)
+
+// Kind is the kind of change that a Hunk makes.
+type Kind int
+
+const (
+	Added Kind = iota
+	Modified
+	Deleted
+)
+
+// Hunk is a range of lines that differ between the base version of a
+// file and its current text.  Lines are 0-based indexes into the
+// slices returned by Lines.
+type Hunk diff.Hunk
+
+// Kind returns the kind of change that h makes.
+func (h Hunk) Kind() Kind {
+	switch {
+	case h.OldLines == 0:
+		return Added
+	case h.NewLines == 0:
+		return Deleted
+	default:
+		return Modified
+	}
+}
+
+// Contains returns whether h covers line in the current text.  A
+// deleted hunk covers the line before the deletion, or the first
+// line if the deletion is at the start of the file.
+func (h Hunk) Contains(line int) bool {
+	if h.NewLines == 0 {
+		return line == h.markLine()
+	}
+	return line >= h.NewStart && line < h.NewStart+h.NewLines
+}
+
+// markLine returns the line that a deleted hunk is marked on.
+func (h Hunk) markLine() int {
+	if h.NewStart == 0 {
+		return 0
+	}
+	return h.NewStart - 1
+}
+
+// Lines splits text into lines.  A trailing newline results in an
+// empty last line, so that joining the lines with "\n" returns text.
+func Lines(text string) []string {
+	return diff.Lines(text)
+}
+
+// Diff returns the hunks that turn old into new.
+func Diff(old, new []string) []Hunk {
+	var hunks []Hunk
+	for _, h := range diff.Diff(old, new) {
+		hunks = append(hunks, Hunk(h))
+	}
+	return hunks
+}
+
+// Revert returns the edit that replaces the lines of h in text with
+// the lines from base.
+func Revert(text []rune, base []string, h Hunk) input.Edit {
+	starts := lineStarts(text)
+	old := strings.Join(base[h.OldStart:h.OldStart+h.OldLines], "\n")
+	switch {
+	case h.NewLines == 0 && h.NewStart < len(starts):
+		return input.Edit{At: starts[h.NewStart], New: []rune(old + "\n")}
+	case h.NewLines == 0:
+		return input.Edit{At: len(text), New: []rune("\n" + old)}
+	}
+	start := starts[h.NewStart]
+	end := len(text)
+	if next := h.NewStart + h.NewLines; next < len(starts) {
+		end = starts[next] - 1
+	}
+	if h.OldLines > 0 {
+		return input.Edit{At: start, Old: text[start:end], New: []rune(old)}
+	}
+	// Removing every line of an added hunk also removes one of the
+	// newlines around it.
+	if end < len(text) {
+		end++
+	} else if start > 0 {
+		start--
+	}
+	return input.Edit{At: start, Old: text[start:end]}
+}
+
+// lineStarts returns the offset of the first rune of each line in
+// text.
+func lineStarts(text []rune) []int {
+	starts := []int{0}
+	for i, r := range text {
+		if r == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
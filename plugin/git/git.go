@@ -0,0 +1,106 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package git contains hooks and commands for displaying the state
+// of files in a git repository, using the local git binary.
+package git
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Base is the version of a file that changes are compared against.
+type Base int
+
+const (
+	// HEAD compares changes against the most recent commit.
+	HEAD Base = iota
+
+	// Index compares changes against the staged version of each
+	// file.
+	Index
+)
+
+func (b Base) String() string {
+	if b == Index {
+		return "the index"
+	}
+	return "HEAD"
+}
+
+// object returns the name that `git show` uses for path (relative to
+// the repository root) in b.
+func (b Base) object(path string) string {
+	path = filepath.ToSlash(path)
+	if b == Index {
+		return ":" + path
+	}
+	return "HEAD:" + path
+}
+
+// run runs git with args in dir, returning its output.  Errors
+// include git's error output.
+func run(dir string, args ...string) (string, error) {
+	return runInput(dir, nil, args...)
+}
+
+// runInput is run with stdin read from in.
+func runInput(dir string, in io.Reader, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdin = in
+	errBuffer := &bytes.Buffer{}
+	cmd.Stderr = errBuffer
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(errBuffer.String()); msg != "" {
+			return "", errors.New(msg)
+		}
+		return "", err
+	}
+	return string(out), nil
+}
+
+// Root returns the root of the repository that contains dir.
+func Root(dir string) (string, error) {
+	out, err := run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return filepath.FromSlash(strings.TrimSpace(out)), nil
+}
+
+// rel returns the root of the repository that contains path and
+// path relative to that root.
+func rel(path string) (root, relPath string, err error) {
+	// git reports the root with symlinks resolved, so path must be
+	// resolved as well for it to be relative to the root.
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(path)); err == nil {
+		path = filepath.Join(dir, filepath.Base(path))
+	}
+	root, err = Root(filepath.Dir(path))
+	if err != nil {
+		return "", "", err
+	}
+	relPath, err = filepath.Rel(root, path)
+	if err != nil {
+		return "", "", err
+	}
+	return root, relPath, nil
+}
+
+// Show returns the text of the file at path in base.  It returns an
+// error if path is not in a repository or is not in base.
+func Show(path string, base Base) (string, error) {
+	root, relPath, err := rel(path)
+	if err != nil {
+		return "", err
+	}
+	return run(root, "show", base.object(relPath))
+}
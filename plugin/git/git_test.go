@@ -0,0 +1,141 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package git_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/plugin/git"
+)
+
+func apply(text string, e input.Edit) string {
+	runes := []rune(text)
+	result := append([]rune(nil), runes[:e.At]...)
+	result = append(result, e.New...)
+	return string(append(result, runes[e.At+len(e.Old):]...))
+}
+
+func TestDiff(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) expect.Expectation {
+		return expect.New(t)
+	})
+
+	o.Spec("it finds added, modified and deleted lines", func(expect expect.Expectation) {
+		old := git.Lines("a\nb\nc\nd\ne\n")
+		new := git.Lines("a\nx\nb\nc\nD\n")
+		hunks := git.Diff(old, new)
+		expect(hunks).To(Equal([]git.Hunk{
+			{OldStart: 1, OldLines: 0, NewStart: 1, NewLines: 1},
+			{OldStart: 3, OldLines: 2, NewStart: 4, NewLines: 1},
+		}))
+		expect(hunks[0].Kind()).To(Equal(git.Added))
+		expect(hunks[1].Kind()).To(Equal(git.Modified))
+
+		hunks = git.Diff(old, git.Lines("a\nd\ne\n"))
+		expect(hunks).To(Equal([]git.Hunk{
+			{OldStart: 1, OldLines: 2, NewStart: 1, NewLines: 0},
+		}))
+		expect(hunks[0].Kind()).To(Equal(git.Deleted))
+		expect(hunks[0].Contains(0)).To(BeTrue())
+		expect(hunks[0].Contains(1)).To(BeFalse())
+	})
+
+	o.Spec("it returns no hunks for equal text", func(expect expect.Expectation) {
+		lines := git.Lines("a\nb\n")
+		expect(git.Diff(lines, lines)).To(HaveLen(0))
+	})
+
+	o.Spec("it reverts each kind of hunk", func(expect expect.Expectation) {
+		base := "a\nb\nc\n"
+		for _, text := range []string{
+			"a\nx\nb\nc\n",
+			"x\na\nb\nc\n",
+			"a\nb\nc\nx",
+			"a\nB\nc\n",
+			"a\nc\n",
+			"b\nc\n",
+			"a\nb\n",
+			"",
+		} {
+			lines := git.Lines(base)
+			hunks := git.Diff(lines, git.Lines(text))
+			expect(hunks).To(HaveLen(1))
+			edit := git.Revert([]rune(text), lines, hunks[0])
+			expect(apply(text, edit)).To(Equal(base))
+		}
+	})
+}
+
+func TestShow(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, string) {
+		expect := expect.New(t)
+		dir, err := ioutil.TempDir("", "vidar-git")
+		expect(err).To(Not(HaveOccurred()))
+		run := func(args ...string) {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = dir
+			out, err := cmd.CombinedOutput()
+			expect(err).To(Not(HaveOccurred()))
+			if err != nil {
+				t.Log(string(out))
+			}
+		}
+		run("init", "-q")
+		run("config", "user.email", "test@example.com")
+		run("config", "user.name", "Test")
+		write(expect, filepath.Join(dir, "foo.txt"), "committed\n")
+		run("add", "foo.txt")
+		run("commit", "-q", "-m", "initial")
+		write(expect, filepath.Join(dir, "foo.txt"), "staged\n")
+		run("add", "foo.txt")
+		write(expect, filepath.Join(dir, "foo.txt"), "unstaged\n")
+		return expect, dir
+	})
+
+	o.AfterEach(func(_ expect.Expectation, dir string) {
+		os.RemoveAll(dir)
+	})
+
+	o.Spec("it shows files in HEAD and the index", func(expect expect.Expectation, dir string) {
+		path := filepath.Join(dir, "foo.txt")
+		text, err := git.Show(path, git.HEAD)
+		expect(err).To(Not(HaveOccurred()))
+		expect(text).To(Equal("committed\n"))
+
+		text, err = git.Show(path, git.Index)
+		expect(err).To(Not(HaveOccurred()))
+		expect(text).To(Equal("staged\n"))
+	})
+
+	o.Spec("it errors for untracked files", func(expect expect.Expectation, dir string) {
+		path := filepath.Join(dir, "bar.txt")
+		write(expect, path, "untracked\n")
+		_, err := git.Show(path, git.HEAD)
+		expect(err).To(HaveOccurred())
+	})
+}
+
+func write(expect expect.Expectation, path, text string) {
+	err := ioutil.WriteFile(path, []byte(text), 0644)
+	expect(err).To(Not(HaveOccurred()))
+}
@@ -0,0 +1,34 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package main
+
+import (
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/plugin/command"
+	"github.com/nelsam/vidar/plugin/git"
+)
+
+// Bindables is the main entry point to the command.
+func Bindables(cmdr command.Commander, driver gxui.Driver, theme gxui.Theme) []bind.Bindable {
+	tracker := git.NewTracker(driver)
+	blame := git.NewBlameView(cmdr, driver, theme)
+	treeStatus := git.NewTreeStatus()
+	return []bind.Bindable{
+		git.NewNextHunk(theme, tracker),
+		git.NewPrevHunk(theme, tracker),
+		git.NewRevertHunk(theme, tracker),
+		git.NewShowHunk(theme, tracker),
+		git.NewToggleBase(theme, tracker),
+		git.Hook{Tracker: tracker},
+		git.OnSave{Tracker: tracker},
+		git.NewToggleBlame(theme, blame),
+		git.BlameHook{View: blame},
+		git.BlameOnSave{View: blame},
+		treeStatus,
+		git.NewToggleChangedFilter(theme, treeStatus),
+		git.TreeOnSave{Status: treeStatus},
+	}
+}
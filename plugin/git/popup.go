@@ -0,0 +1,100 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package git
+
+import (
+	"strings"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/gxui/math"
+	"github.com/nelsam/gxui/mixins"
+	"github.com/nelsam/vidar/commander/input"
+)
+
+var popupBackground = gxui.Color{
+	R: 0.15,
+	G: 0.15,
+	B: 0.2,
+	A: 1,
+}
+
+// PopupEditor is an editor that controls can be displayed on top of.
+type PopupEditor interface {
+	input.Editor
+	gxui.Parent
+
+	Size() math.Size
+	Padding() math.Spacing
+	LineIndex(caret int) int
+	Line(idx int) mixins.TextBoxLine
+	AddChild(gxui.Control) *gxui.Child
+	RemoveChild(gxui.Control)
+}
+
+// showPopup displays the base text of h below its first line in e.
+// It must be called on the UI goroutine.
+func showPopup(t *Tracker, theme gxui.Theme, e PopupEditor, h Hunk, base []string) {
+	path := e.Filepath()
+	hidePopup(t, path)
+
+	text := "(added lines)"
+	if h.OldLines > 0 {
+		text = strings.Join(base[h.OldStart:h.OldStart+h.OldLines], "\n")
+	}
+	label := theme.CreateLabel()
+	label.SetMultiline(true)
+	label.SetColor(gxui.Gray80)
+	label.SetText(text)
+	popup := theme.CreateLinearLayout()
+	popup.SetDirection(gxui.TopToBottom)
+	popup.SetBackgroundBrush(gxui.CreateBrush(popupBackground))
+	popup.SetBorderPen(gxui.CreatePen(1, gxui.Gray50))
+	popup.SetPadding(math.CreateSpacing(2))
+	popup.AddChild(label)
+
+	starts := lineStarts(e.Runes())
+	lineIdx := h.NewStart
+	if h.NewLines == 0 {
+		lineIdx = h.markLine()
+	}
+	if lineIdx >= len(starts) {
+		lineIdx = len(starts) - 1
+	}
+	pos := starts[lineIdx]
+	bounds := e.Size().Rect().Contract(e.Padding())
+	line := e.Line(e.LineIndex(pos))
+	lineOffset := gxui.ChildToParent(math.ZeroPoint, line, e)
+	target := line.PositionAt(pos).Add(lineOffset)
+	target.Y += line.Size().H
+	size := popup.DesiredSize(math.ZeroSize, bounds.Size())
+	c := e.AddChild(popup)
+	c.Layout(size.Rect().Offset(target).Intersect(bounds))
+
+	t.mu.Lock()
+	if f, ok := t.files[path]; ok {
+		f.popup = popup
+	}
+	t.mu.Unlock()
+	e.Redraw()
+}
+
+// hidePopup removes the hunk that is being shown in path, if there
+// is one.  It returns whether a hunk was being shown.  It must be
+// called on the UI goroutine.
+func hidePopup(t *Tracker, path string) bool {
+	t.mu.Lock()
+	f, ok := t.files[path]
+	if !ok || f.popup == nil {
+		t.mu.Unlock()
+		return false
+	}
+	popup := f.popup
+	f.popup = nil
+	t.mu.Unlock()
+	if e, ok := f.editor.(PopupEditor); ok && e.Children().Find(popup) != nil {
+		e.RemoveChild(popup)
+	}
+	return true
+}
@@ -0,0 +1,162 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package git
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// FileStatus is the git status of a file or directory.  Statuses are
+// ordered by how much attention they need, so that directories can
+// show the highest status of the files in them.
+type FileStatus int
+
+const (
+	StatusUnmodified FileStatus = iota
+	StatusIgnored
+	StatusUntracked
+	StatusAdded
+	StatusModified
+	StatusConflicted
+)
+
+func (s FileStatus) String() string {
+	switch s {
+	case StatusIgnored:
+		return "ignored"
+	case StatusUntracked:
+		return "untracked"
+	case StatusAdded:
+		return "added"
+	case StatusModified:
+		return "modified"
+	case StatusConflicted:
+		return "conflicted"
+	default:
+		return "unmodified"
+	}
+}
+
+// Changed returns whether s is a change to the repository, as
+// opposed to a file that is unmodified or ignored.
+func (s FileStatus) Changed() bool {
+	return s > StatusIgnored
+}
+
+// Status is the status of the files in a repository.
+type Status struct {
+	// files holds the status of each file that git reported.
+	files map[string]FileStatus
+
+	// dirs holds directories that git reported as a whole (untracked
+	// or ignored directories); everything in them shares their
+	// status.
+	dirs map[string]FileStatus
+
+	// rollup holds the highest changed status of the files in each
+	// directory that contains changes.
+	rollup map[string]FileStatus
+}
+
+// RepoStatus returns the status of the repository that contains dir.
+func RepoStatus(dir string) (*Status, error) {
+	root, err := Root(dir)
+	if err != nil {
+		return nil, err
+	}
+	out, err := run(root, "status", "--porcelain", "-z", "--ignored")
+	if err != nil {
+		return nil, err
+	}
+	return ParseStatus(root, out), nil
+}
+
+// ParseStatus parses the output of `git status --porcelain -z` that
+// was run in root.
+func ParseStatus(root, out string) *Status {
+	s := &Status{
+		files:  make(map[string]FileStatus),
+		dirs:   make(map[string]FileStatus),
+		rollup: make(map[string]FileStatus),
+	}
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		code, name := entry[:2], entry[3:]
+		if code[0] == 'R' || code[0] == 'C' {
+			// Renames and copies are followed by the original path.
+			i++
+		}
+		status := parseCode(code)
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if strings.HasSuffix(name, "/") {
+			s.dirs[path] = status
+		} else {
+			s.files[path] = status
+		}
+		if !status.Changed() {
+			continue
+		}
+		for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+			if s.rollup[dir] < status {
+				s.rollup[dir] = status
+			}
+			if dir == root || dir == filepath.Dir(dir) {
+				break
+			}
+		}
+	}
+	return s
+}
+
+// parseCode returns the status for a two-letter status code from
+// `git status --porcelain`.
+func parseCode(code string) FileStatus {
+	switch code {
+	case "??":
+		return StatusUntracked
+	case "!!":
+		return StatusIgnored
+	case "DD", "AU", "UD", "UA", "DU", "AA", "UU":
+		return StatusConflicted
+	}
+	if code[0] == 'A' {
+		return StatusAdded
+	}
+	return StatusModified
+}
+
+// Of returns the status of the file or directory at path.
+// Directories have the highest status of the files in them.
+func (s *Status) Of(path string) FileStatus {
+	if status, ok := s.files[path]; ok {
+		return status
+	}
+	if status, ok := s.rollup[path]; ok {
+		return status
+	}
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if status, ok := s.dirs[dir]; ok {
+			return status
+		}
+		if dir == filepath.Dir(dir) {
+			return StatusUnmodified
+		}
+	}
+}
+
+// Equal returns whether s and o hold the same statuses.  Nil
+// statuses are only equal to each other.
+func (s *Status) Equal(o *Status) bool {
+	if s == nil || o == nil {
+		return s == o
+	}
+	return reflect.DeepEqual(s.files, o.files) && reflect.DeepEqual(s.dirs, o.dirs)
+}
@@ -0,0 +1,118 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package git_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/plugin/git"
+)
+
+func TestParseStatus(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	root := filepath.FromSlash("/repo")
+	path := func(name string) string {
+		return filepath.Join(root, filepath.FromSlash(name))
+	}
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, *git.Status) {
+		out := "?? a/b/\x00 M x/y.go\x00R  new.go\x00old.go\x00!! ign/\x00UU x/c.go\x00A  z.go\x00"
+		return expect.New(t), git.ParseStatus(root, out)
+	})
+
+	o.Spec("it parses the status of files", func(expect expect.Expectation, s *git.Status) {
+		expect(s.Of(path("x/y.go"))).To(Equal(git.StatusModified))
+		expect(s.Of(path("new.go"))).To(Equal(git.StatusModified))
+		expect(s.Of(path("x/c.go"))).To(Equal(git.StatusConflicted))
+		expect(s.Of(path("z.go"))).To(Equal(git.StatusAdded))
+		expect(s.Of(path("unchanged.go"))).To(Equal(git.StatusUnmodified))
+	})
+
+	o.Spec("it skips the original path of renames", func(expect expect.Expectation, s *git.Status) {
+		expect(s.Of(path("old.go"))).To(Equal(git.StatusUnmodified))
+		expect(s.Of(path("ign/foo"))).To(Equal(git.StatusIgnored))
+	})
+
+	o.Spec("it applies the status of directories to their files", func(expect expect.Expectation, s *git.Status) {
+		expect(s.Of(path("a/b/c/d.go"))).To(Equal(git.StatusUntracked))
+		expect(s.Of(path("ign/foo.o"))).To(Equal(git.StatusIgnored))
+	})
+
+	o.Spec("it rolls up the highest status to parent directories", func(expect expect.Expectation, s *git.Status) {
+		expect(s.Of(path("x"))).To(Equal(git.StatusConflicted))
+		expect(s.Of(path("a"))).To(Equal(git.StatusUntracked))
+		expect(s.Of(root)).To(Equal(git.StatusConflicted))
+		expect(s.Of(path("ign")).Changed()).To(BeFalse())
+	})
+}
+
+func TestRepoStatus(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, string) {
+		expect := expect.New(t)
+		dir, err := ioutil.TempDir("", "vidar-git")
+		expect(err).To(Not(HaveOccurred()))
+		dir, err = filepath.EvalSymlinks(dir)
+		expect(err).To(Not(HaveOccurred()))
+		run := func(args ...string) {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = dir
+			out, err := cmd.CombinedOutput()
+			expect(err).To(Not(HaveOccurred()))
+			if err != nil {
+				t.Log(string(out))
+			}
+		}
+		run("init", "-q")
+		run("config", "user.email", "test@example.com")
+		run("config", "user.name", "Test")
+		write(expect, filepath.Join(dir, "foo.txt"), "foo\n")
+		write(expect, filepath.Join(dir, ".gitignore"), "*.o\n")
+		run("add", "foo.txt", ".gitignore")
+		run("commit", "-q", "-m", "initial")
+		return expect, dir
+	})
+
+	o.AfterEach(func(_ expect.Expectation, dir string) {
+		os.RemoveAll(dir)
+	})
+
+	o.Spec("it reports the status of a repository", func(expect expect.Expectation, dir string) {
+		write(expect, filepath.Join(dir, "foo.txt"), "bar\n")
+		expect(os.Mkdir(filepath.Join(dir, "sub"), 0755)).To(Not(HaveOccurred()))
+		write(expect, filepath.Join(dir, "sub", "new.txt"), "new\n")
+		write(expect, filepath.Join(dir, "foo.o"), "")
+
+		s, err := git.RepoStatus(filepath.Join(dir, "sub"))
+		expect(err).To(Not(HaveOccurred()))
+		expect(s.Of(filepath.Join(dir, "foo.txt"))).To(Equal(git.StatusModified))
+		expect(s.Of(filepath.Join(dir, "sub", "new.txt"))).To(Equal(git.StatusUntracked))
+		expect(s.Of(filepath.Join(dir, "foo.o"))).To(Equal(git.StatusIgnored))
+		expect(s.Of(filepath.Join(dir, ".gitignore"))).To(Equal(git.StatusUnmodified))
+	})
+
+	o.Spec("it fails outside of a repository", func(expect expect.Expectation, _ string) {
+		dir, err := ioutil.TempDir("", "vidar-nogit")
+		expect(err).To(Not(HaveOccurred()))
+		defer os.RemoveAll(dir)
+		_, err = git.RepoStatus(dir)
+		expect(err).To(HaveOccurred())
+	})
+}
@@ -0,0 +1,235 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package git
+
+import (
+	"sync"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/diff"
+	"github.com/nelsam/vidar/piece"
+	"github.com/nelsam/vidar/plugin/status"
+	"github.com/nelsam/vidar/setting"
+)
+
+// gutterOwner is the name that change markers are set under in the
+// gutter.
+const gutterOwner = "git"
+
+var marks = map[Kind]input.GutterMark{
+	Added:    {Text: "+", Color: status.ColorInfo},
+	Modified: {Text: "~", Color: status.ColorWarn},
+	Deleted:  {Text: "-", Color: status.ColorErr},
+}
+
+// file is the diff state of a single open file.
+type file struct {
+	editor input.Editor
+
+	// base is nil if the file isn't tracked in the base version.
+	base  []string
+	hunks []Hunk
+
+	// text is the most recent text that hasn't been diffed yet, and
+	// diffing is whether a goroutine is diffing.
+	text    *piece.Table
+	diffing bool
+
+	popup gxui.Control
+}
+
+// Tracker keeps the hunks of each open file up to date with its text
+// and marks them in the editor's gutter.
+type Tracker struct {
+	driver gxui.Driver
+
+	mu    sync.Mutex
+	base  Base
+	files map[string]*file
+}
+
+// NewTracker returns a *Tracker that compares files against HEAD.
+func NewTracker(driver gxui.Driver) *Tracker {
+	return &Tracker{driver: driver, files: make(map[string]*file)}
+}
+
+// Base returns the version that t compares files against.
+func (t *Tracker) Base() Base {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.base
+}
+
+// SetBase changes the version that t compares files against and
+// reloads every open file.
+func (t *Tracker) SetBase(b Base) {
+	t.mu.Lock()
+	t.base = b
+	var paths []string
+	for path := range t.files {
+		paths = append(paths, path)
+	}
+	t.mu.Unlock()
+	for _, path := range paths {
+		go t.load(path)
+	}
+}
+
+// Hunks returns the hunks in path and the lines of its base version.
+// The base is nil if path isn't tracked.
+func (t *Tracker) Hunks(path string) ([]Hunk, []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f, ok := t.files[path]
+	if !ok {
+		return nil, nil
+	}
+	return append([]Hunk(nil), f.hunks...), f.base
+}
+
+// load reads the base version of path and diffs the file against it.
+func (t *Tracker) load(path string) {
+	text, err := Show(path, t.Base())
+	t.mu.Lock()
+	f, ok := t.files[path]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	f.base = nil
+	if err == nil {
+		f.base = Lines(text)
+	}
+	t.mu.Unlock()
+	t.driver.Call(func() {
+		t.changed(path, input.Snapshot(f.editor))
+	})
+}
+
+// changed queues text to be diffed against the base of path.  Only
+// one diff runs per file at a time; text that arrives during a diff
+// replaces any text that is still waiting.
+func (t *Tracker) changed(path string, text *piece.Table) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f, ok := t.files[path]
+	if !ok {
+		return
+	}
+	f.text = text
+	if f.diffing {
+		return
+	}
+	f.diffing = true
+	go t.diff(path, f)
+}
+
+func (t *Tracker) diff(path string, f *file) {
+	for {
+		t.mu.Lock()
+		text, base := f.text, f.base
+		t.mu.Unlock()
+
+		var hunks []Hunk
+		if base != nil {
+			// Reading a table never fails.
+			lines, _ := diff.ReadLines(piece.NewReader(text))
+			hunks = Diff(base, lines)
+		}
+
+		t.mu.Lock()
+		f.hunks = hunks
+		if f.text == text {
+			f.diffing = false
+			t.mu.Unlock()
+			break
+		}
+		t.mu.Unlock()
+	}
+	t.driver.Call(func() { t.mark(f) })
+}
+
+// mark sets the gutter marks for f.  It must be called on the UI
+// goroutine.
+func (t *Tracker) mark(f *file) {
+	g, ok := f.editor.(input.Gutter)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	lines := make(map[int]input.GutterMark)
+	for _, h := range f.hunks {
+		if h.Kind() == Deleted {
+			lines[h.markLine()] = marks[Deleted]
+			continue
+		}
+		for l := h.NewStart; l < h.NewStart+h.NewLines; l++ {
+			lines[l] = marks[h.Kind()]
+		}
+	}
+	t.mu.Unlock()
+	g.SetGutterMarks(gutterOwner, lines)
+}
+
+// Hook keeps a Tracker up to date as files are opened and edited.
+type Hook struct {
+	Tracker *Tracker
+}
+
+func (h Hook) Name() string {
+	return "git-gutter"
+}
+
+func (h Hook) OpName() string {
+	return "input-handler"
+}
+
+func (h Hook) Init(e input.Editor, _ []rune) {
+	t := h.Tracker
+	path := e.Filepath()
+	if path == "" {
+		return
+	}
+	t.mu.Lock()
+	t.files[path] = &file{editor: e}
+	t.mu.Unlock()
+	go t.load(path)
+}
+
+func (h Hook) TextChanged(input.Editor, input.Edit) {
+}
+
+func (h Hook) Apply(input.Editor) error {
+	return nil
+}
+
+// Applied diffs the editor's new text in the background and closes
+// any hunk that is being shown, since it may no longer match.
+func (h Hook) Applied(e input.Editor, _ []input.Edit) {
+	t := h.Tracker
+	path := e.Filepath()
+	t.changed(path, input.Snapshot(e))
+	hidePopup(t, path)
+}
+
+// OnSave reloads the base version of files when they are saved,
+// picking up commits and staged changes made outside of vidar.
+type OnSave struct {
+	Tracker *Tracker
+}
+
+func (o OnSave) Name() string {
+	return "git-gutter-on-save"
+}
+
+func (o OnSave) OpName() string {
+	return "save-current-file"
+}
+
+func (o OnSave) AfterSave(_ setting.Project, path, _ string) error {
+	go o.Tracker.load(path)
+	return nil
+}
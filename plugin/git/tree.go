@@ -0,0 +1,182 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package git
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/navigator"
+	"github.com/nelsam/vidar/plugin/status"
+	"github.com/nelsam/vidar/setting"
+)
+
+// treeOwner is the name that decorations are set under in the project
+// tree.
+const treeOwner = "git"
+
+// refreshDelay is how long TreeStatus waits after a change before
+// running `git status`, so that a burst of changes (e.g. a checkout)
+// only runs it once.
+const refreshDelay = 200 * time.Millisecond
+
+var statusColors = map[FileStatus]gxui.Color{
+	StatusIgnored:    gxui.Gray50,
+	StatusUntracked:  {R: 0.5, G: 0.8, B: 0.9, A: 1},
+	StatusAdded:      status.ColorInfo,
+	StatusModified:   status.ColorWarn,
+	StatusConflicted: status.ColorErr,
+}
+
+// TreeStatus decorates the project tree with the git status of its
+// files.  It is updated when the tree's watcher reports changes.
+type TreeStatus struct {
+	mu       sync.Mutex
+	tree     navigator.Decoratable
+	root     string
+	status   *Status
+	filtered bool
+	pending  bool
+}
+
+// NewTreeStatus returns an empty *TreeStatus.
+func NewTreeStatus() *TreeStatus {
+	return &TreeStatus{}
+}
+
+func (t *TreeStatus) Name() string {
+	return "git-tree-status"
+}
+
+// RootChanged implements navigator.TreeHook.
+func (t *TreeStatus) RootChanged(tree navigator.Decoratable, root string) {
+	t.mu.Lock()
+	t.tree = tree
+	t.root = root
+	t.status = nil
+	t.mu.Unlock()
+	tree.SetColors(treeOwner, nil)
+	go t.refresh()
+}
+
+// Changed implements navigator.TreeHook.
+func (t *TreeStatus) Changed(path string) {
+	if strings.Contains(path, string(filepath.Separator)+".git"+string(filepath.Separator)) {
+		return
+	}
+	t.schedule()
+}
+
+// schedule refreshes the status after refreshDelay, unless a refresh
+// is already scheduled.
+func (t *TreeStatus) schedule() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.pending {
+		return
+	}
+	t.pending = true
+	time.AfterFunc(refreshDelay, func() {
+		t.mu.Lock()
+		t.pending = false
+		t.mu.Unlock()
+		t.refresh()
+	})
+}
+
+// refresh runs `git status` and updates the tree's decorations.
+func (t *TreeStatus) refresh() {
+	t.mu.Lock()
+	tree, root := t.tree, t.root
+	t.mu.Unlock()
+	if tree == nil || root == "" {
+		return
+	}
+	s, err := RepoStatus(root)
+	if err != nil {
+		// Projects that aren't in a repository have no status.
+		s = nil
+	}
+	t.mu.Lock()
+	if t.root != root || s.Equal(t.status) {
+		t.mu.Unlock()
+		return
+	}
+	t.status = s
+	filtered := t.filtered
+	t.mu.Unlock()
+
+	if s == nil {
+		tree.SetColors(treeOwner, nil)
+		tree.SetFilter(treeOwner, nil)
+		return
+	}
+	tree.SetColors(treeOwner, t.color)
+	if filtered {
+		tree.SetFilter(treeOwner, t.changed)
+	}
+}
+
+func (t *TreeStatus) color(path string) (gxui.Color, bool) {
+	t.mu.Lock()
+	s := t.status
+	t.mu.Unlock()
+	if s == nil {
+		return gxui.Color{}, false
+	}
+	c, ok := statusColors[s.Of(path)]
+	return c, ok
+}
+
+func (t *TreeStatus) changed(path string, _ bool) bool {
+	t.mu.Lock()
+	s := t.status
+	t.mu.Unlock()
+	return s == nil || s.Of(path).Changed()
+}
+
+// ToggleFilter shows only changed files in the tree, or shows every
+// file again.  It returns whether only changed files are shown, and
+// false if the tree's root is not in a repository.
+func (t *TreeStatus) ToggleFilter() bool {
+	t.mu.Lock()
+	tree := t.tree
+	if tree == nil || t.status == nil {
+		t.filtered = false
+		t.mu.Unlock()
+		return false
+	}
+	t.filtered = !t.filtered
+	filtered := t.filtered
+	t.mu.Unlock()
+	if filtered {
+		tree.SetFilter(treeOwner, t.changed)
+		return true
+	}
+	tree.SetFilter(treeOwner, nil)
+	return false
+}
+
+// TreeOnSave refreshes a TreeStatus when files are saved, in case the
+// tree isn't watching the file's directory.
+type TreeOnSave struct {
+	Status *TreeStatus
+}
+
+func (o TreeOnSave) Name() string {
+	return "git-tree-status-on-save"
+}
+
+func (o TreeOnSave) OpName() string {
+	return "save-current-file"
+}
+
+func (o TreeOnSave) AfterSave(setting.Project, string, string) error {
+	o.Status.schedule()
+	return nil
+}
@@ -0,0 +1,262 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package goaction
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	"github.com/nelsam/vidar/command/action"
+	"github.com/nelsam/vidar/commander/input"
+)
+
+// errCheck offers to switch the innermost `if err != nil` statement
+// around start to end between checking an assignment in its init
+// statement and checking one on the line before it, and between
+// returning the error as it is and wrapping it with fmt.Errorf.
+func errCheck(f *file, start, end token.Pos) []action.Action {
+	path := f.enclosing(start, end)
+	for i := len(path) - 1; i > 0; i-- {
+		list, ok := stmtList(path[i])
+		if !ok || len(list) == 0 {
+			continue
+		}
+		for j, stmt := range list {
+			ifStmt, ok := stmt.(*ast.IfStmt)
+			if !ok || !inside(ifStmt, start, end) {
+				continue
+			}
+			errName, ok := nilCheck(ifStmt.Cond)
+			if !ok {
+				continue
+			}
+			var actions []action.Action
+			if j > 0 {
+				actions = append(actions, joinInit(f, list[j-1], ifStmt, errName, list[j+1:])...)
+			}
+			actions = append(actions, splitInit(f, path[:i+1], ifStmt, list[j+1:])...)
+			actions = append(actions, wrapErr(f, path[:i+1], ifStmt, errName)...)
+			return actions
+		}
+		// The caret may be on the assignment before an if statement.
+		for j, stmt := range list[:len(list)-1] {
+			if stmt.Pos() > start || stmt.End() < end {
+				continue
+			}
+			ifStmt, ok := list[j+1].(*ast.IfStmt)
+			if !ok {
+				break
+			}
+			if errName, ok := nilCheck(ifStmt.Cond); ok {
+				return joinInit(f, stmt, ifStmt, errName, list[j+2:])
+			}
+		}
+	}
+	return nil
+}
+
+// joinInit offers to move prev, an assignment to errName, into the
+// init statement of ifStmt.  after is the rest of the statements in
+// the block, which must not use anything that prev declares.
+func joinInit(f *file, prev ast.Stmt, ifStmt *ast.IfStmt, errName string, after []ast.Stmt) []action.Action {
+	assign, ok := prev.(*ast.AssignStmt)
+	if !ok || assign.Tok != token.DEFINE || ifStmt.Init != nil {
+		return nil
+	}
+	names := assigned(assign)
+	if !names[errName] || uses(after, names) || f.hasComments(assign.End(), ifStmt.Pos()) {
+		return nil
+	}
+	return []action.Action{{
+		Name:  "Move the assignment into the if statement",
+		Edits: []input.Edit{f.edit(assign.Pos(), ifStmt.Cond.Pos(), "if "+f.source(assign)+"; ")},
+	}}
+}
+
+// splitInit offers to move the init statement of ifStmt to its own
+// line before it.  path is the path to the block that ifStmt is in;
+// after is the rest of the statements in that block, which must not
+// be able to see what the init statement declares.
+func splitInit(f *file, path []ast.Node, ifStmt *ast.IfStmt, after []ast.Stmt) []action.Action {
+	if ifStmt.Init == nil || f.hasComments(ifStmt.Pos(), ifStmt.Cond.Pos()) {
+		return nil
+	}
+	if assign, ok := ifStmt.Init.(*ast.AssignStmt); ok && assign.Tok == token.DEFINE {
+		names := assigned(assign)
+		if uses(after, names) {
+			return nil
+		}
+		f.check()
+		scope := blockScope(f, path)
+		if scope == nil {
+			return nil
+		}
+		for name := range names {
+			if scope.Lookup(name) != nil {
+				return nil
+			}
+		}
+	}
+	init := f.source(ifStmt.Init) + "\n" + f.indent(ifStmt.Pos()) + "if "
+	return []action.Action{{
+		Name:  "Move the assignment out of the if statement",
+		Edits: []input.Edit{f.edit(ifStmt.Pos(), ifStmt.Cond.Pos(), init)},
+	}}
+}
+
+// wrapErr offers to wrap errName with fmt.Errorf in the return
+// statements of ifStmt's body that return it as it is, or to return
+// it as it is in the ones that wrap it.
+func wrapErr(f *file, path []ast.Node, ifStmt *ast.IfStmt, errName string) []action.Action {
+	fmtName := f.importName("fmt")
+	var wrap, unwrap []input.Edit
+	for _, stmt := range ifStmt.Body.List {
+		ret, ok := stmt.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) == 0 {
+			continue
+		}
+		last := ret.Results[len(ret.Results)-1]
+		if ident, ok := last.(*ast.Ident); ok && ident.Name == errName {
+			name := fmtName
+			if name == "" {
+				name = "fmt"
+			}
+			msg := "%w"
+			if fn := funcName(path); fn != "" {
+				msg = fn + ": %w"
+			}
+			wrap = append(wrap, f.edit(last.Pos(), last.End(), fmt.Sprintf("%s.Errorf(%s, %s)", name, strconv.Quote(msg), errName)))
+			continue
+		}
+		if fmtName == "" {
+			continue
+		}
+		call, ok := last.(*ast.CallExpr)
+		if !ok || len(call.Args) < 2 || !isSelector(call.Fun, fmtName, "Errorf") {
+			continue
+		}
+		if ident, ok := call.Args[len(call.Args)-1].(*ast.Ident); ok && ident.Name == errName {
+			unwrap = append(unwrap, f.edit(last.Pos(), last.End(), errName))
+		}
+	}
+	var actions []action.Action
+	if len(wrap) > 0 {
+		if fmtName == "" {
+			wrap = append(wrap, f.addImports(map[string]bool{"fmt": true})...)
+		}
+		actions = append(actions, action.Action{
+			Name:  "Wrap the returned error with fmt.Errorf",
+			Edits: wrap,
+		})
+	}
+	if len(unwrap) > 0 {
+		actions = append(actions, action.Action{
+			Name:  "Return the error without wrapping it",
+			Edits: unwrap,
+		})
+	}
+	return actions
+}
+
+// nilCheck returns the name of the variable that cond compares to
+// nil, if cond is `name != nil`.
+func nilCheck(cond ast.Expr) (string, bool) {
+	bin, ok := cond.(*ast.BinaryExpr)
+	if !ok || bin.Op != token.NEQ {
+		return "", false
+	}
+	x, ok := bin.X.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	if y, ok := bin.Y.(*ast.Ident); !ok || y.Name != "nil" {
+		return "", false
+	}
+	return x.Name, true
+}
+
+// stmtList returns the statements in n, if n is a block of
+// statements.
+func stmtList(n ast.Node) ([]ast.Stmt, bool) {
+	switch n := n.(type) {
+	case *ast.BlockStmt:
+		return n.List, true
+	case *ast.CaseClause:
+		return n.Body, true
+	case *ast.CommClause:
+		return n.Body, true
+	}
+	return nil, false
+}
+
+// blockScope returns the scope of the last block in path.
+func blockScope(f *file, path []ast.Node) *types.Scope {
+	block := path[len(path)-1]
+	if len(path) > 1 {
+		switch parent := path[len(path)-2].(type) {
+		case *ast.FuncDecl:
+			block = parent.Type
+		case *ast.FuncLit:
+			block = parent.Type
+		}
+	}
+	return f.info.Scopes[block]
+}
+
+// inside returns whether start to end is in the header or body of
+// ifStmt, but not in its else branch.
+func inside(ifStmt *ast.IfStmt, start, end token.Pos) bool {
+	return ifStmt.Pos() <= start && end <= ifStmt.Body.End()
+}
+
+// assigned returns the names that assign declares.
+func assigned(assign *ast.AssignStmt) map[string]bool {
+	names := make(map[string]bool)
+	for _, lhs := range assign.Lhs {
+		if ident, ok := lhs.(*ast.Ident); ok && ident.Name != "_" {
+			names[ident.Name] = true
+		}
+	}
+	return names
+}
+
+// uses returns whether any identifier in stmts has one of names.
+// Shadowing isn't taken into account, so it may report uses that
+// aren't.
+func uses(stmts []ast.Stmt, names map[string]bool) bool {
+	found := false
+	for _, stmt := range stmts {
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok && names[ident.Name] {
+				found = true
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+// funcName returns the name of the function declaration in path, or
+// "" if path isn't in one.
+func funcName(path []ast.Node) string {
+	for i := len(path) - 1; i >= 0; i-- {
+		if decl, ok := path[i].(*ast.FuncDecl); ok {
+			return decl.Name.Name
+		}
+	}
+	return ""
+}
+
+func isSelector(expr ast.Expr, pkg, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	return ok && x.Name == pkg
+}
@@ -0,0 +1,662 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package goaction
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+	"unicode"
+
+	"github.com/nelsam/vidar/commander/input"
+)
+
+// ExtractVar returns the edits that move the expression from start to
+// end in e into a new local variable called name, declared before the
+// statement that the expression is in.
+func ExtractVar(e input.Editor, start, end int, name string) ([]input.Edit, error) {
+	f, from, to, err := loadRange(e, start, end, name)
+	if err != nil {
+		return nil, err
+	}
+	path := f.enclosing(from, to)
+	expr, ok := selectedExpr(path, from, to)
+	if !ok {
+		return nil, errors.New("the selection is not an expression")
+	}
+	if err := checkValue(f, path, expr); err != nil {
+		return nil, err
+	}
+	stmt, err := insertionPoint(path, expr)
+	if err != nil {
+		return nil, err
+	}
+	for ident, obj := range f.info.Uses {
+		if ident.Pos() < from || ident.End() > to {
+			continue
+		}
+		if _, ok := obj.(*types.Var); ok && obj.Pos() >= stmt.Pos() && obj.Pos() < from {
+			return nil, fmt.Errorf("the expression uses %s, which is declared in the statement that it is in", ident.Name)
+		}
+	}
+	if err := checkName(f, name, stmt.Pos()); err != nil {
+		return nil, err
+	}
+	decl := fmt.Sprintf("%s := %s\n%s", name, f.source(expr), f.indent(stmt.Pos()))
+	return []input.Edit{
+		f.edit(stmt.Pos(), stmt.Pos(), decl),
+		f.edit(expr.Pos(), expr.End(), name),
+	}, nil
+}
+
+// ExtractFunc returns the edits that move the statements or the
+// expression from start to end in e into a new function called name,
+// declared after the function that they are in, and call it in their
+// place.  The variables that they use from the function that they are
+// in become parameters; the ones that they declare or change that are
+// used after them become results.
+func ExtractFunc(e input.Editor, start, end int, name string) ([]input.Edit, error) {
+	f, from, to, err := loadRange(e, start, end, name)
+	if err != nil {
+		return nil, err
+	}
+	path := f.enclosing(from, to)
+	var fn *ast.FuncDecl
+	for _, n := range path {
+		if decl, ok := n.(*ast.FuncDecl); ok && decl.Body != nil {
+			fn = decl
+		}
+	}
+	if fn == nil {
+		return nil, errors.New("only code in functions can be extracted")
+	}
+	if f.pkg.Scope().Lookup(name) != nil {
+		return nil, fmt.Errorf("%s is already declared in package %s", name, f.pkg.Name())
+	}
+	if err := checkName(f, name, from); err != nil {
+		return nil, err
+	}
+	x := &extraction{f: f, fn: fn, from: from, to: to}
+	x.missing = make(map[string]bool)
+	x.qual = f.qualifier(x.missing)
+	if stmts, loop := selectedStmts(path, from, to); stmts != nil {
+		x.nodes = make([]ast.Node, 0, len(stmts))
+		for _, s := range stmts {
+			x.nodes = append(x.nodes, s)
+		}
+		x.inLoop = loop
+		if err := x.checkFlow(); err != nil {
+			return nil, err
+		}
+		return x.stmts(name)
+	}
+	expr, ok := selectedExpr(path, from, to)
+	if !ok {
+		return nil, errors.New("the selection must be whole statements or an expression")
+	}
+	if err := checkValue(f, path, expr); err != nil {
+		return nil, err
+	}
+	x.nodes = []ast.Node{expr}
+	return x.expr(name, expr)
+}
+
+// extraction is code being extracted into a new function.
+type extraction struct {
+	f        *file
+	fn       *ast.FuncDecl
+	from, to token.Pos
+	nodes    []ast.Node
+	inLoop   bool
+
+	qual    types.Qualifier
+	missing map[string]bool
+}
+
+// checkFlow reports control flow in x that would not work in another
+// function: returns, gotos, defers and branches to statements outside
+// of x.
+func (x *extraction) checkFlow() error {
+	labels := make(map[string]bool)
+	for _, n := range x.nodes {
+		ast.Inspect(n, func(n ast.Node) bool {
+			if l, ok := n.(*ast.LabeledStmt); ok {
+				labels[l.Label.Name] = true
+			}
+			_, lit := n.(*ast.FuncLit)
+			return !lit
+		})
+	}
+	var err error
+	var visit func(n ast.Node, breakable, loop bool)
+	visit = func(n ast.Node, breakable, loop bool) {
+		ast.Inspect(n, func(child ast.Node) bool {
+			if err != nil {
+				return false
+			}
+			if child == n {
+				return true
+			}
+			switch c := child.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.ReturnStmt:
+				err = errors.New("the selection contains a return statement")
+			case *ast.DeferStmt:
+				err = errors.New("the selection contains a defer statement, which would run when the new function returns")
+			case *ast.BranchStmt:
+				err = branchErr(c, labels, breakable, loop)
+			case *ast.ForStmt, *ast.RangeStmt:
+				visit(c, true, true)
+				return false
+			case *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+				visit(c, true, loop)
+				return false
+			}
+			return true
+		})
+	}
+	for _, n := range x.nodes {
+		visit(&ast.BlockStmt{List: []ast.Stmt{n.(ast.Stmt)}}, false, false)
+	}
+	return err
+}
+
+// branchErr returns the error for b, a branch statement in code being
+// extracted, or nil if it can be extracted.  labels are the labels
+// declared in the code; breakable and loop are whether b is in a
+// statement that it can break out of or continue in, respectively.
+func branchErr(b *ast.BranchStmt, labels map[string]bool, breakable, loop bool) error {
+	switch {
+	case b.Tok == token.GOTO && (b.Label == nil || !labels[b.Label.Name]):
+		return errors.New("the selection contains a goto statement to a label outside of it")
+	case b.Tok == token.FALLTHROUGH:
+		if !breakable {
+			return errors.New("the selection contains a fallthrough statement")
+		}
+	case b.Label != nil:
+		if !labels[b.Label.Name] {
+			return fmt.Errorf("the selection contains a %s to label %s, which is outside of it", b.Tok, b.Label.Name)
+		}
+	case b.Tok == token.BREAK && !breakable, b.Tok == token.CONTINUE && !loop:
+		return fmt.Errorf("the selection contains a %s statement for a loop or switch outside of it", b.Tok)
+	}
+	return nil
+}
+
+// stmts extracts the statements in x into a function called name.
+func (x *extraction) stmts(name string) ([]input.Edit, error) {
+	params, err := x.params()
+	if err != nil {
+		return nil, err
+	}
+	results := x.changed(params)
+	declared := x.declared()
+	results = append(results, declared...)
+
+	var body bytes.Buffer
+	body.WriteString(x.reindent(x.from, x.to))
+	if len(results) > 0 {
+		body.WriteString("\n\treturn " + names(results))
+	}
+	call := x.call(name, params)
+	indent := x.f.indent(x.from)
+	switch {
+	case len(results) == 0:
+	case len(declared) == len(results):
+		call = names(results) + " := " + call
+	default:
+		var decls string
+		for _, v := range declared {
+			decls += fmt.Sprintf("var %s %s\n%s", v.Name(), types.TypeString(v.Type(), x.qual), indent)
+		}
+		call = decls + names(results) + " = " + call
+	}
+	return x.edits(name, params, results, body.String(), call), nil
+}
+
+// expr extracts expr into a function called name that returns its
+// value.
+func (x *extraction) expr(name string, expr ast.Expr) ([]input.Edit, error) {
+	params, err := x.params()
+	if err != nil {
+		return nil, err
+	}
+	var results []*types.Var
+	switch t := x.f.info.Types[expr].Type.(type) {
+	case *types.Tuple:
+		for i := 0; i < t.Len(); i++ {
+			results = append(results, types.NewVar(token.NoPos, nil, "", t.At(i).Type()))
+		}
+	default:
+		results = append(results, types.NewVar(token.NoPos, nil, "", t))
+	}
+	body := "\treturn " + strings.TrimPrefix(x.reindent(expr.Pos(), expr.End()), "\t")
+	return x.edits(name, params, results, body, x.call(name, params)), nil
+}
+
+// edits returns the edits that declare the new function after x.fn
+// and replace x with call.
+func (x *extraction) edits(name string, params, results []*types.Var, body, call string) []input.Edit {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "\n\nfunc %s%s(", name, x.typeParams(true))
+	for i, p := range params {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%s %s", p.Name(), types.TypeString(p.Type(), x.qual))
+	}
+	b.WriteString(")")
+	if len(results) > 0 {
+		ts := make([]string, 0, len(results))
+		for _, r := range results {
+			ts = append(ts, typeString(r.Type(), x.qual))
+		}
+		if len(ts) == 1 {
+			b.WriteString(" " + ts[0])
+		} else {
+			b.WriteString(" (" + strings.Join(ts, ", ") + ")")
+		}
+	}
+	fmt.Fprintf(&b, " {\n%s\n}", body)
+	edits := []input.Edit{
+		x.f.edit(x.from, x.to, call),
+		x.f.edit(x.fn.End(), x.fn.End(), b.String()),
+	}
+	return append(edits, x.f.addImports(x.missing)...)
+}
+
+// params returns the variables that x uses from the function that it
+// is in, in the order that they are first used.
+func (x *extraction) params() ([]*types.Var, error) {
+	var params []*types.Var
+	seen := make(map[*types.Var]bool)
+	var err error
+	for _, n := range x.nodes {
+		ast.Inspect(n, func(n ast.Node) bool {
+			if u, ok := n.(*ast.UnaryExpr); ok && u.Op == token.AND {
+				if ident, ok := unparen(u.X).(*ast.Ident); ok {
+					if v := x.local(ident); v != nil && err == nil {
+						err = fmt.Errorf("the selection takes the address of %s, which would be a copy in the new function", v.Name())
+					}
+				}
+			}
+			ident, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			if v := x.local(ident); v != nil && !seen[v] {
+				seen[v] = true
+				params = append(params, v)
+			}
+			return true
+		})
+	}
+	return params, err
+}
+
+// local returns the variable that ident uses, if it is declared in
+// x.fn but not in x.
+func (x *extraction) local(ident *ast.Ident) *types.Var {
+	v, ok := x.f.info.Uses[ident].(*types.Var)
+	if !ok || v.IsField() {
+		return nil
+	}
+	if v.Pos() < x.fn.Pos() || v.Pos() >= x.fn.End() {
+		return nil
+	}
+	if v.Pos() >= x.from && v.Pos() < x.to {
+		return nil
+	}
+	return v
+}
+
+// changed returns the params that x assigns to and that are used after
+// it (or anywhere else, if it is in a loop).
+func (x *extraction) changed(params []*types.Var) []*types.Var {
+	assigned := make(map[*types.Var]bool)
+	mark := func(expr ast.Expr) {
+		if ident, ok := assignedIdent(x.f, expr); ok {
+			if v := x.local(ident); v != nil {
+				assigned[v] = true
+			}
+		}
+	}
+	for _, n := range x.nodes {
+		ast.Inspect(n, func(n ast.Node) bool {
+			switch s := n.(type) {
+			case *ast.AssignStmt:
+				for _, lhs := range s.Lhs {
+					mark(lhs)
+				}
+			case *ast.IncDecStmt:
+				mark(s.X)
+			case *ast.RangeStmt:
+				if s.Tok == token.ASSIGN {
+					mark(s.Key)
+					mark(s.Value)
+				}
+			}
+			return true
+		})
+	}
+	var changed []*types.Var
+	for _, p := range params {
+		if assigned[p] && x.usedOutside(p, x.inLoop) {
+			changed = append(changed, p)
+		}
+	}
+	return changed
+}
+
+// declared returns the variables that x declares at its top level and
+// that are used after it.
+func (x *extraction) declared() []*types.Var {
+	var declared []*types.Var
+	for _, n := range x.nodes {
+		ast.Inspect(n, func(n ast.Node) bool {
+			switch n.(type) {
+			case *ast.BlockStmt, *ast.FuncLit, *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt,
+				*ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+				return false
+			}
+			ident, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			if v, ok := x.f.info.Defs[ident].(*types.Var); ok && x.usedOutside(v, false) {
+				declared = append(declared, v)
+			}
+			return true
+		})
+	}
+	return declared
+}
+
+// usedOutside returns whether v is used after x, or anywhere outside
+// of x if anywhere is true.
+func (x *extraction) usedOutside(v *types.Var, anywhere bool) bool {
+	for ident, obj := range x.f.info.Uses {
+		if obj != v || ident.Pos() < x.fn.Pos() || ident.Pos() >= x.fn.End() {
+			continue
+		}
+		if ident.Pos() >= x.to || (anywhere && ident.Pos() < x.from) {
+			return true
+		}
+	}
+	return false
+}
+
+// call returns the call to the new function called name.
+func (x *extraction) call(name string, params []*types.Var) string {
+	args := make([]string, 0, len(params))
+	for _, p := range params {
+		args = append(args, p.Name())
+	}
+	return fmt.Sprintf("%s%s(%s)", name, x.typeParams(false), strings.Join(args, ", "))
+}
+
+// typeParams returns the type parameters that x.fn has (including the
+// ones from its receiver), as they are declared if decl is true and
+// as they are passed otherwise.
+func (x *extraction) typeParams(decl bool) string {
+	obj, ok := x.f.info.Defs[x.fn.Name].(*types.Func)
+	if !ok {
+		return ""
+	}
+	sig := obj.Type().(*types.Signature)
+	var params []string
+	for _, list := range []*types.TypeParamList{sig.RecvTypeParams(), sig.TypeParams()} {
+		for i := 0; i < list.Len(); i++ {
+			p := list.At(i)
+			if decl {
+				params = append(params, p.Obj().Name()+" "+types.TypeString(p.Constraint(), x.qual))
+				continue
+			}
+			params = append(params, p.Obj().Name())
+		}
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(params, ", ") + "]"
+}
+
+// reindent returns the source from start to end, indented by one tab
+// instead of the indentation of the line that it starts on.  Lines in
+// raw strings are left alone.
+func (x *extraction) reindent(start, end token.Pos) string {
+	f := x.f
+	base := f.indent(start)
+	raw := make(map[int]bool)
+	ast.Inspect(f.ast, func(n ast.Node) bool {
+		lit, ok := n.(*ast.BasicLit)
+		if ok && lit.Kind == token.STRING && strings.HasPrefix(lit.Value, "`") {
+			for l := f.tok.Line(lit.Pos()) + 1; l <= f.tok.Line(lit.End()); l++ {
+				raw[l] = true
+			}
+		}
+		return true
+	})
+	lines := strings.Split(string(f.src[f.tok.Offset(start):f.tok.Offset(end)]), "\n")
+	first := f.tok.Line(start)
+	for i, line := range lines {
+		switch {
+		case i == 0:
+			lines[i] = "\t" + line
+		case raw[first+i]:
+		case strings.TrimSpace(line) == "":
+			lines[i] = ""
+		default:
+			lines[i] = "\t" + strings.TrimPrefix(line, base)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// loadRange parses and type checks e, returning the positions of the
+// text from start to end without any surrounding whitespace.  name
+// must be a valid identifier.
+func loadRange(e input.Editor, start, end int, name string) (f *file, from, to token.Pos, err error) {
+	if !token.IsIdentifier(name) {
+		return nil, 0, 0, fmt.Errorf("%q is not a valid name", name)
+	}
+	text := e.Runes()
+	for start < end && unicode.IsSpace(text[start]) {
+		start++
+	}
+	for end > start && unicode.IsSpace(text[end-1]) {
+		end--
+	}
+	if start == end {
+		return nil, 0, 0, errors.New("nothing is selected")
+	}
+	f, err = parse(e.Filepath(), text)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	f.check()
+	if f.pkg == nil {
+		return nil, 0, 0, errors.New("could not type check " + e.Filepath())
+	}
+	return f, f.pos(start), f.pos(end), nil
+}
+
+// selectedStmts returns the statements in one block of path that span
+// from to to, and whether they are in a loop.
+func selectedStmts(path []ast.Node, from, to token.Pos) ([]ast.Stmt, bool) {
+	for i := len(path) - 1; i >= 0; i-- {
+		list, ok := stmtList(path[i])
+		if !ok {
+			continue
+		}
+		first, last := -1, -1
+		for j, s := range list {
+			if s.Pos() == from {
+				first = j
+			}
+			if s.End() == to {
+				last = j
+			}
+		}
+		if first < 0 || last < first {
+			return nil, false
+		}
+		for _, n := range path[:i] {
+			switch n.(type) {
+			case *ast.ForStmt, *ast.RangeStmt:
+				return list[first : last+1], true
+			case *ast.FuncLit:
+				// Loops outside of a function literal don't run its
+				// code again.
+				return list[first : last+1], false
+			}
+		}
+		return list[first : last+1], false
+	}
+	return nil, false
+}
+
+// selectedExpr returns the expression in path that spans from to to.
+func selectedExpr(path []ast.Node, from, to token.Pos) (ast.Expr, bool) {
+	for i := len(path) - 1; i >= 0; i-- {
+		if expr, ok := path[i].(ast.Expr); ok && expr.Pos() == from && expr.End() == to {
+			return expr, true
+		}
+	}
+	return nil, false
+}
+
+// checkValue returns an error if expr, the last expression in path,
+// isn't a value that can be moved.
+func checkValue(f *file, path []ast.Node, expr ast.Expr) error {
+	tv, ok := f.info.Types[expr]
+	if !ok || !tv.IsValue() {
+		return errors.New("the selection is not a value")
+	}
+	if _, ok := tv.Type.(*types.Tuple); ok && len(path) > 1 {
+		if _, ok := path[len(path)-2].(*ast.ExprStmt); ok {
+			return errors.New("the selection is a statement, not a value")
+		}
+	}
+	if len(path) > 1 {
+		switch parent := path[len(path)-2].(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range parent.Lhs {
+				if lhs == expr {
+					return errors.New("the selection is assigned to")
+				}
+			}
+		case *ast.SelectorExpr:
+			if parent.Sel == expr {
+				return errors.New("the selection is part of a selector")
+			}
+		case *ast.KeyValueExpr:
+			if parent.Key == expr {
+				return errors.New("the selection is a key in a composite literal")
+			}
+		}
+	}
+	return nil
+}
+
+// insertionPoint returns the statement in a block of path that expr is
+// in, which a variable for expr can be declared before.  It returns an
+// error if expr might not be evaluated (or might be evaluated more
+// than once) when that statement is.
+func insertionPoint(path []ast.Node, expr ast.Expr) (ast.Stmt, error) {
+	for i := len(path) - 2; i >= 0; i-- {
+		child := path[i+1]
+		switch n := path[i].(type) {
+		case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+			return child.(ast.Stmt), nil
+		case *ast.FuncLit:
+			return nil, errors.New("the expression is in a function literal with no statements around it")
+		case *ast.ForStmt:
+			if child == n.Cond || child == n.Post {
+				return nil, errors.New("the expression is evaluated on every iteration of its loop")
+			}
+		case *ast.IfStmt:
+			if child == n.Else {
+				return nil, errors.New("the expression is only evaluated when the if statement's condition is false")
+			}
+		case *ast.BinaryExpr:
+			if (n.Op == token.LAND || n.Op == token.LOR) && child == n.Y {
+				return nil, fmt.Errorf("the expression is only evaluated depending on the left side of %s", n.Op)
+			}
+		}
+	}
+	return nil, errors.New("the expression is not in a function")
+}
+
+// checkName returns an error if name would conflict with anything in
+// scope at pos.
+func checkName(f *file, name string, pos token.Pos) error {
+	scope := f.pkg.Scope().Innermost(pos)
+	if scope == nil {
+		scope = f.pkg.Scope()
+	}
+	if _, obj := scope.LookupParent(name, pos); obj != nil {
+		return fmt.Errorf("%s is already declared", name)
+	}
+	return nil
+}
+
+// assignedIdent returns the variable that is changed when expr is
+// assigned to, if it is one whose value would be copied into a
+// function.
+func assignedIdent(f *file, expr ast.Expr) (*ast.Ident, bool) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e, true
+	case *ast.ParenExpr:
+		return assignedIdent(f, e.X)
+	case *ast.SelectorExpr:
+		if t, ok := f.info.Types[e.X]; ok {
+			if _, ptr := t.Type.Underlying().(*types.Pointer); ptr {
+				return nil, false
+			}
+		}
+		return assignedIdent(f, e.X)
+	case *ast.IndexExpr:
+		if t, ok := f.info.Types[e.X]; ok {
+			if _, array := t.Type.Underlying().(*types.Array); !array {
+				return nil, false
+			}
+		}
+		return assignedIdent(f, e.X)
+	}
+	return nil, false
+}
+
+// unparen returns expr without any parentheses around it.
+func unparen(expr ast.Expr) ast.Expr {
+	for {
+		p, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			return expr
+		}
+		expr = p.X
+	}
+}
+
+// names returns the names of vars, separated by commas.
+func names(vars []*types.Var) string {
+	n := make([]string, 0, len(vars))
+	for _, v := range vars {
+		n = append(n, v.Name())
+	}
+	return strings.Join(n, ", ")
+}
+
+// typeString returns t as go source, using untyped constants' default
+// types.
+func typeString(t types.Type, qual types.Qualifier) string {
+	return types.TypeString(types.Default(t), qual)
+}
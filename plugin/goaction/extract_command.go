@@ -0,0 +1,137 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package goaction
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/gxui/math"
+	"github.com/nelsam/vidar/command/action"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/plugin/status"
+)
+
+// extractor returns the edits that extract the text from start to end
+// in an editor into something called name.
+type extractor func(e input.Editor, start, end int, name string) ([]input.Edit, error)
+
+// Extract is a command that prompts for a name, then extracts the
+// selected code into a new function or variable with that name.
+type Extract struct {
+	status.General
+
+	name    string
+	what    string
+	key     gxui.KeyboardKey
+	extract extractor
+
+	prompt gxui.Label
+	input  gxui.TextBox
+	asked  bool
+
+	editor  Editor
+	applier Applier
+}
+
+// NewExtractFunc returns an Extract command that extracts the selected
+// statements or expression into a new function.
+func NewExtractFunc(theme gxui.Theme) *Extract {
+	return newExtract(theme, "extract-function", "function", gxui.KeyM, ExtractFunc)
+}
+
+// NewExtractVar returns an Extract command that extracts the selected
+// expression into a new local variable.
+func NewExtractVar(theme gxui.Theme) *Extract {
+	return newExtract(theme, "extract-variable", "variable", gxui.KeyV, ExtractVar)
+}
+
+func newExtract(theme gxui.Theme, name, what string, key gxui.KeyboardKey, extract extractor) *Extract {
+	x := &Extract{
+		name:    name,
+		what:    what,
+		key:     key,
+		extract: extract,
+		prompt:  theme.CreateLabel(),
+		input:   theme.CreateTextBox(),
+	}
+	x.Theme = theme
+	x.input.SetDesiredWidth(math.MaxSize.W)
+	return x
+}
+
+func (x *Extract) Name() string {
+	return x.name
+}
+
+func (x *Extract) Menu() string {
+	return "Golang"
+}
+
+func (x *Extract) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl | gxui.ModAlt,
+		Key:      x.key,
+	}}
+}
+
+func (x *Extract) Start(gxui.Control) gxui.Control {
+	x.asked = false
+	x.input.SetText("")
+	x.prompt.SetText(fmt.Sprintf("Name of the new %s:", x.what))
+	return x.prompt
+}
+
+func (x *Extract) Next() gxui.Focusable {
+	if x.asked {
+		return nil
+	}
+	x.asked = true
+	return x.input
+}
+
+func (x *Extract) Reset() {
+	x.editor = nil
+	x.applier = nil
+}
+
+func (x *Extract) Store(elem interface{}) bind.Status {
+	switch src := elem.(type) {
+	case Editor:
+		x.editor = src
+	case Applier:
+		x.applier = src
+	}
+	if x.editor != nil && x.applier != nil {
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+func (x *Extract) Exec() error {
+	name := strings.TrimSpace(x.input.Text())
+	if name == "" {
+		x.Warn = fmt.Sprintf("The new %s needs a name", x.what)
+		return nil
+	}
+	sel := x.editor.Controller().SelectionSlice()
+	if len(sel) != 1 || sel[0].Length() == 0 {
+		x.Warn = fmt.Sprintf("Select the code to extract into a %s", x.what)
+		return nil
+	}
+	edits, err := x.extract(x.editor, sel[0].Start(), sel[0].End(), name)
+	if err != nil {
+		x.Err = fmt.Sprintf("Could not extract %s %s: %s", x.what, name, err)
+		return err
+	}
+	if err := action.Check(input.Snapshot(x.editor), edits); err != nil {
+		x.Err = fmt.Sprintf("Could not extract %s %s: %s", x.what, name, err)
+		return err
+	}
+	x.applier.Apply(x.editor, edits...)
+	return nil
+}
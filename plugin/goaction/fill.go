@@ -0,0 +1,130 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package goaction
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/nelsam/vidar/command/action"
+	"github.com/nelsam/vidar/commander/input"
+)
+
+// fillStruct offers to add the fields that are missing from the
+// innermost struct literal around start to end, each set to its zero
+// value.
+func fillStruct(f *file, start, end token.Pos) []action.Action {
+	path := f.enclosing(start, end)
+	var lit *ast.CompositeLit
+	for i := len(path) - 1; i >= 0; i-- {
+		if l, ok := path[i].(*ast.CompositeLit); ok && l.Lbrace <= start && end <= l.Rbrace {
+			lit = l
+			break
+		}
+	}
+	if lit == nil {
+		return nil
+	}
+	set := make(map[string]bool)
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			// Positional literals have to list every field.
+			return nil
+		}
+		if key, ok := kv.Key.(*ast.Ident); ok {
+			set[key.Name] = true
+		}
+	}
+	f.check()
+	tv, ok := f.info.Types[lit]
+	if !ok {
+		return nil
+	}
+	s, ok := tv.Type.Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+	missing := make(map[string]bool)
+	qual := f.qualifier(missing)
+	var fields []string
+	for i := 0; i < s.NumFields(); i++ {
+		field := s.Field(i)
+		if set[field.Name()] || field.Name() == "_" {
+			continue
+		}
+		if !field.Exported() && field.Pkg() != f.pkg {
+			continue
+		}
+		fields = append(fields, fmt.Sprintf("%s: %s", field.Name(), zero(field.Type(), qual)))
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	edits := []input.Edit{fillEdit(f, lit, fields)}
+	edits = append(edits, f.addImports(missing)...)
+	return []action.Action{{
+		Name:  "Fill struct literal with zero values",
+		Edits: edits,
+	}}
+}
+
+// fillEdit returns the edit that adds fields to lit, matching the way
+// lit is already laid out.
+func fillEdit(f *file, lit *ast.CompositeLit, fields []string) input.Edit {
+	indent := f.indent(lit.Lbrace)
+	if len(lit.Elts) == 0 {
+		var b strings.Builder
+		for _, field := range fields {
+			fmt.Fprintf(&b, "\n%s\t%s,", indent, field)
+		}
+		b.WriteString("\n" + indent)
+		return f.edit(lit.Lbrace+1, lit.Rbrace, b.String())
+	}
+	last := lit.Elts[len(lit.Elts)-1]
+	if f.tok.Line(last.End()) == f.tok.Line(lit.Rbrace) {
+		return f.edit(last.End(), last.End(), ", "+strings.Join(fields, ", "))
+	}
+	// Multiline literals end their last element with a comma.
+	after := last.End()
+	for int(after) < int(lit.Rbrace) && f.src[f.tok.Offset(after)] != ',' {
+		after++
+	}
+	if after < lit.Rbrace {
+		after++
+	}
+	eltIndent := f.indent(last.Pos())
+	var b strings.Builder
+	for _, field := range fields {
+		fmt.Fprintf(&b, "\n%s%s,", eltIndent, field)
+	}
+	return f.edit(after, after, b.String())
+}
+
+// zero returns the zero value of t as go source.
+func zero(t types.Type, qual types.Qualifier) string {
+	if _, ok := t.(*types.TypeParam); ok {
+		return fmt.Sprintf("*new(%s)", types.TypeString(t, qual))
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return "false"
+		case u.Info()&types.IsNumeric != 0:
+			return "0"
+		case u.Info()&types.IsString != 0:
+			return `""`
+		}
+		return "nil"
+	case *types.Struct, *types.Array:
+		return types.TypeString(t, qual) + "{}"
+	default:
+		return "nil"
+	}
+}
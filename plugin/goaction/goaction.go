@@ -0,0 +1,43 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package goaction offers code actions for go files, like filling in
+// struct literals, adding struct tags and changing the way errors are
+// checked.
+package goaction
+
+import (
+	"log"
+
+	"github.com/nelsam/vidar/command/action"
+	"github.com/nelsam/vidar/commander/input"
+)
+
+// Provider is a hook that offers go code actions to the
+// show-code-actions command.
+type Provider struct{}
+
+func (Provider) Name() string {
+	return "go-code-actions"
+}
+
+func (Provider) OpName() string {
+	return "show-code-actions"
+}
+
+// Actions returns the go code actions that apply to the text from
+// start to end in e.
+func (Provider) Actions(e input.Editor, start, end int) []action.Action {
+	f, err := parse(e.Filepath(), e.Runes())
+	if err != nil {
+		log.Printf("goaction: could not parse %s: %s", e.Filepath(), err)
+		return nil
+	}
+	from, to := f.pos(start), f.pos(end)
+	var actions []action.Action
+	actions = append(actions, fillStruct(f, from, to)...)
+	actions = append(actions, structTags(f, from, to)...)
+	actions = append(actions, errCheck(f, from, to)...)
+	return actions
+}
@@ -0,0 +1,524 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package goaction_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/command/action"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/piece"
+	"github.com/nelsam/vidar/plugin/goaction"
+)
+
+type fakeEditor struct {
+	path string
+	text string
+}
+
+func (e *fakeEditor) Filepath() string                    { return e.path }
+func (e *fakeEditor) Text() string                        { return e.text }
+func (e *fakeEditor) Runes() []rune                       { return []rune(e.text) }
+func (e *fakeEditor) SetText(t string)                    { e.text = t }
+func (e *fakeEditor) SyntaxLayers() []input.SyntaxLayer   { return nil }
+func (e *fakeEditor) SetSyntaxLayers([]input.SyntaxLayer) {}
+
+// actions returns the actions at the | in src, which is removed from
+// the editor's text.
+func actions(e *fakeEditor, src string) []action.Action {
+	caret := len([]rune(src[:strings.Index(src, "|")]))
+	e.text = strings.Replace(src, "|", "", 1)
+	return goaction.Provider{}.Actions(e, caret, caret)
+}
+
+// apply applies the action named name to e, returning false if there
+// is no such action.
+func apply(e *fakeEditor, actions []action.Action, name string) bool {
+	for _, a := range actions {
+		if a.Name != name {
+			continue
+		}
+		if err := action.Check(piece.New(e.Runes()), a.Edits); err != nil {
+			panic(err)
+		}
+		// Apply the last edit first, so that the offsets of the
+		// edits before it stay put.
+		edits := append([]input.Edit(nil), a.Edits...)
+		sort.Slice(edits, func(i, j int) bool {
+			return edits[i].At > edits[j].At
+		})
+		for _, edit := range edits {
+			text := e.Runes()
+			e.text = string(text[:edit.At]) + string(edit.New) + string(text[edit.At+len(edit.Old):])
+		}
+		return true
+	}
+	return false
+}
+
+func TestActions(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, *fakeEditor) {
+		dir, err := ioutil.TempDir("", "goaction")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.RemoveAll(dir) })
+		return expect.New(t), &fakeEditor{path: filepath.Join(dir, "foo.go")}
+	})
+
+	o.Spec("it fills empty struct literals with zero values", func(expect expect.Expectation, e *fakeEditor) {
+		a := actions(e, `package foo
+
+type bar struct {
+	name  string
+	count int
+	ok    bool
+	next  *bar
+	inner struct{ x int }
+	ids   [2]int
+}
+
+func baz() {
+	b := bar{|}
+	_ = b
+}
+`)
+		expect(apply(e, a, "Fill struct literal with zero values")).To(BeTrue())
+		expect(e.text).To(ContainSubstring(`	b := bar{
+		name: "",
+		count: 0,
+		ok: false,
+		next: nil,
+		inner: struct{x int}{},
+		ids: [2]int{},
+	}
+`))
+	})
+
+	o.Spec("it adds the missing fields after the ones already set", func(expect expect.Expectation, e *fakeEditor) {
+		a := actions(e, `package foo
+
+type bar struct{ a, b, c int }
+
+var single = bar{b: 1|}
+
+var multi = bar{
+	a: 1,
+}
+`)
+		expect(apply(e, a, "Fill struct literal with zero values")).To(BeTrue())
+		expect(e.text).To(ContainSubstring("bar{b: 1, a: 0, c: 0}"))
+
+		a = actions(e, strings.Replace(e.text, "a: 1,", "a: 1,|", 1))
+		expect(apply(e, a, "Fill struct literal with zero values")).To(BeTrue())
+		expect(e.text).To(ContainSubstring("bar{\n\ta: 1,\n\tb: 0,\n\tc: 0,\n}"))
+	})
+
+	o.Spec("it adds and removes struct tags", func(expect expect.Expectation, e *fakeEditor) {
+		a := actions(e, "package foo\n\ntype user struct {\n\tUserID int `db:\"id\"`|\n\tHTTPServer string\n\tname string\n}\n")
+		expect(apply(e, a, "Add json tags (snake_case)")).To(BeTrue())
+		expect(e.text).To(ContainSubstring("\tUserID int `db:\"id\" json:\"user_id\"`\n\tHTTPServer string `json:\"http_server\"`\n\tname string\n"))
+
+		a = actions(e, strings.Replace(e.text, "name string", "name| string", 1))
+		expect(apply(e, a, "Add yaml tags (camelCase)")).To(BeTrue())
+		expect(e.text).To(ContainSubstring("`db:\"id\" json:\"user_id\" yaml:\"userID\"`"))
+		expect(e.text).To(ContainSubstring("`json:\"http_server\" yaml:\"httpServer\"`"))
+
+		a = actions(e, strings.Replace(e.text, "name string", "name| string", 1))
+		expect(apply(e, a, "Remove db tags")).To(BeTrue())
+		expect(e.text).To(ContainSubstring("\tUserID int `json:\"user_id\" yaml:\"userID\"`\n"))
+	})
+
+	o.Spec("it moves assignments into and out of if statements", func(expect expect.Expectation, e *fakeEditor) {
+		src := `package foo
+
+func bar() (int, error) { return 0, nil }
+
+func baz() error {
+	if _, err := bar(); err != nil {|
+		return err
+	}
+	return nil
+}
+`
+		a := actions(e, src)
+		expect(apply(e, a, "Move the assignment out of the if statement")).To(BeTrue())
+		expect(e.text).To(ContainSubstring("\t_, err := bar()\n\tif err != nil {\n"))
+
+		a = actions(e, strings.Replace(e.text, "_, err", "|_, err", 1))
+		expect(apply(e, a, "Move the assignment into the if statement")).To(BeTrue())
+		expect(e.text).To(Equal(strings.Replace(src, "|", "", 1)))
+	})
+
+	o.Spec("it does not move assignments that are used after the if statement", func(expect expect.Expectation, e *fakeEditor) {
+		a := actions(e, `package foo
+
+func bar() (int, error) { return 0, nil }
+
+func baz() (int, error) {
+	n, err := bar()
+	if err != nil {|
+		return 0, err
+	}
+	return n, nil
+}
+`)
+		expect(apply(e, a, "Move the assignment into the if statement")).To(BeFalse())
+	})
+
+	o.Spec("it wraps and unwraps returned errors", func(expect expect.Expectation, e *fakeEditor) {
+		src := `package foo
+
+import "os"
+
+func baz() error {
+	if err := os.Remove("x"); err != nil {|
+		return err
+	}
+	return nil
+}
+`
+		a := actions(e, src)
+		expect(apply(e, a, "Wrap the returned error with fmt.Errorf")).To(BeTrue())
+		expect(e.text).To(ContainSubstring(`return fmt.Errorf("baz: %w", err)`))
+		expect(e.text).To(ContainSubstring("import \"os\"\nimport \"fmt\"\n"))
+
+		a = actions(e, strings.Replace(e.text, "return fmt", "return| fmt", 1))
+		expect(apply(e, a, "Return the error without wrapping it")).To(BeTrue())
+		expect(e.text).To(ContainSubstring("\t\treturn err\n"))
+	})
+}
+
+func TestStubs(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, *fakeEditor) {
+		dir, err := ioutil.TempDir("", "goaction")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.RemoveAll(dir) })
+		if err := ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/foo\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return expect.New(t), &fakeEditor{path: filepath.Join(dir, "foo.go")}
+	})
+
+	o.Spec("it adds the missing methods and imports", func(expect expect.Expectation, e *fakeEditor) {
+		e.text = `package foo
+
+type buf struct{}
+
+func (bf *buf) Close() error { return nil }
+`
+		edits, err := goaction.Stubs(e, "*buf", goaction.Interface{Path: "io", Pkg: "io", Name: "ReadWriteCloser"}, "")
+		expect(err).To(Not(HaveOccurred()))
+		expect(apply(e, []action.Action{{Name: "stubs", Edits: edits}}, "stubs")).To(BeTrue())
+		expect(e.text).To(Equal(`package foo
+
+type buf struct{}
+
+func (bf *buf) Read(p []byte) (n int, err error) {
+	panic("not implemented")
+}
+
+func (bf *buf) Write(p []byte) (n int, err error) {
+	panic("not implemented")
+}
+
+func (bf *buf) Close() error { return nil }
+`))
+
+		edits, err = goaction.Stubs(e, "*buf", goaction.Interface{Path: "io", Pkg: "io", Name: "WriterTo"}, "")
+		expect(err).To(Not(HaveOccurred()))
+		expect(apply(e, []action.Action{{Name: "stubs", Edits: edits}}, "stubs")).To(BeTrue())
+		expect(e.text).To(ContainSubstring("package foo\n\nimport \"io\"\n\ntype buf struct{}\n\nfunc (bf *buf) WriteTo(w io.Writer) (n int64, err error) {\n"))
+	})
+
+	o.Spec("it instantiates generic interfaces", func(expect expect.Expectation, e *fakeEditor) {
+		e.text = `package foo
+
+type Store[K comparable, V any] interface {
+	Get(K) (V, bool)
+}
+
+type cache[K comparable, V any] map[K]V
+
+type names struct{}
+`
+		if err := ioutil.WriteFile(e.path, []byte(e.text), 0644); err != nil {
+			panic(err)
+		}
+		iface := goaction.Interface{Path: "example.com/foo", Pkg: "foo", Name: "Store"}
+		edits, err := goaction.Stubs(e, "cache", iface, "")
+		expect(err).To(Not(HaveOccurred()))
+		expect(apply(e, []action.Action{{Name: "stubs", Edits: edits}}, "stubs")).To(BeTrue())
+		expect(e.text).To(ContainSubstring("type cache[K comparable, V any] map[K]V\n\nfunc (c cache[K, V]) Get(K) (V, bool) {\n"))
+
+		edits, err = goaction.Stubs(e, "*names", iface, "int, []string")
+		expect(err).To(Not(HaveOccurred()))
+		expect(apply(e, []action.Action{{Name: "stubs", Edits: edits}}, "stubs")).To(BeTrue())
+		expect(e.text).To(ContainSubstring("func (n *names) Get(int) ([]string, bool) {\n"))
+
+		_, err = goaction.Stubs(e, "*names", iface, "")
+		expect(err).To(HaveOccurred())
+	})
+
+	o.Spec("it reports methods with the wrong signature", func(expect expect.Expectation, e *fakeEditor) {
+		e.text = `package foo
+
+type s struct{}
+
+func (s) String() int { return 0 }
+`
+		_, err := goaction.Stubs(e, "s", goaction.Interface{Path: "fmt", Pkg: "fmt", Name: "Stringer"}, "")
+		expect(err).To(HaveOccurred())
+	})
+}
+
+func TestSearch(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, []goaction.Interface) {
+		return expect.New(t), []goaction.Interface{
+			{Path: "io", Pkg: "io", Name: "Reader"},
+			{Path: "io", Pkg: "io", Name: "ReaderAt"},
+			{Path: "net/http", Pkg: "http", Name: "Handler"},
+			{Path: "log/slog", Pkg: "slog", Name: "Handler"},
+		}
+	})
+
+	o.Spec("it finds interfaces by their full or short names", func(expect expect.Expectation, ifaces []goaction.Interface) {
+		expect(goaction.Search(ifaces, "io.Reader")).To(Equal(ifaces[:1]))
+		expect(goaction.Search(ifaces, "http.Handler")).To(Equal(ifaces[2:3]))
+		expect(goaction.Search(ifaces, "log/slog.Handler")).To(Equal(ifaces[3:]))
+		expect(goaction.Search(ifaces, "ReaderAt")).To(Equal(ifaces[1:2]))
+	})
+
+	o.Spec("it lists the best matches for a partial name first", func(expect expect.Expectation, ifaces []goaction.Interface) {
+		found := goaction.Search(ifaces, "Handler")
+		expect(found[0].Name).To(Equal("Handler"))
+		expect(found[1].Name).To(Equal("Handler"))
+
+		found = goaction.Search(ifaces, "Read")
+		expect(found[0].Path).To(Equal("io"))
+		expect(found[1].Path).To(Equal("io"))
+	})
+}
+
+// selection sets e's text to src without the two |s in it, returning
+// the offsets of the text between them.
+func selection(e *fakeEditor, src string) (start, end int) {
+	parts := strings.SplitN(src, "|", 3)
+	e.text = strings.Join(parts, "")
+	start = len([]rune(parts[0]))
+	return start, start + len([]rune(parts[1]))
+}
+
+func TestExtract(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, *fakeEditor) {
+		dir, err := ioutil.TempDir("", "goaction")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.RemoveAll(dir) })
+		return expect.New(t), &fakeEditor{path: filepath.Join(dir, "foo.go")}
+	})
+
+	o.Spec("it extracts statements into a function", func(expect expect.Expectation, e *fakeEditor) {
+		start, end := selection(e, `package foo
+
+func sum(values []int, scale int) int {
+	total := 0
+	|for _, v := range values {
+		total += v * scale
+	}
+	avg := total / len(values)|
+	return total + avg
+}
+`)
+		edits, err := goaction.ExtractFunc(e, start, end, "add")
+		expect(err).To(Not(HaveOccurred()))
+		expect(apply(e, []action.Action{{Name: "extract", Edits: edits}}, "extract")).To(BeTrue())
+		expect(e.text).To(Equal(`package foo
+
+func sum(values []int, scale int) int {
+	total := 0
+	var avg int
+	total, avg = add(values, total, scale)
+	return total + avg
+}
+
+func add(values []int, total int, scale int) (int, int) {
+	for _, v := range values {
+		total += v * scale
+	}
+	avg := total / len(values)
+	return total, avg
+}
+`))
+	})
+
+	o.Spec("it extracts expressions into a function", func(expect expect.Expectation, e *fakeEditor) {
+		start, end := selection(e, `package foo
+
+func area(w, h float64) float64 {
+	return |w * h / 2|
+}
+`)
+		edits, err := goaction.ExtractFunc(e, start, end, "half")
+		expect(err).To(Not(HaveOccurred()))
+		expect(apply(e, []action.Action{{Name: "extract", Edits: edits}}, "extract")).To(BeTrue())
+		expect(e.text).To(Equal(`package foo
+
+func area(w, h float64) float64 {
+	return half(w, h)
+}
+
+func half(w float64, h float64) float64 {
+	return w * h / 2
+}
+`))
+	})
+
+	o.Spec("it passes the type parameters of generic functions", func(expect expect.Expectation, e *fakeEditor) {
+		start, end := selection(e, `package foo
+
+func first[T any](values []T) T {
+	|v := values[0]|
+	return v
+}
+`)
+		edits, err := goaction.ExtractFunc(e, start, end, "head")
+		expect(err).To(Not(HaveOccurred()))
+		expect(apply(e, []action.Action{{Name: "extract", Edits: edits}}, "extract")).To(BeTrue())
+		expect(e.text).To(Equal(`package foo
+
+func first[T any](values []T) T {
+	v := head[T](values)
+	return v
+}
+
+func head[T any](values []T) T {
+	v := values[0]
+	return v
+}
+`))
+	})
+
+	o.Spec("it reports control flow that cannot be extracted", func(expect expect.Expectation, e *fakeEditor) {
+		for _, body := range []string{
+			"if n > 0 {\n\t\t\treturn\n\t\t}",
+			"if n > 0 {\n\t\t\tbreak\n\t\t}",
+			"if n > 0 {\n\t\t\tcontinue outer\n\t\t}",
+			"goto done",
+		} {
+			start, end := selection(e, `package foo
+
+func loop(n int) {
+outer:
+	for {
+		|`+body+`|
+		n--
+	}
+done:
+}
+`)
+			_, err := goaction.ExtractFunc(e, start, end, "f")
+			expect(err).To(HaveOccurred())
+		}
+
+		start, end := selection(e, `package foo
+
+func loop(n int) {
+	for {
+		|for n > 0 {
+			n--
+			if n == 5 {
+				break
+			}
+		}|
+	}
+}
+`)
+		_, err := goaction.ExtractFunc(e, start, end, "f")
+		expect(err).To(Not(HaveOccurred()))
+	})
+
+	o.Spec("it extracts expressions into variables", func(expect expect.Expectation, e *fakeEditor) {
+		start, end := selection(e, `package foo
+
+func area(w, h float64) float64 {
+	if w > 0 {
+		return |w * h| / 2
+	}
+	return 0
+}
+`)
+		edits, err := goaction.ExtractVar(e, start, end, "a")
+		expect(err).To(Not(HaveOccurred()))
+		expect(apply(e, []action.Action{{Name: "extract", Edits: edits}}, "extract")).To(BeTrue())
+		expect(e.text).To(Equal(`package foo
+
+func area(w, h float64) float64 {
+	if w > 0 {
+		a := w * h
+		return a / 2
+	}
+	return 0
+}
+`))
+	})
+
+	o.Spec("it does not move expressions that are evaluated conditionally", func(expect expect.Expectation, e *fakeEditor) {
+		start, end := selection(e, `package foo
+
+func ok(p *int) bool {
+	return p != nil && |*p > 0|
+}
+`)
+		_, err := goaction.ExtractVar(e, start, end, "positive")
+		expect(err).To(HaveOccurred())
+
+		start, end = selection(e, `package foo
+
+func count(n int) {
+	for i := 0; |i < n|; i++ {
+	}
+}
+`)
+		_, err = goaction.ExtractVar(e, start, end, "more")
+		expect(err).To(HaveOccurred())
+	})
+
+	o.Spec("it reports names that are already in use", func(expect expect.Expectation, e *fakeEditor) {
+		start, end := selection(e, `package foo
+
+func area(w, h float64) float64 {
+	return |w * h|
+}
+`)
+		_, err := goaction.ExtractVar(e, start, end, "w")
+		expect(err).To(HaveOccurred())
+		_, err = goaction.ExtractFunc(e, start, end, "area")
+		expect(err).To(HaveOccurred())
+	})
+}
@@ -0,0 +1,266 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package goaction
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nelsam/vidar/commander/input"
+)
+
+// Stubs returns the edits that add stubs for the methods of iface that
+// recv is missing, after recv's declaration.  recv is the name of a
+// type declared in e, starting with a * for pointer receivers;
+// typeArgs are the type arguments for iface, if it is generic.  There
+// are no edits if recv already implements iface.
+func Stubs(e input.Editor, recv string, iface Interface, typeArgs string) ([]input.Edit, error) {
+	f, err := parse(e.Filepath(), e.Runes())
+	if err != nil {
+		return nil, err
+	}
+	return stubs(f, recv, iface, typeArgs)
+}
+
+func stubs(f *file, recv string, iface Interface, typeArgs string) ([]input.Edit, error) {
+	ptr := strings.HasPrefix(recv, "*")
+	name := strings.TrimSpace(strings.TrimPrefix(recv, "*"))
+	decl, spec := typeSpec(f, name)
+	if spec == nil {
+		return nil, fmt.Errorf("type %s is not declared in %s", name, filepath.Base(f.path))
+	}
+	f.check(iface.Path)
+	if f.pkg == nil {
+		return nil, errors.New("could not type check " + filepath.Base(f.path))
+	}
+	obj, ok := f.pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a type", name)
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("methods cannot be declared on %s", name)
+	}
+	if _, ok := named.Underlying().(*types.Interface); ok {
+		return nil, fmt.Errorf("methods cannot be declared on interface type %s", name)
+	}
+
+	ifaceType, err := lookupInterface(f, spec, named, iface, typeArgs)
+	if err != nil {
+		return nil, err
+	}
+	var recvType types.Type = named
+	if ptr {
+		recvType = types.NewPointer(named)
+	}
+	methods := types.NewMethodSet(recvType)
+	var missing []*types.Func
+	for i := 0; i < ifaceType.NumMethods(); i++ {
+		m := ifaceType.Method(i)
+		if !m.Exported() && m.Pkg() != f.pkg {
+			return nil, fmt.Errorf("%s has unexported method %s, which only %s can implement", iface.Name, m.Name(), iface.Path)
+		}
+		sel := methods.Lookup(m.Pkg(), m.Name())
+		if sel == nil {
+			if !ptr && types.NewMethodSet(types.NewPointer(named)).Lookup(m.Pkg(), m.Name()) != nil {
+				return nil, fmt.Errorf("%s has method %s with a pointer receiver; use *%s as the receiver", name, m.Name(), name)
+			}
+			missing = append(missing, m)
+			continue
+		}
+		if !types.Identical(sel.Type(), m.Type()) {
+			return nil, fmt.Errorf("%s.%s has the wrong signature for %s", name, m.Name(), iface.Name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	imports := make(map[string]bool)
+	qual := f.qualifier(imports)
+	recvName := receiverName(f, name)
+	recvText := name + typeParamNames(spec)
+	if ptr {
+		recvText = "*" + recvText
+	}
+	var b bytes.Buffer
+	for _, m := range missing {
+		sig := m.Type().(*types.Signature)
+		b.WriteString("\n\nfunc (")
+		if recvName != "" && !declares(sig, recvName) {
+			b.WriteString(recvName + " ")
+		}
+		fmt.Fprintf(&b, "%s) %s", recvText, m.Name())
+		types.WriteSignature(&b, sig, qual)
+		b.WriteString(" {\n\tpanic(\"not implemented\")\n}")
+	}
+	edits := []input.Edit{f.edit(decl.End(), decl.End(), b.String())}
+	return append(edits, f.addImports(imports)...), nil
+}
+
+// lookupInterface returns the interface type of iface, instantiated
+// with typeArgs if it is generic.  If it is generic and there are no
+// typeArgs, it is instantiated with the type parameters of named,
+// which is declared by spec.
+func lookupInterface(f *file, spec *ast.TypeSpec, named *types.Named, iface Interface, typeArgs string) (*types.Interface, error) {
+	pkg := f.pkg
+	if iface.Path != f.pkg.Path() {
+		var err error
+		pkg, err = f.importer.Import(iface.Path)
+		if err != nil {
+			return nil, fmt.Errorf("could not load %s: %s", iface.Path, err)
+		}
+	}
+	obj, ok := pkg.Scope().Lookup(iface.Name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a type", iface)
+	}
+	t := obj.Type()
+	if generic, ok := t.(*types.Named); ok && generic.TypeParams().Len() > 0 {
+		var targs []types.Type
+		if typeArgs == "" {
+			params := named.TypeParams()
+			if params.Len() != generic.TypeParams().Len() {
+				return nil, fmt.Errorf("%s is generic; give its type arguments, e.g. %s[int]", iface.Name, iface.Name)
+			}
+			for i := 0; i < params.Len(); i++ {
+				targs = append(targs, params.At(i))
+			}
+		} else {
+			for _, arg := range splitArgs(typeArgs) {
+				tv, err := types.Eval(f.fset, f.pkg, spec.Type.Pos(), arg)
+				if err != nil {
+					return nil, fmt.Errorf("type argument %s: %s", arg, err)
+				}
+				if !tv.IsType() {
+					return nil, fmt.Errorf("type argument %s is not a type", arg)
+				}
+				targs = append(targs, tv.Type)
+			}
+		}
+		inst, err := types.Instantiate(nil, t, targs, true)
+		if err != nil {
+			return nil, err
+		}
+		t = inst
+	}
+	u, ok := t.Underlying().(*types.Interface)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an interface", iface)
+	}
+	return u, nil
+}
+
+// typeSpec returns the declaration of the type name in f.
+func typeSpec(f *file, name string) (*ast.GenDecl, *ast.TypeSpec) {
+	for _, decl := range f.ast.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if ts := spec.(*ast.TypeSpec); ts.Name.Name == name {
+				return gen, ts
+			}
+		}
+	}
+	return nil, nil
+}
+
+// receiverName returns the receiver name that the methods of the type
+// name in f use, or the lowercase first letter of name if it doesn't
+// have any methods yet.
+func receiverName(f *file, name string) string {
+	for _, decl := range f.ast.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || len(fn.Recv.List) == 0 {
+			continue
+		}
+		recv := fn.Recv.List[0]
+		if baseType(recv.Type) != name {
+			continue
+		}
+		if len(recv.Names) == 0 || recv.Names[0].Name == "_" {
+			return ""
+		}
+		return recv.Names[0].Name
+	}
+	r, _ := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r))
+}
+
+// baseType returns the name of the type in a receiver expression like
+// *T or T[K, V].
+func baseType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return baseType(t.X)
+	case *ast.IndexExpr:
+		return baseType(t.X)
+	case *ast.IndexListExpr:
+		return baseType(t.X)
+	case *ast.ParenExpr:
+		return baseType(t.X)
+	}
+	return ""
+}
+
+// typeParamNames returns the type parameters of spec as they are
+// listed in a receiver, e.g. "[K, V]".
+func typeParamNames(spec *ast.TypeSpec) string {
+	if spec.TypeParams == nil || len(spec.TypeParams.List) == 0 {
+		return ""
+	}
+	var names []string
+	for _, field := range spec.TypeParams.List {
+		for _, n := range field.Names {
+			names = append(names, n.Name)
+		}
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
+
+// declares returns whether sig has a parameter or result called name.
+func declares(sig *types.Signature, name string) bool {
+	for _, vars := range []*types.Tuple{sig.Params(), sig.Results()} {
+		for i := 0; i < vars.Len(); i++ {
+			if vars.At(i).Name() == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitArgs splits a list of type arguments at the commas that aren't
+// nested in brackets, braces or parentheses.
+func splitArgs(args string) []string {
+	var split []string
+	depth, start := 0, 0
+	for i, r := range args {
+		switch r {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				split = append(split, strings.TrimSpace(args[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(split, strings.TrimSpace(args[start:]))
+}
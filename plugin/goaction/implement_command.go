@@ -0,0 +1,248 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package goaction
+
+import (
+	"fmt"
+	"go/ast"
+	"path/filepath"
+	"strings"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/gxui/math"
+	"github.com/nelsam/vidar/command/action"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/plugin/status"
+)
+
+// maxMatches is the number of matching interfaces that are listed
+// when more than one matches.
+const maxMatches = 8
+
+// Applier is a type that can apply edits, like the input handler.
+type Applier interface {
+	Apply(input.Editor, ...input.Edit)
+}
+
+// Editor is the type of editor that go commands find the caret in.
+type Editor interface {
+	input.Editor
+	Controller() *gxui.TextBoxController
+}
+
+type elementer interface {
+	Elements() []interface{}
+}
+
+// Implement is a command that prompts for a receiver type and an
+// interface, then adds stubs for the methods of the interface that
+// the type is missing.
+type Implement struct {
+	status.General
+
+	prompt gxui.Label
+	recv   gxui.TextBox
+	iface  gxui.TextBox
+	step   int
+
+	loaded  <-chan []Interface
+	ifaces  []Interface
+	matches []Interface
+
+	editor  input.Editor
+	applier Applier
+}
+
+// NewImplement returns a new Implement command.
+func NewImplement(theme gxui.Theme) *Implement {
+	i := &Implement{
+		prompt: theme.CreateLabel(),
+		recv:   theme.CreateTextBox(),
+		iface:  theme.CreateTextBox(),
+	}
+	i.Theme = theme
+	i.recv.SetDesiredWidth(math.MaxSize.W)
+	i.iface.SetDesiredWidth(math.MaxSize.W)
+	return i
+}
+
+func (i *Implement) Name() string {
+	return "implement-interface"
+}
+
+func (i *Implement) Menu() string {
+	return "Golang"
+}
+
+func (i *Implement) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl | gxui.ModShift,
+		Key:      gxui.KeyI,
+	}}
+}
+
+// Start suggests the type at the caret as the receiver and starts
+// loading the interfaces that can be implemented.
+func (i *Implement) Start(on gxui.Control) gxui.Control {
+	i.step = 0
+	i.ifaces = nil
+	i.matches = nil
+	i.recv.SetText("")
+	i.iface.SetText("")
+	e := findEditor(on)
+	if e == nil {
+		return nil
+	}
+	i.recv.SetText(caretType(e))
+	loaded := make(chan []Interface, 1)
+	i.loaded = loaded
+	dir := filepath.Dir(e.Filepath())
+	go func() {
+		loaded <- Interfaces(dir)
+	}()
+	return i.prompt
+}
+
+// Next prompts for the receiver, then for the interface until the
+// interface's name matches only one interface.
+func (i *Implement) Next() gxui.Focusable {
+	switch i.step {
+	case 0:
+		i.step++
+		i.prompt.SetText("Receiver type (*T for pointer receivers):")
+		return i.recv
+	case 1:
+		i.step++
+		i.prompt.SetText("Interface (e.g. io.Reader or Container[int]):")
+		return i.iface
+	}
+	if i.loaded != nil {
+		i.ifaces = <-i.loaded
+		i.loaded = nil
+	}
+	name, _ := splitTypeArgs(i.iface.Text())
+	if name == "" {
+		return nil
+	}
+	i.matches = Search(i.ifaces, name)
+	if len(i.matches) <= 1 {
+		return nil
+	}
+	shown := i.matches
+	if len(shown) > maxMatches {
+		shown = shown[:maxMatches]
+	}
+	names := make([]string, 0, len(shown))
+	for _, m := range shown {
+		names = append(names, m.String())
+	}
+	i.prompt.SetText(fmt.Sprintf("%d interfaces match %s: %s", len(i.matches), name, strings.Join(names, ", ")))
+	return i.iface
+}
+
+func (i *Implement) Reset() {
+	i.editor = nil
+	i.applier = nil
+}
+
+func (i *Implement) Store(elem interface{}) bind.Status {
+	switch src := elem.(type) {
+	case input.Editor:
+		i.editor = src
+	case Applier:
+		i.applier = src
+	}
+	if i.editor != nil && i.applier != nil {
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+func (i *Implement) Exec() error {
+	recv := strings.TrimSpace(i.recv.Text())
+	name, args := splitTypeArgs(i.iface.Text())
+	if recv == "" || name == "" {
+		i.Warn = "A receiver type and an interface are both needed"
+		return nil
+	}
+	if len(i.matches) == 0 {
+		i.Err = fmt.Sprintf("No interface matches %s", name)
+		return fmt.Errorf("goaction: no interface matches %s", name)
+	}
+	iface := i.matches[0]
+	edits, err := Stubs(i.editor, recv, iface, args)
+	if err != nil {
+		i.Err = fmt.Sprintf("Could not implement %s: %s", iface, err)
+		return err
+	}
+	if len(edits) == 0 {
+		i.Info = fmt.Sprintf("%s already implements %s", recv, iface)
+		return nil
+	}
+	if err := action.Check(input.Snapshot(i.editor), edits); err != nil {
+		i.Err = fmt.Sprintf("Could not implement %s: %s", iface, err)
+		return err
+	}
+	i.applier.Apply(i.editor, edits...)
+	return nil
+}
+
+// caretType returns the receiver for the type that the caret in e is
+// in: the type being declared, or the receiver of the method being
+// declared.  Struct types get pointer receivers.
+func caretType(e Editor) string {
+	f, err := parse(e.Filepath(), e.Runes())
+	if err != nil {
+		return ""
+	}
+	pos := f.pos(e.Controller().LastCaret())
+	path := f.enclosing(pos, pos)
+	name := ""
+	for i := len(path) - 1; i >= 0 && name == ""; i-- {
+		switch n := path[i].(type) {
+		case *ast.TypeSpec:
+			name = n.Name.Name
+		case *ast.FuncDecl:
+			if n.Recv != nil && len(n.Recv.List) > 0 {
+				name = baseType(n.Recv.List[0].Type)
+			}
+		}
+	}
+	if name == "" {
+		return ""
+	}
+	if _, spec := typeSpec(f, name); spec != nil {
+		if _, ok := spec.Type.(*ast.StructType); ok {
+			return "*" + name
+		}
+	}
+	return name
+}
+
+// splitTypeArgs splits the type arguments from an interface's name,
+// e.g. "Container[int]" is "Container" and "int".
+func splitTypeArgs(iface string) (name, typeArgs string) {
+	iface = strings.TrimSpace(iface)
+	start := strings.Index(iface, "[")
+	if start < 0 || !strings.HasSuffix(iface, "]") {
+		return iface, ""
+	}
+	return iface[:start], iface[start+1 : len(iface)-1]
+}
+
+func findEditor(elem interface{}) Editor {
+	switch src := elem.(type) {
+	case Editor:
+		return src
+	case elementer:
+		for _, child := range src.Elements() {
+			if e := findEditor(child); e != nil {
+				return e
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package goaction
+
+import (
+	"bufio"
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/nelsam/vidar/scoring"
+)
+
+// Interface is an interface type that can be implemented.
+type Interface struct {
+	Path string
+	Pkg  string
+	Name string
+}
+
+// String returns the name that i is searched for by: its package's
+// import path and its name, e.g. "net/http.Handler".
+func (i Interface) String() string {
+	return i.Path + "." + i.Name
+}
+
+var (
+	stdMu         sync.Mutex
+	stdInterfaces []Interface
+)
+
+// Interfaces returns the exported interfaces in the standard library
+// and in the module (or package, outside of modules) that dir is in,
+// along with the unexported ones in dir's package.  The standard
+// library is only searched once.
+func Interfaces(dir string) []Interface {
+	stdMu.Lock()
+	if stdInterfaces == nil {
+		stdInterfaces = listInterfaces(dir, "std")
+	}
+	ifaces := append([]Interface(nil), stdInterfaces...)
+	stdMu.Unlock()
+
+	pattern := "."
+	cmd := exec.Command("go", "list", "-m")
+	cmd.Dir = dir
+	if out, err := cmd.Output(); err == nil && len(bytes.TrimSpace(out)) > 0 {
+		pattern = string(bytes.TrimSpace(out)) + "/..."
+	}
+	return append(ifaces, listInterfaces(dir, pattern)...)
+}
+
+// listInterfaces finds the interfaces in the packages that pattern
+// matches, using `go list` in dir.  Internal packages in the standard
+// library are skipped.
+func listInterfaces(dir, pattern string) []Interface {
+	cmd := exec.Command("go", "list", "-e", "-f", "{{.ImportPath}}\t{{.Dir}}\t{{.Standard}}\t{{join .GoFiles \"\\t\"}}", pattern)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil && len(out) == 0 {
+		return nil
+	}
+	var ifaces []Interface
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		parts := strings.Split(s.Text(), "\t")
+		if len(parts) < 3 {
+			continue
+		}
+		path, pkgDir, std := parts[0], parts[1], parts[2] == "true"
+		if std && (strings.HasPrefix(path, "internal/") || strings.Contains(path, "/internal/") || strings.HasPrefix(path, "vendor/")) {
+			continue
+		}
+		local := pkgDir == dir
+		fset := token.NewFileSet()
+		for _, name := range parts[3:] {
+			if name == "" {
+				continue
+			}
+			f, _ := parser.ParseFile(fset, filepath.Join(pkgDir, name), nil, parser.SkipObjectResolution)
+			if f == nil {
+				continue
+			}
+			ifaces = append(ifaces, fileInterfaces(path, f, local)...)
+		}
+	}
+	return ifaces
+}
+
+// fileInterfaces returns the interfaces declared in f, which is in
+// the package at path.  Unexported interfaces are only included if
+// unexported is true.
+func fileInterfaces(path string, f *ast.File, unexported bool) []Interface {
+	var ifaces []Interface
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if _, ok := ts.Type.(*ast.InterfaceType); !ok {
+				continue
+			}
+			if !unexported && !ts.Name.IsExported() {
+				continue
+			}
+			ifaces = append(ifaces, Interface{Path: path, Pkg: f.Name.Name, Name: ts.Name.Name})
+		}
+	}
+	return ifaces
+}
+
+// Search returns the interfaces in ifaces that match query, best
+// first.  If query names exactly one interface - by its import path
+// and name (e.g. "net/http.Handler"), its package and name (e.g.
+// "http.Handler") or just its name - only that interface is returned.
+func Search(ifaces []Interface, query string) []Interface {
+	var exact, named []Interface
+	byKey := make(map[string][]Interface)
+	keys := make([]string, 0, len(ifaces))
+	for _, i := range ifaces {
+		if i.String() == query {
+			return []Interface{i}
+		}
+		short := i.Pkg + "." + i.Name
+		if short == query {
+			exact = append(exact, i)
+		}
+		if i.Name == query {
+			named = append(named, i)
+		}
+		// Import paths are only searched when the query has one.
+		key := short
+		if strings.Contains(query, "/") {
+			key = i.String()
+		}
+		if _, ok := byKey[key]; !ok {
+			keys = append(keys, key)
+		}
+		byKey[key] = append(byKey[key], i)
+	}
+	if len(exact) == 1 {
+		return exact
+	}
+	if len(exact) == 0 && len(named) == 1 {
+		return named
+	}
+	var found []Interface
+	for _, key := range scoring.Sort(keys, query) {
+		found = append(found, byKey[key]...)
+	}
+	return found
+}
@@ -0,0 +1,319 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package goaction
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nelsam/vidar/commander/input"
+)
+
+// file is a go file being edited.  It is parsed when it is loaded and
+// type checked, along with the rest of its package, on demand.
+type file struct {
+	path string
+	text []rune
+	src  []byte
+
+	fset *token.FileSet
+	tok  *token.File
+	ast  *ast.File
+
+	checked  bool
+	importer types.Importer
+	pkg      *types.Package
+	info     *types.Info
+}
+
+// parse parses text, the contents of the go file at path.  Syntax
+// errors are ignored as long as the package clause can be parsed.
+func parse(path string, text []rune) (*file, error) {
+	f := &file{
+		path: path,
+		text: text,
+		src:  []byte(string(text)),
+		fset: token.NewFileSet(),
+	}
+	astFile, err := parser.ParseFile(f.fset, path, f.src, parser.ParseComments)
+	if astFile == nil || astFile.Name == nil {
+		return nil, err
+	}
+	f.ast = astFile
+	f.tok = f.fset.File(astFile.Pos())
+	return f, nil
+}
+
+// check type checks f along with the other files in its package,
+// using the export data of its imports from `go list`.  Type errors
+// are ignored, so f.info has all of the information that could be
+// found; f.pkg is nil if f could not be checked at all.
+//
+// The packages in extra are loaded along with f's imports, so that
+// they can be imported with f.importer afterward.
+func (f *file) check(extra ...string) {
+	if f.checked {
+		return
+	}
+	f.checked = true
+	files := append([]*ast.File{f.ast}, f.siblings()...)
+	imports := make(map[string]bool)
+	for _, path := range extra {
+		imports[path] = true
+	}
+	for _, file := range files {
+		for _, spec := range file.Imports {
+			if path, err := strconv.Unquote(spec.Path.Value); err == nil {
+				imports[path] = true
+			}
+		}
+	}
+	pkgPath, exports := exportData(filepath.Dir(f.path), imports)
+	if pkgPath == "" {
+		pkgPath = f.ast.Name.Name
+	}
+	f.importer = importer.ForCompiler(f.fset, "gc", func(path string) (io.ReadCloser, error) {
+		export, ok := exports[path]
+		if !ok {
+			return nil, errors.New("goaction: no export data for " + path)
+		}
+		return os.Open(export)
+	})
+	conf := types.Config{
+		Importer: f.importer,
+		Error:    func(error) {},
+	}
+	f.info = &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:     make(map[ast.Node]*types.Scope),
+	}
+	f.pkg, _ = conf.Check(pkgPath, f.fset, files, f.info)
+}
+
+// siblings parses the files in f's directory that are built along
+// with it, skipping any that can't be parsed.
+func (f *file) siblings() []*ast.File {
+	dir, name := filepath.Split(f.path)
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	isTest := strings.HasSuffix(name, "_test.go")
+	var files []*ast.File
+	for _, info := range infos {
+		sibling := info.Name()
+		if sibling == name || info.IsDir() || !strings.HasSuffix(sibling, ".go") {
+			continue
+		}
+		if !isTest && strings.HasSuffix(sibling, "_test.go") {
+			continue
+		}
+		if match, err := build.Default.MatchFile(dir, sibling); err != nil || !match {
+			continue
+		}
+		parsed, _ := parser.ParseFile(f.fset, filepath.Join(dir, sibling), nil, parser.ParseComments)
+		if parsed == nil || parsed.Name == nil || parsed.Name.Name != f.ast.Name.Name {
+			continue
+		}
+		files = append(files, parsed)
+	}
+	return files
+}
+
+// exportData uses `go list` in dir to find the import path of the
+// package in dir and the export data files of imports and their
+// dependencies.  Packages that can't be found or built are left out.
+func exportData(dir string, imports map[string]bool) (pkgPath string, exports map[string]string) {
+	exports = make(map[string]string)
+	paths := make([]string, 0, len(imports))
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	args := append([]string{"list", "-e", "-export", "-deps", "-f", "{{.ImportPath}}\t{{.Dir}}\t{{.Export}}", "."}, paths...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil && len(out) == 0 {
+		return "", exports
+	}
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		parts := strings.Split(s.Text(), "\t")
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[1] == dir {
+			pkgPath = parts[0]
+		}
+		if parts[2] != "" {
+			exports[parts[0]] = parts[2]
+		}
+	}
+	return pkgPath, exports
+}
+
+// offset returns the rune offset of pos in f.
+func (f *file) offset(pos token.Pos) int {
+	return utf8.RuneCount(f.src[:f.tok.Offset(pos)])
+}
+
+// pos returns the position of the rune offset in f.
+func (f *file) pos(offset int) token.Pos {
+	if offset > len(f.text) {
+		offset = len(f.text)
+	}
+	return f.tok.Pos(len(string(f.text[:offset])))
+}
+
+// source returns the text of n.
+func (f *file) source(n ast.Node) string {
+	return string(f.src[f.tok.Offset(n.Pos()):f.tok.Offset(n.End())])
+}
+
+// edit returns an edit that replaces the text from start to end with
+// text.
+func (f *file) edit(start, end token.Pos, text string) input.Edit {
+	from, to := f.offset(start), f.offset(end)
+	return input.Edit{
+		At:  from,
+		Old: f.text[from:to],
+		New: []rune(text),
+	}
+}
+
+// indent returns the indentation of the line that pos is on.
+func (f *file) indent(pos token.Pos) string {
+	start := f.tok.Offset(f.tok.LineStart(f.tok.Line(pos)))
+	end := start
+	for end < len(f.src) && (f.src[end] == ' ' || f.src[end] == '\t') {
+		end++
+	}
+	return string(f.src[start:end])
+}
+
+// hasComments returns whether there are any comments from start to
+// end.
+func (f *file) hasComments(start, end token.Pos) bool {
+	for _, g := range f.ast.Comments {
+		if g.End() > start && g.Pos() < end {
+			return true
+		}
+	}
+	return false
+}
+
+// importName returns the name that path is imported as in f, or ""
+// if f doesn't import it.
+func (f *file) importName(path string) string {
+	for _, spec := range f.ast.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || p != path {
+			continue
+		}
+		if spec.Name != nil {
+			return spec.Name.Name
+		}
+		if f.info != nil {
+			if pkg, ok := f.info.Implicits[spec].(*types.PkgName); ok {
+				return pkg.Imported().Name()
+			}
+		}
+		return filepath.Base(p)
+	}
+	return ""
+}
+
+// qualifier returns a types.Qualifier that names packages the way f
+// imports them.  Packages that f doesn't import are named by their
+// package name and added to missing.
+func (f *file) qualifier(missing map[string]bool) types.Qualifier {
+	return func(pkg *types.Package) string {
+		if f.pkg != nil && pkg.Path() == f.pkg.Path() {
+			return ""
+		}
+		if name := f.importName(pkg.Path()); name != "" {
+			return name
+		}
+		missing[pkg.Path()] = true
+		return pkg.Name()
+	}
+}
+
+// addImports returns the edits that import paths in f.
+func (f *file) addImports(paths map[string]bool) []input.Edit {
+	if len(paths) == 0 {
+		return nil
+	}
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+	for _, decl := range f.ast.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT || !gen.Lparen.IsValid() {
+			continue
+		}
+		var lines string
+		for _, path := range sorted {
+			lines += "\t" + strconv.Quote(path) + "\n"
+		}
+		at := f.tok.LineStart(f.tok.Line(gen.Rparen))
+		return []input.Edit{f.edit(at, at, lines)}
+	}
+	var decl string
+	for _, path := range sorted {
+		decl += "\nimport " + strconv.Quote(path)
+	}
+	after := f.ast.Name.End()
+	if len(f.ast.Imports) > 0 {
+		after = f.ast.Imports[len(f.ast.Imports)-1].End()
+	} else {
+		decl = "\n" + decl
+	}
+	return []input.Edit{f.edit(after, after, decl)}
+}
+
+// enclosing returns the nodes in f that contain start to end, from the
+// outermost (f itself) to the innermost.
+func (f *file) enclosing(start, end token.Pos) []ast.Node {
+	var stack, path []ast.Node
+	ast.Inspect(f.ast, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return false
+		}
+		if n.Pos() > start || n.End() < end {
+			return false
+		}
+		stack = append(stack, n)
+		if len(stack) > len(path) {
+			path = append(path[:0], stack...)
+		}
+		return true
+	})
+	return path
+}
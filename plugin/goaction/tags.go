@@ -0,0 +1,213 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package goaction
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/nelsam/vidar/command/action"
+	"github.com/nelsam/vidar/commander/input"
+)
+
+// tagKeys are the struct tag keys that actions are offered for.
+var tagKeys = []string{"json", "yaml", "db"}
+
+// A naming is a style for the names in struct tags.
+type naming struct {
+	name string
+	join func(words []string) string
+}
+
+var namings = []naming{
+	{name: "snake_case", join: func(words []string) string {
+		return strings.ToLower(strings.Join(words, "_"))
+	}},
+	{name: "camelCase", join: func(words []string) string {
+		return strings.ToLower(words[0]) + strings.Join(words[1:], "")
+	}},
+}
+
+// tagPair is a key and its value in a struct tag.
+type tagPair struct {
+	key, value string
+}
+
+// structTags offers to add and remove struct tags on the fields of the
+// innermost struct type around start to end.  If text is selected,
+// only the selected fields are changed.
+func structTags(f *file, start, end token.Pos) []action.Action {
+	path := f.enclosing(start, end)
+	var st *ast.StructType
+	for i := len(path) - 1; i >= 0; i-- {
+		if s, ok := path[i].(*ast.StructType); ok {
+			st = s
+			break
+		}
+	}
+	if st == nil || st.Fields == nil {
+		return nil
+	}
+	var fields []*ast.Field
+	for _, field := range st.Fields.List {
+		if start != end && (field.End() <= start || field.Pos() >= end) {
+			continue
+		}
+		if len(field.Names) != 1 || !field.Names[0].IsExported() {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	var actions []action.Action
+	for _, key := range tagKeys {
+		for _, n := range namings {
+			var edits []input.Edit
+			for _, field := range fields {
+				pairs, ok := fieldTag(field)
+				if !ok || lookup(pairs, key) >= 0 {
+					continue
+				}
+				pairs = append(pairs, tagPair{key: key, value: n.join(words(field.Names[0].Name))})
+				edits = append(edits, tagEdit(f, field, pairs))
+			}
+			if len(edits) > 0 {
+				actions = append(actions, action.Action{
+					Name:  fmt.Sprintf("Add %s tags (%s)", key, n.name),
+					Edits: edits,
+				})
+			}
+		}
+		var edits []input.Edit
+		for _, field := range fields {
+			pairs, ok := fieldTag(field)
+			if !ok {
+				continue
+			}
+			i := lookup(pairs, key)
+			if i < 0 {
+				continue
+			}
+			edits = append(edits, tagEdit(f, field, append(pairs[:i:i], pairs[i+1:]...)))
+		}
+		if len(edits) > 0 {
+			actions = append(actions, action.Action{
+				Name:  fmt.Sprintf("Remove %s tags", key),
+				Edits: edits,
+			})
+		}
+	}
+	return actions
+}
+
+// tagEdit returns the edit that replaces field's tag with pairs,
+// removing the tag if pairs is empty.
+func tagEdit(f *file, field *ast.Field, pairs []tagPair) input.Edit {
+	if len(pairs) == 0 {
+		return f.edit(field.Type.End(), field.Tag.End(), "")
+	}
+	parts := make([]string, 0, len(pairs))
+	for _, p := range pairs {
+		parts = append(parts, p.key+":"+strconv.Quote(p.value))
+	}
+	tag := strings.Join(parts, " ")
+	if strconv.CanBackquote(tag) {
+		tag = "`" + tag + "`"
+	} else {
+		tag = strconv.Quote(tag)
+	}
+	if field.Tag == nil {
+		return f.edit(field.Type.End(), field.Type.End(), " "+tag)
+	}
+	return f.edit(field.Tag.Pos(), field.Tag.End(), tag)
+}
+
+// fieldTag returns the pairs in field's tag.  It returns false if the
+// tag isn't in the conventional format.
+func fieldTag(field *ast.Field) ([]tagPair, bool) {
+	if field.Tag == nil {
+		return nil, true
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return nil, false
+	}
+	return parseTag(tag)
+}
+
+// parseTag parses a struct tag in the conventional format, as described
+// by reflect.StructTag.
+func parseTag(tag string) ([]tagPair, bool) {
+	var pairs []tagPair
+	for {
+		tag = strings.TrimLeft(tag, " ")
+		if tag == "" {
+			return pairs, true
+		}
+		colon := strings.Index(tag, `:"`)
+		if colon <= 0 || strings.ContainsAny(tag[:colon], " \"") {
+			return nil, false
+		}
+		key := tag[:colon]
+		tag = tag[colon+1:]
+		end := 1
+		for end < len(tag) && tag[end] != '"' {
+			if tag[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(tag) {
+			return nil, false
+		}
+		value, err := strconv.Unquote(tag[:end+1])
+		if err != nil {
+			return nil, false
+		}
+		pairs = append(pairs, tagPair{key: key, value: value})
+		tag = tag[end+1:]
+	}
+}
+
+func lookup(pairs []tagPair, key string) int {
+	for i, p := range pairs {
+		if p.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// words splits a go identifier into its words, keeping acronyms
+// together: "UserID" is "User" and "ID"; "HTTPServer" is "HTTP" and
+// "Server".
+func words(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, r := runes[i-1], runes[i]
+		switch {
+		case r == '_':
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+		case unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			words = append(words, string(runes[start:i]))
+			start = i
+		case unicode.IsLower(r) && unicode.IsUpper(prev) && i-1 > start:
+			words = append(words, string(runes[start:i-1]))
+			start = i - 1
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
@@ -0,0 +1,94 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package gobuild runs `go build` and collects the errors that it
+// reports into a list that can be navigated from the editor.
+package gobuild
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// location matches the file:line:col prefix of compiler errors.  The
+// column is optional, since some tools only report lines.
+var location = regexp.MustCompile(`^(\S[^:]*\.go):(\d+)(?::(\d+))?: ?(.*)$`)
+
+// Problem is a single error reported by the build.
+type Problem struct {
+	// Path is the absolute path to the file that the problem is in.
+	// It is empty for errors that are not tied to a file.
+	Path string
+
+	// Line and Column are 1-based, the way the compiler reports
+	// them.  Column is 0 when the compiler didn't report one.
+	Line, Column int
+
+	Msg string
+
+	// Edited is set when the line that the problem is on has been
+	// edited since the build ran, so the problem may be stale.
+	Edited bool
+}
+
+// HasLocation returns whether p points to a location in a file.
+func (p Problem) HasLocation() bool {
+	return p.Path != "" && p.Line > 0
+}
+
+// Parse parses the output of `go build`, run in dir, into a list of
+// problems.  Lines that are indented further are treated as part of
+// the previous problem's message, and package headers (`# pkg`) are
+// skipped.
+func Parse(dir, output string) []Problem {
+	var problems []Problem
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(problems) > 0 {
+			last := &problems[len(problems)-1]
+			last.Msg += "\n" + strings.TrimSpace(line)
+			continue
+		}
+		m := location.FindStringSubmatch(line)
+		if m == nil {
+			problems = append(problems, Problem{Msg: line})
+			continue
+		}
+		path := m[1]
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		l, _ := strconv.Atoi(m[2])
+		col, _ := strconv.Atoi(m[3])
+		problems = append(problems, Problem{Path: path, Line: l, Column: col, Msg: m[4]})
+	}
+	return problems
+}
+
+// Run runs `go build` in dir with args, returning the problems that
+// it reports.  The returned error is only non-nil if go build could
+// not be run at all.
+func Run(ctx context.Context, env []string, dir string, args []string) ([]Problem, error) {
+	cmd := exec.CommandContext(ctx, "go", append([]string{"build"}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = env
+	out := &bytes.Buffer{}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if _, ok := err.(*exec.ExitError); err != nil && !ok {
+		return nil, err
+	}
+	return Parse(dir, out.String()), nil
+}
@@ -0,0 +1,106 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package gobuild_test
+
+import (
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/piece"
+	"github.com/nelsam/vidar/plugin/gobuild"
+)
+
+func TestParse(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) expect.Expectation {
+		return expect.New(t)
+	})
+
+	o.Spec("it parses compiler errors relative to the build directory", func(expect expect.Expectation) {
+		output := "# github.com/foo/bar\n" +
+			"./bar.go:12:3: undefined: baz\n" +
+			"sub/sub.go:4:10: cannot use x (variable of type int) as string value in assignment\n" +
+			"/abs/path/main.go:7: missing return\n"
+		problems := gobuild.Parse("/src/bar", output)
+		expect(problems).To(Equal([]gobuild.Problem{
+			{Path: "/src/bar/bar.go", Line: 12, Column: 3, Msg: "undefined: baz"},
+			{Path: "/src/bar/sub/sub.go", Line: 4, Column: 10, Msg: "cannot use x (variable of type int) as string value in assignment"},
+			{Path: "/abs/path/main.go", Line: 7, Msg: "missing return"},
+		}))
+	})
+
+	o.Spec("it appends indented lines to the previous problem", func(expect expect.Expectation) {
+		output := "./bar.go:5:9: not enough return values\n" +
+			"\thave ()\n" +
+			"\twant (error)\n"
+		problems := gobuild.Parse("/src/bar", output)
+		expect(problems).To(HaveLen(1))
+		expect(problems[0].Msg).To(Equal("not enough return values\nhave ()\nwant (error)"))
+	})
+
+	o.Spec("it keeps errors that have no location", func(expect expect.Expectation) {
+		problems := gobuild.Parse("/src/bar", "go: cannot find main module\n")
+		expect(problems).To(HaveLen(1))
+		expect(problems[0].HasLocation()).To(BeFalse())
+		expect(problems[0].Msg).To(Equal("go: cannot find main module"))
+	})
+}
+
+func TestList(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, *gobuild.List) {
+		l := gobuild.NewList()
+		l.Set([]gobuild.Problem{
+			{Path: "/a.go", Line: 2, Msg: "first"},
+			{Msg: "no location"},
+			{Path: "/a.go", Line: 4, Msg: "second"},
+			{Path: "/b.go", Line: 1, Msg: "third"},
+		})
+		return expect.New(t), l
+	})
+
+	o.Spec("it steps forward through problems with locations", func(expect expect.Expectation, l *gobuild.List) {
+		var msgs []string
+		for i := 0; i < 4; i++ {
+			p, ok := l.Next()
+			expect(ok).To(BeTrue())
+			msgs = append(msgs, p.Msg)
+		}
+		expect(msgs).To(Equal([]string{"first", "second", "third", "first"}))
+	})
+
+	o.Spec("it steps backward from the end of the list", func(expect expect.Expectation, l *gobuild.List) {
+		p, ok := l.Prev()
+		expect(ok).To(BeTrue())
+		expect(p.Msg).To(Equal("third"))
+		p, _ = l.Prev()
+		expect(p.Msg).To(Equal("second"))
+	})
+
+	o.Spec("it reports when there are no problems", func(expect expect.Expectation, l *gobuild.List) {
+		l.Set(nil)
+		_, ok := l.Next()
+		expect(ok).To(BeFalse())
+	})
+
+	o.Spec("it marks edited lines and moves later problems", func(expect expect.Expectation, l *gobuild.List) {
+		text := piece.NewString("one\ntwo!\nnew\nthree\nfour\n")
+		// A "!" and a new line were added to the end of line 2.
+		l.Edited("/a.go", text, []input.Edit{{At: 7, New: []rune("!\nnew")}})
+		problems, _ := l.Problems()
+		expect(problems[0].Edited).To(BeTrue())
+		expect(problems[0].Line).To(Equal(2))
+		expect(problems[2].Edited).To(BeFalse())
+		expect(problems[2].Line).To(Equal(5))
+		expect(problems[3].Line).To(Equal(1))
+	})
+}
@@ -0,0 +1,213 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package gobuild
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/plugin/status"
+	"github.com/nelsam/vidar/setting"
+)
+
+type Projecter interface {
+	Project() setting.Project
+}
+
+// CurrentFiler is a type that knows which file is currently open.
+type CurrentFiler interface {
+	CurrentFile() string
+}
+
+// Navigator is a type that can display a pane's frame.
+type Navigator interface {
+	ShowNavPane(gxui.Control)
+}
+
+// Build is a command that runs `go build` for the current project
+// and displays the problems that it reports.
+type Build struct {
+	status.General
+
+	pane *Pane
+	list *List
+
+	proj  Projecter
+	files CurrentFiler
+	nav   Navigator
+
+	mu     sync.Mutex
+	cancel func()
+}
+
+// NewBuild returns a *Build that stores its problems in list and
+// displays them in pane.
+func NewBuild(theme gxui.Theme, pane *Pane, list *List) *Build {
+	b := &Build{pane: pane, list: list}
+	b.Theme = theme
+	return b
+}
+
+func (b *Build) Name() string {
+	return "go-build"
+}
+
+func (b *Build) Menu() string {
+	return "Golang"
+}
+
+func (b *Build) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl,
+		Key:      gxui.KeyB,
+	}}
+}
+
+func (b *Build) Reset() {
+	b.proj = nil
+	b.files = nil
+	b.nav = nil
+}
+
+func (b *Build) Store(target interface{}) bind.Status {
+	switch src := target.(type) {
+	case Projecter:
+		b.proj = src
+		if files, ok := target.(CurrentFiler); ok {
+			b.files = files
+		}
+	case Navigator:
+		b.nav = src
+	}
+	if b.proj != nil && b.nav != nil {
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+// dir returns the directory to build in.  The default project's path
+// is the root of the filesystem, so the current file's directory is
+// used instead of it.
+func (b *Build) dir(proj setting.Project) string {
+	if proj.Name != setting.DefaultProject.Name && proj.Path != "" {
+		return proj.Path
+	}
+	if b.files == nil || b.files.CurrentFile() == "" {
+		return ""
+	}
+	return filepath.Dir(b.files.CurrentFile())
+}
+
+func (b *Build) Exec() error {
+	proj := b.proj.Project()
+	dir := b.dir(proj)
+	if dir == "" {
+		b.Warn = "No project or file to build"
+		return nil
+	}
+	args := proj.BuildArgs()
+	title := "go build " + strings.Join(args, " ")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b.mu.Lock()
+	if b.cancel != nil {
+		b.cancel()
+	}
+	b.cancel = cancel
+	b.mu.Unlock()
+
+	b.pane.SetSummary(fmt.Sprintf("%s (running)", title))
+	b.nav.ShowNavPane(b.pane.Frame())
+	go func() {
+		defer cancel()
+		problems, err := Run(ctx, proj.Environ(), dir, args)
+		if err == context.Canceled {
+			return
+		}
+		if err != nil {
+			b.pane.SetSummary(fmt.Sprintf("%s: %s", title, err))
+			return
+		}
+		b.list.Set(problems)
+		if len(problems) == 0 {
+			b.pane.SetSummary(fmt.Sprintf("%s: ok", title))
+			return
+		}
+		b.pane.SetSummary(fmt.Sprintf("%s: %d problems", title, len(problems)))
+	}()
+	b.Info = "Running " + title
+	return nil
+}
+
+// Step is a command that moves to the next or previous problem in a
+// List.
+type Step struct {
+	status.General
+
+	cmdr Commander
+	list *List
+	name string
+	key  gxui.KeyboardEvent
+	step func(*List) (Problem, bool)
+}
+
+// NewNextError returns a *Step that moves to the next problem in
+// list.
+func NewNextError(theme gxui.Theme, cmdr Commander, list *List) *Step {
+	s := &Step{
+		cmdr: cmdr,
+		list: list,
+		name: "next-error",
+		key:  gxui.KeyboardEvent{Key: gxui.KeyF4},
+		step: (*List).Next,
+	}
+	s.Theme = theme
+	return s
+}
+
+// NewPrevError returns a *Step that moves to the previous problem in
+// list.
+func NewPrevError(theme gxui.Theme, cmdr Commander, list *List) *Step {
+	s := &Step{
+		cmdr: cmdr,
+		list: list,
+		name: "previous-error",
+		key:  gxui.KeyboardEvent{Modifier: gxui.ModShift, Key: gxui.KeyF4},
+		step: (*List).Prev,
+	}
+	s.Theme = theme
+	return s
+}
+
+func (s *Step) Name() string {
+	return s.name
+}
+
+func (s *Step) Menu() string {
+	return "Golang"
+}
+
+func (s *Step) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{s.key}
+}
+
+func (s *Step) Exec(interface{}) bind.Status {
+	p, ok := s.step(s.list)
+	if !ok {
+		s.Info = "No build errors"
+		return bind.Done
+	}
+	Open(s.cmdr, p)
+	s.Info = p.Msg
+	if p.Edited {
+		s.Warn = "This line has been edited since the build: " + p.Msg
+	}
+	return bind.Done
+}
@@ -0,0 +1,196 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package gobuild
+
+import (
+	"sync"
+
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/piece"
+)
+
+// List is the list of problems from the most recent build, along
+// with the problem that was most recently visited.
+type List struct {
+	mu       sync.Mutex
+	problems []Problem
+	current  int
+	onChange []func()
+}
+
+// NewList returns an empty *List.
+func NewList() *List {
+	return &List{current: -1}
+}
+
+// OnChange registers f to be called whenever l's problems or its
+// current problem change.
+func (l *List) OnChange(f func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onChange = append(l.onChange, f)
+}
+
+func (l *List) changed() {
+	l.mu.Lock()
+	callbacks := append([]func(){}, l.onChange...)
+	l.mu.Unlock()
+	for _, f := range callbacks {
+		f()
+	}
+}
+
+// Set replaces the problems in l, resetting the current problem.
+func (l *List) Set(problems []Problem) {
+	l.mu.Lock()
+	l.problems = problems
+	l.current = -1
+	l.mu.Unlock()
+	l.changed()
+}
+
+// Problems returns a copy of the problems in l, along with the index
+// of the current problem (or -1 if no problem has been visited).
+func (l *List) Problems() ([]Problem, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Problem(nil), l.problems...), l.current
+}
+
+// Select makes the problem at index i the current problem and
+// returns it.
+func (l *List) Select(i int) (Problem, bool) {
+	l.mu.Lock()
+	if i < 0 || i >= len(l.problems) {
+		l.mu.Unlock()
+		return Problem{}, false
+	}
+	l.current = i
+	p := l.problems[i]
+	l.mu.Unlock()
+	l.changed()
+	return p, true
+}
+
+// Next moves to the next problem that has a location, wrapping
+// around to the start of the list.
+func (l *List) Next() (Problem, bool) {
+	return l.step(1)
+}
+
+// Prev moves to the previous problem that has a location, wrapping
+// around to the end of the list.
+func (l *List) Prev() (Problem, bool) {
+	return l.step(-1)
+}
+
+func (l *List) step(dir int) (Problem, bool) {
+	l.mu.Lock()
+	n := len(l.problems)
+	i := l.current
+	if i < 0 && dir < 0 {
+		i = n
+	}
+	for range l.problems {
+		i = ((i+dir)%n + n) % n
+		if l.problems[i].HasLocation() {
+			l.current = i
+			p := l.problems[i]
+			l.mu.Unlock()
+			l.changed()
+			return p, true
+		}
+	}
+	l.mu.Unlock()
+	return Problem{}, false
+}
+
+// has returns whether l has any problems in path.
+func (l *List) has(path string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, p := range l.problems {
+		if p.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// Edited updates the problems in path to account for edits.  text
+// is the text of the file after the edits were applied.  Problems
+// on lines that the edits touched are marked as edited, and problems
+// after them are moved to follow any lines that were added or
+// removed.
+func (l *List) Edited(path string, text *piece.Table, edits []input.Edit) {
+	l.mu.Lock()
+	changed := false
+	for _, e := range edits {
+		line := 1 + text.LineOf(clamp(e.At, text.Len()))
+		removed := count(e.Old, '\n')
+		delta := count(e.New, '\n') - removed
+		for i, p := range l.problems {
+			if p.Path != path || p.Line < line {
+				continue
+			}
+			if p.Line <= line+removed {
+				if !p.Edited {
+					l.problems[i].Edited = true
+					changed = true
+				}
+				continue
+			}
+			if delta != 0 {
+				l.problems[i].Line += delta
+				changed = true
+			}
+		}
+	}
+	l.mu.Unlock()
+	if changed {
+		l.changed()
+	}
+}
+
+func count(runes []rune, r rune) int {
+	n := 0
+	for _, c := range runes {
+		if c == r {
+			n++
+		}
+	}
+	return n
+}
+
+func clamp(i, max int) int {
+	if i < 0 {
+		return 0
+	}
+	if i > max {
+		return max
+	}
+	return i
+}
+
+// OnEdit is a hook that keeps a List up to date as files are edited.
+type OnEdit struct {
+	List *List
+}
+
+func (o OnEdit) Name() string {
+	return "build-problems-on-edit"
+}
+
+func (o OnEdit) OpName() string {
+	return "input-handler"
+}
+
+func (o OnEdit) Applied(e input.Editor, edits []input.Edit) {
+	path := e.Filepath()
+	if !o.List.has(path) {
+		return
+	}
+	o.List.Edited(path, input.Snapshot(e), edits)
+}
@@ -0,0 +1,24 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package main
+
+import (
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/plugin/command"
+	"github.com/nelsam/vidar/plugin/gobuild"
+)
+
+// Bindables is the main entry point to the command.
+func Bindables(cmdr command.Commander, driver gxui.Driver, theme gxui.Theme) []bind.Bindable {
+	list := gobuild.NewList()
+	pane := gobuild.NewPane(cmdr, driver, theme, list)
+	return []bind.Bindable{
+		gobuild.NewBuild(theme, pane, list),
+		gobuild.NewNextError(theme, cmdr, list),
+		gobuild.NewPrevError(theme, cmdr, list),
+		gobuild.OnEdit{List: list},
+	}
+}
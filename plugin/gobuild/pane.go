@@ -0,0 +1,165 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package gobuild
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/gxui/math"
+	"github.com/nelsam/gxui/mixins"
+	"github.com/nelsam/gxui/themes/basic"
+	"github.com/nelsam/vidar/command/focus"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/plugin/status"
+)
+
+var currentColor = gxui.Color{
+	R: 0.6,
+	G: 0.8,
+	B: 1,
+	A: 1,
+}
+
+// Commander is a type that can look up and execute bindables.
+type Commander interface {
+	Bindable(name string) bind.Bindable
+	Execute(bind.Bindable)
+}
+
+// Opener is a type that can open locations in the code.
+type Opener interface {
+	For(...focus.Opt) bind.Bindable
+}
+
+// Open opens the location of p using cmdr's focus-location command.
+func Open(cmdr Commander, p Problem) {
+	opts := []focus.Opt{focus.Path(p.Path), focus.Line(p.Line - 1)}
+	if p.Column > 0 {
+		opts = append(opts, focus.Column(p.Column-1))
+	}
+	opener := cmdr.Bindable("focus-location").(Opener)
+	cmdr.Execute(opener.For(opts...))
+}
+
+// Pane displays the problems in a List.  Clicking a problem opens
+// its location and makes it the current problem.
+type Pane struct {
+	cmdr   Commander
+	driver gxui.Driver
+	theme  *basic.Theme
+	list   *List
+
+	frame  gxui.LinearLayout
+	header gxui.Label
+	rows   gxui.LinearLayout
+
+	mu      sync.Mutex
+	summary string
+	pending bool
+}
+
+// NewPane returns a *Pane that displays the problems in list.
+func NewPane(cmdr Commander, driver gxui.Driver, theme gxui.Theme, list *List) *Pane {
+	p := &Pane{
+		cmdr:    cmdr,
+		driver:  driver,
+		theme:   theme.(*basic.Theme),
+		list:    list,
+		frame:   theme.CreateLinearLayout(),
+		header:  theme.CreateLabel(),
+		rows:    theme.CreateLinearLayout(),
+		summary: "No build has been run",
+	}
+	p.frame.SetDirection(gxui.TopToBottom)
+	p.rows.SetDirection(gxui.TopToBottom)
+	p.header.SetText(p.summary)
+	p.frame.AddChild(p.header)
+	scrollable := theme.CreateScrollLayout()
+	scrollable.SetScrollAxis(false, true)
+	scrollable.SetChild(p.rows)
+	p.frame.AddChild(scrollable)
+	p.frame.SetChildWeight(scrollable, 1)
+	list.OnChange(p.refresh)
+	return p
+}
+
+// Frame returns the control that displays p's problems.
+func (p *Pane) Frame() gxui.Control {
+	return p.frame
+}
+
+// SetSummary sets the text displayed above the problems.
+func (p *Pane) SetSummary(summary string) {
+	p.mu.Lock()
+	p.summary = summary
+	p.mu.Unlock()
+	p.refresh()
+}
+
+// refresh schedules a redraw of p, unless one is already scheduled.
+func (p *Pane) refresh() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pending {
+		return
+	}
+	p.pending = true
+	p.driver.Call(p.redraw)
+}
+
+func (p *Pane) redraw() {
+	p.mu.Lock()
+	p.pending = false
+	p.header.SetText(p.summary)
+	p.mu.Unlock()
+
+	problems, current := p.list.Problems()
+	p.rows.RemoveAll()
+	for i, prob := range problems {
+		p.rows.AddChild(p.row(i, prob, i == current))
+	}
+}
+
+func (p *Pane) row(i int, prob Problem, current bool) gxui.Control {
+	b := &mixins.Button{}
+	b.Init(b, p.theme)
+	b.SetMargin(math.Spacing{L: 3})
+	b.SetPadding(math.Spacing{L: 1, R: 1, T: 1, B: 1})
+	b.SetText(problemText(prob))
+	switch {
+	case current:
+		b.Label().SetColor(currentColor)
+	case prob.Edited:
+		b.Label().SetColor(status.ColorWarn)
+	default:
+		b.Label().SetColor(status.ColorErr)
+	}
+	if prob.HasLocation() {
+		b.OnClick(func(gxui.MouseEvent) {
+			if prob, ok := p.list.Select(i); ok {
+				Open(p.cmdr, prob)
+			}
+		})
+	}
+	return b
+}
+
+func problemText(p Problem) string {
+	if !p.HasLocation() {
+		return p.Msg
+	}
+	text := fmt.Sprintf("%s:%d", filepath.Base(p.Path), p.Line)
+	if p.Column > 0 {
+		text += fmt.Sprintf(":%d", p.Column)
+	}
+	text += ": " + p.Msg
+	if p.Edited {
+		text += " (edited)"
+	}
+	return text
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/nelsam/gxui/math"
 	"github.com/nelsam/gxui/themes/basic"
 	"github.com/nelsam/vidar/command/caret"
+	"github.com/nelsam/vidar/commander/bind"
 	"github.com/nelsam/vidar/commander/input"
 	"github.com/nelsam/vidar/setting"
 )
@@ -21,9 +22,18 @@ type Projecter interface {
 	Project() setting.Project
 }
 
-func New(theme *basic.Theme, driver gxui.Driver) (*Completions, *GoCode) {
+// Commander is a type that can look up and execute bindables, like
+// the commander.  It is used to expand snippets chosen from the
+// suggestion list.
+type Commander interface {
+	Bindable(name string) bind.Bindable
+	Execute(bind.Bindable)
+}
+
+func New(theme *basic.Theme, driver gxui.Driver, cmdr Commander) (*Completions, *GoCode) {
 	g := GoCode{
 		driver:  driver,
+		cmdr:    cmdr,
 		lists:   make(map[Editor]*suggestionList),
 		cancels: make(map[Editor]func()),
 	}
@@ -45,6 +55,7 @@ func ctxCancelled(ctx context.Context) bool {
 
 type GoCode struct {
 	driver gxui.Driver
+	cmdr   Commander
 
 	mu      sync.RWMutex
 	lists   map[Editor]*suggestionList
@@ -7,14 +7,17 @@ package gocode
 import (
 	"context"
 	"log"
+	"strings"
 	"unicode"
 
 	"github.com/nelsam/gxui"
 	"github.com/nelsam/gxui/math"
 	"github.com/nelsam/gxui/mixins"
 	"github.com/nelsam/gxui/themes/basic"
+	"github.com/nelsam/vidar/commander/bind"
 	"github.com/nelsam/vidar/commander/input"
 	"github.com/nelsam/vidar/setting"
+	"github.com/nelsam/vidar/snippet"
 	"github.com/nelsam/vidar/suggestion"
 )
 
@@ -35,6 +38,12 @@ type Applier interface {
 	Apply(input.Editor, ...input.Edit)
 }
 
+// snippetInserter is the insert-snippet command from the snippets
+// plugin.
+type snippetInserter interface {
+	For(s snippet.Snippet, start, end int) bind.Bindable
+}
+
 type suggestionList struct {
 	mixins.List
 	driver  gxui.Driver
@@ -125,12 +134,33 @@ func (s *suggestionList) show(ctx context.Context, pos int) int {
 }
 
 func (s *suggestionList) parseSuggestions(runes []rune, start int) []suggestion.Suggestion {
-	suggestion, err := suggestion.For(s.project.Environ(), s.editor.Filepath(), string(runes), start)
+	suggestions, err := suggestion.For(s.project.Environ(), s.editor.Filepath(), string(runes), start)
 	if err != nil {
 		log.Printf("Failed to load suggestion: %s", err)
+	}
+	return append(suggestions, s.snippets()...)
+}
+
+// snippets returns suggestions for the user's go snippets, if the
+// snippets plugin is loaded to expand them.
+func (s *suggestionList) snippets() []suggestion.Suggestion {
+	if _, ok := s.gocode.cmdr.Bindable("insert-snippet").(snippetInserter); !ok {
 		return nil
 	}
-	return suggestion
+	snippets, err := setting.Snippets("go")
+	if err != nil {
+		log.Printf("Failed to load snippets: %s", err)
+		return nil
+	}
+	var suggestions []suggestion.Suggestion
+	for i := range snippets {
+		suggestions = append(suggestions, suggestion.Suggestion{
+			Name:      snippets[i].Prefix,
+			Signature: strings.TrimSpace("snippet " + snippets[i].Description),
+			Snippet:   &snippets[i],
+		})
+	}
+	return suggestions
 }
 
 func (s *suggestionList) apply() {
@@ -144,6 +174,10 @@ func (s *suggestionList) apply() {
 	end := carets[0]
 	runes := s.ctrl.TextRunes()
 
+	if suggestion.Snippet != nil {
+		s.expand(*suggestion.Snippet, start, end)
+		return
+	}
 	if start <= end {
 		go s.applier.Apply(s.editor, input.Edit{
 			At:  start,
@@ -153,6 +187,21 @@ func (s *suggestionList) apply() {
 	}
 }
 
+// expand expands sn in place of the text from start to end using the
+// snippets plugin, so that its tab stops can be visited.
+func (s *suggestionList) expand(sn snippet.Snippet, start, end int) {
+	i, ok := s.gocode.cmdr.Bindable("insert-snippet").(snippetInserter)
+	if !ok {
+		log.Printf("Cannot expand snippet %s: insert-snippet is not a snippet inserter", sn.Prefix)
+		return
+	}
+	// apply is called while the gocode lock is held, and selecting
+	// the snippet's tab stops calls gocode's caret movement hooks.
+	s.driver.Call(func() {
+		s.gocode.cmdr.Execute(i.For(sn, start, end))
+	})
+}
+
 func wordPart(r rune) bool {
 	return r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r)
 }
@@ -15,8 +15,9 @@ import (
 )
 
 type GolangHook struct {
-	Theme  *basic.Theme
-	Driver gxui.Driver
+	Theme     *basic.Theme
+	Driver    gxui.Driver
+	Commander gocode.Commander
 }
 
 func (h GolangHook) Name() string {
@@ -31,7 +32,7 @@ func (h GolangHook) FileBindables(path string) []bind.Bindable {
 	if !strings.HasSuffix(path, ".go") {
 		return nil
 	}
-	completions, gocode := gocode.New(h.Theme, h.Driver)
+	completions, gocode := gocode.New(h.Theme, h.Driver, h.Commander)
 	return []bind.Bindable{
 		completions,
 		gocode,
@@ -42,8 +43,9 @@ func (h GolangHook) FileBindables(path string) []bind.Bindable {
 func Bindables(cmdr command.Commander, driver gxui.Driver, theme gxui.Theme) []bind.Bindable {
 	return []bind.Bindable{
 		GolangHook{
-			Theme:  theme.(*basic.Theme),
-			Driver: driver,
+			Theme:     theme.(*basic.Theme),
+			Driver:    driver,
+			Commander: cmdr,
 		},
 	}
 }
@@ -0,0 +1,257 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package gocover
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/gxui/themes/basic"
+	"github.com/nelsam/vidar/command/fs"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/plugin/status"
+	"github.com/nelsam/vidar/setting"
+)
+
+type Projecter interface {
+	Project() setting.Project
+}
+
+type Editor interface {
+	Filepath() string
+}
+
+// Coverage is a command that runs `go test -coverprofile` for the
+// current file's package and displays the coverage.
+type Coverage struct {
+	status.General
+
+	overlay *Overlay
+
+	proj      Projecter
+	editor    Editor
+	annotator Annotator
+}
+
+// NewCoverage returns a *Coverage that displays coverage using o.
+func NewCoverage(theme gxui.Theme, o *Overlay) *Coverage {
+	c := &Coverage{overlay: o}
+	c.Theme = theme
+	return c
+}
+
+func (c *Coverage) Name() string {
+	return "go-test-coverage"
+}
+
+func (c *Coverage) Menu() string {
+	return "Golang"
+}
+
+func (c *Coverage) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl | gxui.ModAlt,
+		Key:      gxui.KeyC,
+	}}
+}
+
+func (c *Coverage) Reset() {
+	c.proj = nil
+	c.editor = nil
+	c.annotator = nil
+}
+
+func (c *Coverage) Store(target interface{}) bind.Status {
+	switch src := target.(type) {
+	case Projecter:
+		c.proj = src
+	case Editor:
+		c.editor = src
+	case Annotator:
+		c.annotator = src
+	}
+	if c.proj != nil && c.editor != nil && c.annotator != nil {
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+func (c *Coverage) Exec() error {
+	path := c.editor.Filepath()
+	if path == "" {
+		c.Warn = "No file is open"
+		return nil
+	}
+	dir := filepath.Dir(path)
+	env := c.proj.Project().Environ()
+	f, err := ioutil.TempFile("", "vidar-cover")
+	if err != nil {
+		c.Err = fmt.Sprintf("Could not create a coverage profile: %s", err)
+		return nil
+	}
+	f.Close()
+	profile := f.Name()
+	annotator := c.annotator
+	go func() {
+		defer os.Remove(profile)
+		ctx := context.Background()
+		if err := Run(ctx, env, dir, profile); err != nil {
+			c.overlay.report(fmt.Sprintf("go test -coverprofile: %s", err))
+			return
+		}
+		files, err := Load(ctx, env, dir, profile)
+		if err != nil {
+			c.overlay.report(fmt.Sprintf("Could not load coverage: %s", err))
+			return
+		}
+		c.overlay.Set(annotator, files)
+	}()
+	c.Info = fmt.Sprintf("Running tests with coverage in %s", filepath.Base(dir))
+	return nil
+}
+
+// LoadProfile is a command that loads an existing coverage profile
+// and displays its coverage.
+type LoadProfile struct {
+	status.General
+
+	overlay *Overlay
+	file    *fs.Locator
+	input   <-chan gxui.Focusable
+
+	proj      Projecter
+	annotator Annotator
+}
+
+// NewLoadProfile returns a *LoadProfile that displays coverage using
+// o.
+func NewLoadProfile(driver gxui.Driver, theme *basic.Theme, o *Overlay) *LoadProfile {
+	l := &LoadProfile{overlay: o}
+	l.Theme = theme
+	l.file = fs.NewLocator(driver, theme, fs.All)
+	return l
+}
+
+func (l *LoadProfile) Name() string {
+	return "load-coverage-profile"
+}
+
+func (l *LoadProfile) Menu() string {
+	return "Golang"
+}
+
+func (l *LoadProfile) Defaults() []fmt.Stringer {
+	return nil
+}
+
+func (l *LoadProfile) Start(control gxui.Control) gxui.Control {
+	l.file.LoadDir(control)
+	input := make(chan gxui.Focusable, 1)
+	l.input = input
+	input <- l.file
+	close(input)
+	return nil
+}
+
+func (l *LoadProfile) Next() gxui.Focusable {
+	return <-l.input
+}
+
+func (l *LoadProfile) Reset() {
+	l.proj = nil
+	l.annotator = nil
+}
+
+func (l *LoadProfile) Store(target interface{}) bind.Status {
+	switch src := target.(type) {
+	case Projecter:
+		l.proj = src
+	case Annotator:
+		l.annotator = src
+	}
+	if l.proj != nil && l.annotator != nil {
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+func (l *LoadProfile) Exec() error {
+	path := l.file.Path()
+	if path == "" {
+		l.Err = "no profile path provided"
+		return fmt.Errorf("gocover.LoadProfile: %s", l.Err)
+	}
+	env := l.proj.Project().Environ()
+	annotator := l.annotator
+	go func() {
+		files, err := Load(context.Background(), env, filepath.Dir(path), path)
+		if err != nil {
+			l.overlay.report(fmt.Sprintf("Could not load coverage: %s", err))
+			return
+		}
+		l.overlay.Set(annotator, files)
+	}()
+	l.Info = fmt.Sprintf("Loading coverage from %s", filepath.Base(path))
+	return nil
+}
+
+// Toggle is a command that shows or hides coverage.
+type Toggle struct {
+	status.General
+
+	overlay   *Overlay
+	annotator Annotator
+}
+
+// NewToggle returns a *Toggle that shows or hides o.
+func NewToggle(theme gxui.Theme, o *Overlay) *Toggle {
+	t := &Toggle{overlay: o}
+	t.Theme = theme
+	return t
+}
+
+func (t *Toggle) Name() string {
+	return "toggle-coverage"
+}
+
+func (t *Toggle) Menu() string {
+	return "View"
+}
+
+func (t *Toggle) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl | gxui.ModAlt | gxui.ModShift,
+		Key:      gxui.KeyC,
+	}}
+}
+
+func (t *Toggle) Reset() {
+	t.annotator = nil
+}
+
+func (t *Toggle) Store(target interface{}) bind.Status {
+	if a, ok := target.(Annotator); ok {
+		t.annotator = a
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+func (t *Toggle) Exec() error {
+	if t.overlay.Empty() {
+		t.Warn = "No coverage has been loaded"
+		return nil
+	}
+	if t.overlay.Toggle(t.annotator) {
+		t.Info = "Showing coverage"
+		return nil
+	}
+	t.Info = "Hiding coverage"
+	return nil
+}
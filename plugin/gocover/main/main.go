@@ -0,0 +1,25 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package main
+
+import (
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/gxui/themes/basic"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/plugin/command"
+	"github.com/nelsam/vidar/plugin/gocover"
+)
+
+// Bindables is the main entry point to the command.
+func Bindables(cmdr command.Commander, driver gxui.Driver, theme gxui.Theme) []bind.Bindable {
+	status, _ := cmdr.(gocover.StatusShower)
+	overlay := gocover.NewOverlay(driver, theme, status)
+	return []bind.Bindable{
+		gocover.NewCoverage(theme, overlay),
+		gocover.NewLoadProfile(driver, theme.(*basic.Theme), overlay),
+		gocover.NewToggle(theme, overlay),
+		gocover.Hook{Overlay: overlay},
+	}
+}
@@ -0,0 +1,233 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package gocover
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/navigator"
+	"github.com/nelsam/vidar/plugin/status"
+)
+
+// owner is the name that coverage annotations are set under in the
+// project tree, and that coverage layers are set under in editors.
+const owner = "coverage"
+
+// StatusShower is a type that can display a status to the user
+// outside of a command.
+type StatusShower interface {
+	ShowStatus(gxui.Control)
+}
+
+// Annotator is a type that can display annotations beside files and
+// directories, like the project tree.
+type Annotator interface {
+	SetAnnotations(owner string, annotations map[string]navigator.Annotation)
+}
+
+// Overlay paints coverage over the text of open editors and displays
+// per-file and per-package percentages in the project tree.
+type Overlay struct {
+	driver gxui.Driver
+	theme  gxui.Theme
+	status StatusShower
+
+	mu        sync.Mutex
+	visible   bool
+	files     map[string]*fileCoverage
+	editors   map[string]input.Editor
+	annotator Annotator
+}
+
+// NewOverlay returns an empty *Overlay.  If status is non-nil,
+// errors from background work are reported using it.
+func NewOverlay(driver gxui.Driver, theme gxui.Theme, status StatusShower) *Overlay {
+	return &Overlay{
+		driver:  driver,
+		theme:   theme,
+		status:  status,
+		files:   make(map[string]*fileCoverage),
+		editors: make(map[string]input.Editor),
+	}
+}
+
+// Set replaces the coverage in o with files, keyed by path, and
+// shows it.
+func (o *Overlay) Set(a Annotator, files map[string][]Block) {
+	o.mu.Lock()
+	o.visible = true
+	o.annotator = a
+	o.files = make(map[string]*fileCoverage, len(files))
+	for path, blocks := range files {
+		o.files[path] = newFileCoverage(blocks)
+	}
+	o.mu.Unlock()
+	o.refresh()
+}
+
+// Toggle shows or hides o's coverage, returning whether it is
+// visible after the toggle.
+func (o *Overlay) Toggle(a Annotator) bool {
+	o.mu.Lock()
+	o.visible = !o.visible
+	o.annotator = a
+	visible := o.visible
+	o.mu.Unlock()
+	o.refresh()
+	return visible
+}
+
+// Empty returns whether o has no coverage to display.
+func (o *Overlay) Empty() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.files) == 0
+}
+
+// report displays msg as an error, if o has a StatusShower.
+func (o *Overlay) report(msg string) {
+	if o.status == nil {
+		return
+	}
+	o.driver.Call(func() {
+		s := status.General{Theme: o.theme, Err: msg}
+		o.status.ShowStatus(s.Status())
+	})
+}
+
+// refresh repaints every open editor and the project tree.
+func (o *Overlay) refresh() {
+	o.driver.Call(func() {
+		o.mu.Lock()
+		editors := make([]input.Editor, 0, len(o.editors))
+		for _, e := range o.editors {
+			editors = append(editors, e)
+		}
+		o.mu.Unlock()
+		for _, e := range editors {
+			o.paint(e)
+		}
+		o.annotate()
+	})
+}
+
+// annotate sets the percentages in the project tree.
+func (o *Overlay) annotate() {
+	o.mu.Lock()
+	a := o.annotator
+	var notes map[string]navigator.Annotation
+	if o.visible {
+		notes = annotations(o.files)
+	}
+	o.mu.Unlock()
+	if a != nil {
+		a.SetAnnotations(owner, notes)
+	}
+}
+
+func annotations(files map[string]*fileCoverage) map[string]navigator.Annotation {
+	notes := make(map[string]navigator.Annotation)
+	dirs := make(map[string][]Block)
+	for path, f := range files {
+		dir := filepath.Dir(path)
+		dirs[dir] = append(dirs[dir], f.blocks...)
+		if !f.hasStmt {
+			continue
+		}
+		notes[path] = annotation(f.percent)
+	}
+	for dir, blocks := range dirs {
+		if percent, ok := Percent(blocks); ok {
+			notes[dir] = annotation(percent)
+		}
+	}
+	return notes
+}
+
+func annotation(percent float64) navigator.Annotation {
+	color := status.ColorErr
+	switch {
+	case percent >= 80:
+		color = status.ColorInfo
+	case percent >= 50:
+		color = status.ColorWarn
+	}
+	return navigator.Annotation{Text: fmt.Sprintf("%.1f%%", percent), Color: color}
+}
+
+// paint replaces the coverage layers in e.  It must be called on the
+// UI goroutine.
+func (o *Overlay) paint(e input.Editor) {
+	l, ok := e.(input.Layerer)
+	if !ok {
+		return
+	}
+	var layers []input.SyntaxLayer
+	o.mu.Lock()
+	f, ok := o.files[e.Filepath()]
+	if ok && o.visible {
+		f.load(input.Snapshot(e))
+		layers = f.layers()
+	}
+	o.mu.Unlock()
+	l.SetOwnedLayers(owner, layers)
+}
+
+// Hook keeps an Overlay's coverage painted in each editor, shifting
+// it as text is edited and clearing it once a file no longer
+// resembles the code that was tested.
+type Hook struct {
+	Overlay *Overlay
+}
+
+func (h Hook) Name() string {
+	return "coverage-overlay"
+}
+
+func (h Hook) OpName() string {
+	return "input-handler"
+}
+
+func (h Hook) Init(e input.Editor, _ []rune) {
+	o := h.Overlay
+	o.mu.Lock()
+	o.editors[e.Filepath()] = e
+	o.mu.Unlock()
+	o.driver.Call(func() { o.paint(e) })
+}
+
+func (h Hook) TextChanged(input.Editor, input.Edit) {
+}
+
+func (h Hook) Apply(input.Editor) error {
+	return nil
+}
+
+func (h Hook) Applied(e input.Editor, edits []input.Edit) {
+	o := h.Overlay
+	path := e.Filepath()
+	o.mu.Lock()
+	f, ok := o.files[path]
+	if !ok {
+		o.mu.Unlock()
+		return
+	}
+	if f.moved(input.Snapshot(e), edits) {
+		show := o.visible && f.loaded
+		o.mu.Unlock()
+		if show {
+			o.paint(e)
+		}
+		return
+	}
+	delete(o.files, path)
+	o.mu.Unlock()
+	o.paint(e)
+	o.annotate()
+}
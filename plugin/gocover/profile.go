@@ -0,0 +1,266 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package gocover contains commands for displaying test coverage in
+// the editor and project tree.
+package gocover
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Block is a block of statements in a coverage profile.  Lines and
+// columns are 1-based, and columns count bytes, the way `go tool
+// cover` writes them.
+type Block struct {
+	StartLine, StartCol int
+	EndLine, EndCol     int
+	NumStmt             int
+	Count               int
+}
+
+// Covered returns whether b ran at least once.
+func (b Block) Covered() bool {
+	return b.Count > 0
+}
+
+// Profile is a parsed coverage profile.  Files is keyed by the file
+// names in the profile, which are import paths followed by a file
+// name.
+type Profile struct {
+	Mode  string
+	Files map[string][]Block
+}
+
+// ParseProfile parses a profile written by `go test -coverprofile`.
+// Blocks that appear more than once (e.g. from tests in several
+// packages) are merged.
+func ParseProfile(r io.Reader) (Profile, error) {
+	p := Profile{Files: make(map[string][]Block)}
+	type key struct {
+		file                                 string
+		startLine, startCol, endLine, endCol int
+	}
+	index := make(map[key]int)
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if line == 1 && strings.HasPrefix(text, "mode: ") {
+			p.Mode = strings.TrimPrefix(text, "mode: ")
+			continue
+		}
+		file, b, err := parseBlock(text)
+		if err != nil {
+			return Profile{}, fmt.Errorf("line %d: %s", line, err)
+		}
+		k := key{file, b.StartLine, b.StartCol, b.EndLine, b.EndCol}
+		if i, ok := index[k]; ok {
+			p.Files[file][i].Count += b.Count
+			continue
+		}
+		index[k] = len(p.Files[file])
+		p.Files[file] = append(p.Files[file], b)
+	}
+	if err := scanner.Err(); err != nil {
+		return Profile{}, err
+	}
+	for _, blocks := range p.Files {
+		sort.Slice(blocks, func(i, j int) bool {
+			if blocks[i].StartLine != blocks[j].StartLine {
+				return blocks[i].StartLine < blocks[j].StartLine
+			}
+			return blocks[i].StartCol < blocks[j].StartCol
+		})
+	}
+	return p, nil
+}
+
+// parseBlock parses a line in the form
+// "name.go:line.col,line.col numStmt count".
+func parseBlock(text string) (string, Block, error) {
+	colon := strings.LastIndex(text, ":")
+	if colon < 0 {
+		return "", Block{}, errors.New("missing file name")
+	}
+	fields := strings.Fields(text[colon+1:])
+	if len(fields) != 3 {
+		return "", Block{}, fmt.Errorf("expected 3 fields after the file name, got %d", len(fields))
+	}
+	var pos [4]int
+	positions := strings.FieldsFunc(fields[0], func(r rune) bool { return r == '.' || r == ',' })
+	if len(positions) != 4 {
+		return "", Block{}, fmt.Errorf("malformed position %q", fields[0])
+	}
+	for i, s := range positions {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return "", Block{}, fmt.Errorf("malformed position %q", fields[0])
+		}
+		pos[i] = n
+	}
+	stmts, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return "", Block{}, fmt.Errorf("malformed statement count %q", fields[1])
+	}
+	count, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return "", Block{}, fmt.Errorf("malformed count %q", fields[2])
+	}
+	return text[:colon], Block{
+		StartLine: pos[0],
+		StartCol:  pos[1],
+		EndLine:   pos[2],
+		EndCol:    pos[3],
+		NumStmt:   stmts,
+		Count:     count,
+	}, nil
+}
+
+// Percent returns the percentage of statements in blocks that were
+// covered.  It returns false if blocks contain no statements.
+func Percent(blocks []Block) (float64, bool) {
+	var covered, total int
+	for _, b := range blocks {
+		total += b.NumStmt
+		if b.Covered() {
+			covered += b.NumStmt
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return 100 * float64(covered) / float64(total), true
+}
+
+// Load reads the profile at path and resolves its file names to
+// paths on disk using `go list` in dir.
+func Load(ctx context.Context, env []string, dir, path string) (map[string][]Block, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p, err := ParseProfile(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return Resolve(ctx, env, dir, p)
+}
+
+// Resolve returns the blocks in p keyed by the path of each file on
+// disk.  Packages are looked up with `go list` in dir; files in
+// packages that can't be found are left out.
+func Resolve(ctx context.Context, env []string, dir string, p Profile) (map[string][]Block, error) {
+	files := make(map[string][]Block, len(p.Files))
+	pkgSet := make(map[string]bool)
+	for name, blocks := range p.Files {
+		if abs, ok := localPath(name); ok {
+			files[abs] = blocks
+			continue
+		}
+		pkgSet[path.Dir(name)] = true
+	}
+	if len(pkgSet) == 0 {
+		return files, nil
+	}
+	var pkgs []string
+	for pkg := range pkgSet {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	args := append([]string{"list", "-e", "-f", "{{.ImportPath}}\t{{.Dir}}"}, pkgs...)
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	errBuffer := &bytes.Buffer{}
+	cmd.Stderr = errBuffer
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(errBuffer.String()); msg != "" {
+			return nil, errors.New(msg)
+		}
+		return nil, err
+	}
+	dirs := ParseList(out)
+	for name, blocks := range p.Files {
+		if _, ok := localPath(name); ok {
+			continue
+		}
+		pkgDir, ok := dirs[path.Dir(name)]
+		if !ok {
+			continue
+		}
+		files[filepath.Join(pkgDir, path.Base(name))] = blocks
+	}
+	return files, nil
+}
+
+// ParseList parses the output of `go list -f
+// '{{.ImportPath}}\t{{.Dir}}'` into a map of import paths to
+// directories.
+func ParseList(out []byte) map[string]string {
+	dirs := make(map[string]string)
+	for _, line := range strings.Split(string(out), "\n") {
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			continue
+		}
+		dirs[parts[0]] = parts[1]
+	}
+	return dirs
+}
+
+// localPath returns the path on disk of a file name that go test
+// wrote as a path rather than an import path.  Packages outside of
+// GOPATH and modules are written as the directory prefixed with an
+// underscore.
+func localPath(name string) (string, bool) {
+	if strings.HasPrefix(name, "_") {
+		name = name[1:]
+	}
+	if !filepath.IsAbs(name) {
+		return "", false
+	}
+	return name, true
+}
+
+// Run runs `go test -coverprofile` for the package in dir, writing
+// the profile to out.  Tests may fail and still produce a profile,
+// so a non-nil error is only returned when no profile was written.
+func Run(ctx context.Context, env []string, dir, out string) error {
+	cmd := exec.CommandContext(ctx, "go", "test", "-coverprofile="+out, ".")
+	cmd.Dir = dir
+	cmd.Env = env
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if info, statErr := os.Stat(out); statErr == nil && info.Size() > 0 {
+		return nil
+	}
+	if msg := strings.TrimSpace(string(output)); msg != "" {
+		return errors.New(msg)
+	}
+	return err
+}
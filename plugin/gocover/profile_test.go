@@ -0,0 +1,92 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package gocover_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/piece"
+	"github.com/nelsam/vidar/plugin/gocover"
+)
+
+func TestProfile(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) expect.Expectation {
+		return expect.New(t)
+	})
+
+	o.Spec("it parses blocks and merges duplicates", func(expect expect.Expectation) {
+		profile := "mode: set\n" +
+			"example.com/foo/foo.go:5.13,7.2 1 1\n" +
+			"example.com/foo/foo.go:3.14,4.3 2 0\n" +
+			"example.com/foo/foo.go:5.13,7.2 1 0\n" +
+			"_/tmp/bar/bar.go:1.1,2.2 3 0\n"
+		p, err := gocover.ParseProfile(strings.NewReader(profile))
+		expect(err).To(Not(HaveOccurred()))
+		expect(p.Mode).To(Equal("set"))
+		expect(p.Files["example.com/foo/foo.go"]).To(Equal([]gocover.Block{
+			{StartLine: 3, StartCol: 14, EndLine: 4, EndCol: 3, NumStmt: 2, Count: 0},
+			{StartLine: 5, StartCol: 13, EndLine: 7, EndCol: 2, NumStmt: 1, Count: 1},
+		}))
+		expect(p.Files["_/tmp/bar/bar.go"]).To(HaveLen(1))
+	})
+
+	o.Spec("it reports malformed lines", func(expect expect.Expectation) {
+		_, err := gocover.ParseProfile(strings.NewReader("mode: set\nfoo.go:5.13,7 1 1\n"))
+		expect(err).To(HaveOccurred())
+		expect(err.Error()).To(ContainSubstring("line 2"))
+	})
+
+	o.Spec("it calculates the percentage of covered statements", func(expect expect.Expectation) {
+		percent, ok := gocover.Percent([]gocover.Block{
+			{NumStmt: 3, Count: 2},
+			{NumStmt: 1, Count: 0},
+		})
+		expect(ok).To(BeTrue())
+		expect(percent).To(Equal(75.0))
+
+		_, ok = gocover.Percent(nil)
+		expect(ok).To(BeFalse())
+	})
+
+	o.Spec("it parses go list output", func(expect expect.Expectation) {
+		dirs := gocover.ParseList([]byte("example.com/foo\t/src/foo\nexample.com/missing\t\n"))
+		expect(dirs).To(Equal(map[string]string{"example.com/foo": "/src/foo"}))
+	})
+}
+
+func TestSpans(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) expect.Expectation {
+		return expect.New(t)
+	})
+
+	o.Spec("it converts byte columns to rune offsets", func(expect expect.Expectation) {
+		text := piece.New([]rune("func f() {\n\ts := \"é\"; x()\n}\n"))
+		covered, uncovered := gocover.Spans(text, []gocover.Block{
+			{StartLine: 1, StartCol: 10, EndLine: 2, EndCol: 11, NumStmt: 1, Count: 1},
+			{StartLine: 2, StartCol: 13, EndLine: 3, EndCol: 2, NumStmt: 1, Count: 0},
+		})
+		expect(covered).To(Equal([]input.Span{{Start: 9, End: 20}}))
+		expect(uncovered).To(Equal([]input.Span{{Start: 22, End: 27}}))
+	})
+
+	o.Spec("it clamps blocks that run past the end of the text", func(expect expect.Expectation) {
+		text := piece.New([]rune("a\nb"))
+		covered, _ := gocover.Spans(text, []gocover.Block{
+			{StartLine: 2, StartCol: 1, EndLine: 9, EndCol: 1, NumStmt: 1, Count: 1},
+		})
+		expect(covered).To(Equal([]input.Span{{Start: 2, End: 3}}))
+	})
+}
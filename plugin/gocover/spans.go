@@ -0,0 +1,208 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package gocover
+
+import (
+	"unicode/utf8"
+
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/piece"
+	"github.com/nelsam/vidar/theme"
+)
+
+const (
+	// divergenceLimit is the fraction of a file's lines that may be
+	// edited before its coverage is cleared, since it no longer
+	// describes the file.
+	divergenceLimit = 0.25
+
+	// minDivergence is the number of lines that may always be
+	// edited, so that small files don't lose their coverage after a
+	// couple of edits.
+	minDivergence = 10
+)
+
+// Spans converts blocks to spans of text, split by whether the
+// blocks were covered.  Blocks that don't fit in text are clamped to
+// its end.
+func Spans(text *piece.Table, blocks []Block) (covered, uncovered []input.Span) {
+	for _, b := range blocks {
+		s := input.Span{
+			Start: offset(text, b.StartLine, b.StartCol),
+			End:   offset(text, b.EndLine, b.EndCol),
+		}
+		if s.End <= s.Start {
+			continue
+		}
+		if b.Covered() {
+			covered = append(covered, s)
+			continue
+		}
+		uncovered = append(uncovered, s)
+	}
+	return covered, uncovered
+}
+
+// offset returns the rune offset in text of a 1-based line and byte
+// column.
+func offset(text *piece.Table, line, col int) int {
+	if line < 1 {
+		return 0
+	}
+	start, ok := text.LineStart(line - 1)
+	if !ok {
+		return text.Len()
+	}
+	end := text.Len()
+	if next, ok := text.LineStart(line); ok {
+		end = next - 1
+	}
+	i := start
+	for _, r := range text.Slice(start, end) {
+		if col <= 1 {
+			break
+		}
+		col -= utf8.RuneLen(r)
+		i++
+	}
+	return i
+}
+
+// fileCoverage is the coverage of a single file, kept in line with
+// the file's text as it is edited.
+type fileCoverage struct {
+	blocks  []Block
+	percent float64
+	hasStmt bool
+
+	// loaded is whether spans have been computed from an editor's
+	// text.  Until then, blocks are still in line with the profile.
+	loaded    bool
+	covered   []input.Span
+	uncovered []input.Span
+
+	lines int
+
+	// edited are the runs of lines that have been edited, each
+	// from the start of its first line to the end of its last.
+	// removed is the number of lines that edits removed.
+	edited  []input.Span
+	removed int
+}
+
+func newFileCoverage(blocks []Block) *fileCoverage {
+	f := &fileCoverage{blocks: blocks}
+	f.percent, f.hasStmt = Percent(blocks)
+	return f
+}
+
+// load computes the spans of f from text, if they haven't been
+// computed already.
+func (f *fileCoverage) load(text *piece.Table) {
+	if f.loaded {
+		return
+	}
+	f.loaded = true
+	f.covered, f.uncovered = Spans(text, f.blocks)
+	f.lines = text.Lines()
+}
+
+// layers returns the syntax layers for f.  The spans are copied,
+// since f's spans are moved in place as its file is edited.
+func (f *fileCoverage) layers() []input.SyntaxLayer {
+	return []input.SyntaxLayer{
+		{Construct: theme.Covered, Spans: append([]input.Span(nil), f.covered...)},
+		{Construct: theme.Uncovered, Spans: append([]input.Span(nil), f.uncovered...)},
+	}
+}
+
+// moved shifts f's spans to account for edits, which have been
+// applied to text, returning false if so many lines have been edited
+// that f's coverage should be cleared.
+func (f *fileCoverage) moved(text *piece.Table, edits []input.Edit) bool {
+	if !f.loaded {
+		return true
+	}
+	for i, s := range f.covered {
+		f.covered[i] = s.Move(edits)
+	}
+	for i, s := range f.uncovered {
+		f.uncovered[i] = s.Move(edits)
+	}
+	for i, s := range f.edited {
+		f.edited[i] = s.Move(edits)
+	}
+	for i, e := range edits {
+		if lost := count(e.Old, '\n') - count(e.New, '\n'); lost > 0 {
+			f.removed += lost
+		}
+		s := input.Span{Start: e.At, End: e.At + len(e.New)}.Move(edits[i+1:])
+		f.edited = addLines(f.edited, lineSpan(text, s))
+	}
+	edited := f.removed
+	for _, s := range f.edited {
+		edited += 1 + text.LineOf(s.End) - text.LineOf(s.Start)
+	}
+	limit := int(float64(f.lines) * divergenceLimit)
+	if limit < minDivergence {
+		limit = minDivergence
+	}
+	return edited <= limit
+}
+
+// lineSpan returns s, extended to the start of its first line and the
+// end of its last line in text.
+func lineSpan(text *piece.Table, s input.Span) input.Span {
+	last := text.LineOf(clamp(s.End, text.Len()))
+	s.Start, _ = text.LineStart(text.LineOf(clamp(s.Start, text.Len())))
+	s.End = text.Len()
+	if next, ok := text.LineStart(last + 1); ok {
+		s.End = next - 1
+	}
+	return s
+}
+
+// addLines adds s to spans, which are sorted, merging the spans that
+// share lines.
+func addLines(spans []input.Span, s input.Span) []input.Span {
+	var merged []input.Span
+	for _, other := range spans {
+		switch {
+		case other.End < s.Start:
+			merged = append(merged, other)
+		case other.Start > s.End:
+			merged = append(merged, s)
+			s = other
+		default:
+			if other.Start < s.Start {
+				s.Start = other.Start
+			}
+			if other.End > s.End {
+				s.End = other.End
+			}
+		}
+	}
+	return append(merged, s)
+}
+
+func clamp(i, max int) int {
+	if i < 0 {
+		return 0
+	}
+	if i > max {
+		return max
+	}
+	return i
+}
+
+func count(runes []rune, r rune) int {
+	n := 0
+	for _, c := range runes {
+		if c == r {
+			n++
+		}
+	}
+	return n
+}
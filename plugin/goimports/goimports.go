@@ -39,7 +39,12 @@ func (o OnSave) OpName() string {
 	return "save-current-file"
 }
 
+// BeforeSave runs goimports on text, unless proj has its own
+// formatters configured for path.
 func (o OnSave) BeforeSave(proj setting.Project, path, text string) (newText string, err error) {
+	if len(proj.Formatters(path)) > 0 {
+		return text, nil
+	}
 	return goimports(path, text, proj.Environ())
 }
 
@@ -13,6 +13,7 @@ import (
 	"github.com/nelsam/gxui/themes/basic"
 	"github.com/nelsam/vidar/commander/bind"
 	"github.com/nelsam/vidar/plugin/comments"
+	"github.com/nelsam/vidar/plugin/goaction"
 	"github.com/nelsam/vidar/plugin/gocode"
 	"github.com/nelsam/vidar/plugin/godef"
 	"github.com/nelsam/vidar/plugin/goimports"
@@ -23,6 +24,11 @@ import (
 type GolangHook struct {
 	Theme  *basic.Theme
 	Driver gxui.Driver
+	Status gosyntax.StatusShower
+
+	// Commander is used to move carets when jumping between
+	// matching brackets.
+	Commander gosyntax.Commander
 }
 
 func (h GolangHook) Name() string {
@@ -37,13 +43,20 @@ func (h GolangHook) FileBindables(path string) []bind.Bindable {
 	if !strings.HasSuffix(path, ".go") {
 		return nil
 	}
-	completions, gocode := gocode.New(h.Theme, h.Driver)
+	completions, gocode := gocode.New(h.Theme, h.Driver, h.Commander)
+	highlight := gosyntax.New(h.Theme, h.Status)
 	return []bind.Bindable{
 		comments.NewToggle(),
+		goaction.Provider{},
+		goaction.NewImplement(h.Theme),
+		goaction.NewExtractFunc(h.Theme),
+		goaction.NewExtractVar(h.Theme),
 		godef.New(h.Theme),
 		goimports.New(h.Theme),
 		goimports.OnSave{},
-		gosyntax.New(),
+		highlight,
+		gosyntax.NewJumpToMatch(highlight, h.Commander),
+		gosyntax.NewSelectToMatch(highlight),
 		license.NewHeaderUpdate(h.Theme),
 		completions,
 		gocode,
@@ -0,0 +1,63 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// +build !linux !go1.8
+
+package plugin
+
+import (
+	"path/filepath"
+
+	"github.com/nelsam/gxui/themes/basic"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/plugin/gomod"
+	"github.com/nelsam/vidar/plugin/gotmpl"
+)
+
+type GoModHook struct {
+	Theme *basic.Theme
+}
+
+func (h GoModHook) Name() string {
+	return "gomod-hook"
+}
+
+func (h GoModHook) OpName() string {
+	return "focus-location"
+}
+
+func (h GoModHook) FileBindables(path string) []bind.Bindable {
+	switch filepath.Base(path) {
+	case "go.mod", "go.work":
+		return []bind.Bindable{
+			gomod.NewHighlight(),
+			gomod.NewSource(h.Theme),
+		}
+	case "go.sum", "go.work.sum":
+		return []bind.Bindable{
+			gomod.NewSumHighlight(),
+		}
+	}
+	return nil
+}
+
+type GoTemplateHook struct {
+}
+
+func (h GoTemplateHook) Name() string {
+	return "gotmpl-hook"
+}
+
+func (h GoTemplateHook) OpName() string {
+	return "focus-location"
+}
+
+func (h GoTemplateHook) FileBindables(path string) []bind.Bindable {
+	if !gotmpl.IsTemplate(path) {
+		return nil
+	}
+	return []bind.Bindable{
+		gotmpl.New(),
+	}
+}
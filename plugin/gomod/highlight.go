@@ -0,0 +1,75 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package gomod
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/syntax/gomod"
+)
+
+// Highlight is a hook that highlights go.mod, go.work and go.sum
+// files.
+type Highlight struct {
+	lex    func(string) []input.SyntaxLayer
+	layers []input.SyntaxLayer
+
+	mu sync.Mutex
+}
+
+// NewHighlight returns a Highlight for go.mod and go.work files.
+func NewHighlight() *Highlight {
+	return &Highlight{lex: func(text string) []input.SyntaxLayer {
+		return gomod.Parse(text).Layers()
+	}}
+}
+
+// NewSumHighlight returns a Highlight for go.sum files.
+func NewSumHighlight() *Highlight {
+	return &Highlight{lex: gomod.SumLayers}
+}
+
+func (h *Highlight) Name() string {
+	return "gomod-syntax-highlight"
+}
+
+func (h *Highlight) OpName() string {
+	return "input-handler"
+}
+
+func (h *Highlight) Applied(e input.Editor, edits []input.Edit) {
+	layers := e.SyntaxLayers()
+	for i, l := range layers {
+		layers[i] = l.Move(edits)
+	}
+	e.SetSyntaxLayers(layers)
+}
+
+func (h *Highlight) Init(e input.Editor, text []rune) {
+	h.TextChanged(context.Background(), e, nil)
+}
+
+func (h *Highlight) TextChanged(ctx context.Context, editor input.Editor, _ []input.Edit) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// The parsers work on the whole file, so this copies the
+	// snapshot.  go.mod, go.work and go.sum files are small.
+	layers := h.lex(input.Snapshot(editor).String())
+	select {
+	case <-ctx.Done():
+		return
+	default:
+	}
+	h.layers = layers
+}
+
+func (h *Highlight) Apply(e input.Editor) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	e.SetSyntaxLayers(h.layers)
+	return nil
+}
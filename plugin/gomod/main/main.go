@@ -0,0 +1,48 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/plugin/command"
+	"github.com/nelsam/vidar/plugin/gomod"
+)
+
+type GoModHook struct {
+	Theme gxui.Theme
+}
+
+func (h GoModHook) Name() string {
+	return "gomod-hook"
+}
+
+func (h GoModHook) OpName() string {
+	return "focus-location"
+}
+
+func (h GoModHook) FileBindables(path string) []bind.Bindable {
+	switch filepath.Base(path) {
+	case "go.mod", "go.work":
+		return []bind.Bindable{
+			gomod.NewHighlight(),
+			gomod.NewSource(h.Theme),
+		}
+	case "go.sum", "go.work.sum":
+		return []bind.Bindable{
+			gomod.NewSumHighlight(),
+		}
+	}
+	return nil
+}
+
+// Bindables is the main entry point to the command.
+func Bindables(cmdr command.Commander, driver gxui.Driver, theme gxui.Theme) []bind.Bindable {
+	return []bind.Bindable{
+		GoModHook{Theme: theme},
+	}
+}
@@ -0,0 +1,191 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package gomod contains plugins for working with go.mod, go.work
+// and go.sum files.
+package gomod
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/command/focus"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/plugin/status"
+	"github.com/nelsam/vidar/setting"
+	"github.com/nelsam/vidar/syntax/gomod"
+)
+
+type Projecter interface {
+	Project() setting.Project
+}
+
+type Commander interface {
+	Execute(bind.Bindable)
+}
+
+type Opener interface {
+	For(...focus.Opt) bind.Bindable
+}
+
+type Editor interface {
+	Filepath() string
+	Text() string
+}
+
+type CursorController interface {
+	LastCaret() int
+}
+
+// Source is a command that opens the source of the module under
+// the caret in a go.mod or go.work file.  Replace directives are
+// followed, so replaced modules open their replacement.
+type Source struct {
+	status.General
+
+	proj   Projecter
+	cmdr   Commander
+	opener Opener
+	editor Editor
+	ctrl   CursorController
+}
+
+func NewSource(theme gxui.Theme) *Source {
+	s := &Source{}
+	s.Theme = theme
+	return s
+}
+
+func (s *Source) Name() string {
+	return "goto-module-source"
+}
+
+func (s *Source) Menu() string {
+	return "Golang"
+}
+
+func (s *Source) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl | gxui.ModShift,
+		Key:      gxui.KeyG,
+	}}
+}
+
+func (s *Source) Reset() {
+	s.proj = nil
+	s.cmdr = nil
+	s.opener = nil
+	s.editor = nil
+	s.ctrl = nil
+}
+
+func (s *Source) Store(target interface{}) bind.Status {
+	switch src := target.(type) {
+	case Projecter:
+		s.proj = src
+	case Commander:
+		s.cmdr = src
+	case Editor:
+		s.editor = src
+	case CursorController:
+		s.ctrl = src
+	case Opener:
+		s.opener = src
+	}
+	if s.proj != nil && s.cmdr != nil && s.opener != nil && s.ctrl != nil && s.editor != nil {
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+func (s *Source) Exec() error {
+	f := gomod.Parse(s.editor.Text())
+	m, ok := f.ModuleAt(s.ctrl.LastCaret())
+	if !ok {
+		s.Warn = "No module path under the caret"
+		return nil
+	}
+	m = f.Resolve(m)
+	var cache string
+	if !m.Local() {
+		var err error
+		cache, err = modCache(s.proj.Project().Environ())
+		if err != nil {
+			s.Err = fmt.Sprintf("Could not find the module cache: %s", err)
+			return err
+		}
+	}
+	dir := gomod.Dir(m, filepath.Dir(s.editor.Filepath()), cache)
+	if dir == "" {
+		s.Warn = fmt.Sprintf("Module %s has no version to look up", m.Path)
+		return nil
+	}
+	path, err := sourceFile(dir)
+	if err != nil {
+		s.Err = fmt.Sprintf("Could not open source for %s (it may need to be downloaded with go mod download): %s", m.Path, err)
+		return err
+	}
+	s.cmdr.Execute(s.opener.For(focus.Path(path)))
+	return nil
+}
+
+// modCache returns the module cache directory for the passed in
+// environment.
+func modCache(environ []string) (string, error) {
+	cmd := exec.Command("go", "env", "GOMODCACHE", "GOPATH")
+	cmd.Env = environ
+	errBuf := &bytes.Buffer{}
+	cmd.Stderr = errBuf
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("go env: %s: %s", err, errBuf.String())
+	}
+	lines := strings.Split(string(out), "\n")
+	if len(lines) < 2 {
+		return "", fmt.Errorf("go env output %q not understood", out)
+	}
+	if cache := strings.TrimSpace(lines[0]); cache != "" {
+		return cache, nil
+	}
+	// Go versions before 1.15 don't have GOMODCACHE; the cache
+	// is always in the first GOPATH entry.
+	gopath := filepath.SplitList(strings.TrimSpace(lines[1]))
+	if len(gopath) == 0 {
+		return "", fmt.Errorf("GOPATH is empty")
+	}
+	return filepath.Join(gopath[0], "pkg", "mod"), nil
+}
+
+// sourceFile returns the file to open for a module's source
+// directory.  The module's go.mod is preferred, but modules that
+// don't have one will open the first go file in dir.
+func sourceFile(dir string) (string, error) {
+	mod := filepath.Join(dir, "go.mod")
+	if _, err := os.Stat(mod); err == nil {
+		return mod, nil
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var names []string
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".go") {
+			continue
+		}
+		names = append(names, info.Name())
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("%s contains no go.mod or go files", dir)
+	}
+	sort.Strings(names)
+	return filepath.Join(dir, names[0]), nil
+}
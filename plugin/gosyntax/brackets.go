@@ -0,0 +1,199 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package gosyntax
+
+import (
+	"fmt"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/syntax"
+	"github.com/nelsam/vidar/theme"
+)
+
+// Commander is a type that can look up and execute bindables.
+type Commander interface {
+	Bindable(name string) bind.Bindable
+	Execute(bind.Bindable)
+}
+
+// Mover is a type that can move carets.
+type Mover interface {
+	To(...int) bind.Bindable
+}
+
+// CaretEditor is an input.Editor that knows where its carets are.
+type CaretEditor interface {
+	input.Editor
+	Carets() []int
+}
+
+// SelectionEditor is a CaretEditor that can select text.
+type SelectionEditor interface {
+	CaretEditor
+	SelectSlice([]gxui.TextSelection)
+}
+
+// matchAt returns the pair of brackets that caret is touching.  A
+// bracket directly after caret takes precedence over one directly
+// before it.
+func matchAt(pairs []syntax.Pair, caret int) (syntax.Pair, bool) {
+	for _, pos := range []int{caret, caret - 1} {
+		for _, p := range pairs {
+			if p.Open == pos || p.Close == pos {
+				return p, true
+			}
+		}
+	}
+	return syntax.Pair{}, false
+}
+
+// movePairs moves pairs to account for edits, dropping any pair that
+// had one of its brackets removed.
+func movePairs(pairs []syntax.Pair, edits []input.Edit) []syntax.Pair {
+	moved := pairs[:0]
+	for _, p := range pairs {
+		open := input.Span{Start: p.Open, End: p.Open + 1}.Move(edits)
+		close := input.Span{Start: p.Close, End: p.Close + 1}.Move(edits)
+		if open.End-open.Start != 1 || close.End-close.Start != 1 {
+			continue
+		}
+		moved = append(moved, syntax.Pair{Open: open.Start, Close: close.Start})
+	}
+	return moved
+}
+
+// highlightMatch replaces the theme.MatchedPair layer in e with a
+// layer for the pairs of brackets that h.carets are touching.  h.mu
+// must be locked by the caller.
+func (h *Highlight) highlightMatch(e input.Editor) {
+	var spans []input.Span
+	for _, c := range h.carets {
+		p, ok := matchAt(h.pairs, c)
+		if !ok {
+			continue
+		}
+		spans = append(spans, input.Span{Start: p.Open, End: p.Open + 1}, input.Span{Start: p.Close, End: p.Close + 1})
+	}
+	var layers []input.SyntaxLayer
+	for _, l := range e.SyntaxLayers() {
+		if l.Construct == theme.MatchedPair {
+			continue
+		}
+		layers = append(layers, l)
+	}
+	if len(spans) > 0 {
+		layers = append(layers, input.SyntaxLayer{Construct: theme.MatchedPair, Spans: spans})
+	}
+	e.SetSyntaxLayers(layers)
+}
+
+// match returns the pair of brackets that caret is touching.
+func (h *Highlight) match(caret int) (syntax.Pair, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return matchAt(h.pairs, caret)
+}
+
+// JumpToMatch is a command that moves each caret that is on a
+// bracket to the bracket that matches it.
+type JumpToMatch struct {
+	highlight *Highlight
+	cmdr      Commander
+}
+
+// NewJumpToMatch returns a *JumpToMatch that uses the brackets that h
+// has found.
+func NewJumpToMatch(h *Highlight, cmdr Commander) *JumpToMatch {
+	return &JumpToMatch{highlight: h, cmdr: cmdr}
+}
+
+func (j *JumpToMatch) Name() string {
+	return "jump-to-matching-bracket"
+}
+
+func (j *JumpToMatch) Menu() string {
+	return "Golang"
+}
+
+func (j *JumpToMatch) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl,
+		Key:      gxui.KeyBackslash,
+	}}
+}
+
+func (j *JumpToMatch) Exec(target interface{}) bind.Status {
+	e, ok := target.(CaretEditor)
+	if !ok {
+		return bind.Waiting
+	}
+	carets := e.Carets()
+	moved := make([]int, 0, len(carets))
+	for _, c := range carets {
+		p, ok := j.highlight.match(c)
+		switch {
+		case !ok:
+			moved = append(moved, c)
+		case c == p.Open || c == p.Open+1:
+			moved = append(moved, p.Close)
+		default:
+			moved = append(moved, p.Open)
+		}
+	}
+	m := j.cmdr.Bindable("caret-movement").(Mover)
+	j.cmdr.Execute(m.To(moved...))
+	return bind.Done
+}
+
+// SelectToMatch is a command that selects everything between each
+// caret's bracket and the bracket that matches it, including the
+// brackets themselves.
+type SelectToMatch struct {
+	highlight *Highlight
+}
+
+// NewSelectToMatch returns a *SelectToMatch that uses the brackets
+// that h has found.
+func NewSelectToMatch(h *Highlight) *SelectToMatch {
+	return &SelectToMatch{highlight: h}
+}
+
+func (s *SelectToMatch) Name() string {
+	return "select-to-matching-bracket"
+}
+
+func (s *SelectToMatch) Menu() string {
+	return "Golang"
+}
+
+func (s *SelectToMatch) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl | gxui.ModShift,
+		Key:      gxui.KeyBackslash,
+	}}
+}
+
+func (s *SelectToMatch) Exec(target interface{}) bind.Status {
+	e, ok := target.(SelectionEditor)
+	if !ok {
+		return bind.Waiting
+	}
+	var selections []gxui.TextSelection
+	for _, c := range e.Carets() {
+		p, ok := s.highlight.match(c)
+		if !ok {
+			selections = append(selections, gxui.CreateTextSelection(c, c, false))
+			continue
+		}
+		// Leave the caret at the end of the selection closest to
+		// where it started.
+		atStart := c <= p.Open+1
+		selections = append(selections, gxui.CreateTextSelection(p.Open, p.Close+1, atStart))
+	}
+	e.SelectSlice(selections)
+	return bind.Done
+}
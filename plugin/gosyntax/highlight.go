@@ -6,69 +6,92 @@ package gosyntax
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
+	"github.com/nelsam/gxui"
 	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/plugin/status"
 	"github.com/nelsam/vidar/syntax"
 )
 
+// StatusShower is a type that can display a status to the user
+// outside of a command.
+type StatusShower interface {
+	ShowStatus(gxui.Control)
+}
+
+// Folder is a type that can fold regions of text.
+type Folder interface {
+	FoldRegions() []input.FoldRegion
+	SetFoldRegions([]input.FoldRegion)
+}
+
 type Highlight struct {
 	ctx    context.Context
 	layers []input.SyntaxLayer
-	syntax *syntax.Syntax
+	folds  []input.FoldRegion
+
+	theme  gxui.Theme
+	status StatusShower
+
+	// errs is the list of errors from the most recent parse, and
+	// applied is the list of errors that are currently being
+	// displayed in the editor.
+	errs    []syntax.Error
+	applied []syntax.Error
+	current int
 
+	// parsed is the list of bracket pairs from the most recent
+	// parse, and pairs is the list that matches the editor's text.
+	parsed []syntax.Pair
+	pairs  []syntax.Pair
+	carets []int
+
+	// mu guards the fields above.  It's held on the UI goroutine,
+	// so it is never held while parsing.
 	mu sync.Mutex
 }
 
-func New() *Highlight {
-	return &Highlight{syntax: syntax.New()}
+// New returns a new *Highlight.  If status is non-nil, syntax
+// errors will be reported using it.
+func New(theme gxui.Theme, status StatusShower) *Highlight {
+	return &Highlight{
+		theme:   theme,
+		status:  status,
+		current: -1,
+	}
 }
 
 func (h *Highlight) Name() string {
 	return "go-syntax-highlight"
 }
 
-func (h *Highlight) OpName() string {
-	return "input-handler"
+func (h *Highlight) OpNames() []string {
+	return []string{"input-handler", "caret-movement"}
 }
 
 func (h *Highlight) Applied(e input.Editor, edits []input.Edit) {
 	layers := e.SyntaxLayers()
 	for i, l := range layers {
-		layers[i] = h.moveLayer(l, edits)
+		layers[i] = l.Move(edits)
 	}
 	e.SetSyntaxLayers(layers)
-}
 
-func (h *Highlight) moveLayer(l input.SyntaxLayer, edits []input.Edit) input.SyntaxLayer {
-	for i, s := range l.Spans {
-		l.Spans[i] = h.moveSpan(s, edits)
+	if f, ok := e.(Folder); ok {
+		folds := f.FoldRegions()
+		for i, r := range folds {
+			folds[i] = r.Move(edits)
+		}
+		f.SetFoldRegions(folds)
 	}
-	return l
-}
 
-func (h *Highlight) moveSpan(s input.Span, edits []input.Edit) input.Span {
-	for _, e := range edits {
-		if e.At > s.End {
-			return s
-		}
-		delta := len(e.New) - len(e.Old)
-		if delta == 0 {
-			continue
-		}
-		s.End += delta
-		if s.End < e.At {
-			s.End = e.At
-		}
-		if e.At > s.Start {
-			continue
-		}
-		s.Start += delta
-		if s.Start < e.At {
-			s.Start = e.At
-		}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, err := range h.applied {
+		h.applied[i].Span = err.Span.Move(edits)
 	}
-	return s
+	h.pairs = movePairs(h.pairs, edits)
 }
 
 func (h *Highlight) Init(e input.Editor, text []rune) {
@@ -76,26 +99,93 @@ func (h *Highlight) Init(e input.Editor, text []rune) {
 }
 
 func (h *Highlight) TextChanged(ctx context.Context, editor input.Editor, _ []input.Edit) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
 	// TODO: only update layers that changed.
-	err := h.syntax.Parse(editor.Text())
-	if err != nil {
-		// TODO: Report the error in the UI
-		_ = err
-	}
+	//
+	// go/parser needs the whole file in one piece, so this copies
+	// the snapshot.  It's only done in the background, after the
+	// edit has been applied.
+	s := syntax.New()
+	s.Parse(input.Snapshot(editor).String())
 	select {
 	case <-ctx.Done():
 		return
 	default:
 	}
+	layers, folds, errs, pairs := s.Layers(), s.Folds(), s.Errors(), s.Pairs()
 
-	h.layers = h.syntax.Layers()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.layers = layers
+	h.folds = folds
+	h.errs = errs
+	h.parsed = pairs
 }
 
 func (h *Highlight) Apply(e input.Editor) error {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	e.SetSyntaxLayers(h.layers)
+	if f, ok := e.(Folder); ok {
+		f.SetFoldRegions(h.folds)
+	}
+	if len(h.errs) != len(h.applied) {
+		h.show(countStatus(len(h.errs)))
+	}
+	h.applied = append([]syntax.Error(nil), h.errs...)
+	h.current = -1
+	h.pairs = append([]syntax.Pair(nil), h.parsed...)
+	h.highlightMatch(e)
 	return nil
 }
+
+// Moved highlights the brackets that the carets are on and displays
+// the message for the syntax error under the caret, if there is one.
+func (h *Highlight) Moved(e input.Editor, carets []int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.carets = append(h.carets[:0], carets...)
+	h.highlightMatch(e)
+
+	idx := -1
+	for i, err := range h.applied {
+		for _, c := range carets {
+			if err.Span.Start <= c && c <= err.Span.End {
+				idx = i
+				break
+			}
+		}
+		if idx >= 0 {
+			break
+		}
+	}
+	if idx == h.current {
+		return
+	}
+	h.current = idx
+	if idx < 0 {
+		h.show(countStatus(len(h.applied)))
+		return
+	}
+	err := h.applied[idx]
+	h.show(fmt.Sprintf("%d:%d: %s (%d of %d syntax errors)", err.Line, err.Column, err.Msg, idx+1, len(h.applied)))
+}
+
+func countStatus(count int) string {
+	switch count {
+	case 0:
+		return ""
+	case 1:
+		return "1 syntax error"
+	default:
+		return fmt.Sprintf("%d syntax errors", count)
+	}
+}
+
+// show displays msg as an error.  An empty msg clears the status.
+func (h *Highlight) show(msg string) {
+	if h.status == nil {
+		return
+	}
+	s := status.General{Theme: h.theme, Err: msg}
+	h.status.ShowStatus(s.Status())
+}
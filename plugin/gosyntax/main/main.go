@@ -14,6 +14,12 @@ import (
 )
 
 type GolangHook struct {
+	Theme  gxui.Theme
+	Status gosyntax.StatusShower
+
+	// Commander is used to move carets when jumping between
+	// matching brackets.
+	Commander gosyntax.Commander
 }
 
 func (h GolangHook) Name() string {
@@ -28,14 +34,18 @@ func (h GolangHook) FileBindables(path string) []bind.Bindable {
 	if !strings.HasSuffix(path, ".go") {
 		return nil
 	}
+	highlight := gosyntax.New(h.Theme, h.Status)
 	return []bind.Bindable{
-		gosyntax.New(),
+		highlight,
+		gosyntax.NewJumpToMatch(highlight, h.Commander),
+		gosyntax.NewSelectToMatch(highlight),
 	}
 }
 
 // Bindables is the main entry point to the command.
 func Bindables(cmdr command.Commander, driver gxui.Driver, theme gxui.Theme) []bind.Bindable {
+	status, _ := cmdr.(gosyntax.StatusShower)
 	return []bind.Bindable{
-		GolangHook{},
+		GolangHook{Theme: theme, Status: status, Commander: cmdr},
 	}
 }
@@ -0,0 +1,291 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package gotest
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/plugin/status"
+	"github.com/nelsam/vidar/setting"
+)
+
+type Projecter interface {
+	Project() setting.Project
+}
+
+type Editor interface {
+	Filepath() string
+	Text() string
+}
+
+type CursorController interface {
+	LastCaret() int
+}
+
+// Navigator is a type that can display a pane's frame.
+type Navigator interface {
+	ShowNavPane(gxui.Control)
+}
+
+// runner contains the logic shared between the test commands.
+type runner struct {
+	status.General
+
+	pane   *Pane
+	proj   Projecter
+	editor Editor
+	nav    Navigator
+}
+
+func (r *runner) Menu() string {
+	return "Golang"
+}
+
+func (r *runner) reset() {
+	r.proj = nil
+	r.editor = nil
+	r.nav = nil
+}
+
+func (r *runner) store(target interface{}) {
+	switch src := target.(type) {
+	case Projecter:
+		r.proj = src
+	case Editor:
+		r.editor = src
+	case Navigator:
+		r.nav = src
+	}
+}
+
+func (r *runner) ready() bool {
+	return r.proj != nil && r.editor != nil && r.nav != nil
+}
+
+func (r *runner) run(dir, title string, args ...string) {
+	r.pane.Run(r.proj.Project().Environ(), dir, title, args)
+	r.nav.ShowNavPane(r.pane.Frame())
+	r.Info = fmt.Sprintf("Running %s", title)
+}
+
+// TestAtCaret is a command that runs the test function that the
+// caret is in.
+type TestAtCaret struct {
+	runner
+
+	ctrl CursorController
+}
+
+// NewTestAtCaret returns a *TestAtCaret that displays its results
+// in pane.
+func NewTestAtCaret(theme gxui.Theme, pane *Pane) *TestAtCaret {
+	t := &TestAtCaret{}
+	t.Theme = theme
+	t.pane = pane
+	return t
+}
+
+func (t *TestAtCaret) Name() string {
+	return "go-test-at-caret"
+}
+
+func (t *TestAtCaret) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl | gxui.ModShift,
+		Key:      gxui.KeyT,
+	}}
+}
+
+func (t *TestAtCaret) Reset() {
+	t.reset()
+	t.ctrl = nil
+}
+
+func (t *TestAtCaret) Store(target interface{}) bind.Status {
+	t.store(target)
+	if ctrl, ok := target.(CursorController); ok {
+		t.ctrl = ctrl
+	}
+	if t.ready() && t.ctrl != nil {
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+func (t *TestAtCaret) Exec() error {
+	text := t.editor.Text()
+	runes := []rune(text)
+	caret := t.ctrl.LastCaret()
+	if caret > len(runes) {
+		caret = len(runes)
+	}
+	name, ok := TestAt(text, len(string(runes[:caret])))
+	if !ok {
+		t.Warn = "No test function under the caret"
+		return nil
+	}
+	t.run(filepath.Dir(t.editor.Filepath()), name, append(RunArgs(name), ".")...)
+	return nil
+}
+
+// TestPackage is a command that runs the tests in the current file's
+// package.
+type TestPackage struct {
+	runner
+}
+
+// NewTestPackage returns a *TestPackage that displays its results
+// in pane.
+func NewTestPackage(theme gxui.Theme, pane *Pane) *TestPackage {
+	t := &TestPackage{}
+	t.Theme = theme
+	t.pane = pane
+	return t
+}
+
+func (t *TestPackage) Name() string {
+	return "go-test-package"
+}
+
+func (t *TestPackage) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl | gxui.ModAlt,
+		Key:      gxui.KeyT,
+	}}
+}
+
+func (t *TestPackage) Reset() {
+	t.reset()
+}
+
+func (t *TestPackage) Store(target interface{}) bind.Status {
+	t.store(target)
+	if t.ready() {
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+func (t *TestPackage) Exec() error {
+	path := t.editor.Filepath()
+	if path == "" {
+		t.Warn = "No file is open"
+		return nil
+	}
+	dir := filepath.Dir(path)
+	t.run(dir, filepath.Base(dir), ".")
+	return nil
+}
+
+// TestModule is a command that runs all of the tests in the current
+// file's module.  If the file is not in a module, the tests in the
+// project are run instead.
+type TestModule struct {
+	runner
+}
+
+// NewTestModule returns a *TestModule that displays its results in
+// pane.
+func NewTestModule(theme gxui.Theme, pane *Pane) *TestModule {
+	t := &TestModule{}
+	t.Theme = theme
+	t.pane = pane
+	return t
+}
+
+func (t *TestModule) Name() string {
+	return "go-test-module"
+}
+
+func (t *TestModule) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl | gxui.ModAlt | gxui.ModShift,
+		Key:      gxui.KeyT,
+	}}
+}
+
+func (t *TestModule) Reset() {
+	t.reset()
+}
+
+func (t *TestModule) Store(target interface{}) bind.Status {
+	t.store(target)
+	if t.ready() {
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+func (t *TestModule) Exec() error {
+	var dir string
+	if path := t.editor.Filepath(); path != "" {
+		dir = ModuleRoot(filepath.Dir(path))
+	}
+	if dir == "" {
+		dir = t.proj.Project().Path
+	}
+	if dir == "" {
+		t.Warn = "No module or project to test"
+		return nil
+	}
+	t.run(dir, filepath.Base(dir)+"/...", "./...")
+	return nil
+}
+
+// RerunFailed is a command that runs the tests that failed in the
+// most recent test run again.
+type RerunFailed struct {
+	status.General
+
+	pane *Pane
+	nav  Navigator
+}
+
+// NewRerunFailed returns a *RerunFailed that re-runs the failed
+// tests in pane.
+func NewRerunFailed(theme gxui.Theme, pane *Pane) *RerunFailed {
+	r := &RerunFailed{pane: pane}
+	r.Theme = theme
+	return r
+}
+
+func (r *RerunFailed) Name() string {
+	return "go-test-rerun-failed"
+}
+
+func (r *RerunFailed) Menu() string {
+	return "Golang"
+}
+
+func (r *RerunFailed) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Key: gxui.KeyF8,
+	}}
+}
+
+func (r *RerunFailed) Reset() {
+	r.nav = nil
+}
+
+func (r *RerunFailed) Store(target interface{}) bind.Status {
+	if nav, ok := target.(Navigator); ok {
+		r.nav = nav
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+func (r *RerunFailed) Exec() error {
+	if !r.pane.RerunFailed() {
+		r.Info = "No failed tests to re-run"
+		return nil
+	}
+	r.nav.ShowNavPane(r.pane.Frame())
+	r.Info = "Re-running failed tests"
+	return nil
+}
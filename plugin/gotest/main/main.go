@@ -0,0 +1,23 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package main
+
+import (
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/plugin/command"
+	"github.com/nelsam/vidar/plugin/gotest"
+)
+
+// Bindables is the main entry point to the command.
+func Bindables(cmdr command.Commander, driver gxui.Driver, theme gxui.Theme) []bind.Bindable {
+	pane := gotest.NewPane(cmdr, driver, theme)
+	return []bind.Bindable{
+		gotest.NewTestAtCaret(theme, pane),
+		gotest.NewTestPackage(theme, pane),
+		gotest.NewTestModule(theme, pane),
+		gotest.NewRerunFailed(theme, pane),
+	}
+}
@@ -0,0 +1,329 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package gotest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/gxui/math"
+	"github.com/nelsam/gxui/mixins"
+	"github.com/nelsam/gxui/themes/basic"
+	"github.com/nelsam/vidar/command/focus"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/plugin/status"
+)
+
+var runningColor = gxui.Color{
+	R: 0.6,
+	G: 0.8,
+	B: 1,
+	A: 1,
+}
+
+// Commander is a type that can look up and execute bindables.
+type Commander interface {
+	Bindable(name string) bind.Bindable
+	Execute(bind.Bindable)
+}
+
+// Opener is a type that can open locations in the code.
+type Opener interface {
+	For(...focus.Opt) bind.Bindable
+}
+
+// row is a clickable line in the results tree.
+type row struct {
+	mixins.Button
+}
+
+func newRow(theme *basic.Theme, depth int) *row {
+	r := &row{}
+	r.Init(r, theme)
+	r.SetMargin(math.Spacing{L: 3 + depth*10})
+	r.SetPadding(math.Spacing{L: 1, R: 1, T: 1, B: 1})
+	return r
+}
+
+// Pane displays the results of the most recent test run as a tree
+// of packages, tests and subtests.  Clicking a package or test shows
+// its output, and clicking a failure opens its location.
+type Pane struct {
+	cmdr   Commander
+	driver gxui.Driver
+	theme  *basic.Theme
+
+	frame  gxui.LinearLayout
+	header gxui.Label
+	tree   gxui.LinearLayout
+
+	mu       sync.Mutex
+	title    string
+	env      []string
+	dir      string
+	results  *Results
+	err      error
+	done     bool
+	cancel   func()
+	rows     map[*Node]*row
+	expanded map[*Node]bool
+	pending  bool
+}
+
+// NewPane creates an empty *Pane.
+func NewPane(cmdr Commander, driver gxui.Driver, theme gxui.Theme) *Pane {
+	p := &Pane{
+		cmdr:   cmdr,
+		driver: driver,
+		theme:  theme.(*basic.Theme),
+		frame:  theme.CreateLinearLayout(),
+		header: theme.CreateLabel(),
+		tree:   theme.CreateLinearLayout(),
+	}
+	p.frame.SetDirection(gxui.TopToBottom)
+	p.tree.SetDirection(gxui.TopToBottom)
+	p.header.SetText("No tests have been run")
+	p.frame.AddChild(p.header)
+	scrollable := theme.CreateScrollLayout()
+	scrollable.SetScrollAxis(false, true)
+	scrollable.SetChild(p.tree)
+	p.frame.AddChild(scrollable)
+	p.frame.SetChildWeight(scrollable, 1)
+	return p
+}
+
+// Frame returns the control that displays p's results.
+func (p *Pane) Frame() gxui.Control {
+	return p.frame
+}
+
+// Run runs `go test -json` in dir with args, replacing p's results
+// with the results of the new run.  Any run that is still in
+// progress is cancelled.
+func (p *Pane) Run(env []string, dir, title string, args []string) {
+	p.start(env, dir, title, func(ctx context.Context, handle func(Event)) error {
+		return Run(ctx, env, dir, args, handle)
+	})
+}
+
+// RerunFailed runs the tests that failed in the most recent run
+// again, using the same environment and directory.  It returns false
+// if there are no failed tests to run.
+func (p *Pane) RerunFailed() bool {
+	p.mu.Lock()
+	if p.results == nil {
+		p.mu.Unlock()
+		return false
+	}
+	failed := p.results.Failed()
+	env, dir := p.env, p.dir
+	p.mu.Unlock()
+
+	if len(failed) == 0 {
+		return false
+	}
+	var pkgs []string
+	for pkg := range failed {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	p.start(env, dir, "Failed tests", func(ctx context.Context, handle func(Event)) error {
+		for _, pkg := range pkgs {
+			if err := Run(ctx, env, dir, append(RunArgs(failed[pkg]...), pkg), handle); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return true
+}
+
+func (p *Pane) start(env []string, dir, title string, run func(context.Context, func(Event)) error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	results := &Results{Dir: PackageDir(env, dir)}
+
+	p.mu.Lock()
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.title = title
+	p.env = env
+	p.dir = dir
+	p.results = results
+	p.err = nil
+	p.done = false
+	p.cancel = cancel
+	p.rows = make(map[*Node]*row)
+	p.expanded = make(map[*Node]bool)
+	p.mu.Unlock()
+	p.refresh()
+
+	go func() {
+		err := run(ctx, func(e Event) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if p.results != results {
+				return
+			}
+			results.Add(e)
+			p.refreshLocked()
+		})
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.results != results {
+			return
+		}
+		if err != context.Canceled {
+			p.err = err
+		}
+		p.done = true
+		p.cancel = nil
+		cancel()
+		p.refreshLocked()
+	}()
+}
+
+func (p *Pane) refresh() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.refreshLocked()
+}
+
+// refreshLocked schedules a redraw of the tree, unless one is
+// already scheduled.  Test output can arrive much faster than the UI
+// can redraw, so events that arrive before the redraw are drawn
+// with it.  p.mu must be locked by the caller.
+func (p *Pane) refreshLocked() {
+	if p.pending {
+		return
+	}
+	p.pending = true
+	p.driver.Call(func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.pending = false
+		p.redraw()
+	})
+}
+
+// redraw rebuilds the tree from p.results.  It must be called on
+// the UI goroutine with p.mu locked.
+func (p *Pane) redraw() {
+	p.header.SetText(p.summary())
+	p.tree.RemoveAll()
+	if p.results == nil {
+		return
+	}
+	for _, pkg := range p.results.Packages {
+		p.addNode(pkg, 0)
+	}
+}
+
+func (p *Pane) summary() string {
+	if p.results == nil {
+		return "No tests have been run"
+	}
+	var passed, failed, skipped int
+	var count func([]*Node)
+	count = func(nodes []*Node) {
+		for _, n := range nodes {
+			switch n.Status {
+			case Passed:
+				passed++
+			case Failed:
+				failed++
+			case Skipped:
+				skipped++
+			}
+			count(n.Children)
+		}
+	}
+	for _, pkg := range p.results.Packages {
+		count(pkg.Children)
+	}
+	state := "running"
+	if p.done {
+		state = "done"
+	}
+	summary := fmt.Sprintf("%s (%s): %d passed, %d failed, %d skipped", p.title, state, passed, failed, skipped)
+	if p.err != nil {
+		summary += fmt.Sprintf("; go test: %s", p.err)
+	}
+	return summary
+}
+
+func (p *Pane) addNode(n *Node, depth int) {
+	r, ok := p.rows[n]
+	if !ok {
+		r = newRow(p.theme, depth)
+		r.OnClick(func(gxui.MouseEvent) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.expanded[n] = !p.expanded[n]
+			p.redraw()
+		})
+		p.rows[n] = r
+	}
+	r.SetText(nodeText(n))
+	r.Label().SetColor(statusColor(n.Status))
+	p.tree.AddChild(r)
+
+	if n.Status == Failed {
+		for _, f := range n.Failures {
+			p.addFailure(f, depth+1)
+		}
+	}
+	if p.expanded[n] {
+		for _, line := range n.Output {
+			l := p.theme.CreateLabel()
+			l.SetText(line)
+			l.SetMargin(math.Spacing{L: 3 + (depth+1)*10})
+			p.tree.AddChild(l)
+		}
+	}
+	for _, c := range n.Children {
+		p.addNode(c, depth+1)
+	}
+}
+
+func (p *Pane) addFailure(f Failure, depth int) {
+	r := newRow(p.theme, depth)
+	text := fmt.Sprintf("%s:%d", f.Path, f.Line)
+	if f.Msg != "" {
+		text += ": " + f.Msg
+	}
+	r.SetText(text)
+	r.Label().SetColor(status.ColorErr)
+	r.OnClick(func(gxui.MouseEvent) {
+		opener := p.cmdr.Bindable("focus-location").(Opener)
+		p.cmdr.Execute(opener.For(focus.Path(f.Path), focus.Line(f.Line-1)))
+	})
+	p.tree.AddChild(r)
+}
+
+func nodeText(n *Node) string {
+	text := fmt.Sprintf("%s %s", n.Status, n.Name)
+	if n.Status != Running {
+		text += fmt.Sprintf(" (%s)", n.Elapsed.Round(time.Millisecond))
+	}
+	return text
+}
+
+func statusColor(s Status) gxui.Color {
+	switch s {
+	case Passed:
+		return status.ColorInfo
+	case Failed:
+		return status.ColorErr
+	case Skipped:
+		return status.ColorWarn
+	default:
+		return runningColor
+	}
+}
@@ -0,0 +1,216 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package gotest
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a single event from `go test -json`.  See `go doc
+// cmd/test2json` for details.
+type Event struct {
+	Time    time.Time
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// Status is the status of a package, test or subtest.
+type Status int
+
+const (
+	Running Status = iota
+	Passed
+	Failed
+	Skipped
+)
+
+func (s Status) String() string {
+	switch s {
+	case Passed:
+		return "ok"
+	case Failed:
+		return "FAIL"
+	case Skipped:
+		return "skip"
+	default:
+		return "..."
+	}
+}
+
+// Failure is a file:line location found in a test's output.  Output
+// from t.Log includes locations too, so they should only be treated
+// as failures when the test failed.
+type Failure struct {
+	Path string
+	Line int
+	Msg  string
+}
+
+// Node is a package, test or subtest in a test run.
+type Node struct {
+	// Name is the last element of the node's name - the package
+	// import path, the test name, or the subtest name.
+	Name    string
+	Package string
+
+	// Test is the full name of the test, including any parent test
+	// names.  It is empty for packages.
+	Test string
+
+	Status   Status
+	Elapsed  time.Duration
+	Output   []string
+	Failures []Failure
+	Children []*Node
+}
+
+var (
+	// relFailure matches the file:line prefix that the testing
+	// package adds to t.Error and friends.
+	relFailure = regexp.MustCompile(`^\s+([^\s/\\:]+\.go):(\d+): ?(.*)$`)
+
+	// absFailure matches the absolute paths in stack traces.
+	absFailure = regexp.MustCompile(`^\s+(\S+\.go):(\d+)(?:\s.*)?$`)
+)
+
+// Results is the tree of results from one or more runs of `go test
+// -json`.
+type Results struct {
+	// Dir returns the directory that pkg is in, which is used to
+	// find test files from their names in test output.  If it is nil,
+	// failures will only be found for absolute paths.
+	Dir func(pkg string) string
+
+	Packages []*Node
+	dirs     map[string]string
+}
+
+// Add adds e to r, returning the node that it changed.
+func (r *Results) Add(e Event) *Node {
+	n := r.node(e.Package, e.Test)
+	switch e.Action {
+	case "run":
+		n.Status = Running
+	case "pass":
+		n.Status = Passed
+	case "fail", "build-fail":
+		n.Status = Failed
+	case "skip":
+		n.Status = Skipped
+	case "output", "build-output":
+		n.Output = append(n.Output, strings.TrimRight(e.Output, "\n"))
+		if f, ok := r.failure(e.Package, e.Output); ok {
+			n.Failures = append(n.Failures, f)
+		}
+	}
+	if e.Elapsed > 0 {
+		n.Elapsed = time.Duration(e.Elapsed * float64(time.Second))
+	}
+	return n
+}
+
+// Failed returns the names of the top-level tests that failed,
+// keyed by package.  A test is included if it or any of its subtests
+// failed.
+func (r *Results) Failed() map[string][]string {
+	failed := make(map[string][]string)
+	for _, p := range r.Packages {
+		for _, t := range p.Children {
+			if t.failed() {
+				failed[p.Package] = append(failed[p.Package], t.Test)
+			}
+		}
+		sort.Strings(failed[p.Package])
+	}
+	return failed
+}
+
+func (n *Node) failed() bool {
+	if n.Status == Failed {
+		return true
+	}
+	for _, c := range n.Children {
+		if c.failed() {
+			return true
+		}
+	}
+	return false
+}
+
+// node finds or creates the node for test in pkg.
+func (r *Results) node(pkg, test string) *Node {
+	var n *Node
+	for _, p := range r.Packages {
+		if p.Package == pkg {
+			n = p
+			break
+		}
+	}
+	if n == nil {
+		n = &Node{Name: pkg, Package: pkg}
+		r.Packages = append(r.Packages, n)
+	}
+	if test == "" {
+		return n
+	}
+	parts := strings.Split(test, "/")
+	for i, name := range parts {
+		full := strings.Join(parts[:i+1], "/")
+		var child *Node
+		for _, c := range n.Children {
+			if c.Test == full {
+				child = c
+				break
+			}
+		}
+		if child == nil {
+			child = &Node{Name: name, Package: pkg, Test: full}
+			n.Children = append(n.Children, child)
+		}
+		n = child
+	}
+	return n
+}
+
+// failure parses a Failure from a line of output in pkg.
+func (r *Results) failure(pkg, line string) (Failure, bool) {
+	line = strings.TrimRight(line, "\n")
+	if m := relFailure.FindStringSubmatch(line); m != nil {
+		dir := r.dir(pkg)
+		if dir == "" {
+			return Failure{}, false
+		}
+		l, _ := strconv.Atoi(m[2])
+		return Failure{Path: filepath.Join(dir, m[1]), Line: l, Msg: m[3]}, true
+	}
+	if m := absFailure.FindStringSubmatch(line); m != nil && filepath.IsAbs(m[1]) {
+		l, _ := strconv.Atoi(m[2])
+		return Failure{Path: m[1], Line: l}, true
+	}
+	return Failure{}, false
+}
+
+func (r *Results) dir(pkg string) string {
+	if r.Dir == nil {
+		return ""
+	}
+	if r.dirs == nil {
+		r.dirs = make(map[string]string)
+	}
+	d, ok := r.dirs[pkg]
+	if !ok {
+		d = r.Dir(pkg)
+		r.dirs[pkg] = d
+	}
+	return d
+}
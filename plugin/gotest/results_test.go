@@ -0,0 +1,127 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package gotest_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/plugin/gotest"
+)
+
+func TestResults(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, *gotest.Results) {
+		return expect.New(t), &gotest.Results{
+			Dir: func(pkg string) string {
+				return filepath.Join("/src", pkg)
+			},
+		}
+	})
+
+	o.Spec("it builds a tree of packages, tests and subtests", func(expect expect.Expectation, r *gotest.Results) {
+		r.Add(gotest.Event{Action: "run", Package: "foo", Test: "TestFoo"})
+		r.Add(gotest.Event{Action: "run", Package: "foo", Test: "TestFoo/bar"})
+		r.Add(gotest.Event{Action: "pass", Package: "foo", Test: "TestFoo/bar", Elapsed: 0.5})
+		r.Add(gotest.Event{Action: "skip", Package: "foo", Test: "TestSkip"})
+		r.Add(gotest.Event{Action: "pass", Package: "foo", Test: "TestFoo", Elapsed: 1})
+		r.Add(gotest.Event{Action: "pass", Package: "foo", Elapsed: 1.5})
+
+		expect(r.Packages).To(HaveLen(1))
+		pkg := r.Packages[0]
+		expect(pkg.Name).To(Equal("foo"))
+		expect(pkg.Status).To(Equal(gotest.Passed))
+		expect(pkg.Elapsed).To(Equal(1500 * time.Millisecond))
+		expect(pkg.Children).To(HaveLen(2))
+
+		foo := pkg.Children[0]
+		expect(foo.Test).To(Equal("TestFoo"))
+		expect(foo.Status).To(Equal(gotest.Passed))
+		expect(foo.Children).To(HaveLen(1))
+		expect(foo.Children[0].Name).To(Equal("bar"))
+		expect(foo.Children[0].Test).To(Equal("TestFoo/bar"))
+		expect(foo.Children[0].Elapsed).To(Equal(500 * time.Millisecond))
+
+		expect(pkg.Children[1].Status).To(Equal(gotest.Skipped))
+	})
+
+	o.Spec("it finds failure locations in test output", func(expect expect.Expectation, r *gotest.Results) {
+		r.Add(gotest.Event{Action: "run", Package: "foo", Test: "TestFoo"})
+		r.Add(gotest.Event{Action: "output", Package: "foo", Test: "TestFoo", Output: "    foo_test.go:12: expected 1, got 2\n"})
+		r.Add(gotest.Event{Action: "output", Package: "foo", Test: "TestFoo", Output: "\t/usr/lib/go/src/testing/testing.go:1439 +0x102\n"})
+		n := r.Add(gotest.Event{Action: "fail", Package: "foo", Test: "TestFoo"})
+
+		expect(n.Status).To(Equal(gotest.Failed))
+		expect(n.Output).To(HaveLen(2))
+		expect(n.Output[0]).To(Equal("    foo_test.go:12: expected 1, got 2"))
+		expect(n.Failures).To(HaveLen(2))
+		expect(n.Failures[0]).To(Equal(gotest.Failure{Path: filepath.Join("/src", "foo", "foo_test.go"), Line: 12, Msg: "expected 1, got 2"}))
+		expect(n.Failures[1]).To(Equal(gotest.Failure{Path: "/usr/lib/go/src/testing/testing.go", Line: 1439}))
+	})
+
+	o.Spec("it lists top-level tests with failures", func(expect expect.Expectation, r *gotest.Results) {
+		r.Add(gotest.Event{Action: "fail", Package: "foo", Test: "TestB/sub"})
+		r.Add(gotest.Event{Action: "pass", Package: "foo", Test: "TestB"})
+		r.Add(gotest.Event{Action: "fail", Package: "foo", Test: "TestA"})
+		r.Add(gotest.Event{Action: "pass", Package: "foo", Test: "TestC"})
+		r.Add(gotest.Event{Action: "pass", Package: "bar", Test: "TestD"})
+
+		failed := r.Failed()
+		expect(failed).To(HaveLen(1))
+		expect(failed["foo"]).To(Equal([]string{"TestA", "TestB"}))
+	})
+}
+
+func TestRunArgs(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) expect.Expectation {
+		return expect.New(t)
+	})
+
+	o.Spec("it matches the named tests exactly", func(expect expect.Expectation) {
+		expect(gotest.RunArgs("TestA", "TestB")).To(Equal([]string{"-run", "^(TestA|TestB)$"}))
+	})
+
+	o.Spec("it runs a single benchmark without running tests", func(expect expect.Expectation) {
+		expect(gotest.RunArgs("BenchmarkA")).To(Equal([]string{"-run", "^$", "-bench", "^(BenchmarkA)$"}))
+	})
+}
+
+func TestTestAt(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) expect.Expectation {
+		return expect.New(t)
+	})
+
+	const src = `package foo
+
+func helper() {}
+
+func TestFoo(t *testing.T) {
+	helper()
+}
+`
+
+	o.Spec("it finds the test function containing the offset", func(expect expect.Expectation) {
+		name, ok := gotest.TestAt(src, len("package foo\n\nfunc helper() {}\n\nfunc TestFoo(t *testing.T) {\n\th"))
+		expect(ok).To(BeTrue())
+		expect(name).To(Equal("TestFoo"))
+	})
+
+	o.Spec("it ignores functions that are not tests", func(expect expect.Expectation) {
+		_, ok := gotest.TestAt(src, len("package foo\n\nfunc hel"))
+		expect(ok).To(BeFalse())
+	})
+}
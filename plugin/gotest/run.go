@@ -0,0 +1,142 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package gotest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Run runs `go test -json` in dir with args, calling handle with each
+// event as it is read.  Test failures are reported through events,
+// so the returned error is only non-nil if go test itself failed or
+// its output could not be read.
+func Run(ctx context.Context, env []string, dir string, args []string, handle func(Event)) error {
+	cmd := exec.CommandContext(ctx, "go", append([]string{"test", "-json"}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = env
+	errBuffer := &bytes.Buffer{}
+	cmd.Stderr = errBuffer
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(out)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			e = Event{Action: "output", Output: scanner.Text()}
+		}
+		handle(e)
+	}
+	scanErr := scanner.Err()
+	if scanErr != nil {
+		// go test blocks on a full pipe, so it would never exit if
+		// the rest of its output weren't read.
+		io.Copy(ioutil.Discard, out)
+	}
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if scanErr != nil {
+		return fmt.Errorf("could not read go test output: %s", scanErr)
+	}
+	if msg := strings.TrimSpace(errBuffer.String()); msg != "" && err != nil {
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// PackageDir returns a function that finds the directory for a
+// package using `go list`, for use as Results.Dir.
+func PackageDir(env []string, dir string) func(string) string {
+	return func(pkg string) string {
+		cmd := exec.Command("go", "list", "-f", "{{.Dir}}", pkg)
+		cmd.Dir = dir
+		cmd.Env = env
+		out, err := cmd.Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+}
+
+// RunArgs returns the arguments to pass to go test to run only the
+// named tests.
+func RunArgs(tests ...string) []string {
+	var quoted []string
+	for _, t := range tests {
+		quoted = append(quoted, regexp.QuoteMeta(t))
+	}
+	pattern := "^(" + strings.Join(quoted, "|") + ")$"
+	if len(tests) == 1 && strings.HasPrefix(tests[0], "Benchmark") {
+		return []string{"-run", "^$", "-bench", pattern}
+	}
+	return []string{"-run", pattern}
+}
+
+var testPrefixes = []string{"Test", "Benchmark", "Example", "Fuzz"}
+
+// TestAt returns the name of the test function in src that contains
+// offset (a byte offset).
+func TestAt(src string, offset int) (string, bool) {
+	f, _ := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	if f == nil {
+		return "", false
+	}
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil {
+			continue
+		}
+		// With a fresh FileSet, the file's base is 1.
+		start, end := int(fn.Pos())-1, int(fn.End())-1
+		if offset < start || offset > end {
+			continue
+		}
+		for _, p := range testPrefixes {
+			if strings.HasPrefix(fn.Name.Name, p) {
+				return fn.Name.Name, true
+			}
+		}
+		return "", false
+	}
+	return "", false
+}
+
+// ModuleRoot returns the directory containing the go.mod file for
+// dir, or an empty string if there is none.
+func ModuleRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,76 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package gotmpl contains syntax highlighting for go template
+// files.
+package gotmpl
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/syntax/gotmpl"
+)
+
+type Highlight struct {
+	layers []input.SyntaxLayer
+
+	mu sync.Mutex
+}
+
+func New() *Highlight {
+	return &Highlight{}
+}
+
+func (h *Highlight) Name() string {
+	return "gotmpl-syntax-highlight"
+}
+
+func (h *Highlight) OpName() string {
+	return "input-handler"
+}
+
+func (h *Highlight) Applied(e input.Editor, edits []input.Edit) {
+	layers := e.SyntaxLayers()
+	for i, l := range layers {
+		layers[i] = l.Move(edits)
+	}
+	e.SetSyntaxLayers(layers)
+}
+
+func (h *Highlight) Init(e input.Editor, text []rune) {
+	h.TextChanged(context.Background(), e, nil)
+}
+
+func (h *Highlight) TextChanged(ctx context.Context, editor input.Editor, _ []input.Edit) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// Actions may span lines, so the lexer works on a copy of the
+	// whole snapshot rather than reading it a line at a time.
+	layers := gotmpl.Layers(input.Snapshot(editor).String())
+	select {
+	case <-ctx.Done():
+		return
+	default:
+	}
+	h.layers = layers
+}
+
+func (h *Highlight) Apply(e input.Editor) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	e.SetSyntaxLayers(h.layers)
+	return nil
+}
+
+// IsTemplate returns whether or not path is a go template file.
+func IsTemplate(path string) bool {
+	switch filepath.Ext(path) {
+	case ".tmpl", ".gotmpl", ".gohtml", ".gotxt":
+		return true
+	}
+	return false
+}
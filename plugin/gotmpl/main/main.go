@@ -0,0 +1,39 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package main
+
+import (
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/plugin/command"
+	"github.com/nelsam/vidar/plugin/gotmpl"
+)
+
+type GoTemplateHook struct {
+}
+
+func (h GoTemplateHook) Name() string {
+	return "gotmpl-hook"
+}
+
+func (h GoTemplateHook) OpName() string {
+	return "focus-location"
+}
+
+func (h GoTemplateHook) FileBindables(path string) []bind.Bindable {
+	if !gotmpl.IsTemplate(path) {
+		return nil
+	}
+	return []bind.Bindable{
+		gotmpl.New(),
+	}
+}
+
+// Bindables is the main entry point to the command.
+func Bindables(cmdr command.Commander, driver gxui.Driver, theme gxui.Theme) []bind.Bindable {
+	return []bind.Bindable{
+		GoTemplateHook{},
+	}
+}
@@ -11,10 +11,67 @@ import (
 	"github.com/nelsam/gxui/themes/basic"
 	"github.com/nelsam/vidar/commander"
 	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/plugin/debug"
+	"github.com/nelsam/vidar/plugin/format"
+	"github.com/nelsam/vidar/plugin/git"
+	"github.com/nelsam/vidar/plugin/gobuild"
+	"github.com/nelsam/vidar/plugin/gocover"
+	"github.com/nelsam/vidar/plugin/gotest"
+	"github.com/nelsam/vidar/plugin/snippets"
+	"github.com/nelsam/vidar/plugin/tmsyntax"
 )
 
 func Bindables(cmdr *commander.Commander, driver gxui.Driver, theme *basic.Theme) []bind.Bindable {
+	tests := gotest.NewPane(cmdr, driver, theme)
+	problems := gobuild.NewList()
+	build := gobuild.NewPane(cmdr, driver, theme, problems)
+	breakpoints := debug.DefaultBreakpoints()
+	debugger := debug.New(cmdr, driver, theme, breakpoints)
+	coverage := gocover.NewOverlay(driver, theme, cmdr)
+	tracker := git.NewTracker(driver)
+	blame := git.NewBlameView(cmdr, driver, theme)
+	treeStatus := git.NewTreeStatus()
+	expander := snippets.New(cmdr)
+	tmsyntax.LoadGrammars()
 	return []bind.Bindable{
-		GolangHook{Theme: theme, Driver: driver},
+		GolangHook{Theme: theme, Driver: driver, Status: cmdr, Commander: cmdr},
+		TextMateHook{},
+		GoModHook{Theme: theme},
+		GoTemplateHook{},
+		format.OnSave{},
+		gotest.NewTestAtCaret(theme, tests),
+		gotest.NewTestPackage(theme, tests),
+		gotest.NewTestModule(theme, tests),
+		gotest.NewRerunFailed(theme, tests),
+		gobuild.NewBuild(theme, build, problems),
+		gobuild.NewNextError(theme, cmdr, problems),
+		gobuild.NewPrevError(theme, cmdr, problems),
+		gobuild.OnEdit{List: problems},
+		debug.NewContinue(theme, debugger),
+		debug.NewNext(theme, debugger),
+		debug.NewStep(theme, debugger),
+		debug.NewStepOut(theme, debugger),
+		debug.NewStop(theme, debugger),
+		debug.NewToggleBreakpoint(theme, breakpoints),
+		debug.Hook{Debugger: debugger},
+		gocover.NewCoverage(theme, coverage),
+		gocover.NewLoadProfile(driver, theme, coverage),
+		gocover.NewToggle(theme, coverage),
+		gocover.Hook{Overlay: coverage},
+		git.NewNextHunk(theme, tracker),
+		git.NewPrevHunk(theme, tracker),
+		git.NewRevertHunk(theme, tracker),
+		git.NewShowHunk(theme, tracker),
+		git.NewToggleBase(theme, tracker),
+		git.Hook{Tracker: tracker},
+		git.OnSave{Tracker: tracker},
+		git.NewToggleBlame(theme, blame),
+		git.BlameHook{View: blame},
+		git.BlameOnSave{View: blame},
+		treeStatus,
+		git.NewToggleChangedFilter(theme, treeStatus),
+		git.TreeOnSave{Status: treeStatus},
+		expander,
+		snippets.NewInsert(theme, expander),
 	}
 }
@@ -0,0 +1,275 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package snippets expands the user's snippets in the editor and
+// moves between their tab stops.
+package snippets
+
+import (
+	"errors"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/setting"
+	"github.com/nelsam/vidar/snippet"
+)
+
+// packageClause matches the package clause of a go file.
+var packageClause = regexp.MustCompile(`(?m)^package\s+([\pL_][\pL\pN_]*)`)
+
+// Commander is a type that can look up and execute bindables, like
+// the commander.
+type Commander interface {
+	Bindable(name string) bind.Bindable
+	Execute(bind.Bindable)
+}
+
+type handlerOwner interface {
+	InputHandler() input.Handler
+}
+
+type selecter interface {
+	Select(...gxui.TextSelection) bind.Bindable
+}
+
+// Editor is the type of editor that snippets can be expanded in.
+type Editor interface {
+	input.Editor
+
+	Controller() *gxui.TextBoxController
+	SelectSlice([]gxui.TextSelection)
+}
+
+// session is an expanded snippet whose tab stops are being visited.
+type session struct {
+	stops   []snippet.Stop
+	current int
+}
+
+// Expander expands snippets and keeps track of the tab stops in the
+// ones that are being filled in.  It is bound to the input handler:
+// tab expands the snippet whose prefix is before the caret or moves
+// to the next tab stop, shift-tab moves to the previous one and
+// escape stops visiting tab stops.
+//
+// Its methods must be called on the UI goroutine.
+type Expander struct {
+	cmdr     Commander
+	sessions map[input.Editor]*session
+}
+
+// New returns an Expander that uses cmdr to apply snippets and select
+// their tab stops.
+func New(cmdr Commander) *Expander {
+	return &Expander{
+		cmdr:     cmdr,
+		sessions: make(map[input.Editor]*session),
+	}
+}
+
+func (x *Expander) Name() string {
+	return "snippet-tab-stops"
+}
+
+func (x *Expander) OpName() string {
+	return "input-handler"
+}
+
+// Tab expands the snippet whose prefix is before the caret, or moves
+// between the tab stops of the snippet being filled in.
+func (x *Expander) Tab(ie input.Editor, back bool) bool {
+	e, ok := ie.(Editor)
+	if !ok {
+		return false
+	}
+	if s, ok := x.sessions[ie]; ok {
+		if back {
+			if s.current == 0 {
+				return true
+			}
+			s.current--
+		} else {
+			s.current++
+		}
+		x.show(e, s)
+		return true
+	}
+	if back {
+		return false
+	}
+	expanded, err := x.expandPrefix(e)
+	if err != nil {
+		log.Printf("Error expanding snippet: %s", err)
+	}
+	return expanded
+}
+
+// Cancel stops visiting the tab stops of the snippet being filled in.
+func (x *Expander) Cancel(e input.Editor) bool {
+	if _, ok := x.sessions[e]; !ok {
+		return false
+	}
+	delete(x.sessions, e)
+	return true
+}
+
+// Applied moves the fields of the snippet being filled in to follow
+// edits.  Edits that change part of a field along with text outside
+// of it end the snippet.
+func (x *Expander) Applied(e input.Editor, edits []input.Edit) {
+	s, ok := x.sessions[e]
+	if !ok {
+		return
+	}
+	for _, edit := range edits {
+		for _, stop := range s.stops {
+			for i, f := range stop.Fields {
+				moved, ok := f.Edited(edit.At, len(edit.Old), len(edit.New))
+				if !ok {
+					delete(x.sessions, e)
+					return
+				}
+				stop.Fields[i] = moved
+			}
+		}
+	}
+}
+
+// expandPrefix expands the snippet whose prefix is the word before
+// the caret.  It returns false if there is no such snippet.
+func (x *Expander) expandPrefix(e Editor) (bool, error) {
+	sels := e.Controller().SelectionSlice()
+	if len(sels) != 1 || sels[0].Length() != 0 {
+		return false, nil
+	}
+	text := e.Runes()
+	end := sels[0].Start()
+	start := end
+	for start > 0 && wordPart(text[start-1]) {
+		start--
+	}
+	if start == end {
+		return false, nil
+	}
+	snippets, err := setting.Snippets(language(e.Filepath()))
+	if err != nil {
+		return false, err
+	}
+	s, ok := find(snippets, string(text[start:end]))
+	if !ok {
+		return false, nil
+	}
+	return true, x.Expand(e, s, start, end, "")
+}
+
+// Expand replaces the text from start to end with s and selects its
+// first tab stop.  selection is the value of s's $SELECTION variable.
+func (x *Expander) Expand(e Editor, s snippet.Snippet, start, end int, selection string) error {
+	h, ok := x.cmdr.(handlerOwner)
+	if !ok {
+		return errors.New("snippets: the commander has no input handler to apply snippets with")
+	}
+	text := e.Runes()
+	vars := variables(e.Filepath(), text)
+	vars[snippet.Selection] = selection
+	exp, err := snippet.Expand(s.Body, vars, indent(text, start))
+	if err != nil {
+		return err
+	}
+	delete(x.sessions, e)
+	h.InputHandler().Apply(e, input.Edit{
+		At:  start,
+		Old: text[start:end],
+		New: exp.Text,
+	})
+	for _, stop := range exp.Stops {
+		for i := range stop.Fields {
+			stop.Fields[i].Start += start
+			stop.Fields[i].End += start
+		}
+	}
+	sess := &session{stops: exp.Stops}
+	x.sessions[e] = sess
+	x.show(e, sess)
+	return nil
+}
+
+// show selects every field of s's current tab stop.  Once the last
+// tab stop is reached, the snippet is finished.
+func (x *Expander) show(e Editor, s *session) {
+	stop := s.stops[s.current]
+	if s.current == len(s.stops)-1 {
+		delete(x.sessions, e)
+	}
+	sels := make([]gxui.TextSelection, 0, len(stop.Fields))
+	for _, f := range stop.Fields {
+		sels = append(sels, gxui.CreateTextSelection(f.Start, f.End, false))
+	}
+	if m, ok := x.cmdr.Bindable("caret-movement").(selecter); ok {
+		x.cmdr.Execute(m.Select(sels...))
+		return
+	}
+	e.SelectSlice(sels)
+}
+
+// variables returns the variables for snippets expanded in the file
+// at path, which contains text.
+func variables(path string, text []rune) snippet.Vars {
+	return snippet.Vars{
+		snippet.FileName:  filepath.Base(path),
+		snippet.FilePath:  path,
+		snippet.Directory: filepath.Dir(path),
+		snippet.Package:   packageName(path, text),
+	}
+}
+
+// packageName returns the name from text's package clause, or the
+// name of path's directory if it doesn't have one yet.
+func packageName(path string, text []rune) string {
+	if m := packageClause.FindStringSubmatch(string(text)); m != nil {
+		return m[1]
+	}
+	return filepath.Base(filepath.Dir(path))
+}
+
+// language returns the name of the snippet file for path: its
+// extension, or its name if it doesn't have one (like Makefile).
+func language(path string) string {
+	if ext := filepath.Ext(path); ext != "" {
+		return strings.TrimPrefix(ext, ".")
+	}
+	return filepath.Base(path)
+}
+
+// indent returns the indentation of the line that pos is on.
+func indent(text []rune, pos int) string {
+	start := pos
+	for start > 0 && text[start-1] != '\n' {
+		start--
+	}
+	end := start
+	for end < pos && (text[end] == ' ' || text[end] == '\t') {
+		end++
+	}
+	return string(text[start:end])
+}
+
+func find(snippets []snippet.Snippet, prefix string) (snippet.Snippet, bool) {
+	for _, s := range snippets {
+		if s.Prefix == prefix {
+			return s, true
+		}
+	}
+	return snippet.Snippet{}, false
+}
+
+func wordPart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r)
+}
@@ -0,0 +1,131 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package snippets
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/plugin/status"
+	"github.com/nelsam/vidar/setting"
+	"github.com/nelsam/vidar/snippet"
+)
+
+// Insert is a command that prompts for the prefix of a snippet and
+// expands it in place of the selection, which the snippet can use as
+// $SELECTION.
+type Insert struct {
+	status.General
+
+	expander *Expander
+	prefix   gxui.TextBox
+	input    gxui.Focusable
+
+	editor Editor
+
+	// chosen is the snippet to expand without prompting, from start
+	// to end.  It is set by For.
+	chosen     *snippet.Snippet
+	start, end int
+}
+
+// NewInsert returns an Insert that expands snippets using x.
+func NewInsert(theme gxui.Theme, x *Expander) *Insert {
+	i := &Insert{
+		expander: x,
+		prefix:   theme.CreateTextBox(),
+	}
+	i.Theme = theme
+	return i
+}
+
+// For returns a bindable that expands s in place of the text from
+// start to end without prompting, e.g. when s is chosen from a
+// completion list.
+func (i *Insert) For(s snippet.Snippet, start, end int) bind.Bindable {
+	n := &Insert{
+		expander: i.expander,
+		chosen:   &s,
+		start:    start,
+		end:      end,
+	}
+	n.Theme = i.Theme
+	return n
+}
+
+func (i *Insert) Name() string {
+	return "insert-snippet"
+}
+
+func (i *Insert) Menu() string {
+	return "Edit"
+}
+
+func (i *Insert) Defaults() []fmt.Stringer {
+	return []fmt.Stringer{gxui.KeyboardEvent{
+		Modifier: gxui.ModControl,
+		Key:      gxui.KeyJ,
+	}}
+}
+
+func (i *Insert) Start(gxui.Control) gxui.Control {
+	i.prefix.SetText("")
+	i.input = i.prefix
+	return nil
+}
+
+func (i *Insert) Next() gxui.Focusable {
+	input := i.input
+	i.input = nil
+	return input
+}
+
+func (i *Insert) Reset() {
+	i.editor = nil
+}
+
+func (i *Insert) Store(elem interface{}) bind.Status {
+	if e, ok := elem.(Editor); ok {
+		i.editor = e
+		return bind.Done
+	}
+	return bind.Waiting
+}
+
+func (i *Insert) Exec() error {
+	if i.chosen != nil {
+		return i.expander.Expand(i.editor, *i.chosen, i.start, i.end, "")
+	}
+	prefix := i.prefix.Text()
+	if prefix == "" {
+		i.Warn = "No snippet prefix provided"
+		return nil
+	}
+	lang := language(i.editor.Filepath())
+	snippets, err := setting.Snippets(lang)
+	if err != nil {
+		i.Err = fmt.Sprintf("Could not load %s snippets: %s", lang, err)
+		return err
+	}
+	s, ok := find(snippets, prefix)
+	if !ok {
+		i.Warn = fmt.Sprintf("No %s snippet has the prefix %s", lang, prefix)
+		return nil
+	}
+	sels := i.editor.Controller().SelectionSlice()
+	if len(sels) != 1 {
+		i.Err = "Snippets can only be inserted at a single caret"
+		return errors.New("snippets: cannot insert a snippet at multiple carets")
+	}
+	sel := sels[0]
+	selected := string(i.editor.Runes()[sel.Start():sel.End()])
+	if err := i.expander.Expand(i.editor, s, sel.Start(), sel.End(), selected); err != nil {
+		i.Err = fmt.Sprintf("Could not expand snippet %s: %s", prefix, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// +build !linux !go1.8
+
+package plugin
+
+import (
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/plugin/tmsyntax"
+	"github.com/nelsam/vidar/textmate"
+)
+
+type TextMateHook struct {
+}
+
+func (h TextMateHook) Name() string {
+	return "textmate-hook"
+}
+
+func (h TextMateHook) OpName() string {
+	return "focus-location"
+}
+
+func (h TextMateHook) FileBindables(path string) []bind.Bindable {
+	g := textmate.ForPath(path)
+	if g == nil {
+		return nil
+	}
+	return []bind.Bindable{
+		tmsyntax.New(g),
+	}
+}
@@ -0,0 +1,7 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package tmsyntax contains syntax highlighting for files that
+// have a TextMate grammar registered in the textmate package.
+package tmsyntax
@@ -0,0 +1,66 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package tmsyntax
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/piece"
+	"github.com/nelsam/vidar/textmate"
+)
+
+type Highlight struct {
+	grammar *textmate.Grammar
+	layers  []input.SyntaxLayer
+
+	mu sync.Mutex
+}
+
+func New(g *textmate.Grammar) *Highlight {
+	return &Highlight{grammar: g}
+}
+
+func (h *Highlight) Name() string {
+	return "textmate-syntax-highlight"
+}
+
+func (h *Highlight) OpName() string {
+	return "input-handler"
+}
+
+func (h *Highlight) Applied(e input.Editor, edits []input.Edit) {
+	layers := e.SyntaxLayers()
+	for i, l := range layers {
+		layers[i] = l.Move(edits)
+	}
+	e.SetSyntaxLayers(layers)
+}
+
+func (h *Highlight) Init(e input.Editor, text []rune) {
+	h.TextChanged(context.Background(), e, nil)
+}
+
+func (h *Highlight) TextChanged(ctx context.Context, editor input.Editor, _ []input.Edit) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	layers, err := h.grammar.HighlightReader(ctx, piece.NewReader(input.Snapshot(editor)))
+	if err != nil {
+		if err != ctx.Err() {
+			log.Printf("Error highlighting %s: %s", editor.Filepath(), err)
+		}
+		return
+	}
+	h.layers = layers
+}
+
+func (h *Highlight) Apply(e input.Editor) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	e.SetSyntaxLayers(h.layers)
+	return nil
+}
@@ -0,0 +1,21 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package tmsyntax
+
+import (
+	"log"
+
+	"github.com/nelsam/vidar/setting"
+	"github.com/nelsam/vidar/textmate"
+)
+
+// LoadGrammars registers the user's grammars from the grammars
+// directory in the config directory.  Grammars that fail to load are
+// logged and skipped.
+func LoadGrammars() {
+	for _, err := range textmate.LoadDir(setting.GrammarsDir()) {
+		log.Printf("Error loading TextMate grammar: %s", err)
+	}
+}
@@ -0,0 +1,42 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package main
+
+import (
+	"github.com/nelsam/gxui"
+	"github.com/nelsam/vidar/commander/bind"
+	"github.com/nelsam/vidar/plugin/command"
+	"github.com/nelsam/vidar/plugin/tmsyntax"
+	"github.com/nelsam/vidar/textmate"
+)
+
+type TextMateHook struct {
+}
+
+func (h TextMateHook) Name() string {
+	return "textmate-hook"
+}
+
+func (h TextMateHook) OpName() string {
+	return "focus-location"
+}
+
+func (h TextMateHook) FileBindables(path string) []bind.Bindable {
+	g := textmate.ForPath(path)
+	if g == nil {
+		return nil
+	}
+	return []bind.Bindable{
+		tmsyntax.New(g),
+	}
+}
+
+// Bindables is the main entry point to the command.
+func Bindables(cmdr command.Commander, driver gxui.Driver, theme gxui.Theme) []bind.Bindable {
+	tmsyntax.LoadGrammars()
+	return []bind.Bindable{
+		TextMateHook{},
+	}
+}
@@ -0,0 +1,43 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// +build !windows
+
+package safefile
+
+import (
+	"os"
+	"syscall"
+)
+
+// chown gives f the owner and group from finfo.  Only root can give
+// files away, so a file that we can't chown is left with our own
+// owner, as long as its group is unchanged.
+func chown(f *os.File, finfo os.FileInfo) error {
+	stat, ok := finfo.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	uid, gid := int(stat.Uid), int(stat.Gid)
+	if uid == os.Getuid() && gid == os.Getgid() {
+		return nil
+	}
+	if err := f.Chown(uid, gid); err != nil {
+		// We may still be able to keep the group, if we're in it.
+		if err := f.Chown(-1, gid); err != nil && !os.IsPermission(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncDir flushes the rename of a file in dir to disk.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
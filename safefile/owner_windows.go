@@ -0,0 +1,21 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// +build windows
+
+package safefile
+
+import "os"
+
+// chown is a no-op on windows, where files are owned by the user
+// that replaces them.
+func chown(*os.File, os.FileInfo) error {
+	return nil
+}
+
+// syncDir is a no-op on windows, which doesn't support syncing
+// directories.
+func syncDir(string) error {
+	return nil
+}
@@ -0,0 +1,174 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package safefile writes files without leaving them truncated or
+// half-written if something goes wrong part of the way through.
+package safefile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxLinks is the most symlinks that are followed before giving up,
+// to avoid looping forever on symlinks that point at each other.
+const maxLinks = 255
+
+// defaultMode is the mode of new files.
+const defaultMode os.FileMode = 0644
+
+// Write replaces the contents of the file at path with data.  The
+// data is written to a temporary file in the same directory, synced
+// to disk, and then renamed over the original, so path always holds
+// either its old contents or data.  If path is a symlink, the file
+// that it points to is replaced.  The original file's mode and (where
+// the OS supports it) ownership are kept.
+//
+// Since the file is replaced rather than written to, other hard links
+// to the file will keep its old contents.  Once the rename succeeds,
+// a failure to sync the directory is only logged.
+func Write(path string, data []byte) (err error) {
+	target, err := Resolve(path)
+	if err != nil {
+		return err
+	}
+	mode := defaultMode
+	finfo, err := os.Stat(target)
+	switch {
+	case err == nil:
+		mode = finfo.Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	dir, name := filepath.Split(target)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := ioutil.TempFile(dir, "."+name+".vidar-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		return err
+	}
+	if finfo != nil {
+		if err := chown(tmp, finfo); err != nil {
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return err
+	}
+	if err := syncDir(dir); err != nil {
+		// The file has already been replaced, so the only risk is
+		// that the rename is lost in a crash.  Some filesystems
+		// don't support syncing directories at all.
+		log.Printf("Warning: could not sync directory %s after writing %s: %s", dir, target, err)
+	}
+	return nil
+}
+
+// Resolve follows symlinks at path, returning the path of the file
+// that they point to.  Unlike filepath.EvalSymlinks, the file doesn't
+// need to exist, so that a symlink to a file that hasn't been created
+// yet resolves to the path that the file will be created at.
+func Resolve(path string) (string, error) {
+	for i := 0; i < maxLinks; i++ {
+		finfo, err := os.Lstat(path)
+		if os.IsNotExist(err) {
+			return path, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if finfo.Mode()&os.ModeSymlink == 0 {
+			return path, nil
+		}
+		link, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(link) {
+			link = filepath.Join(filepath.Dir(path), link)
+		}
+		path = link
+	}
+	return "", fmt.Errorf("too many levels of symbolic links at %s", path)
+}
+
+// Backup copies the file at path into dir before it is overwritten,
+// keeping up to count backups of each file.  The newest backup ends
+// in .~1~, the one before it in .~2~, and so on.  Backups are named
+// after the file's full path, so files with the same name in
+// different directories don't share backups.  Nothing is backed up
+// if the file doesn't exist yet.
+func Backup(path, dir string, count int) error {
+	if count < 1 {
+		return nil
+	}
+	finfo, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	base := filepath.Join(dir, BackupName(abs))
+	os.Remove(backupPath(base, count))
+	for i := count - 1; i > 0; i-- {
+		err := os.Rename(backupPath(base, i), backupPath(base, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	backup := backupPath(base, 1)
+	if err := Write(backup, data); err != nil {
+		return err
+	}
+	// Backups shouldn't be readable by anyone who couldn't read the
+	// original.
+	return os.Chmod(backup, finfo.Mode().Perm())
+}
+
+// BackupName returns the name that backups of the file at the
+// absolute path are given, without the backup number.
+func BackupName(path string) string {
+	path = strings.Replace(filepath.ToSlash(path), ":", "%", -1)
+	return strings.Replace(strings.TrimPrefix(path, "/"), "/", "%", -1)
+}
+
+func backupPath(base string, n int) string {
+	return fmt.Sprintf("%s.~%d~", base, n)
+}
@@ -0,0 +1,132 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package safefile_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/safefile"
+)
+
+func read(expect expect.Expectation, path string) string {
+	b, err := ioutil.ReadFile(path)
+	expect(err).To(Not(HaveOccurred()))
+	return string(b)
+}
+
+func TestWrite(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, string) {
+		expect := expect.New(t)
+		dir, err := ioutil.TempDir("", "vidar-safefile")
+		expect(err).To(Not(HaveOccurred()))
+		return expect, dir
+	})
+
+	o.AfterEach(func(_ expect.Expectation, dir string) {
+		os.RemoveAll(dir)
+	})
+
+	o.Spec("it creates new files", func(expect expect.Expectation, dir string) {
+		path := filepath.Join(dir, "new.txt")
+		expect(safefile.Write(path, []byte("foo"))).To(Not(HaveOccurred()))
+		expect(read(expect, path)).To(Equal("foo"))
+
+		files, err := ioutil.ReadDir(dir)
+		expect(err).To(Not(HaveOccurred()))
+		expect(files).To(HaveLen(1))
+	})
+
+	o.Spec("it keeps the mode of existing files", func(expect expect.Expectation, dir string) {
+		if runtime.GOOS == "windows" {
+			return
+		}
+		path := filepath.Join(dir, "script.sh")
+		expect(ioutil.WriteFile(path, []byte("old"), 0700)).To(Not(HaveOccurred()))
+		expect(os.Chmod(path, 0750)).To(Not(HaveOccurred()))
+
+		expect(safefile.Write(path, []byte("new"))).To(Not(HaveOccurred()))
+		expect(read(expect, path)).To(Equal("new"))
+		finfo, err := os.Stat(path)
+		expect(err).To(Not(HaveOccurred()))
+		expect(finfo.Mode().Perm()).To(Equal(os.FileMode(0750)))
+	})
+
+	o.Spec("it writes through symlinks", func(expect expect.Expectation, dir string) {
+		if runtime.GOOS == "windows" {
+			return
+		}
+		target := filepath.Join(dir, "target.txt")
+		expect(ioutil.WriteFile(target, []byte("old"), 0644)).To(Not(HaveOccurred()))
+		link := filepath.Join(dir, "link.txt")
+		expect(os.Symlink("target.txt", link)).To(Not(HaveOccurred()))
+
+		expect(safefile.Write(link, []byte("new"))).To(Not(HaveOccurred()))
+		expect(read(expect, target)).To(Equal("new"))
+		finfo, err := os.Lstat(link)
+		expect(err).To(Not(HaveOccurred()))
+		expect(finfo.Mode() & os.ModeSymlink).To(Equal(os.ModeSymlink))
+	})
+
+	o.Spec("it resolves symlinks to files that don't exist yet", func(expect expect.Expectation, dir string) {
+		if runtime.GOOS == "windows" {
+			return
+		}
+		link := filepath.Join(dir, "link.txt")
+		expect(os.Symlink("missing.txt", link)).To(Not(HaveOccurred()))
+		resolved, err := safefile.Resolve(link)
+		expect(err).To(Not(HaveOccurred()))
+		expect(resolved).To(Equal(filepath.Join(dir, "missing.txt")))
+	})
+}
+
+func TestBackup(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, string) {
+		expect := expect.New(t)
+		dir, err := ioutil.TempDir("", "vidar-safefile")
+		expect(err).To(Not(HaveOccurred()))
+		return expect, dir
+	})
+
+	o.AfterEach(func(_ expect.Expectation, dir string) {
+		os.RemoveAll(dir)
+	})
+
+	o.Spec("it rotates backups", func(expect expect.Expectation, dir string) {
+		path := filepath.Join(dir, "foo.txt")
+		backups := filepath.Join(dir, "backups")
+		for _, text := range []string{"one", "two", "three", "four"} {
+			expect(safefile.Backup(path, backups, 2)).To(Not(HaveOccurred()))
+			expect(safefile.Write(path, []byte(text))).To(Not(HaveOccurred()))
+		}
+
+		base := filepath.Join(backups, safefile.BackupName(path))
+		expect(read(expect, base+".~1~")).To(Equal("three"))
+		expect(read(expect, base+".~2~")).To(Equal("two"))
+		_, err := os.Stat(base + ".~3~")
+		expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	o.Spec("it does nothing when turned off", func(expect expect.Expectation, dir string) {
+		path := filepath.Join(dir, "foo.txt")
+		expect(ioutil.WriteFile(path, []byte("foo"), 0644)).To(Not(HaveOccurred()))
+		backups := filepath.Join(dir, "backups")
+		expect(safefile.Backup(path, backups, 0)).To(Not(HaveOccurred()))
+		_, err := os.Stat(backups)
+		expect(os.IsNotExist(err)).To(BeTrue())
+	})
+}
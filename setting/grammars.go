@@ -0,0 +1,18 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package setting
+
+import "path/filepath"
+
+// grammarsDir is the directory in the config directory that
+// TextMate grammar files are loaded from.
+const grammarsDir = "grammars"
+
+// GrammarsDir returns the directory that the user's TextMate
+// grammars (*.tmLanguage.json files) are loaded from, e.g.
+// ~/.config/vidar/grammars.
+func GrammarsDir() string {
+	return filepath.Join(defaultConfigDir, grammarsDir)
+}
@@ -14,6 +14,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/OpenPeeDeeP/xdg"
@@ -87,6 +88,8 @@ func init() {
 		log.Printf("Error reading settings: %s", err)
 	}
 	settings.SetDefault("fonts", []Font(nil))
+	settings.SetDefault("autoclose", map[string]string(nil))
+	settings.SetDefault("backup", Backup{})
 }
 
 func updateDeprecatedGopath(c *config.Config) error {
@@ -121,9 +124,128 @@ type Project struct {
 	Path string
 	Env  map[string]string
 
+	// Format is the list of formatter chains that files in the
+	// project are run through before they are saved.
+	Format []FormatChain `toml:",omitempty" json:",omitempty" yaml:",omitempty"`
+
+	// Build is the list of arguments passed to `go build` by the
+	// build command.  It defaults to DefaultBuildArgs.
+	Build []string `toml:",omitempty" json:",omitempty" yaml:",omitempty"`
+
 	// Gopath is deprecated.  It is now merged into Env.
 	// It's kept here for migration purposes.
-	Gopath string `toml:",omitempty" json:",omitempty" yaml:",omitempty`
+	Gopath string `toml:",omitempty" json:",omitempty" yaml:",omitempty"`
+}
+
+// FormatChain is an ordered list of formatters to run on any file
+// matching Glob.
+type FormatChain struct {
+	// Glob is matched against both the file's name and its path
+	// relative to the project, using filepath.Match.
+	Glob       string
+	Formatters []Formatter
+}
+
+// Formatter is a command that formats files before they are saved.
+// The file's contents are written to the command's stdin, and the
+// formatted contents are read from its stdout.
+type Formatter struct {
+	// Name is used to identify the formatter in errors.  It defaults
+	// to Command.
+	Name    string
+	Command string
+
+	// Args are passed to Command after environment variables in them
+	// are expanded.  $FILE expands to the path of the file being
+	// saved.
+	Args []string
+
+	// Env is added to the project's environment, the same way that
+	// the project's Env is added to the OS environment.
+	Env map[string]string
+
+	// Timeout is the longest that the formatter may run, as parsed by
+	// time.ParseDuration.  It defaults to DefaultFormatTimeout.
+	Timeout string
+
+	// Blocking prevents the file from being saved if the formatter
+	// fails.
+	Blocking bool
+}
+
+// DefaultFormatTimeout is the timeout used for formatters that don't
+// set one.
+const DefaultFormatTimeout = 10 * time.Second
+
+// String returns f's name.
+func (f Formatter) String() string {
+	if f.Name != "" {
+		return f.Name
+	}
+	return f.Command
+}
+
+// Duration returns the parsed value of f.Timeout, falling back to
+// DefaultFormatTimeout if it is empty or can't be parsed.
+func (f Formatter) Duration() time.Duration {
+	if f.Timeout == "" {
+		return DefaultFormatTimeout
+	}
+	d, err := time.ParseDuration(f.Timeout)
+	if err != nil {
+		log.Printf("Error parsing timeout for formatter %s: %s", f, err)
+		return DefaultFormatTimeout
+	}
+	return d
+}
+
+// Environ returns the environment that f should run in as part of
+// p.
+func (f Formatter) Environ(p Project) []string {
+	environ := p.Environ()
+	for k, v := range f.Env {
+		environ = addEnv(environ, k, v)
+	}
+	return environ
+}
+
+// Formatters returns the formatters that should run on path, in the
+// order that they should run.
+func (p Project) Formatters(path string) []Formatter {
+	rel, err := filepath.Rel(p.Path, path)
+	if err != nil {
+		rel = path
+	}
+	var formatters []Formatter
+	for _, c := range p.Format {
+		if !matches(c.Glob, filepath.Base(path)) && !matches(c.Glob, rel) {
+			continue
+		}
+		formatters = append(formatters, c.Formatters...)
+	}
+	return formatters
+}
+
+func matches(glob, path string) bool {
+	ok, err := filepath.Match(glob, path)
+	if err != nil {
+		log.Printf("Error matching format glob %s: %s", glob, err)
+		return false
+	}
+	return ok
+}
+
+// DefaultBuildArgs are the arguments passed to `go build` for
+// projects that don't configure their own.
+var DefaultBuildArgs = []string{"./..."}
+
+// BuildArgs returns the arguments that should be passed to `go build`
+// when building p.
+func (p Project) BuildArgs() []string {
+	if len(p.Build) == 0 {
+		return DefaultBuildArgs
+	}
+	return p.Build
 }
 
 func (p Project) LicenseHeader() string {
@@ -246,6 +368,47 @@ func Projects() (projs []Project) {
 	return projs
 }
 
+// AutoClose returns the pairs of runes that should be closed
+// automatically in files with the extension ext (without the leading
+// dot), as a string of opening and closing runes (e.g. "()[]").  If
+// the user has not configured pairs for ext, ok will be false.
+func AutoClose(ext string) (pairs string, ok bool) {
+	all, _ := settings.Get("autoclose").(map[string]string)
+	pairs, ok = all[strings.ToLower(ext)]
+	return pairs, ok
+}
+
+// DefaultBackupCount is the number of backups kept of each file when
+// backups are turned on without setting a count.
+const DefaultBackupCount = 3
+
+// Backup configures the backups that are made of files before they
+// are saved over.
+type Backup struct {
+	// Dir is the directory that backups are written to.  Environment
+	// variables in it are expanded.  Backups are turned off if it is
+	// empty.
+	Dir string
+
+	// Count is the number of backups kept of each file.  It defaults
+	// to DefaultBackupCount.
+	Count int
+}
+
+// Backups returns the user's backup settings, with Dir expanded and
+// Count defaulted.
+func Backups() Backup {
+	b, _ := settings.Get("backup").(Backup)
+	if b.Dir == "" {
+		return b
+	}
+	b.Dir = os.ExpandEnv(b.Dir)
+	if b.Count <= 0 {
+		b.Count = DefaultBackupCount
+	}
+	return b
+}
+
 func AddProject(project Project) {
 	projects.Set("projects", append(Projects(), project))
 	if err := projects.Write(); err != nil {
@@ -0,0 +1,31 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package setting
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/nelsam/vidar/setting/config"
+	"github.com/nelsam/vidar/snippet"
+)
+
+// snippetsDir is the directory in the config directory that snippet
+// files are loaded from.
+const snippetsDir = "snippets"
+
+// Snippets loads the user's snippets for files with the extension
+// ext (without the leading dot) from the snippets directory in the
+// config directory, e.g. ~/.config/vidar/snippets/go.toml.  The file
+// is read each time, so changes to it apply without a restart.
+func Snippets(ext string) ([]snippet.Snippet, error) {
+	c, err := config.New(opener{}, strings.ToLower(ext), filepath.Join(defaultConfigDir, snippetsDir))
+	if err != nil {
+		return nil, err
+	}
+	c.SetDefault("snippets", []snippet.Snippet(nil))
+	s, _ := c.Get("snippets").([]snippet.Snippet)
+	return s, nil
+}
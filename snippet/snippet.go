@@ -0,0 +1,351 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package snippet parses and expands snippets: templates of text
+// with numbered tab stops, placeholders and variables.
+//
+// A snippet's body uses the following syntax:
+//
+//	$1, ${1}          a tab stop
+//	${1:text}         a tab stop with placeholder text, which may
+//	                  contain other tab stops and variables
+//	$0                the final caret position, after all other
+//	                  tab stops (the end of the snippet if absent)
+//	$NAME, ${NAME}    a variable, like $FILENAME
+//	${NAME:text}      a variable with default text, used when the
+//	                  variable is empty
+//	\$, \}, \\        a literal $, } or \
+//
+// A tab stop number that appears more than once is mirrored: every
+// occurrence is given the same placeholder text, and all of them are
+// selected together when the tab stop is reached.
+package snippet
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// The variables that editors provide when expanding snippets.
+const (
+	// FileName is the base name of the file being edited.
+	FileName = "FILENAME"
+
+	// FilePath is the full path of the file being edited.
+	FilePath = "FILEPATH"
+
+	// Directory is the directory of the file being edited.
+	Directory = "DIRECTORY"
+
+	// Package is the name of the package that the file is in.
+	Package = "PACKAGE"
+
+	// Selection is the text that was selected when the snippet was
+	// expanded.
+	Selection = "SELECTION"
+)
+
+// Snippet is a snippet that can be expanded by typing its Prefix.
+type Snippet struct {
+	Prefix      string
+	Description string
+	Body        string
+}
+
+// Vars holds the values of the variables that a snippet may use.
+type Vars map[string]string
+
+// Field is the span of runes in an expanded snippet that a tab stop
+// occupies.
+type Field struct {
+	Start, End int
+}
+
+// Edited returns f after the removed runes starting at at are
+// replaced with added runes.  Text inserted at either end of f
+// becomes part of it, so that typing in an empty field fills it in.
+// An edit that replaces all of f along with text around it (like
+// typing over a placeholder that f is nested in) leaves f empty at
+// the end of the new text.  It returns false if the edit changes only
+// part of f along with text outside of it.
+func (f Field) Edited(at, removed, added int) (Field, bool) {
+	oldEnd := at + removed
+	delta := added - removed
+	switch {
+	case at > f.End || (at == f.End && removed > 0):
+		return f, true
+	case oldEnd < f.Start || (oldEnd == f.Start && removed > 0):
+		return Field{Start: f.Start + delta, End: f.End + delta}, true
+	case at >= f.Start && oldEnd <= f.End:
+		return Field{Start: f.Start, End: f.End + delta}, true
+	case at <= f.Start && oldEnd >= f.End:
+		return Field{Start: at + added, End: at + added}, true
+	default:
+		return f, false
+	}
+}
+
+// Stop is a tab stop in an expanded snippet, with a field for each
+// place that it appears.
+type Stop struct {
+	Index  int
+	Fields []Field
+}
+
+// Expansion is the result of expanding a snippet.  Its Stops are in
+// the order that they should be visited, ending with stop 0.
+type Expansion struct {
+	Text  []rune
+	Stops []Stop
+}
+
+// Expand parses body and expands it using vars.  Every line after
+// the first is prefixed with indent, so that the snippet matches the
+// indentation of the line it is expanded on.
+func Expand(body string, vars Vars, indent string) (Expansion, error) {
+	nodes, err := parse(body)
+	if err != nil {
+		return Expansion{}, err
+	}
+	x := &expander{
+		vars:         vars,
+		indent:       []rune(indent),
+		placeholders: make(map[int][]node),
+		fields:       make(map[int][]Field),
+	}
+	x.collect(nodes)
+	x.expand(nodes, nil)
+	return x.expansion(), nil
+}
+
+type node interface{}
+
+type text string
+
+type tabStop struct {
+	index    int
+	children []node
+}
+
+type variable struct {
+	name     string
+	children []node
+}
+
+type parser struct {
+	body []rune
+	pos  int
+}
+
+func parse(body string) ([]node, error) {
+	p := &parser{body: []rune(body)}
+	return p.nodes(false)
+}
+
+func (p *parser) nodes(nested bool) ([]node, error) {
+	var (
+		nodes []node
+		lit   []rune
+	)
+	flush := func() {
+		if len(lit) > 0 {
+			nodes = append(nodes, text(lit))
+			lit = nil
+		}
+	}
+	for p.pos < len(p.body) {
+		r := p.body[p.pos]
+		switch {
+		case r == '\\' && p.pos+1 < len(p.body) && strings.ContainsRune(`$}\`, p.body[p.pos+1]):
+			lit = append(lit, p.body[p.pos+1])
+			p.pos += 2
+		case r == '}' && nested:
+			flush()
+			return nodes, nil
+		case r == '$':
+			n, ok, err := p.dollar()
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				lit = append(lit, r)
+				p.pos++
+				continue
+			}
+			flush()
+			nodes = append(nodes, n)
+		default:
+			lit = append(lit, r)
+			p.pos++
+		}
+	}
+	if nested {
+		return nil, errors.New("snippet: unclosed ${")
+	}
+	flush()
+	return nodes, nil
+}
+
+// dollar parses the tab stop or variable at p.pos, which is a $.  It
+// returns false if the $ doesn't start one, in which case it is
+// literal text.
+func (p *parser) dollar() (node, bool, error) {
+	start := p.pos
+	p.pos++
+	if p.pos >= len(p.body) {
+		p.pos = start
+		return nil, false, nil
+	}
+	if p.body[p.pos] != '{' {
+		if idx, ok := p.number(); ok {
+			return tabStop{index: idx}, true, nil
+		}
+		if name, ok := p.name(); ok {
+			return variable{name: name}, true, nil
+		}
+		p.pos = start
+		return nil, false, nil
+	}
+	p.pos++
+	idx, isStop := p.number()
+	var name string
+	if !isStop {
+		var ok bool
+		name, ok = p.name()
+		if !ok {
+			return nil, false, fmt.Errorf("snippet: expected a tab stop or variable name at offset %d", p.pos)
+		}
+	}
+	if p.pos >= len(p.body) {
+		return nil, false, errors.New("snippet: unclosed ${")
+	}
+	var children []node
+	switch p.body[p.pos] {
+	case '}':
+	case ':':
+		p.pos++
+		var err error
+		children, err = p.nodes(true)
+		if err != nil {
+			return nil, false, err
+		}
+	default:
+		return nil, false, fmt.Errorf("snippet: unexpected %q at offset %d", p.body[p.pos], p.pos)
+	}
+	p.pos++
+	if isStop {
+		return tabStop{index: idx, children: children}, true, nil
+	}
+	return variable{name: name, children: children}, true, nil
+}
+
+func (p *parser) number() (int, bool) {
+	n, start := 0, p.pos
+	for p.pos < len(p.body) && '0' <= p.body[p.pos] && p.body[p.pos] <= '9' {
+		n = n*10 + int(p.body[p.pos]-'0')
+		p.pos++
+	}
+	return n, p.pos > start
+}
+
+func (p *parser) name() (string, bool) {
+	start := p.pos
+	for p.pos < len(p.body) {
+		r := p.body[p.pos]
+		if r != '_' && !unicode.IsLetter(r) && (p.pos == start || !unicode.IsDigit(r)) {
+			break
+		}
+		p.pos++
+	}
+	return string(p.body[start:p.pos]), p.pos > start
+}
+
+type expander struct {
+	vars         Vars
+	indent       []rune
+	placeholders map[int][]node
+	fields       map[int][]Field
+	out          []rune
+}
+
+// collect finds the first placeholder given for each tab stop, so
+// that mirrors before it are given the same text.
+func (x *expander) collect(nodes []node) {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case tabStop:
+			if _, ok := x.placeholders[n.index]; !ok && len(n.children) > 0 {
+				x.placeholders[n.index] = n.children
+			}
+			x.collect(n.children)
+		case variable:
+			x.collect(n.children)
+		}
+	}
+}
+
+// expand writes nodes to x.out.  mirroring holds the tab stops whose
+// placeholders are being copied, to stop a placeholder that mirrors
+// its own tab stop from repeating forever.
+func (x *expander) expand(nodes []node, mirroring map[int]bool) {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case text:
+			for _, r := range n {
+				x.out = append(x.out, r)
+				if r == '\n' {
+					x.out = append(x.out, x.indent...)
+				}
+			}
+		case tabStop:
+			start := len(x.out)
+			children, nested := n.children, mirroring
+			if len(children) == 0 && !mirroring[n.index] {
+				children = x.placeholders[n.index]
+				nested = with(mirroring, n.index)
+			}
+			x.expand(children, nested)
+			x.fields[n.index] = append(x.fields[n.index], Field{Start: start, End: len(x.out)})
+		case variable:
+			if v := x.vars[n.name]; v != "" {
+				x.out = append(x.out, []rune(v)...)
+				continue
+			}
+			x.expand(n.children, mirroring)
+		}
+	}
+}
+
+func (x *expander) expansion() Expansion {
+	if _, ok := x.fields[0]; !ok {
+		x.fields[0] = []Field{{Start: len(x.out), End: len(x.out)}}
+	}
+	e := Expansion{Text: x.out}
+	for idx, fields := range x.fields {
+		sort.Slice(fields, func(i, j int) bool {
+			return fields[i].Start < fields[j].Start
+		})
+		e.Stops = append(e.Stops, Stop{Index: idx, Fields: fields})
+	}
+	sort.Slice(e.Stops, func(i, j int) bool {
+		a, b := e.Stops[i].Index, e.Stops[j].Index
+		if a == 0 || b == 0 {
+			return b == 0 && a != 0
+		}
+		return a < b
+	})
+	return e
+}
+
+func with(m map[int]bool, idx int) map[int]bool {
+	n := make(map[int]bool, len(m)+1)
+	for k, v := range m {
+		n[k] = v
+	}
+	n[idx] = true
+	return n
+}
@@ -0,0 +1,123 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package snippet_test
+
+import (
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/snippet"
+)
+
+func TestExpand(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) expect.Expectation {
+		return expect.New(t)
+	})
+
+	o.Spec("it visits tab stops in order, ending with stop 0", func(expect expect.Expectation) {
+		x, err := snippet.Expand("for ${1:i} := 0; $1 < ${2:n}; $1++ {\n\t$0\n}", nil, "\t")
+		expect(err).To(Not(HaveOccurred()))
+		expect(string(x.Text)).To(Equal("for i := 0; i < n; i++ {\n\t\t\n\t}"))
+		expect(x.Stops).To(Equal([]snippet.Stop{
+			{Index: 1, Fields: []snippet.Field{{Start: 4, End: 5}, {Start: 12, End: 13}, {Start: 19, End: 20}}},
+			{Index: 2, Fields: []snippet.Field{{Start: 16, End: 17}}},
+			{Index: 0, Fields: []snippet.Field{{Start: 27, End: 27}}},
+		}))
+	})
+
+	o.Spec("it mirrors placeholders given after the first use of a stop", func(expect expect.Expectation) {
+		x, err := snippet.Expand("$1 ${1:foo}", nil, "")
+		expect(err).To(Not(HaveOccurred()))
+		expect(string(x.Text)).To(Equal("foo foo"))
+		expect(x.Stops[0].Fields).To(Equal([]snippet.Field{{Start: 0, End: 3}, {Start: 4, End: 7}}))
+	})
+
+	o.Spec("it puts the final caret at the end without a $0", func(expect expect.Expectation) {
+		x, err := snippet.Expand("if err != nil {\n\treturn ${1:err}\n}", nil, "")
+		expect(err).To(Not(HaveOccurred()))
+		expect(x.Stops).To(HaveLen(2))
+		expect(x.Stops[1]).To(Equal(snippet.Stop{Index: 0, Fields: []snippet.Field{{Start: 29, End: 29}}}))
+	})
+
+	o.Spec("it nests tab stops and variables in placeholders", func(expect expect.Expectation) {
+		vars := snippet.Vars{snippet.Package: "foo"}
+		x, err := snippet.Expand("${1:func ${2:Test$PACKAGE}()}", vars, "")
+		expect(err).To(Not(HaveOccurred()))
+		expect(string(x.Text)).To(Equal("func Testfoo()"))
+		expect(x.Stops[0].Fields).To(Equal([]snippet.Field{{Start: 0, End: 14}}))
+		expect(x.Stops[1].Fields).To(Equal([]snippet.Field{{Start: 5, End: 12}}))
+	})
+
+	o.Spec("it uses variable defaults when they are empty", func(expect expect.Expectation) {
+		vars := snippet.Vars{snippet.FileName: "foo.go", snippet.Selection: ""}
+		x, err := snippet.Expand("${FILENAME} ${SELECTION:${1:none}} $UNKNOWN.", vars, "")
+		expect(err).To(Not(HaveOccurred()))
+		expect(string(x.Text)).To(Equal("foo.go none ."))
+		expect(x.Stops[0].Fields).To(Equal([]snippet.Field{{Start: 7, End: 11}}))
+	})
+
+	o.Spec("it handles escapes and literal dollar signs", func(expect expect.Expectation) {
+		x, err := snippet.Expand(`\$1 costs $ 5 \\ ${1:\}}`, nil, "")
+		expect(err).To(Not(HaveOccurred()))
+		expect(string(x.Text)).To(Equal(`$1 costs $ 5 \ }`))
+	})
+
+	o.Spec("it reports unclosed and invalid fields", func(expect expect.Expectation) {
+		_, err := snippet.Expand("${1:foo", nil, "")
+		expect(err).To(HaveOccurred())
+		_, err = snippet.Expand("${-}", nil, "")
+		expect(err).To(HaveOccurred())
+		_, err = snippet.Expand("${1 }", nil, "")
+		expect(err).To(HaveOccurred())
+	})
+}
+
+func TestFieldEdited(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, snippet.Field) {
+		return expect.New(t), snippet.Field{Start: 10, End: 15}
+	})
+
+	o.Spec("it grows when text is typed in or around it", func(expect expect.Expectation, f snippet.Field) {
+		for _, at := range []int{10, 12, 15} {
+			moved, ok := f.Edited(at, 0, 2)
+			expect(ok).To(BeTrue())
+			expect(moved).To(Equal(snippet.Field{Start: 10, End: 17}))
+		}
+		moved, ok := f.Edited(10, 5, 1)
+		expect(ok).To(BeTrue())
+		expect(moved).To(Equal(snippet.Field{Start: 10, End: 11}))
+	})
+
+	o.Spec("it follows edits before it and ignores edits after it", func(expect expect.Expectation, f snippet.Field) {
+		moved, ok := f.Edited(2, 8, 3)
+		expect(ok).To(BeTrue())
+		expect(moved).To(Equal(snippet.Field{Start: 5, End: 10}))
+
+		moved, ok = f.Edited(15, 3, 0)
+		expect(ok).To(BeTrue())
+		expect(moved).To(Equal(f))
+	})
+
+	o.Spec("it collapses when an edit replaces all of it", func(expect expect.Expectation, f snippet.Field) {
+		moved, ok := f.Edited(8, 10, 3)
+		expect(ok).To(BeTrue())
+		expect(moved).To(Equal(snippet.Field{Start: 11, End: 11}))
+	})
+
+	o.Spec("it fails when an edit crosses its edge", func(expect expect.Expectation, f snippet.Field) {
+		_, ok := f.Edited(8, 4, 0)
+		expect(ok).To(BeFalse())
+		_, ok = f.Edited(14, 2, 0)
+		expect(ok).To(BeFalse())
+	})
+}
@@ -4,12 +4,20 @@
 
 package suggestion
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/nelsam/vidar/snippet"
+)
 
 // A suggestion is a simple implementation of gxui.CodeSuggestion.
 type Suggestion struct {
 	Name      string
 	Signature string
+
+	// Snippet is set for suggestions that expand a snippet, rather
+	// than inserting Name.
+	Snippet *snippet.Snippet
 }
 
 // String handles displaying the suggestion.
@@ -0,0 +1,112 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package syntax
+
+import (
+	"go/scanner"
+	"go/token"
+	"sort"
+
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/theme"
+)
+
+var closers = map[byte]byte{
+	'(': ')',
+	'[': ']',
+	'{': '}',
+}
+
+// Pair is a matching pair of brackets.  Open and Close are the rune
+// offsets of the opening and closing brackets.
+type Pair struct {
+	Open, Close int
+}
+
+// Pairs returns the pairs of matching brackets found the last time
+// that s.Parse was called, sorted by the position of their opening
+// bracket.
+func (s *Syntax) Pairs() []Pair {
+	pairs := append([]Pair(nil), s.pairs...)
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].Open < pairs[j].Open
+	})
+	return pairs
+}
+
+// addPair adds open and close as a Pair, as long as they are both
+// brackets and the closing bracket matches the opening bracket.
+// Positions in the AST for missing brackets point at whatever was
+// found instead, so they have to be checked.
+func (s *Syntax) addPair(open, close token.Pos) {
+	if !open.IsValid() || !close.IsValid() {
+		return
+	}
+	openOff, closeOff := s.fileSet.Position(open).Offset, s.fileSet.Position(close).Offset
+	if openOff >= len(s.source) || closeOff >= len(s.source) {
+		return
+	}
+	if closers[s.source[openOff]] == 0 || closers[s.source[openOff]] != s.source[closeOff] {
+		return
+	}
+	s.pairs = append(s.pairs, Pair{Open: s.runePos(openOff), Close: s.runePos(closeOff)})
+}
+
+// addUnbalanced highlights every bracket in the source that does
+// not have a match as theme.Bad.  Brackets in strings and comments
+// are ignored.
+func (s *Syntax) addUnbalanced() {
+	file := token.NewFileSet().AddFile("", -1, len(s.source))
+	var scan scanner.Scanner
+	scan.Init(file, []byte(s.source), func(token.Position, string) {}, 0)
+
+	var (
+		opened []int
+		bad    []int
+	)
+	for {
+		pos, tok, _ := scan.Scan()
+		if tok == token.EOF {
+			break
+		}
+		off := file.Offset(pos)
+		switch tok {
+		case token.LPAREN, token.LBRACK, token.LBRACE:
+			opened = append(opened, off)
+		case token.RPAREN, token.RBRACK, token.RBRACE:
+			// If the closer matches a bracket further up the
+			// stack, the brackets in between were never closed.
+			match := -1
+			for i := len(opened) - 1; i >= 0; i-- {
+				if closers[s.source[opened[i]]] == s.source[off] {
+					match = i
+					break
+				}
+			}
+			if match == -1 {
+				bad = append(bad, off)
+				continue
+			}
+			bad = append(bad, opened[match+1:]...)
+			opened = opened[:match]
+		}
+	}
+	bad = append(bad, opened...)
+	if len(bad) == 0 {
+		return
+	}
+	layer, ok := s.layers[theme.Bad]
+	if !ok {
+		layer = &input.SyntaxLayer{Construct: theme.Bad}
+		s.layers[theme.Bad] = layer
+	}
+	for _, off := range bad {
+		start := s.runePos(off)
+		span := input.Span{Start: start, End: start + 1}
+		if !covered(layer.Spans, span) {
+			layer.Spans = append(layer.Spans, span)
+		}
+	}
+}
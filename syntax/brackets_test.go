@@ -0,0 +1,82 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package syntax_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/syntax"
+	"github.com/nelsam/vidar/theme"
+)
+
+func TestBrackets(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, *syntax.Syntax) {
+		return expect.New(t), syntax.New()
+	})
+
+	o.Spec("it pairs matching brackets", func(expect expect.Expectation, s *syntax.Syntax) {
+		const src = `
+		package foo
+
+		func foo(x []int) {
+			println(x[0])
+		}
+		`
+		err := s.Parse(src)
+		expect(err).To(BeNil())
+
+		pairs := s.Pairs()
+		expect(pairs).To(HaveLen(5))
+		expect(pairs[0]).To(Equal(syntax.Pair{Open: strings.Index(src, "(x"), Close: strings.Index(src, ") {")}))
+		expect(pairs[1]).To(Equal(syntax.Pair{Open: strings.Index(src, "[]"), Close: strings.Index(src, "[]") + 1}))
+		expect(pairs[2]).To(Equal(syntax.Pair{Open: strings.Index(src, "{"), Close: strings.LastIndex(src, "}")}))
+		expect(pairs[3]).To(Equal(syntax.Pair{Open: strings.Index(src, "(x["), Close: strings.Index(src, "])") + 1}))
+		expect(pairs[4]).To(Equal(syntax.Pair{Open: strings.Index(src, "[0"), Close: strings.Index(src, "])")}))
+	})
+
+	o.Spec("it flags unbalanced brackets", func(expect expect.Expectation, s *syntax.Syntax) {
+		const src = `
+		package foo
+
+		func foo() {
+			println("(", (1)
+		}
+		`
+		err := s.Parse(src)
+		expect(err).To(Not(BeNil()))
+
+		bad := findLayer(theme.Bad, s.Layers())
+		var found bool
+		for _, span := range bad.Spans {
+			if span.Start == strings.Index(src, "println(")+len("println") {
+				found = true
+			}
+		}
+		expect(found).To(BeTrue())
+	})
+
+	o.Spec("it ignores brackets in strings and comments", func(expect expect.Expectation, s *syntax.Syntax) {
+		const src = `
+		package foo
+
+		// foo does things (
+		func foo() {
+			println("[")
+		}
+		`
+		err := s.Parse(src)
+		expect(err).To(BeNil())
+
+		bad := findLayer(theme.Bad, s.Layers())
+		expect(bad.Spans).To(HaveLen(0))
+	})
+}
@@ -29,6 +29,7 @@ func (s *Syntax) addBadDecl(decl *ast.BadDecl) {
 }
 
 func (s *Syntax) addFuncDecl(decl *ast.FuncDecl) {
+	s.addFold(decl.Pos(), decl.End())
 	if decl.Recv != nil {
 		s.addFieldList(decl.Recv)
 	}
@@ -48,6 +49,7 @@ func (s *Syntax) addGenDecl(decl *ast.GenDecl) {
 		log.Printf("Error: Don't know how to handle token %v", decl.Tok)
 		return
 	}
+	s.addFold(decl.Pos(), decl.End())
 	if decl.Lparen != 0 && decl.Rparen != 0 {
 		defer s.rainbowScope(decl.Lparen, 1, decl.Rparen, 1)()
 	}
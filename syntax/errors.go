@@ -0,0 +1,103 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package syntax
+
+import (
+	"go/scanner"
+	"unicode"
+
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/theme"
+)
+
+// Error is a syntax error found while parsing source code.
+type Error struct {
+	// Span is the span of runes that the error refers to.
+	Span input.Span
+
+	// Line and Column are the 1-based line and column (in bytes)
+	// of the error, for display purposes.
+	Line, Column int
+
+	Msg string
+}
+
+// Errors returns the syntax errors found the last time that s.Parse
+// was called, in the order that they appear in the source.
+func (s *Syntax) Errors() []Error {
+	return s.errs
+}
+
+// addErrors adds a theme.Bad span for each error in err, keeping
+// at most one error per line.  It returns err if it isn't a
+// scanner.ErrorList, or the first of the errors that it kept
+// otherwise.
+func (s *Syntax) addErrors(source []rune, err error) error {
+	list, ok := err.(scanner.ErrorList)
+	if !ok {
+		return err
+	}
+	// The parser reports every error it finds when AllErrors is
+	// set, which includes errors that are just side effects of the
+	// first error on a line.
+	list.RemoveMultiples()
+	for _, e := range list {
+		span := s.errSpan(source, e.Pos.Offset)
+		s.errs = append(s.errs, Error{
+			Span:   span,
+			Line:   e.Pos.Line,
+			Column: e.Pos.Column,
+			Msg:    e.Msg,
+		})
+		layer, ok := s.layers[theme.Bad]
+		if !ok {
+			layer = &input.SyntaxLayer{Construct: theme.Bad}
+			s.layers[theme.Bad] = layer
+		}
+		if !covered(layer.Spans, span) {
+			layer.Spans = append(layer.Spans, span)
+		}
+	}
+	return list.Err()
+}
+
+// covered returns whether or not span starts within one of spans.
+// Bad declarations, expressions and statements are already
+// highlighted, so errors inside of them don't need another span.
+func covered(spans []input.Span, span input.Span) bool {
+	for _, s := range spans {
+		if s.Start <= span.Start && span.Start < s.End {
+			return true
+		}
+	}
+	return false
+}
+
+// errSpan returns the span to highlight for an error at byteOffset.
+// Errors at the end of a line or the file (e.g. a missing
+// semicolon) are moved back to the last rune before them, so that
+// there is something visible to highlight.  Errors at the start of
+// a word highlight the whole word.
+func (s *Syntax) errSpan(source []rune, byteOffset int) input.Span {
+	start := len(source)
+	if byteOffset < len(s.runeOffsets) {
+		start = s.runePos(byteOffset)
+	}
+	for start > 0 && (start == len(source) || unicode.IsSpace(source[start])) {
+		start--
+	}
+	if start >= len(source) {
+		return input.Span{Start: start, End: start}
+	}
+	end := start + 1
+	for end < len(source) && isWordRune(source[start]) && isWordRune(source[end]) {
+		end++
+	}
+	return input.Span{Start: start, End: end}
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
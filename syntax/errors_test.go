@@ -0,0 +1,101 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package syntax_test
+
+import (
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/syntax"
+	"github.com/nelsam/vidar/theme"
+)
+
+func TestErrors(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, *syntax.Syntax) {
+		return expect.New(t), syntax.New()
+	})
+
+	o.Spec("it reports every error with its position", func(expect expect.Expectation, s *syntax.Syntax) {
+		const src = `
+		package foo
+
+		func foo() {
+			x := (1
+		}
+
+		func bar() {
+			y := (2
+		}
+		`
+		err := s.Parse(src)
+		expect(err).To(Not(BeNil()))
+
+		errs := s.Errors()
+		expect(errs).To(HaveLen(2))
+		expect(errs[0].Line).To(Equal(5))
+		expect(errs[0].Msg).To(Equal("expected ')', found newline"))
+		expect(errs[1].Line).To(Equal(9))
+
+		// The unclosed parens are flagged as well as the errors.
+		bad := findLayer(theme.Bad, s.Layers())
+		expect(bad.Spans).To(HaveLen(4))
+	})
+
+	o.Spec("it highlights the last rune before an error at the end of a line", func(expect expect.Expectation, s *syntax.Syntax) {
+		const src = `
+		package foo
+
+		var x = (1 + 2
+		`
+		err := s.Parse(src)
+		expect(err).To(Not(BeNil()))
+
+		errs := s.Errors()
+		expect(errs[0].Span).To(matchPosition{src: src, match: "2"})
+	})
+
+	o.Spec("it highlights the rune that an error was found at", func(expect expect.Expectation, s *syntax.Syntax) {
+		const src = `
+		package foo
+
+		var x = ]
+		`
+		err := s.Parse(src)
+		expect(err).To(Not(BeNil()))
+
+		errs := s.Errors()
+		expect(errs[0].Span).To(matchPosition{src: src, match: "]"})
+	})
+
+	o.Spec("it keeps highlighting after more than ten errors", func(expect expect.Expectation, s *syntax.Syntax) {
+		src := "package foo\n\n"
+		for i := 0; i < 12; i++ {
+			src += "func bar() {\n\tx := (1\n}\n\n"
+		}
+		src += "func foo() {}\n"
+		err := s.Parse(src)
+		expect(err).To(Not(BeNil()))
+
+		expect(s.Errors()).To(HaveLen(12))
+		funcs := findLayer(theme.Func, s.Layers())
+		expect(funcs.Spans).To(HaveLen(13))
+		expect(funcs.Spans[12]).To(matchPosition{src: src, match: "foo", idx: 1})
+	})
+
+	o.Spec("it clears errors once they are fixed", func(expect expect.Expectation, s *syntax.Syntax) {
+		expect(s.Parse("package foo\nvar x = ]\n")).To(Not(BeNil()))
+		expect(s.Errors()).To(Not(HaveLen(0)))
+
+		expect(s.Parse("package foo\nvar x = 1\n")).To(BeNil())
+		expect(s.Errors()).To(HaveLen(0))
+		bad := findLayer(theme.Bad, s.Layers())
+		expect(bad.Spans).To(HaveLen(0))
+	})
+}
@@ -31,7 +31,7 @@ func (s *Syntax) addIdentTypeExpr(expr ast.Expr, identType theme.LanguageConstru
 	case *ast.BasicLit:
 		s.addBasicLit(src)
 	case *ast.BinaryExpr:
-		s.addBinaryExpr(src)
+		s.addBinaryExpr(src, identType)
 	case *ast.CallExpr:
 		s.addCallExpr(src)
 	case *ast.ChanType:
@@ -41,7 +41,9 @@ func (s *Syntax) addIdentTypeExpr(expr ast.Expr, identType theme.LanguageConstru
 	case *ast.FuncType:
 		s.addFuncType(src)
 	case *ast.IndexExpr:
-		s.addIndexExpr(src)
+		s.addIndexExpr(src, identType)
+	case *ast.IndexListExpr:
+		s.addIndexListExpr(src, identType)
 	case *ast.InterfaceType:
 		s.addInterfaceType(src)
 	case *ast.KeyValueExpr:
@@ -61,7 +63,7 @@ func (s *Syntax) addIdentTypeExpr(expr ast.Expr, identType theme.LanguageConstru
 	case *ast.TypeAssertExpr:
 		s.addTypeAssertExpr(src)
 	case *ast.UnaryExpr:
-		s.addUnaryExpr(src)
+		s.addUnaryExpr(src, identType)
 	case *ast.Ellipsis:
 		s.addEllipsis(src)
 	case *ast.Ident:
@@ -70,6 +72,8 @@ func (s *Syntax) addIdentTypeExpr(expr ast.Expr, identType theme.LanguageConstru
 			"delete", "imag", "len", "make", "new", "panic",
 			"print", "println", "real", "recover":
 
+			s.addNode(theme.Builtin, src)
+		case "any", "comparable":
 			s.addNode(theme.Builtin, src)
 		case "nil":
 			s.addNode(theme.Nil, src)
@@ -87,9 +91,13 @@ func (s *Syntax) addBadExpr(src *ast.BadExpr) {
 	s.addNode(theme.Bad, src)
 }
 
-func (s *Syntax) addBinaryExpr(src *ast.BinaryExpr) {
-	s.addExpr(src.X)
-	s.addExpr(src.Y)
+// addBinaryExpr adds a binary expression.  In a type context
+// (identType is theme.Type), the only valid binary expression is a
+// union of constraints (e.g. ~int | ~string), so both sides are
+// added as types.
+func (s *Syntax) addBinaryExpr(src *ast.BinaryExpr, identType theme.LanguageConstruct) {
+	s.addIdentTypeExpr(src.X, identType)
+	s.addIdentTypeExpr(src.Y, identType)
 }
 
 func (s *Syntax) addCallExpr(src *ast.CallExpr) {
@@ -101,14 +109,29 @@ func (s *Syntax) addCallExpr(src *ast.CallExpr) {
 }
 
 func (s *Syntax) addFuncLitExpr(src *ast.FuncLit) {
+	s.addFold(src.Pos(), src.End())
 	s.addFuncType(src.Type)
 	s.addBlockStmt(src.Body)
 }
 
-func (s *Syntax) addIndexExpr(src *ast.IndexExpr) {
-	s.addExpr(src.X)
+// addIndexExpr adds an index expression.  In a type context
+// (identType is theme.Type), it is an instantiation of a generic
+// type with a single type argument.
+func (s *Syntax) addIndexExpr(src *ast.IndexExpr, identType theme.LanguageConstruct) {
+	s.addIdentTypeExpr(src.X, identType)
+	defer s.rainbowScope(src.Lbrack, 1, src.Rbrack, 1)()
+	s.addIdentTypeExpr(src.Index, identType)
+}
+
+// addIndexListExpr adds an instantiation of a generic type or
+// function with multiple type arguments.  The type arguments are
+// always added as types.
+func (s *Syntax) addIndexListExpr(src *ast.IndexListExpr, identType theme.LanguageConstruct) {
+	s.addIdentTypeExpr(src.X, identType)
 	defer s.rainbowScope(src.Lbrack, 1, src.Rbrack, 1)()
-	s.addExpr(src.Index)
+	for _, idx := range src.Indices {
+		s.addTypeExpr(idx)
+	}
 }
 
 func (s *Syntax) addKeyValueExpr(src *ast.KeyValueExpr) {
@@ -144,6 +167,9 @@ func (s *Syntax) addTypeAssertExpr(src *ast.TypeAssertExpr) {
 	s.addExpr(src.Type)
 }
 
-func (s *Syntax) addUnaryExpr(src *ast.UnaryExpr) {
-	s.addExpr(src.X)
+// addUnaryExpr adds a unary expression.  In a type context
+// (identType is theme.Type), it is an approximation constraint
+// (e.g. ~int).
+func (s *Syntax) addUnaryExpr(src *ast.UnaryExpr, identType theme.LanguageConstruct) {
+	s.addIdentTypeExpr(src.X, identType)
 }
@@ -0,0 +1,55 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package syntax
+
+import (
+	"go/token"
+	"sort"
+
+	"github.com/nelsam/vidar/commander/input"
+)
+
+// Folds returns the regions of the source code that can be folded,
+// found the last time that s.Parse was called.  They are sorted by
+// their start position; nested regions come after the regions that
+// contain them.
+func (s *Syntax) Folds() []input.FoldRegion {
+	folds := append([]input.FoldRegion(nil), s.folds...)
+	sort.SliceStable(folds, func(i, j int) bool {
+		if folds[i].Start == folds[j].Start {
+			return folds[i].End > folds[j].End
+		}
+		return folds[i].Start < folds[j].Start
+	})
+	var parents []input.FoldRegion
+	for i, f := range folds {
+		for len(parents) > 0 && parents[len(parents)-1].End < f.End {
+			parents = parents[:len(parents)-1]
+		}
+		folds[i].Level = len(parents) + 1
+		parents = append(parents, f)
+	}
+	return folds
+}
+
+// addFold adds a fold region from start to end, if start and end
+// are on different lines.
+func (s *Syntax) addFold(start, end token.Pos) {
+	if !start.IsValid() || !end.IsValid() {
+		return
+	}
+	startPos, endPos := s.fileSet.Position(start), s.fileSet.Position(end)
+	if startPos.Line == endPos.Line {
+		return
+	}
+	span := input.Span{
+		Start: s.runePos(startPos.Offset),
+		End:   s.runePos(endPos.Offset),
+	}
+	if span.Start < 0 || span.End < 0 {
+		return
+	}
+	s.folds = append(s.folds, input.FoldRegion{Span: span})
+}
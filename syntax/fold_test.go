@@ -0,0 +1,92 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package syntax_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/syntax"
+)
+
+func TestFolds(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	const src = `
+	package foo
+
+	import (
+		"fmt"
+		"os"
+	)
+
+	// Foo is a thing.
+	// It does stuff.
+	type Foo struct {
+		Bar string
+	}
+
+	func (f Foo) Baz(args []string) {
+		for _, a := range args {
+			if a == "" {
+				continue
+			} else {
+				fmt.Println(a)
+			}
+		}
+		x := []int{
+			1,
+		}
+		switch len(x) {
+		case 1:
+			os.Exit(1)
+		}
+		if true { return }
+	}
+	`
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, []input.FoldRegion) {
+		expect := expect.New(t)
+		s := syntax.New()
+		err := s.Parse(src)
+		expect(err).To(BeNil())
+		return expect, s.Folds()
+	})
+
+	region := func(start, end string) input.FoldRegion {
+		return input.FoldRegion{Span: input.Span{
+			Start: strings.Index(src, start),
+			End:   strings.Index(src, end) + len(end),
+		}}
+	}
+
+	o.Spec("it finds regions in order with their nesting level", func(expect expect.Expectation, folds []input.FoldRegion) {
+		expected := []struct {
+			start, end string
+			level      int
+		}{
+			{"import (", "\"os\"\n\t)", 1},
+			{"// Foo is", "stuff.", 1},
+			{"type Foo", "string\n\t}", 1},
+			{"func (f Foo)", "return }\n\t}", 1},
+			{"for _, a", "Println(a)\n\t\t\t}\n\t\t}", 2},
+			{"if a ==", "continue\n\t\t\t}", 3},
+			{"{\n\t\t\t\tfmt", "Println(a)\n\t\t\t}", 3},
+			{"[]int{", "1,\n\t\t}", 2},
+			{"switch", "Exit(1)\n\t\t}", 2},
+		}
+		expect(folds).To(HaveLen(len(expected)))
+		for i, e := range expected {
+			r := region(e.start, e.end)
+			r.Level = e.level
+			expect(folds[i]).To(Equal(r))
+		}
+	})
+}
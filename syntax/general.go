@@ -29,6 +29,9 @@ func (s *Syntax) addFuncType(src *ast.FuncType) {
 	if src.Func != token.NoPos {
 		s.add(theme.Keyword, src.Func, len("func"))
 	}
+	if src.TypeParams != nil {
+		s.addFieldList(src.TypeParams)
+	}
 	if src.Params != nil {
 		s.addFieldList(src.Params)
 	}
@@ -81,6 +84,7 @@ func (s *Syntax) addBasicLit(src *ast.BasicLit) {
 }
 
 func (s *Syntax) addCompositeLit(src *ast.CompositeLit) {
+	s.addFold(src.Pos(), src.End())
 	s.addExpr(src.Type)
 	defer s.rainbowScope(src.Lbrace, 1, src.Rbrace, 1)()
 	for _, elt := range src.Elts {
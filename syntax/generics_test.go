@@ -0,0 +1,178 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package syntax_test
+
+import (
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/syntax"
+	"github.com/nelsam/vidar/theme"
+)
+
+func TestGenerics(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) expect.Expectation {
+		return expect.New(t)
+	})
+
+	o.Group("FuncTypeParams", func() {
+		const src = `
+		package foo
+
+		func Map[T, U any](s []T, f func(T) U) []U {
+			return nil
+		}
+		`
+
+		o.BeforeEach(func(expect expect.Expectation) (expect.Expectation, []input.SyntaxLayer) {
+			s := syntax.New()
+			err := s.Parse(src)
+			expect(err).To(BeNil())
+
+			return expect, s.Layers()
+		})
+
+		o.Spec("it highlights any as a builtin", func(expect expect.Expectation, layers []input.SyntaxLayer) {
+			builtins := findLayer(theme.Builtin, layers)
+			expect(builtins.Spans).To(HaveLen(1))
+			expect(builtins.Spans[0]).To(matchPosition{src: src, match: "any"})
+		})
+
+		o.Spec("it highlights type parameters used as types", func(expect expect.Expectation, layers []input.SyntaxLayer) {
+			typs := findLayer(theme.Type, layers)
+			expect(typs.Spans).To(HaveLen(4))
+			expect(typs.Spans[0]).To(matchPosition{src: src, match: "T", idx: 1})
+			expect(typs.Spans[1]).To(matchPosition{src: src, match: "T", idx: 2})
+			expect(typs.Spans[2]).To(matchPosition{src: src, match: "U", idx: 1})
+			expect(typs.Spans[3]).To(matchPosition{src: src, match: "U", idx: 2})
+		})
+
+		o.Spec("it highlights the type parameter brackets as the outer-most scope", func(expect expect.Expectation, layers []input.SyntaxLayer) {
+			outerScope := findLayer(theme.ScopePair, layers)
+			expect(outerScope.Spans).To(HaveLen(8))
+			expect(outerScope.Spans[0]).To(matchPosition{src: src, match: "["})
+			expect(outerScope.Spans[1]).To(matchPosition{src: src, match: "]"})
+			expect(outerScope.Spans[2]).To(matchPosition{src: src, match: "("})
+			expect(outerScope.Spans[3]).To(matchPosition{src: src, match: ")", idx: 1})
+		})
+	})
+
+	o.Group("TypeSpecTypeParams", func() {
+		const src = `
+		package foo
+
+		type Set[K comparable] map[K]struct{}
+		`
+
+		o.BeforeEach(func(expect expect.Expectation) (expect.Expectation, []input.SyntaxLayer) {
+			s := syntax.New()
+			err := s.Parse(src)
+			expect(err).To(BeNil())
+
+			return expect, s.Layers()
+		})
+
+		o.Spec("it highlights comparable as a builtin", func(expect expect.Expectation, layers []input.SyntaxLayer) {
+			builtins := findLayer(theme.Builtin, layers)
+			expect(builtins.Spans).To(HaveLen(1))
+			expect(builtins.Spans[0]).To(matchPosition{src: src, match: "comparable"})
+		})
+
+		o.Spec("it highlights the type parameter brackets", func(expect expect.Expectation, layers []input.SyntaxLayer) {
+			outerScope := findLayer(theme.ScopePair, layers)
+			expect(outerScope.Spans).To(HaveLen(4))
+			expect(outerScope.Spans[0]).To(matchPosition{src: src, match: "["})
+			expect(outerScope.Spans[1]).To(matchPosition{src: src, match: "]"})
+			expect(outerScope.Spans[2]).To(matchPosition{src: src, match: "{"})
+			expect(outerScope.Spans[3]).To(matchPosition{src: src, match: "}"})
+		})
+
+		o.Spec("it highlights the type parameter in the type", func(expect expect.Expectation, layers []input.SyntaxLayer) {
+			typs := findLayer(theme.Type, layers)
+			expect(typs.Spans).To(HaveLen(1))
+			expect(typs.Spans[0]).To(matchPosition{src: src, match: "K", idx: 1})
+		})
+	})
+
+	o.Group("Constraints", func() {
+		const src = `
+		package foo
+
+		type Number interface {
+			~int | ~int64 | float64
+		}
+		`
+
+		o.BeforeEach(func(expect expect.Expectation) (expect.Expectation, []input.SyntaxLayer) {
+			s := syntax.New()
+			err := s.Parse(src)
+			expect(err).To(BeNil())
+
+			return expect, s.Layers()
+		})
+
+		o.Spec("it highlights every term of a union as a type", func(expect expect.Expectation, layers []input.SyntaxLayer) {
+			typs := findLayer(theme.Type, layers)
+			expect(typs.Spans).To(HaveLen(3))
+			expect(typs.Spans[0]).To(matchPosition{src: src, match: "int", idx: 1})
+			expect(typs.Spans[1]).To(matchPosition{src: src, match: "int64"})
+			expect(typs.Spans[2]).To(matchPosition{src: src, match: "float64"})
+		})
+
+		o.Spec("it does not highlight the union as identifiers", func(expect expect.Expectation, layers []input.SyntaxLayer) {
+			idents := findLayer(theme.Ident, layers)
+			expect(idents.Spans).To(HaveLen(0))
+		})
+	})
+
+	o.Group("Instantiation", func() {
+		const src = `
+		package foo
+
+		func main() {
+			var p Pair[string, int]
+			var l List[bool]
+			m := Map[int, string](nil, nil)
+		}
+		`
+
+		o.BeforeEach(func(expect expect.Expectation) (expect.Expectation, []input.SyntaxLayer) {
+			s := syntax.New()
+			err := s.Parse(src)
+			expect(err).To(BeNil())
+
+			return expect, s.Layers()
+		})
+
+		o.Spec("it highlights generic types and their type arguments as types", func(expect expect.Expectation, layers []input.SyntaxLayer) {
+			typs := findLayer(theme.Type, layers)
+			expect(typs.Spans).To(HaveLen(7))
+			expect(typs.Spans[0]).To(matchPosition{src: src, match: "Pair"})
+			expect(typs.Spans[1]).To(matchPosition{src: src, match: "string"})
+			expect(typs.Spans[2]).To(matchPosition{src: src, match: "int"})
+			expect(typs.Spans[3]).To(matchPosition{src: src, match: "List"})
+			expect(typs.Spans[4]).To(matchPosition{src: src, match: "bool"})
+			expect(typs.Spans[5]).To(matchPosition{src: src, match: "int", idx: 1})
+			expect(typs.Spans[6]).To(matchPosition{src: src, match: "string", idx: 1})
+		})
+
+		o.Spec("it highlights the brackets of type arguments", func(expect expect.Expectation, layers []input.SyntaxLayer) {
+			scope := findLayer(theme.ScopePair+1, layers)
+			expect(scope.Spans).To(HaveLen(8))
+			expect(scope.Spans[0]).To(matchPosition{src: src, match: "["})
+			expect(scope.Spans[1]).To(matchPosition{src: src, match: "]"})
+			expect(scope.Spans[2]).To(matchPosition{src: src, match: "[", idx: 1})
+			expect(scope.Spans[3]).To(matchPosition{src: src, match: "]", idx: 1})
+			expect(scope.Spans[4]).To(matchPosition{src: src, match: "[", idx: 2})
+			expect(scope.Spans[5]).To(matchPosition{src: src, match: "]", idx: 2})
+		})
+	})
+}
@@ -0,0 +1,279 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package gomod contains lexers for go.mod, go.work and go.sum
+// files.
+package gomod
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/theme"
+)
+
+var directives = map[string]bool{
+	"module":    true,
+	"go":        true,
+	"toolchain": true,
+	"godebug":   true,
+	"require":   true,
+	"replace":   true,
+	"exclude":   true,
+	"retract":   true,
+	"use":       true,
+}
+
+// Module is a module path (or, for local replacements and go.work
+// use directives, a directory) found in a go.mod or go.work file.
+type Module struct {
+	Path, Version string
+
+	// Start and End are the rune offsets of Path in the file.
+	Start, End int
+}
+
+// Local returns whether or not m refers to a directory on disk
+// rather than a module path.
+func (m Module) Local() bool {
+	return filepath.IsAbs(m.Path) || strings.HasPrefix(m.Path, "./") || strings.HasPrefix(m.Path, "../") ||
+		m.Path == "." || m.Path == ".."
+}
+
+// Replace is a replace directive in a go.mod or go.work file.
+type Replace struct {
+	Old, New Module
+}
+
+// File is a lexed go.mod or go.work file.
+type File struct {
+	Modules  []Module
+	Replaces []Replace
+
+	layers map[theme.LanguageConstruct][]input.Span
+}
+
+type token struct {
+	text       string
+	start, end int
+}
+
+// Parse lexes text as the contents of a go.mod or go.work file.
+// It does not fail on syntax errors; it just highlights as much
+// as it can.
+func Parse(text string) *File {
+	f := &File{layers: make(map[theme.LanguageConstruct][]input.Span)}
+	block := ""
+	offset := 0
+	for _, line := range strings.SplitAfter(text, "\n") {
+		toks := f.lexLine(line, offset)
+		offset += utf8.RuneCountInString(line)
+		if len(toks) == 0 {
+			continue
+		}
+		first := toks[0]
+		switch {
+		case block != "" && first.text == ")":
+			f.add(theme.ScopePair, first)
+			block = ""
+			continue
+		case block != "":
+			f.directive(block, toks)
+			continue
+		}
+		if !directives[first.text] {
+			f.add(theme.Bad, first)
+			continue
+		}
+		f.add(theme.Keyword, first)
+		if len(toks) > 1 && toks[1].text == "(" {
+			f.add(theme.ScopePair, toks[1])
+			block = first.text
+			continue
+		}
+		f.directive(first.text, toks[1:])
+	}
+	return f
+}
+
+// lexLine splits line in to tokens, highlighting any comments and
+// quoted strings.  Quoted strings are returned with their quotes
+// removed.
+func (f *File) lexLine(line string, offset int) []token {
+	var toks []token
+	runes := []rune(line)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			end := len(runes)
+			for end > i && unicode.IsSpace(runes[end-1]) {
+				end--
+			}
+			f.layers[theme.Comment] = append(f.layers[theme.Comment], input.Span{Start: offset + i, End: offset + end})
+			return toks
+		case r == '"' || r == '`':
+			end := i + 1
+			for end < len(runes) && runes[end] != r && runes[end] != '\n' {
+				if r == '"' && runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end < len(runes) && runes[end] == r {
+				end++
+			}
+			f.layers[theme.String] = append(f.layers[theme.String], input.Span{Start: offset + i, End: offset + end})
+			text := string(runes[i+1 : end])
+			text = strings.TrimSuffix(text, string(r))
+			toks = append(toks, token{text: text, start: offset + i + 1, end: offset + i + 1 + utf8.RuneCountInString(text)})
+			i = end
+		case r == '(' || r == ')':
+			toks = append(toks, token{text: string(r), start: offset + i, end: offset + i + 1})
+			i++
+		default:
+			end := i + 1
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '(' && runes[end] != ')' &&
+				!(runes[end] == '/' && end+1 < len(runes) && runes[end+1] == '/') {
+				end++
+			}
+			toks = append(toks, token{text: string(runes[i:end]), start: offset + i, end: offset + end})
+			i = end
+		}
+	}
+	return toks
+}
+
+func (f *File) add(c theme.LanguageConstruct, t token) {
+	f.layers[c] = append(f.layers[c], input.Span{Start: t.start, End: t.end})
+}
+
+func (f *File) module(toks []token) (Module, []token) {
+	if len(toks) == 0 {
+		return Module{}, nil
+	}
+	m := Module{Path: toks[0].text, Start: toks[0].start, End: toks[0].end}
+	f.add(theme.Ident, toks[0])
+	toks = toks[1:]
+	if len(toks) > 0 && toks[0].text != "=>" {
+		m.Version = toks[0].text
+		f.add(theme.Num, toks[0])
+		toks = toks[1:]
+	}
+	return m, toks
+}
+
+func (f *File) directive(name string, toks []token) {
+	switch name {
+	case "go", "toolchain":
+		for _, t := range toks {
+			f.add(theme.Num, t)
+		}
+	case "godebug":
+		for _, t := range toks {
+			f.add(theme.Ident, t)
+		}
+	case "module", "require", "exclude", "use":
+		m, rest := f.module(toks)
+		if m.Path == "" {
+			return
+		}
+		f.Modules = append(f.Modules, m)
+		for _, t := range rest {
+			f.add(theme.Bad, t)
+		}
+	case "retract":
+		// Retractions are either a single version or a
+		// [low, high] interval.
+		for _, t := range toks {
+			f.add(theme.Num, t)
+		}
+	case "replace":
+		old, rest := f.module(toks)
+		if old.Path == "" {
+			return
+		}
+		if len(rest) == 0 || rest[0].text != "=>" {
+			for _, t := range rest {
+				f.add(theme.Bad, t)
+			}
+			return
+		}
+		f.add(theme.Keyword, rest[0])
+		newMod, rest := f.module(rest[1:])
+		f.Modules = append(f.Modules, old, newMod)
+		f.Replaces = append(f.Replaces, Replace{Old: old, New: newMod})
+		for _, t := range rest {
+			f.add(theme.Bad, t)
+		}
+	}
+}
+
+// Layers returns the syntax layers for f.
+func (f *File) Layers() []input.SyntaxLayer {
+	return layers(f.layers)
+}
+
+// ModuleAt returns the module whose path contains the rune offset
+// pos.  If pos is not on a module path, ok will be false.
+func (f *File) ModuleAt(pos int) (m Module, ok bool) {
+	for _, m := range f.Modules {
+		if m.Start <= pos && pos <= m.End {
+			return m, true
+		}
+	}
+	return Module{}, false
+}
+
+// Resolve returns the module that m resolves to after any replace
+// directives in f are applied.
+func (f *File) Resolve(m Module) Module {
+	for _, r := range f.Replaces {
+		if r.Old.Path != m.Path {
+			continue
+		}
+		if r.Old.Version != "" && r.Old.Version != m.Version {
+			continue
+		}
+		return r.New
+	}
+	return m
+}
+
+// Dir returns the directory containing the source for m.  Local
+// paths are resolved relative to the directory that the go.mod or
+// go.work file is in; module paths are resolved to their location
+// in modCache.
+func Dir(m Module, fileDir, modCache string) string {
+	if m.Local() {
+		if filepath.IsAbs(m.Path) {
+			return m.Path
+		}
+		return filepath.Join(fileDir, m.Path)
+	}
+	if m.Version == "" {
+		return ""
+	}
+	return filepath.Join(modCache, escape(m.Path)+"@"+escape(m.Version))
+}
+
+// escape escapes s the way the go command escapes paths in the
+// module cache: upper case letters are replaced with an
+// exclamation point followed by the lower case letter.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			b.WriteRune('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
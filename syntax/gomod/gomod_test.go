@@ -0,0 +1,155 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package gomod_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/syntax/gomod"
+	"github.com/nelsam/vidar/theme"
+)
+
+const modSrc = `module github.com/nelsam/vidar
+
+go 1.13
+
+require (
+	github.com/BurntSushi/toml v0.3.1
+	github.com/nelsam/gxui v0.0.0-20180224 // indirect
+)
+
+replace github.com/nelsam/gxui => ../gxui
+
+exclude golang.org/x/net v1.2.3
+bogus line
+`
+
+func span(src, match string, n int) input.Span {
+	idx := -1
+	for i := 0; i <= n; i++ {
+		next := strings.Index(src[idx+1:], match)
+		if next < 0 {
+			panic("match not found")
+		}
+		idx += next + 1
+	}
+	start := utf8.RuneCountInString(src[:idx])
+	return input.Span{Start: start, End: start + utf8.RuneCountInString(match)}
+}
+
+func spans(layers []input.SyntaxLayer, c theme.LanguageConstruct) []input.Span {
+	for _, l := range layers {
+		if l.Construct == c {
+			return l.Spans
+		}
+	}
+	return nil
+}
+
+func TestMod(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, *gomod.File) {
+		return expect.New(t), gomod.Parse(modSrc)
+	})
+
+	o.Spec("it highlights directives", func(expect expect.Expectation, f *gomod.File) {
+		keywords := spans(f.Layers(), theme.Keyword)
+		expect(keywords).To(Contain(span(modSrc, "module", 0)))
+		expect(keywords).To(Contain(span(modSrc, "go", 0)))
+		expect(keywords).To(Contain(span(modSrc, "require", 0)))
+		expect(keywords).To(Contain(span(modSrc, "replace", 0)))
+		expect(keywords).To(Contain(span(modSrc, "=>", 0)))
+		expect(keywords).To(Contain(span(modSrc, "exclude", 0)))
+	})
+
+	o.Spec("it highlights module paths and versions", func(expect expect.Expectation, f *gomod.File) {
+		layers := f.Layers()
+		expect(spans(layers, theme.Ident)).To(Contain(span(modSrc, "github.com/BurntSushi/toml", 0)))
+		expect(spans(layers, theme.Num)).To(Contain(span(modSrc, "v0.3.1", 0)))
+		expect(spans(layers, theme.Num)).To(Contain(span(modSrc, "1.13", 0)))
+		expect(spans(layers, theme.Ident)).To(Contain(span(modSrc, "../gxui", 0)))
+	})
+
+	o.Spec("it highlights comments, block parens and unknown directives", func(expect expect.Expectation, f *gomod.File) {
+		layers := f.Layers()
+		expect(spans(layers, theme.Comment)).To(Equal([]input.Span{span(modSrc, "// indirect", 0)}))
+		expect(spans(layers, theme.ScopePair)).To(Equal([]input.Span{span(modSrc, "(", 0), span(modSrc, ")", 0)}))
+		expect(spans(layers, theme.Bad)).To(Equal([]input.Span{span(modSrc, "bogus", 0)}))
+	})
+
+	o.Spec("it finds modules at an offset", func(expect expect.Expectation, f *gomod.File) {
+		s := span(modSrc, "github.com/BurntSushi/toml", 0)
+		m, ok := f.ModuleAt(s.Start + 3)
+		expect(ok).To(BeTrue())
+		expect(m.Path).To(Equal("github.com/BurntSushi/toml"))
+		expect(m.Version).To(Equal("v0.3.1"))
+
+		_, ok = f.ModuleAt(span(modSrc, "1.13", 0).Start)
+		expect(ok).To(BeFalse())
+	})
+
+	o.Spec("it resolves replaced modules", func(expect expect.Expectation, f *gomod.File) {
+		m, ok := f.ModuleAt(span(modSrc, "github.com/nelsam/gxui", 0).Start)
+		expect(ok).To(BeTrue())
+		r := f.Resolve(m)
+		expect(r.Path).To(Equal("../gxui"))
+		expect(r.Local()).To(BeTrue())
+		expect(gomod.Dir(r, "/src/vidar", "/cache")).To(Equal(filepath.Join("/src", "gxui")))
+	})
+
+	o.Spec("it escapes module cache paths", func(expect expect.Expectation, f *gomod.File) {
+		m, _ := f.ModuleAt(span(modSrc, "github.com/BurntSushi/toml", 0).Start)
+		expect(gomod.Dir(f.Resolve(m), "/src/vidar", "/cache")).To(Equal(filepath.Join("/cache", "github.com/!burnt!sushi/toml@v0.3.1")))
+	})
+}
+
+func TestWork(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	const src = "go 1.18\n\nuse (\n\t./vidar\n\t\"../gxui\"\n)\n"
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, *gomod.File) {
+		return expect.New(t), gomod.Parse(src)
+	})
+
+	o.Spec("it parses use directives as local modules", func(expect expect.Expectation, f *gomod.File) {
+		expect(f.Modules).To(HaveLen(2))
+		expect(f.Modules[0].Path).To(Equal("./vidar"))
+		expect(f.Modules[1].Path).To(Equal("../gxui"))
+		expect(f.Modules[1].Local()).To(BeTrue())
+		expect(spans(f.Layers(), theme.String)).To(Equal([]input.Span{span(src, `"../gxui"`, 0)}))
+	})
+}
+
+func TestSum(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	const src = "github.com/a/b v1.0.0 h1:abc=\ngithub.com/a/b v1.0.0/go.mod h1:def=\nbroken\n"
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, []input.SyntaxLayer) {
+		return expect.New(t), gomod.SumLayers(src)
+	})
+
+	o.Spec("it highlights paths, versions and hashes", func(expect expect.Expectation, layers []input.SyntaxLayer) {
+		expect(spans(layers, theme.Ident)).To(Equal([]input.Span{span(src, "github.com/a/b", 0), span(src, "github.com/a/b", 1)}))
+		expect(spans(layers, theme.Num)).To(Equal([]input.Span{span(src, "v1.0.0", 0), span(src, "v1.0.0/go.mod", 0)}))
+		expect(spans(layers, theme.String)).To(Equal([]input.Span{span(src, "h1:abc=", 0), span(src, "h1:def=", 0)}))
+	})
+
+	o.Spec("it highlights malformed lines as bad", func(expect expect.Expectation, layers []input.SyntaxLayer) {
+		expect(spans(layers, theme.Bad)).To(Equal([]input.Span{span(src, "broken", 0)}))
+	})
+}
@@ -0,0 +1,59 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package gomod
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/theme"
+)
+
+// SumLayers returns the syntax layers for text as the contents of
+// a go.sum file.  Each line is expected to be a module path, a
+// version and a hash; lines that don't match are highlighted as
+// theme.Bad.
+func SumLayers(text string) []input.SyntaxLayer {
+	spans := make(map[theme.LanguageConstruct][]input.Span)
+	offset := 0
+	for _, line := range strings.SplitAfter(text, "\n") {
+		start := offset
+		offset += utf8.RuneCountInString(line)
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		constructs := []theme.LanguageConstruct{theme.Ident, theme.Num, theme.String}
+		if len(fields) != len(constructs) || !strings.HasPrefix(fields[2], "h") || !strings.Contains(fields[2], ":") {
+			end := start + utf8.RuneCountInString(strings.TrimRight(line, "\r\n"))
+			spans[theme.Bad] = append(spans[theme.Bad], input.Span{Start: start, End: end})
+			continue
+		}
+		pos := 0
+		for i, field := range fields {
+			idx := strings.Index(line[pos:], field) + pos
+			fieldStart := start + utf8.RuneCountInString(line[:idx])
+			spans[constructs[i]] = append(spans[constructs[i]], input.Span{
+				Start: fieldStart,
+				End:   fieldStart + utf8.RuneCountInString(field),
+			})
+			pos = idx + len(field)
+		}
+	}
+	return layers(spans)
+}
+
+func layers(spans map[theme.LanguageConstruct][]input.Span) []input.SyntaxLayer {
+	l := make([]input.SyntaxLayer, 0, len(spans))
+	for c, s := range spans {
+		l = append(l, input.SyntaxLayer{Construct: c, Spans: s})
+	}
+	sort.Slice(l, func(i, j int) bool {
+		return l[i].Construct < l[j].Construct
+	})
+	return l
+}
@@ -0,0 +1,248 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package gotmpl contains a lexer for text/template and
+// html/template files.
+package gotmpl
+
+import (
+	"sort"
+	"unicode"
+
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/theme"
+)
+
+var (
+	keywords = map[string]bool{
+		"if":       true,
+		"else":     true,
+		"end":      true,
+		"range":    true,
+		"with":     true,
+		"define":   true,
+		"template": true,
+		"block":    true,
+		"break":    true,
+		"continue": true,
+	}
+
+	// openers are the keywords that start a block which must be
+	// closed with {{end}}.
+	openers = map[string]bool{
+		"if":     true,
+		"range":  true,
+		"with":   true,
+		"define": true,
+		"block":  true,
+	}
+
+	builtins = map[string]bool{
+		"and":      true,
+		"or":       true,
+		"not":      true,
+		"len":      true,
+		"index":    true,
+		"slice":    true,
+		"print":    true,
+		"printf":   true,
+		"println":  true,
+		"html":     true,
+		"js":       true,
+		"urlquery": true,
+		"call":     true,
+		"eq":       true,
+		"ne":       true,
+		"lt":       true,
+		"le":       true,
+		"gt":       true,
+		"ge":       true,
+		"true":     true,
+		"false":    true,
+	}
+)
+
+type lexer struct {
+	src   []rune
+	pos   int
+	depth int
+	spans map[theme.LanguageConstruct][]input.Span
+}
+
+// Layers returns the syntax layers for text as a Go template using
+// the default {{ and }} delimiters.  Text outside of actions is
+// not highlighted.  The delimiters of actions that open and close
+// blocks are highlighted as scope pairs, so that matching
+// {{if}}/{{end}} pairs share a color.
+func Layers(text string) []input.SyntaxLayer {
+	l := &lexer{
+		src:   []rune(text),
+		spans: make(map[theme.LanguageConstruct][]input.Span),
+	}
+	for l.next() {
+		l.action()
+	}
+	layers := make([]input.SyntaxLayer, 0, len(l.spans))
+	for c, s := range l.spans {
+		layers = append(layers, input.SyntaxLayer{Construct: c, Spans: s})
+	}
+	sort.Slice(layers, func(i, j int) bool {
+		return layers[i].Construct < layers[j].Construct
+	})
+	return layers
+}
+
+func (l *lexer) add(c theme.LanguageConstruct, start, end int) {
+	l.spans[c] = append(l.spans[c], input.Span{Start: start, End: end})
+}
+
+func (l *lexer) hasPrefix(p string) bool {
+	i := l.pos
+	for _, r := range p {
+		if i >= len(l.src) || l.src[i] != r {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// next moves l to the start of the next action, returning false
+// if there are no more actions.
+func (l *lexer) next() bool {
+	for ; l.pos < len(l.src)-1; l.pos++ {
+		if l.hasPrefix("{{") {
+			return true
+		}
+	}
+	return false
+}
+
+// action lexes a single action, starting at its left delimiter.
+func (l *lexer) action() {
+	open := l.pos
+	l.pos += 2
+	if l.hasPrefix("- ") {
+		l.pos++
+	}
+	openEnd := l.pos
+	var (
+		first = true
+		scope = 0
+	)
+	for l.pos < len(l.src) {
+		r := l.src[l.pos]
+		switch {
+		case l.hasPrefix(" -}}") || l.hasPrefix("}}"):
+			end := l.pos + 2
+			if r == ' ' {
+				end += 2
+			}
+			c := theme.LanguageConstruct(theme.ScopePair)
+			switch {
+			case scope > 0:
+				c += theme.LanguageConstruct(l.depth)
+			case scope < 0:
+				c += theme.LanguageConstruct(l.depth + 1)
+			}
+			l.add(c, open, openEnd)
+			l.add(c, l.pos, end)
+			l.pos = end
+			return
+		case unicode.IsSpace(r):
+			l.pos++
+			continue
+		case l.hasPrefix("/*"):
+			l.comment()
+		case r == '"' || r == '`' || r == '\'':
+			l.quoted(r)
+		case r == '$' || r == '.':
+			start := l.pos
+			l.pos++
+			l.word()
+			for l.pos < len(l.src) && l.src[l.pos] == '.' {
+				l.pos++
+				l.word()
+			}
+			l.add(theme.Ident, start, l.pos)
+		case r == '-' || r == '+' || unicode.IsDigit(r):
+			start := l.pos
+			l.pos++
+			for l.pos < len(l.src) && (unicode.IsDigit(l.src[l.pos]) || unicode.IsLetter(l.src[l.pos]) || l.src[l.pos] == '.' || l.src[l.pos] == '_') {
+				l.pos++
+			}
+			l.add(theme.Num, start, l.pos)
+		case unicode.IsLetter(r) || r == '_':
+			start := l.pos
+			l.word()
+			word := string(l.src[start:l.pos])
+			switch {
+			case word == "nil":
+				l.add(theme.Nil, start, l.pos)
+			case keywords[word]:
+				l.add(theme.Keyword, start, l.pos)
+				if !first {
+					break
+				}
+				switch {
+				case openers[word]:
+					l.depth++
+					scope = 1
+				case word == "end" && l.depth > 0:
+					scope = -1
+					l.depth--
+				case word == "else":
+					scope = 1
+				}
+			case builtins[word]:
+				l.add(theme.Builtin, start, l.pos)
+			default:
+				l.add(theme.Func, start, l.pos)
+			}
+		default:
+			// Pipes, parens, assignments and anything else.
+			l.pos++
+		}
+		first = false
+	}
+	// Unterminated action.
+	l.add(theme.Bad, open, openEnd)
+}
+
+func (l *lexer) word() {
+	for l.pos < len(l.src) && (unicode.IsLetter(l.src[l.pos]) || unicode.IsDigit(l.src[l.pos]) || l.src[l.pos] == '_') {
+		l.pos++
+	}
+}
+
+func (l *lexer) comment() {
+	start := l.pos
+	l.pos += 2
+	for l.pos < len(l.src) && !l.hasPrefix("*/") {
+		l.pos++
+	}
+	if l.pos < len(l.src) {
+		l.pos += 2
+	}
+	l.add(theme.Comment, start, l.pos)
+}
+
+func (l *lexer) quoted(q rune) {
+	start := l.pos
+	l.pos++
+	for l.pos < len(l.src) && l.src[l.pos] != q {
+		if q != '`' && (l.src[l.pos] == '\n' || l.hasPrefix("}}")) {
+			l.add(theme.Bad, start, l.pos)
+			return
+		}
+		if q != '`' && l.src[l.pos] == '\\' {
+			l.pos++
+		}
+		l.pos++
+	}
+	if l.pos < len(l.src) {
+		l.pos++
+	}
+	l.add(theme.String, start, l.pos)
+}
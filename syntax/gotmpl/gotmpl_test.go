@@ -0,0 +1,90 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package gotmpl_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/syntax/gotmpl"
+	"github.com/nelsam/vidar/theme"
+)
+
+func span(src, match string) input.Span {
+	idx := strings.Index(src, match)
+	if idx < 0 {
+		panic("match not found")
+	}
+	start := utf8.RuneCountInString(src[:idx])
+	return input.Span{Start: start, End: start + utf8.RuneCountInString(match)}
+}
+
+func spans(layers []input.SyntaxLayer, c theme.LanguageConstruct) []input.Span {
+	for _, l := range layers {
+		if l.Construct == c {
+			return l.Spans
+		}
+	}
+	return nil
+}
+
+func TestLayers(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	const src = `<ul>{{/* items */}}
+{{- range $i, $v := .Items }}
+	<li>{{ printf "%d: %s" $i (upper $v.Name) -}}</li>
+{{ end }}
+</ul>{{ if eq .N 3 }}þ{{ else }}{{ nil }}{{end}}{{ .Broken`
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, []input.SyntaxLayer) {
+		return expect.New(t), gotmpl.Layers(src)
+	})
+
+	o.Spec("it highlights keywords, builtins and functions", func(expect expect.Expectation, layers []input.SyntaxLayer) {
+		expect(spans(layers, theme.Keyword)).To(Contain(span(src, "range")))
+		expect(spans(layers, theme.Keyword)).To(Contain(span(src, "end")))
+		expect(spans(layers, theme.Builtin)).To(Contain(span(src, "printf")))
+		expect(spans(layers, theme.Builtin)).To(Contain(span(src, "eq")))
+		expect(spans(layers, theme.Func)).To(Equal([]input.Span{span(src, "upper")}))
+		expect(spans(layers, theme.Nil)).To(Equal([]input.Span{span(src, "nil")}))
+	})
+
+	o.Spec("it highlights variables, fields, strings, numbers and comments", func(expect expect.Expectation, layers []input.SyntaxLayer) {
+		expect(spans(layers, theme.Ident)).To(Contain(span(src, "$i")))
+		expect(spans(layers, theme.Ident)).To(Contain(span(src, ".Items")))
+		expect(spans(layers, theme.Ident)).To(Contain(span(src, "$v.Name")))
+		expect(spans(layers, theme.String)).To(Equal([]input.Span{span(src, `"%d: %s"`)}))
+		expect(spans(layers, theme.Num)).To(Equal([]input.Span{span(src, "3")}))
+		expect(spans(layers, theme.Comment)).To(Equal([]input.Span{span(src, "/* items */")}))
+	})
+
+	o.Spec("it pairs the delimiters of blocks", func(expect expect.Expectation, layers []input.SyntaxLayer) {
+		blocks := spans(layers, theme.ScopePair+1)
+		expect(blocks).To(HaveLen(10))
+		expect(blocks[0]).To(Equal(span(src, "{{-")))
+		expect(spans(layers, theme.ScopePair)).To(Contain(span(src, " -}}")))
+	})
+
+	o.Spec("it does not highlight text outside of actions", func(expect expect.Expectation, layers []input.SyntaxLayer) {
+		outside := span(src, "<li>")
+		for _, l := range layers {
+			for _, s := range l.Spans {
+				expect(s.End <= outside.Start || s.Start >= outside.End).To(BeTrue())
+			}
+		}
+	})
+
+	o.Spec("it highlights unterminated actions as bad", func(expect expect.Expectation, layers []input.SyntaxLayer) {
+		s := span(src, "{{ .Broken")
+		expect(spans(layers, theme.Bad)).To(Equal([]input.Span{{Start: s.Start, End: s.Start + 2}}))
+	})
+}
@@ -21,6 +21,11 @@ type Syntax struct {
 	fileSet     *token.FileSet
 	layers      map[theme.LanguageConstruct]*input.SyntaxLayer
 	runeOffsets []int
+	runeCount   int
+	errs        []Error
+	folds       []input.FoldRegion
+	source      string
+	pairs       []Pair
 }
 
 // New constructs a new *Syntax value with theme as its Theme field.
@@ -31,7 +36,9 @@ func New() *Syntax {
 // Parse parses the passed in Go source code, replacing s's stored
 // context with that of the parsed source.  It returns any error
 // encountered while parsing source, but will still store as much
-// information as possible.
+// information as possible.  Each syntax error is highlighted as
+// theme.Bad and can be found in s.Errors(), as are any brackets
+// without a match.
 func (s *Syntax) Parse(source string) error {
 	s.runeOffsets = make([]int, len(source))
 	byteOffset := 0
@@ -43,11 +50,19 @@ func (s *Syntax) Parse(source string) error {
 		}
 		byteOffset += bytes - 1
 	}
+	s.runeCount = len(source) - byteOffset
 
 	s.fileSet = token.NewFileSet()
 	s.scope = theme.ScopePair
 	s.layers = make(map[theme.LanguageConstruct]*input.SyntaxLayer)
-	f, err := parser.ParseFile(s.fileSet, "", source, parser.ParseComments)
+	s.errs = nil
+	s.folds = nil
+	s.source = source
+	s.pairs = nil
+
+	// Without parser.AllErrors, the parser bails out after ten
+	// errors and we lose the rest of the file's highlighting.
+	f, err := parser.ParseFile(s.fileSet, "", source, parser.ParseComments|parser.AllErrors)
 
 	// Parse everything we can before returning the error.
 	if f.Package.IsValid() {
@@ -58,6 +73,7 @@ func (s *Syntax) Parse(source string) error {
 	}
 	for _, comment := range f.Comments {
 		s.addNode(theme.Comment, comment)
+		s.addFold(comment.Pos(), comment.End())
 	}
 	for _, decl := range f.Decls {
 		s.addDecl(decl)
@@ -65,6 +81,8 @@ func (s *Syntax) Parse(source string) error {
 	for _, unresolved := range f.Unresolved {
 		s.addUnresolved(unresolved)
 	}
+	err = s.addErrors([]rune(source), err)
+	s.addUnbalanced()
 	return err
 }
 
@@ -84,6 +102,7 @@ func (s *Syntax) Layers() []input.SyntaxLayer {
 func (s *Syntax) rainbowScope(openStart token.Pos, openLen int, closeStart token.Pos, closeLen int) (unscope func()) {
 	s.add(s.scope, openStart, openLen)
 	s.add(s.scope, closeStart, closeLen)
+	s.addPair(openStart, closeStart)
 	s.scope++
 	return func() { s.scope-- }
 }
@@ -109,7 +128,10 @@ func (s *Syntax) add(construct theme.LanguageConstruct, pos token.Pos, byteLengt
 }
 
 func (s *Syntax) runePos(bytePos int) int {
-	if bytePos >= len(s.runeOffsets) {
+	if bytePos == len(s.runeOffsets) {
+		return s.runeCount
+	}
+	if bytePos > len(s.runeOffsets) {
 		return -1
 	}
 	return bytePos + s.runeOffsets[bytePos]
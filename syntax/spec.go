@@ -36,5 +36,8 @@ func (s *Syntax) addImportSpec(imp *ast.ImportSpec) {
 }
 
 func (s *Syntax) addTypeSpec(typ *ast.TypeSpec) {
+	if typ.TypeParams != nil {
+		s.addFieldList(typ.TypeParams)
+	}
 	s.addExpr(typ.Type)
 }
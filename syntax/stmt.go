@@ -78,6 +78,7 @@ func (s *Syntax) addAssignStmt(stmt *ast.AssignStmt) {
 }
 
 func (s *Syntax) addSwitchStmt(stmt *ast.SwitchStmt) {
+	s.addFold(stmt.Pos(), stmt.End())
 	s.add(theme.Keyword, stmt.Switch, len("switch"))
 	s.addStmt(stmt.Init)
 	s.addExpr(stmt.Tag)
@@ -85,6 +86,7 @@ func (s *Syntax) addSwitchStmt(stmt *ast.SwitchStmt) {
 }
 
 func (s *Syntax) addTypeSwitchStmt(stmt *ast.TypeSwitchStmt) {
+	s.addFold(stmt.Pos(), stmt.End())
 	s.add(theme.Keyword, stmt.Switch, len("switch"))
 	s.addStmt(stmt.Init)
 	s.addStmt(stmt.Assign)
@@ -118,6 +120,7 @@ func (s *Syntax) addReturnStmt(stmt *ast.ReturnStmt) {
 }
 
 func (s *Syntax) addRangeStmt(stmt *ast.RangeStmt) {
+	s.addFold(stmt.Pos(), stmt.End())
 	s.add(theme.Keyword, stmt.For, len("for"))
 	s.addExpr(stmt.Key)
 	s.addExpr(stmt.Value)
@@ -136,10 +139,14 @@ func (s *Syntax) addRangeStmt(stmt *ast.RangeStmt) {
 }
 
 func (s *Syntax) addIfStmt(stmt *ast.IfStmt) {
+	s.addFold(stmt.Pos(), stmt.Body.End())
 	s.add(theme.Keyword, stmt.If, len("if"))
 	s.addStmt(stmt.Init)
 	s.addExpr(stmt.Cond)
 	s.addBlockStmt(stmt.Body)
+	if block, ok := stmt.Else.(*ast.BlockStmt); ok {
+		s.addFold(block.Pos(), block.End())
+	}
 	if stmt.Else != nil {
 		s.add(theme.Keyword, stmt.Body.End()+1, len("else"))
 	}
@@ -147,6 +154,7 @@ func (s *Syntax) addIfStmt(stmt *ast.IfStmt) {
 }
 
 func (s *Syntax) addForStmt(stmt *ast.ForStmt) {
+	s.addFold(stmt.Pos(), stmt.End())
 	s.add(theme.Keyword, stmt.For, len("for"))
 	s.addStmt(stmt.Init)
 	s.addExpr(stmt.Cond)
@@ -178,6 +186,7 @@ func (s *Syntax) addExprStmt(stmt *ast.ExprStmt) {
 }
 
 func (s *Syntax) addSelectStmt(stmt *ast.SelectStmt) {
+	s.addFold(stmt.Pos(), stmt.End())
 	s.add(theme.Keyword, stmt.Select, len("select"))
 	s.addBlockStmt(stmt.Body)
 }
@@ -0,0 +1,224 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package textfile detects the encoding and line endings of text
+// files, so that they can be edited as plain UTF-8 text with "\n"
+// line endings and written back the way that they were found.
+package textfile
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding is a character encoding that files can be read and
+// written in.
+type Encoding int
+
+const (
+	UTF8 Encoding = iota
+	UTF16LE
+	UTF16BE
+	Latin1
+)
+
+// Encodings is every encoding that files can be converted to.
+var Encodings = []Encoding{UTF8, UTF16LE, UTF16BE, Latin1}
+
+func (e Encoding) String() string {
+	switch e {
+	case UTF16LE:
+		return "UTF-16 LE"
+	case UTF16BE:
+		return "UTF-16 BE"
+	case Latin1:
+		return "Latin-1"
+	default:
+		return "UTF-8"
+	}
+}
+
+// bom returns the byte order mark for e, if it has one.
+func (e Encoding) bom() []byte {
+	switch e {
+	case UTF8:
+		return []byte{0xEF, 0xBB, 0xBF}
+	case UTF16LE:
+		return []byte{0xFF, 0xFE}
+	case UTF16BE:
+		return []byte{0xFE, 0xFF}
+	default:
+		return nil
+	}
+}
+
+// LineEnding is the sequence that ends lines in a file.
+type LineEnding int
+
+const (
+	LF LineEnding = iota
+	CRLF
+)
+
+// LineEndings is every line ending that files can be converted to.
+var LineEndings = []LineEnding{LF, CRLF}
+
+func (l LineEnding) String() string {
+	if l == CRLF {
+		return "CRLF"
+	}
+	return "LF"
+}
+
+// Format is the encoding and line ending style of a file.
+type Format struct {
+	Encoding   Encoding
+	LineEnding LineEnding
+
+	// BOM is whether the file starts with a byte order mark.
+	BOM bool
+
+	// FinalNewline is whether the file ends with a line ending.
+	FinalNewline bool
+}
+
+// Default is the format of new files.
+var Default = Format{Encoding: UTF8, LineEnding: LF, FinalNewline: true}
+
+// String returns a short description of f, like "UTF-8 BOM  CRLF".
+func (f Format) String() string {
+	enc := f.Encoding.String()
+	if f.BOM {
+		enc += " BOM"
+	}
+	return enc + "  " + f.LineEnding.String()
+}
+
+// Decode detects the format of data and returns its text with "\n"
+// line endings.  Files with a byte order mark are decoded in its
+// encoding; otherwise, UTF-16 is detected from the zero bytes that
+// ASCII text has in it, valid UTF-8 is UTF-8, and anything else is
+// treated as Latin-1, which any sequence of bytes is valid in.
+//
+// A file's line endings are CRLF if most of its lines end in "\r\n".
+// Only "\r\n" is converted to "\n", so files with mixed line endings
+// keep their stray line endings until they are saved as CRLF.
+func Decode(data []byte) (string, Format) {
+	f := Default
+	for _, enc := range []Encoding{UTF8, UTF16LE, UTF16BE} {
+		if bom := enc.bom(); bytes.HasPrefix(data, bom) {
+			f.Encoding, f.BOM = enc, true
+			data = data[len(bom):]
+			break
+		}
+	}
+	if !f.BOM {
+		f.Encoding = detect(data)
+	}
+	text := decode(data, f.Encoding)
+
+	crlf := strings.Count(text, "\r\n")
+	if crlf > 0 && crlf >= strings.Count(text, "\n")-crlf {
+		f.LineEnding = CRLF
+		text = strings.Replace(text, "\r\n", "\n", -1)
+	}
+	f.FinalNewline = text == "" || strings.HasSuffix(text, "\n")
+	return text, f
+}
+
+// detect guesses the encoding of data, which has no byte order mark.
+func detect(data []byte) Encoding {
+	if len(data) >= 2 && len(data)%2 == 0 {
+		var even, odd int
+		for i, b := range data {
+			if b != 0 {
+				continue
+			}
+			if i%2 == 0 {
+				even++
+			} else {
+				odd++
+			}
+		}
+		// Mostly-ASCII UTF-16 has a zero in every other byte.
+		pairs := len(data) / 2
+		switch {
+		case odd > pairs/2 && even == 0:
+			return UTF16LE
+		case even > pairs/2 && odd == 0:
+			return UTF16BE
+		}
+	}
+	if utf8.Valid(data) {
+		return UTF8
+	}
+	return Latin1
+}
+
+func decode(data []byte, enc Encoding) string {
+	switch enc {
+	case UTF16LE, UTF16BE:
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			hi, lo := data[2*i+1], data[2*i]
+			if enc == UTF16BE {
+				hi, lo = lo, hi
+			}
+			units[i] = uint16(hi)<<8 | uint16(lo)
+		}
+		text := string(utf16.Decode(units))
+		if len(data)%2 == 1 {
+			text += string(utf8.RuneError)
+		}
+		return text
+	case Latin1:
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	default:
+		return string(data)
+	}
+}
+
+// Encode returns text, with "\n" line endings, in format f.  It
+// returns an error if text has characters that f's encoding can't
+// represent.
+func Encode(text string, f Format) ([]byte, error) {
+	if f.LineEnding == CRLF {
+		text = strings.Replace(text, "\n", "\r\n", -1)
+	}
+	var data []byte
+	if f.BOM {
+		data = append(data, f.Encoding.bom()...)
+	}
+	switch f.Encoding {
+	case UTF16LE, UTF16BE:
+		for _, u := range utf16.Encode([]rune(text)) {
+			hi, lo := byte(u>>8), byte(u)
+			if f.Encoding == UTF16LE {
+				hi, lo = lo, hi
+			}
+			data = append(data, hi, lo)
+		}
+	case Latin1:
+		line := 1
+		for _, r := range text {
+			if r > 0xFF {
+				return nil, fmt.Errorf("%q on line %d can't be encoded as %s", r, line, f.Encoding)
+			}
+			if r == '\n' {
+				line++
+			}
+			data = append(data, byte(r))
+		}
+	default:
+		data = append(data, text...)
+	}
+	return data, nil
+}
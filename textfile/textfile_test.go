@@ -0,0 +1,113 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package textfile_test
+
+import (
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/textfile"
+)
+
+func TestDecode(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) expect.Expectation {
+		return expect.New(t)
+	})
+
+	o.Spec("it decodes plain UTF-8 as the default format", func(expect expect.Expectation) {
+		text, f := textfile.Decode([]byte("héllo\nwörld\n"))
+		expect(text).To(Equal("héllo\nwörld\n"))
+		expect(f).To(Equal(textfile.Default))
+	})
+
+	o.Spec("it detects CRLF line endings", func(expect expect.Expectation) {
+		text, f := textfile.Decode([]byte("a\r\nb\r\nc"))
+		expect(text).To(Equal("a\nb\nc"))
+		expect(f.LineEnding).To(Equal(textfile.CRLF))
+		expect(f.FinalNewline).To(BeFalse())
+	})
+
+	o.Spec("it detects byte order marks", func(expect expect.Expectation) {
+		text, f := textfile.Decode([]byte("\xEF\xBB\xBFa\n"))
+		expect(text).To(Equal("a\n"))
+		expect(f.Encoding).To(Equal(textfile.UTF8))
+		expect(f.BOM).To(BeTrue())
+
+		text, f = textfile.Decode([]byte("\xFF\xFEa\x00\n\x00"))
+		expect(text).To(Equal("a\n"))
+		expect(f.Encoding).To(Equal(textfile.UTF16LE))
+		expect(f.BOM).To(BeTrue())
+
+		text, f = textfile.Decode([]byte("\xFE\xFF\x00a\x00\n"))
+		expect(text).To(Equal("a\n"))
+		expect(f.Encoding).To(Equal(textfile.UTF16BE))
+		expect(f.BOM).To(BeTrue())
+	})
+
+	o.Spec("it detects UTF-16 without a byte order mark", func(expect expect.Expectation) {
+		text, f := textfile.Decode([]byte("a\x00b\x00\n\x00"))
+		expect(text).To(Equal("ab\n"))
+		expect(f.Encoding).To(Equal(textfile.UTF16LE))
+		expect(f.BOM).To(BeFalse())
+	})
+
+	o.Spec("it falls back to Latin-1 for invalid UTF-8", func(expect expect.Expectation) {
+		text, f := textfile.Decode([]byte("caf\xE9\n"))
+		expect(text).To(Equal("café\n"))
+		expect(f.Encoding).To(Equal(textfile.Latin1))
+	})
+}
+
+func TestEncode(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) expect.Expectation {
+		return expect.New(t)
+	})
+
+	o.Spec("it writes files back the way they were read", func(expect expect.Expectation) {
+		for _, data := range []string{
+			"",
+			"a\nb\n",
+			"a\r\nb\r\n",
+			"\xEF\xBB\xBFa\r\nb",
+			"\xFF\xFEa\x00\r\x00\n\x00",
+			"\xFE\xFF\x00a\x00\n",
+			"a\x00\n\x00",
+			"caf\xE9\r\n",
+		} {
+			text, f := textfile.Decode([]byte(data))
+			b, err := textfile.Encode(text, f)
+			expect(err).To(Not(HaveOccurred()))
+			expect(string(b)).To(Equal(data))
+		}
+	})
+
+	o.Spec("it converts between formats", func(expect expect.Expectation) {
+		f := textfile.Format{Encoding: textfile.UTF16BE, LineEnding: textfile.CRLF, BOM: true}
+		b, err := textfile.Encode("é\n", f)
+		expect(err).To(Not(HaveOccurred()))
+		expect(string(b)).To(Equal("\xFE\xFF\x00\xE9\x00\r\x00\n"))
+	})
+
+	o.Spec("it reports text that an encoding can't hold", func(expect expect.Expectation) {
+		f := textfile.Format{Encoding: textfile.Latin1}
+		_, err := textfile.Encode("a\n€", f)
+		expect(err).To(HaveOccurred())
+		expect(err.Error()).To(ContainSubstring("line 2"))
+	})
+
+	o.Spec("it describes formats", func(expect expect.Expectation) {
+		f := textfile.Format{Encoding: textfile.UTF8, LineEnding: textfile.CRLF, BOM: true}
+		expect(f.String()).To(Equal("UTF-8 BOM  CRLF"))
+		expect(textfile.Default.String()).To(Equal("UTF-8  LF"))
+	})
+}
@@ -0,0 +1,21 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package textmate
+
+func init() {
+	for _, src := range []string{
+		markdownGrammar,
+		jsonGrammar,
+		yamlGrammar,
+		tomlGrammar,
+		shellGrammar,
+		makefileGrammar,
+		sqlGrammar,
+		protobufGrammar,
+		dockerfileGrammar,
+	} {
+		Register(MustParse(src))
+	}
+}
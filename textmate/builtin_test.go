@@ -0,0 +1,176 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package textmate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/textmate"
+	"github.com/nelsam/vidar/theme"
+)
+
+type expectedConstruct struct {
+	match     string
+	construct theme.LanguageConstruct
+}
+
+func TestBuiltinGrammars(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) expect.Expectation {
+		return expect.New(t)
+	})
+
+	for _, tt := range []struct {
+		path     string
+		scope    string
+		src      string
+		expected []expectedConstruct
+	}{
+		{
+			path:  "README.md",
+			scope: "text.markdown",
+			src:   "# Title\n\nSome `code` and a [link](http://x.y).\n\n```go\nfunc main() {}\n```\n- item\n",
+			expected: []expectedConstruct{
+				{"# Title", theme.Keyword},
+				{"`code`", theme.String},
+				{"link", theme.String},
+				{"func main() {}", theme.String},
+				{"-", theme.Keyword},
+			},
+		},
+		{
+			path:  "config.json",
+			scope: "source.json",
+			src:   `{"key": "value", "n": -1.5e3, "ok": true, "none": null}`,
+			expected: []expectedConstruct{
+				{`"key"`, theme.Type},
+				{`"value"`, theme.String},
+				{"-1.5e3", theme.Num},
+				{"true", theme.Builtin},
+				{"null", theme.Nil},
+			},
+		},
+		{
+			path:  "ci.yml",
+			scope: "source.yaml",
+			src:   "# comment\nname: build\nsteps:\n  - run: 'go test'\n    count: 3\n",
+			expected: []expectedConstruct{
+				{"# comment", theme.Comment},
+				{"name", theme.Keyword},
+				{"steps", theme.Keyword},
+				{"run", theme.Keyword},
+				{"'go test'", theme.String},
+				{"3", theme.Num},
+			},
+		},
+		{
+			path:  "Cargo.toml",
+			scope: "source.toml",
+			src:   "[package]\nname = \"vidar\" # comment\nversion = 2\nenabled = false\n",
+			expected: []expectedConstruct{
+				{"package", theme.Keyword},
+				{"name", theme.Ident},
+				{`"vidar"`, theme.String},
+				{"# comment", theme.Comment},
+				{"2", theme.Num},
+				{"false", theme.Builtin},
+			},
+		},
+		{
+			path:  "build.sh",
+			scope: "source.shell",
+			src:   "#!/bin/sh\nif [ -n \"$FOO\" ]; then\n  echo 'hi' # done\nfi\n",
+			expected: []expectedConstruct{
+				{"#!/bin/sh", theme.Comment},
+				{"if", theme.Keyword},
+				{"$FOO", theme.Ident},
+				{"echo", theme.Builtin},
+				{"'hi'", theme.String},
+				{"# done", theme.Comment},
+			},
+		},
+		{
+			path:  "Makefile",
+			scope: "source.makefile",
+			src:   "GO ?= go\n# compile it\nbuild: deps\n\t$(GO) build $@\n",
+			expected: []expectedConstruct{
+				{"GO", theme.Ident},
+				{"# compile it", theme.Comment},
+				{"build", theme.Func},
+				{"$(GO", theme.Ident},
+				{"$@", theme.Builtin},
+			},
+		},
+		{
+			path:  "schema.sql",
+			scope: "source.sql",
+			src:   "-- schema\nCREATE TABLE users (id bigint, name text DEFAULT 'x');\nSELECT count(*) FROM users WHERE name IS NULL;\n",
+			expected: []expectedConstruct{
+				{"-- schema", theme.Comment},
+				{"CREATE", theme.Keyword},
+				{"users", theme.Type},
+				{"bigint", theme.Type},
+				{"'x'", theme.String},
+				{"SELECT", theme.Keyword},
+				{"NULL", theme.Nil},
+			},
+		},
+		{
+			path:  "api.proto",
+			scope: "source.proto",
+			src:   "syntax = \"proto3\";\n// A user.\nmessage User {\n  repeated string names = 1;\n}\n",
+			expected: []expectedConstruct{
+				{"syntax", theme.Keyword},
+				{`"proto3"`, theme.String},
+				{"// A user.", theme.Comment},
+				{"User", theme.Type},
+				{"string", theme.Type},
+				{"1", theme.Num},
+			},
+		},
+		{
+			path:  "Dockerfile.dev",
+			scope: "source.dockerfile",
+			src:   "# base\nFROM golang:1.13 AS build\nRUN go build -o /bin/app \"$PKG\"\n",
+			expected: []expectedConstruct{
+				{"# base", theme.Comment},
+				{"FROM", theme.Keyword},
+				{"golang:1.13", theme.Type},
+				{"build", theme.Func},
+				{"RUN", theme.Keyword},
+				{`"$PKG"`, theme.String},
+			},
+		},
+	} {
+		tt := tt
+		o.Group(tt.path, func() {
+			o.Spec("it finds the grammar by path", func(expect expect.Expectation) {
+				g := textmate.ForPath(tt.path)
+				expect(g).To(Not(BeNil()))
+				expect(g.ScopeName).To(Equal(tt.scope))
+			})
+
+			for _, e := range tt.expected {
+				e := e
+				o.Spec("it highlights "+e.match, func(expect expect.Expectation) {
+					g := textmate.ForScope(tt.scope)
+					layers, err := g.Highlight(context.Background(), tt.src)
+					expect(err).To(BeNil())
+					expect(constructOf(layers, tt.src, e.match)).To(Equal(e.construct))
+				})
+			}
+		})
+	}
+
+	o.Spec("it returns nil for unknown files", func(expect expect.Expectation) {
+		expect(textmate.ForPath("main.go")).To(BeNil())
+	})
+}
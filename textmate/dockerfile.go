@@ -0,0 +1,43 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package textmate
+
+const dockerfileGrammar = `{
+	"name": "Dockerfile",
+	"scopeName": "source.dockerfile",
+	"fileTypes": ["Dockerfile", "Containerfile", "dockerfile"],
+	"patterns": [
+		{"name": "comment.line.number-sign.dockerfile", "match": "^\\s*#.*$"},
+		{
+			"match": "^\\s*(?i:(FROM))\\s+(\\S+)(?:\\s+(?i:(AS))\\s+(\\S+))?",
+			"captures": {
+				"1": {"name": "keyword.other.special-method.dockerfile"},
+				"2": {"name": "entity.name.type.image.dockerfile"},
+				"3": {"name": "keyword.other.special-method.dockerfile"},
+				"4": {"name": "entity.name.function.stage.dockerfile"}
+			}
+		},
+		{
+			"match": "^\\s*(?i:(ADD|ARG|CMD|COPY|ENTRYPOINT|ENV|EXPOSE|HEALTHCHECK|LABEL|MAINTAINER|ONBUILD|RUN|SHELL|STOPSIGNAL|USER|VOLUME|WORKDIR))\\b",
+			"captures": {
+				"1": {"name": "keyword.other.special-method.dockerfile"}
+			}
+		},
+		{"name": "variable.parameter.flag.dockerfile", "match": "\\s--[A-Za-z-]+(?:=\\S*)?"},
+		{"name": "variable.other.dockerfile", "match": "\\$(?:[A-Za-z_][A-Za-z0-9_]*|\\{[^}]*\\})"},
+		{
+			"name": "string.quoted.double.dockerfile",
+			"begin": "\"",
+			"end": "\"|$",
+			"patterns": [{"name": "constant.character.escape.dockerfile", "match": "\\\\."}]
+		},
+		{
+			"name": "string.quoted.single.dockerfile",
+			"begin": "'",
+			"end": "'|$"
+		},
+		{"name": "constant.character.escape.dockerfile", "match": "\\\\$"}
+	]
+}`
@@ -0,0 +1,213 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+// Package textmate implements a small, pure Go engine for
+// TextMate-style grammars.  It supports the subset of the format
+// that is needed for highlighting: match rules, begin/end rules,
+// captures, nested patterns and includes from the repository or
+// other registered grammars.
+//
+// Patterns are compiled with Go's regexp package, so grammars must
+// stick to RE2 syntax (no lookbehind or backreferences).
+package textmate
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nelsam/vidar/theme"
+)
+
+// Grammar is a parsed TextMate grammar.
+type Grammar struct {
+	Name       string           `json:"name"`
+	ScopeName  string           `json:"scopeName"`
+	FileTypes  []string         `json:"fileTypes"`
+	Patterns   []*Rule          `json:"patterns"`
+	Repository map[string]*Rule `json:"repository"`
+}
+
+// Rule is a single rule in a grammar.  A rule either matches
+// a single regular expression (Match), spans from a Begin to
+// an End expression, or includes other rules (Include or a
+// bare list of Patterns).
+type Rule struct {
+	Name          string           `json:"name"`
+	ContentName   string           `json:"contentName"`
+	Match         string           `json:"match"`
+	Begin         string           `json:"begin"`
+	End           string           `json:"end"`
+	Captures      map[string]*Rule `json:"captures"`
+	BeginCaptures map[string]*Rule `json:"beginCaptures"`
+	EndCaptures   map[string]*Rule `json:"endCaptures"`
+	Patterns      []*Rule          `json:"patterns"`
+	Include       string           `json:"include"`
+
+	grammar *Grammar
+
+	match, begin, end  *pattern
+	construct, content theme.LanguageConstruct
+
+	captures, beginCaptures, endCaptures map[int]theme.LanguageConstruct
+}
+
+// pattern is a compiled regular expression, along with whether
+// or not it is anchored to the start of a line.
+type pattern struct {
+	*regexp.Regexp
+	lineStart bool
+
+	// after matches any rune followed by the pattern.  Searching
+	// from the rune before an offset, rather than slicing the line
+	// at the offset, lets \b and \B see the text before the offset
+	// and keeps ^ from matching at it.
+	after *regexp.Regexp
+}
+
+func compile(src string) (*pattern, error) {
+	re, err := regexp.Compile(src)
+	if err != nil {
+		return nil, err
+	}
+	after, err := regexp.Compile(`(?s:.)(?:` + src + `)`)
+	if err != nil {
+		return nil, err
+	}
+	return &pattern{Regexp: re, lineStart: strings.HasPrefix(src, "^"), after: after}, nil
+}
+
+// Parse parses a JSON TextMate grammar and compiles its
+// patterns.
+func Parse(src []byte) (*Grammar, error) {
+	g := &Grammar{}
+	if err := json.Unmarshal(src, g); err != nil {
+		return nil, err
+	}
+	if g.ScopeName == "" {
+		return nil, fmt.Errorf("textmate: grammar %q has no scopeName", g.Name)
+	}
+	for _, r := range g.Patterns {
+		if err := g.compile(r); err != nil {
+			return nil, err
+		}
+	}
+	for name, r := range g.Repository {
+		if err := g.compile(r); err != nil {
+			return nil, fmt.Errorf("textmate: repository rule %q: %s", name, err)
+		}
+	}
+	return g, nil
+}
+
+// MustParse is like Parse, but panics on errors.  It is intended
+// for grammars that are compiled in to the binary.
+func MustParse(src string) *Grammar {
+	g, err := Parse([]byte(src))
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+func (g *Grammar) compile(r *Rule) error {
+	if r.grammar != nil {
+		return nil
+	}
+	r.grammar = g
+	var err error
+	if r.Match != "" {
+		if r.match, err = compile(r.Match); err != nil {
+			return fmt.Errorf("textmate: bad match %q: %s", r.Match, err)
+		}
+	}
+	if r.Begin != "" {
+		if r.begin, err = compile(r.Begin); err != nil {
+			return fmt.Errorf("textmate: bad begin %q: %s", r.Begin, err)
+		}
+		if r.End == "" {
+			return fmt.Errorf("textmate: rule %q has a begin with no end", r.Name)
+		}
+		if r.end, err = compile(r.End); err != nil {
+			return fmt.Errorf("textmate: bad end %q: %s", r.End, err)
+		}
+	}
+	r.construct = Construct(r.Name)
+	r.content = Construct(r.ContentName)
+	if r.captures, err = captures(r.Captures); err != nil {
+		return err
+	}
+	if r.beginCaptures, err = captures(r.BeginCaptures); err != nil {
+		return err
+	}
+	if r.endCaptures, err = captures(r.EndCaptures); err != nil {
+		return err
+	}
+	if r.beginCaptures == nil {
+		r.beginCaptures = r.captures
+	}
+	if r.endCaptures == nil {
+		r.endCaptures = r.captures
+	}
+	for _, p := range r.Patterns {
+		if err := g.compile(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func captures(c map[string]*Rule) (map[int]theme.LanguageConstruct, error) {
+	if len(c) == 0 {
+		return nil, nil
+	}
+	m := make(map[int]theme.LanguageConstruct, len(c))
+	for k, r := range c {
+		i, err := strconv.Atoi(k)
+		if err != nil {
+			return nil, fmt.Errorf("textmate: bad capture index %q", k)
+		}
+		m[i] = Construct(r.Name)
+	}
+	return m, nil
+}
+
+// rules returns the flattened list of rules that r's patterns
+// refer to, resolving includes.
+func (g *Grammar) rules(patterns []*Rule, seen map[*Rule]bool) []*Rule {
+	var out []*Rule
+	for _, r := range patterns {
+		if seen[r] {
+			continue
+		}
+		seen[r] = true
+		if r.Include == "" && (r.match != nil || r.begin != nil) {
+			out = append(out, r)
+			continue
+		}
+		if r.Include == "" {
+			out = append(out, g.rules(r.Patterns, seen)...)
+			continue
+		}
+		switch {
+		case strings.HasPrefix(r.Include, "#"):
+			inc, ok := g.Repository[r.Include[1:]]
+			if !ok {
+				continue
+			}
+			out = append(out, g.rules([]*Rule{inc}, seen)...)
+		case r.Include == "$self" || r.Include == "$base":
+			out = append(out, g.rules(g.Patterns, seen)...)
+		default:
+			other := ForScope(r.Include)
+			if other == nil {
+				continue
+			}
+			out = append(out, other.rules(other.Patterns, seen)...)
+		}
+	}
+	return out
+}
@@ -0,0 +1,295 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package textmate
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/theme"
+)
+
+// Each level of nesting gets depthStep priority levels, so that
+// the names of nested rules always win over the names of the
+// rules that contain them.
+const (
+	regionDepth = iota
+	contentDepth
+	matchDepth
+	captureDepth
+
+	depthStep
+)
+
+// maxStates is the maximum number of begin rules that may be open
+// at once.  Grammars that recurse beyond it are treated as broken
+// and the remaining begin matches are ignored.
+const maxStates = 100
+
+type frame struct {
+	rule         *Rule
+	rules        []*Rule
+	begin        int
+	start        int
+	contentStart int
+}
+
+type tokenizer struct {
+	grammar    *Grammar
+	constructs []theme.LanguageConstruct
+	depths     []int
+	rules      map[*Rule][]*Rule
+
+	line      string
+	lineStart int
+	runeIdx   []int
+	cache     map[*pattern][]int
+}
+
+// Highlight tokenizes text using g and returns the resulting
+// syntax layers.  Spans in the returned layers never overlap.  If
+// ctx is cancelled before tokenizing finishes, ctx.Err() is
+// returned.
+func (g *Grammar) Highlight(ctx context.Context, text string) ([]input.SyntaxLayer, error) {
+	return g.HighlightReader(ctx, strings.NewReader(text))
+}
+
+// HighlightReader is like Highlight, but reads the text from r.
+// Grammars only match within a line, so the text is read one line at
+// a time rather than all at once.
+func (g *Grammar) HighlightReader(ctx context.Context, r io.Reader) ([]input.SyntaxLayer, error) {
+	t := &tokenizer{
+		grammar: g,
+		rules:   make(map[*Rule][]*Rule),
+	}
+	stack := []*frame{{rules: g.rules(g.Patterns, make(map[*Rule]bool))}}
+	lines := bufio.NewReader(r)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		line, err := lines.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if line == "" {
+			break
+		}
+		n := utf8.RuneCountInString(line)
+		t.constructs = append(t.constructs, make([]theme.LanguageConstruct, n)...)
+		t.depths = append(t.depths, make([]int, n)...)
+		stack = t.tokenizeLine(strings.TrimSuffix(line, "\n"), stack)
+		t.lineStart += n
+	}
+	return t.layers(), nil
+}
+
+func (t *tokenizer) setLine(line string) {
+	t.line = line
+	t.cache = make(map[*pattern][]int)
+	t.runeIdx = nil
+	for i := 0; i < len(line); i++ {
+		if line[i] < utf8.RuneSelf {
+			continue
+		}
+		t.runeIdx = make([]int, len(line)+1)
+		r := 0
+		for b := range line {
+			t.runeIdx[b] = r
+			r++
+		}
+		t.runeIdx[len(line)] = r
+		break
+	}
+}
+
+// pos converts a byte offset in the current line to a rune
+// offset in the full text.
+func (t *tokenizer) pos(b int) int {
+	if t.runeIdx == nil {
+		return t.lineStart + b
+	}
+	return t.lineStart + t.runeIdx[b]
+}
+
+func (t *tokenizer) find(p *pattern, from int) []int {
+	if p.lineStart && from > 0 {
+		return nil
+	}
+	if loc, ok := t.cache[p]; ok && (loc == nil || loc[0] >= from) {
+		return loc
+	}
+	var loc []int
+	if from == 0 {
+		loc = p.FindStringSubmatchIndex(t.line)
+	} else {
+		_, size := utf8.DecodeLastRuneInString(t.line[:from])
+		start := from - size
+		loc = p.after.FindStringSubmatchIndex(t.line[start:])
+		if loc != nil {
+			for i := range loc {
+				if loc[i] >= 0 {
+					loc[i] += start
+				}
+			}
+			// Skip the rune that the match was given as context.
+			_, size = utf8.DecodeRuneInString(t.line[loc[0]:])
+			loc[0] += size
+		}
+	}
+	if !p.lineStart {
+		t.cache[p] = loc
+	}
+	return loc
+}
+
+func (t *tokenizer) flatten(r *Rule) []*Rule {
+	if rules, ok := t.rules[r]; ok {
+		return rules
+	}
+	rules := r.grammar.rules(r.Patterns, make(map[*Rule]bool))
+	t.rules[r] = rules
+	return rules
+}
+
+func (t *tokenizer) tokenizeLine(line string, stack []*frame) []*frame {
+	t.setLine(line)
+	pos := 0
+	for i := 0; pos <= len(line) && i <= 4*len(line)+16; i++ {
+		top := stack[len(stack)-1]
+		var (
+			best  []int
+			match *Rule
+			isEnd bool
+		)
+		if top.rule != nil {
+			if loc := t.find(top.rule.end, pos); loc != nil {
+				best, isEnd = loc, true
+			}
+		}
+		for _, r := range top.rules {
+			p := r.match
+			if p == nil {
+				if len(stack) > maxStates {
+					continue
+				}
+				p = r.begin
+			}
+			loc := t.find(p, pos)
+			if loc == nil || (best != nil && loc[0] >= best[0]) {
+				continue
+			}
+			best, match, isEnd = loc, r, false
+		}
+		if best == nil {
+			break
+		}
+		depth := (len(stack) - 1) * depthStep
+		pushed := false
+		switch {
+		case isEnd:
+			t.paint(top.contentStart, t.pos(best[0]), top.rule.content, depth+contentDepth)
+			t.paintCaptures(best, top.rule.endCaptures, depth+captureDepth)
+			t.paint(top.start, t.pos(best[1]), top.rule.construct, depth+regionDepth)
+			stack = stack[:len(stack)-1]
+		case match.match != nil:
+			depth += depthStep
+			t.paint(t.pos(best[0]), t.pos(best[1]), match.construct, depth+matchDepth)
+			t.paintCaptures(best, match.captures, depth+captureDepth)
+		default:
+			depth += depthStep
+			t.paintCaptures(best, match.beginCaptures, depth+captureDepth)
+			stack = append(stack, &frame{
+				rule:         match,
+				rules:        t.flatten(match),
+				begin:        t.pos(best[0]),
+				start:        t.pos(best[0]),
+				contentStart: t.pos(best[1]),
+			})
+			pushed = true
+		}
+		if best[1] > pos || pushed || (isEnd && top.begin != t.pos(pos)) {
+			pos = best[1]
+			continue
+		}
+		// A zero-length match that didn't change the state would
+		// match again forever; skip a rune instead.
+		if pos == len(line) {
+			break
+		}
+		_, size := utf8.DecodeRuneInString(line[pos:])
+		pos += size
+	}
+
+	// Anything left on the stack continues on to the next line.
+	end := t.pos(len(line))
+	for i, f := range stack[1:] {
+		depth := (i + 1) * depthStep
+		t.paint(f.contentStart, end, f.rule.content, depth+contentDepth)
+		t.paint(f.start, end, f.rule.construct, depth+regionDepth)
+		f.start = end + 1
+		f.contentStart = end + 1
+	}
+	return stack
+}
+
+func (t *tokenizer) paintCaptures(loc []int, captures map[int]theme.LanguageConstruct, depth int) {
+	for i, c := range captures {
+		if 2*i+1 >= len(loc) || loc[2*i] < 0 {
+			continue
+		}
+		// Capture 0 is the whole match, so nested groups need to
+		// be able to override it.
+		d := depth
+		if i == 0 {
+			d--
+		}
+		t.paint(t.pos(loc[2*i]), t.pos(loc[2*i+1]), c, d)
+	}
+}
+
+func (t *tokenizer) paint(start, end int, c theme.LanguageConstruct, depth int) {
+	if c == 0 {
+		return
+	}
+	if end > len(t.constructs) {
+		end = len(t.constructs)
+	}
+	for i := start; i < end; i++ {
+		if depth < t.depths[i] {
+			continue
+		}
+		t.depths[i] = depth
+		t.constructs[i] = c
+	}
+}
+
+func (t *tokenizer) layers() []input.SyntaxLayer {
+	spans := make(map[theme.LanguageConstruct][]input.Span)
+	for i := 0; i < len(t.constructs); {
+		c := t.constructs[i]
+		start := i
+		for i < len(t.constructs) && t.constructs[i] == c {
+			i++
+		}
+		if c == 0 {
+			continue
+		}
+		spans[c] = append(spans[c], input.Span{Start: start, End: i})
+	}
+	layers := make([]input.SyntaxLayer, 0, len(spans))
+	for c, s := range spans {
+		layers = append(layers, input.SyntaxLayer{Construct: c, Spans: s})
+	}
+	sort.Slice(layers, func(i, j int) bool {
+		return layers[i].Construct < layers[j].Construct
+	})
+	return layers
+}
@@ -0,0 +1,215 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package textmate_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/commander/input"
+	"github.com/nelsam/vidar/textmate"
+	"github.com/nelsam/vidar/theme"
+)
+
+const testGrammar = `{
+	"name": "Test",
+	"scopeName": "source.test",
+	"fileTypes": ["test"],
+	"patterns": [
+		{"include": "#comment"},
+		{
+			"name": "string.quoted.double.test",
+			"begin": "\"",
+			"end": "\"",
+			"patterns": [{"name": "constant.character.escape.test", "match": "\\\\."}]
+		},
+		{
+			"match": "\\b(func)\\s+([a-z]+)",
+			"captures": {
+				"1": {"name": "keyword.other.test"},
+				"2": {"name": "entity.name.function.test"}
+			}
+		},
+		{"name": "constant.numeric.test", "match": "\\b[0-9]+\\b"},
+		{"name": "keyword.control.test", "match": "^start\\b"},
+		{
+			"begin": "\\bcount",
+			"end": ";",
+			"patterns": [{"name": "constant.numeric.test", "match": "\\b[0-9]+"}]
+		}
+	],
+	"repository": {
+		"comment": {
+			"name": "comment.block.test",
+			"begin": "/\\*",
+			"end": "\\*/"
+		}
+	}
+}`
+
+// constructOf returns the construct that covers every rune in
+// the first occurrence of match in src.  It returns -1 if the
+// match is covered by more than one construct.
+func constructOf(layers []input.SyntaxLayer, src, match string) theme.LanguageConstruct {
+	idx := strings.Index(src, match)
+	if idx < 0 {
+		panic(fmt.Errorf("%q not found in source", match))
+	}
+	start := utf8.RuneCountInString(src[:idx])
+	end := start + utf8.RuneCountInString(match)
+	found := theme.LanguageConstruct(0)
+	for i := start; i < end; i++ {
+		c := theme.LanguageConstruct(0)
+		for _, l := range layers {
+			for _, s := range l.Spans {
+				if s.Start <= i && i < s.End {
+					c = l.Construct
+				}
+			}
+		}
+		if i > start && c != found {
+			return -1
+		}
+		found = c
+	}
+	return found
+}
+
+func TestHighlight(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, *textmate.Grammar) {
+		expect := expect.New(t)
+		g, err := textmate.Parse([]byte(testGrammar))
+		expect(err).To(BeNil())
+		return expect, g
+	})
+
+	o.Spec("it highlights match rules and their captures", func(expect expect.Expectation, g *textmate.Grammar) {
+		const src = "func foo 42"
+		layers, err := g.Highlight(context.Background(), src)
+		expect(err).To(BeNil())
+		expect(constructOf(layers, src, "func")).To(Equal(theme.Keyword))
+		expect(constructOf(layers, src, "foo")).To(Equal(theme.Func))
+		expect(constructOf(layers, src, "42")).To(Equal(theme.Num))
+	})
+
+	o.Spec("it lets nested rules override their parents", func(expect expect.Expectation, g *textmate.Grammar) {
+		const src = `x := "a\nb"`
+		layers, err := g.Highlight(context.Background(), src)
+		expect(err).To(BeNil())
+		expect(constructOf(layers, src, `"a`)).To(Equal(theme.String))
+		expect(constructOf(layers, src, `\n`)).To(Equal(theme.Keyword))
+		expect(constructOf(layers, src, `b"`)).To(Equal(theme.String))
+		expect(constructOf(layers, src, `x :=`)).To(Equal(theme.LanguageConstruct(0)))
+	})
+
+	o.Spec("it continues begin/end rules across lines", func(expect expect.Expectation, g *textmate.Grammar) {
+		const src = "1 /* a\nfunc foo 2\n*/ 3"
+		layers, err := g.Highlight(context.Background(), src)
+		expect(err).To(BeNil())
+		expect(constructOf(layers, src, "/* a")).To(Equal(theme.Comment))
+		expect(constructOf(layers, src, "func foo 2")).To(Equal(theme.Comment))
+		expect(constructOf(layers, src, "*/")).To(Equal(theme.Comment))
+		expect(constructOf(layers, src, "1")).To(Equal(theme.Num))
+		expect(constructOf(layers, src, "3")).To(Equal(theme.Num))
+	})
+
+	o.Spec("it only matches line-anchored patterns at the start of a line", func(expect expect.Expectation, g *textmate.Grammar) {
+		const src = "start start\nstart"
+		layers, err := g.Highlight(context.Background(), src)
+		expect(err).To(BeNil())
+		keywords := layers[0]
+		expect(keywords.Construct).To(Equal(theme.Keyword))
+		expect(keywords.Spans).To(HaveLen(2))
+		expect(keywords.Spans[0]).To(Equal(input.Span{Start: 0, End: 5}))
+		expect(keywords.Spans[1]).To(Equal(input.Span{Start: 12, End: 17}))
+	})
+
+	o.Spec("it sees the text before where it resumes matching", func(expect expect.Expectation, g *textmate.Grammar) {
+		const src = "count9 7;"
+		layers, err := g.Highlight(context.Background(), src)
+		expect(err).To(BeNil())
+		expect(constructOf(layers, src, "9")).To(Equal(theme.LanguageConstruct(0)))
+		expect(constructOf(layers, src, "7")).To(Equal(theme.Num))
+	})
+
+	o.Spec("it uses rune offsets", func(expect expect.Expectation, g *textmate.Grammar) {
+		const src = `"þø" 12`
+		layers, err := g.Highlight(context.Background(), src)
+		expect(err).To(BeNil())
+		expect(constructOf(layers, src, `"þø"`)).To(Equal(theme.String))
+		expect(constructOf(layers, src, "12")).To(Equal(theme.Num))
+	})
+
+	o.Spec("it stops when the context is cancelled", func(expect expect.Expectation, g *textmate.Grammar) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := g.Highlight(ctx, "func foo")
+		expect(err).To(Equal(context.Canceled))
+	})
+}
+
+func TestParseErrors(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) expect.Expectation {
+		return expect.New(t)
+	})
+
+	o.Spec("it rejects grammars without a scope name", func(expect expect.Expectation) {
+		_, err := textmate.Parse([]byte(`{"patterns": []}`))
+		expect(err).To(Not(BeNil()))
+	})
+
+	o.Spec("it rejects patterns that are not valid RE2", func(expect expect.Expectation) {
+		_, err := textmate.Parse([]byte(`{"scopeName": "source.x", "patterns": [{"match": "(?<=a)b"}]}`))
+		expect(err).To(Not(BeNil()))
+	})
+
+	o.Spec("it rejects begin rules with no end", func(expect expect.Expectation) {
+		_, err := textmate.Parse([]byte(`{"scopeName": "source.x", "patterns": [{"begin": "a"}]}`))
+		expect(err).To(Not(BeNil()))
+	})
+}
+
+func TestConstruct(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) expect.Expectation {
+		return expect.New(t)
+	})
+
+	for _, tt := range []struct {
+		scope    string
+		expected theme.LanguageConstruct
+	}{
+		{"comment.line.number-sign.sh", theme.Comment},
+		{"string.quoted.double.json", theme.String},
+		{"constant.numeric.integer", theme.Num},
+		{"constant.language.null.json", theme.Nil},
+		{"constant.language.boolean", theme.Builtin},
+		{"entity.name.function.target", theme.Func},
+		{"storage.type.proto", theme.Type},
+		{"invalid.illegal", theme.Bad},
+		{"meta.structure.dictionary", 0},
+		{"stringy", 0},
+		{"meta.block keyword.control", theme.Keyword},
+	} {
+		tt := tt
+		o.Spec(fmt.Sprintf("it maps %q to %d", tt.scope, tt.expected), func(expect expect.Expectation) {
+			expect(textmate.Construct(tt.scope)).To(Equal(tt.expected))
+		})
+	}
+}
@@ -0,0 +1,45 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package textmate
+
+const jsonGrammar = `{
+	"name": "JSON",
+	"scopeName": "source.json",
+	"fileTypes": ["json", "jsonc", "geojson", "webmanifest"],
+	"patterns": [{"include": "#value"}],
+	"repository": {
+		"value": {
+			"patterns": [
+				{"include": "#comment"},
+				{"include": "#key"},
+				{"include": "#string"},
+				{"name": "constant.numeric.json", "match": "-?(?:0|[1-9][0-9]*)(?:\\.[0-9]+)?(?:[eE][+-]?[0-9]+)?"},
+				{"name": "constant.language.null.json", "match": "\\bnull\\b"},
+				{"name": "constant.language.json", "match": "\\b(?:true|false)\\b"}
+			]
+		},
+		"comment": {
+			"patterns": [
+				{"name": "comment.block.json", "begin": "/\\*", "end": "\\*/"},
+				{"name": "comment.line.double-slash.json", "match": "//.*$"}
+			]
+		},
+		"key": {
+			"match": "(\"(?:[^\"\\\\]|\\\\.)*\")\\s*(:)",
+			"captures": {
+				"1": {"name": "support.type.property-name.json"}
+			}
+		},
+		"string": {
+			"name": "string.quoted.double.json",
+			"begin": "\"",
+			"end": "\"|$",
+			"patterns": [
+				{"name": "constant.character.escape.json", "match": "\\\\(?:[\"\\\\/bfnrt]|u[0-9a-fA-F]{4})"},
+				{"name": "invalid.illegal.escape.json", "match": "\\\\."}
+			]
+		}
+	}
+}`
@@ -0,0 +1,58 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package textmate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// grammarExt is the extension of grammar files that LoadDir loads.
+const grammarExt = ".tmLanguage.json"
+
+// LoadDir parses and registers every grammar file (ending in
+// .tmLanguage.json) in dir, alongside the built-in grammars.  A
+// grammar is registered for its fileTypes, or for the name of its
+// file without the extension (e.g. lua.tmLanguage.json is registered
+// for "lua") if it has none.  A user's grammar replaces a built-in
+// grammar for the same file types.
+//
+// A missing dir is not an error.  Files that fail to load are
+// skipped, and an error is returned for each of them.
+func LoadDir(dir string) []error {
+	infos, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return []error{err}
+	}
+	var errs []error
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), grammarExt) {
+			continue
+		}
+		path := filepath.Join(dir, info.Name())
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		g, err := Parse(src)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("textmate: loading %s: %s", path, err))
+			continue
+		}
+		if len(g.FileTypes) == 0 {
+			Register(g, strings.TrimSuffix(info.Name(), grammarExt))
+			continue
+		}
+		Register(g)
+	}
+	return errs
+}
@@ -0,0 +1,67 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package textmate_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apoydence/onpar"
+	"github.com/apoydence/onpar/expect"
+	. "github.com/apoydence/onpar/matchers"
+	"github.com/nelsam/vidar/textmate"
+)
+
+func TestLoadDir(t *testing.T) {
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) (expect.Expectation, string) {
+		dir, err := ioutil.TempDir("", "textmate")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return expect.New(t), dir
+	})
+
+	o.AfterEach(func(_ expect.Expectation, dir string) {
+		os.RemoveAll(dir)
+	})
+
+	write := func(dir, name, src string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0600); err != nil {
+			panic(err)
+		}
+	}
+
+	o.Spec("it registers grammars by their file types", func(expect expect.Expectation, dir string) {
+		write(dir, "lua.tmLanguage.json", `{"scopeName": "source.lua.test", "fileTypes": ["lua", "rockspec"], "patterns": []}`)
+		expect(textmate.LoadDir(dir)).To(HaveLen(0))
+		expect(textmate.ForPath("init.lua")).To(Not(BeNil()))
+		expect(textmate.ForPath("x.rockspec").ScopeName).To(Equal("source.lua.test"))
+		expect(textmate.ForScope("source.lua.test")).To(Not(BeNil()))
+	})
+
+	o.Spec("it registers grammars without file types by file name", func(expect expect.Expectation, dir string) {
+		write(dir, "zig.tmLanguage.json", `{"scopeName": "source.zig.test", "patterns": []}`)
+		expect(textmate.LoadDir(dir)).To(HaveLen(0))
+		expect(textmate.ForPath("main.zig").ScopeName).To(Equal("source.zig.test"))
+	})
+
+	o.Spec("it skips grammars that fail to load", func(expect expect.Expectation, dir string) {
+		write(dir, "bad.tmLanguage.json", `{"patterns": []}`)
+		write(dir, "notes.txt", `not a grammar`)
+		write(dir, "ok.tmLanguage.json", `{"scopeName": "source.ok.test", "fileTypes": ["oktest"], "patterns": []}`)
+		errs := textmate.LoadDir(dir)
+		expect(errs).To(HaveLen(1))
+		expect(textmate.ForPath("a.oktest")).To(Not(BeNil()))
+	})
+
+	o.Spec("it ignores a missing directory", func(expect expect.Expectation, dir string) {
+		expect(textmate.LoadDir(filepath.Join(dir, "missing"))).To(HaveLen(0))
+	})
+}
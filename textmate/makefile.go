@@ -0,0 +1,58 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package textmate
+
+const makefileGrammar = `{
+	"name": "Makefile",
+	"scopeName": "source.makefile",
+	"fileTypes": ["Makefile", "makefile", "GNUmakefile", "mk", "mak"],
+	"patterns": [
+		{"include": "#comment"},
+		{"name": "keyword.control.makefile", "match": "^\\s*-?(?:include|sinclude|ifeq|ifneq|ifdef|ifndef|else|endif|define|endef|export|unexport|override|undefine|vpath)\\b"},
+		{
+			"match": "^([A-Za-z0-9_.]+)\\s*([:+?!]?=)",
+			"captures": {
+				"1": {"name": "variable.other.makefile"},
+				"2": {"name": "keyword.operator.assignment.makefile"}
+			}
+		},
+		{
+			"match": "^([^\\s:=#][^:=#]*?)\\s*(::?)(?:[^=]|$)",
+			"captures": {
+				"1": {"name": "entity.name.function.target.makefile"}
+			}
+		},
+		{"include": "#variable"},
+		{
+			"name": "string.quoted.double.makefile",
+			"begin": "\"",
+			"end": "\"|$",
+			"patterns": [{"include": "#variable"}]
+		},
+		{
+			"name": "string.quoted.single.makefile",
+			"begin": "'",
+			"end": "'|$"
+		}
+	],
+	"repository": {
+		"comment": {
+			"name": "comment.line.number-sign.makefile",
+			"match": "#.*$"
+		},
+		"variable": {
+			"patterns": [
+				{
+					"match": "\\$[({](?:(call|subst|patsubst|strip|findstring|filter|filter-out|sort|word|words|wordlist|firstword|lastword|dir|notdir|suffix|basename|addsuffix|addprefix|join|wildcard|realpath|abspath|if|or|and|foreach|file|value|eval|origin|flavor|error|warning|info|shell)\\s)?([^)}\\s$]*)",
+					"captures": {
+						"0": {"name": "variable.other.makefile"},
+						"1": {"name": "support.function.makefile"}
+					}
+				},
+				{"name": "variable.language.automatic.makefile", "match": "\\$[@%<?^+|*$]"}
+			]
+		}
+	}
+}`
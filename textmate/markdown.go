@@ -0,0 +1,58 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package textmate
+
+const markdownGrammar = `{
+	"name": "Markdown",
+	"scopeName": "text.markdown",
+	"fileTypes": ["md", "markdown", "mdown", "mkd"],
+	"patterns": [
+		{
+			"name": "markup.raw.block.fenced.markdown",
+			"begin": "^\\s*(\u0060{3,}|~{3,})\\s*([^\\s\u0060]*)",
+			"end": "^\\s*(\u0060{3,}|~{3,})\\s*$",
+			"beginCaptures": {
+				"2": {"name": "entity.name.tag.language.markdown"}
+			}
+		},
+		{"name": "markup.heading.markdown", "match": "^\\s{0,3}#{1,6}(?:\\s.*)?$"},
+		{"name": "markup.heading.setext.markdown", "match": "^(?:=+|-{2,})\\s*$"},
+		{"name": "comment.block.html.markdown", "begin": "<!--", "end": "-->"},
+		{"name": "markup.quote.markdown", "match": "^\\s{0,3}>.*$"},
+		{"name": "markup.raw.block.markdown", "match": "^(?:    |\\t)\\s*\\S.*$"},
+		{
+			"match": "^\\s*([*+-]|[0-9]+[.)])\\s",
+			"captures": {
+				"1": {"name": "markup.list.markdown"}
+			}
+		},
+		{"include": "#inline"}
+	],
+	"repository": {
+		"inline": {
+			"patterns": [
+				{"name": "constant.character.escape.markdown", "match": "\\\\[\\\\\u0060*_{}\\[\\]()#+.!-]"},
+				{"name": "markup.raw.inline.markdown", "match": "(\u0060+)[^\u0060]+?\u0060+"},
+				{"name": "markup.bold.markdown", "match": "(?:\\*\\*|__)[^*_\\s](?:.*?[^*_\\s])?(?:\\*\\*|__)"},
+				{"name": "markup.italic.markdown", "match": "(?:\\*|\\b_)[^*_\\s](?:[^*]*?[^*_\\s])?(?:\\*|_\\b)"},
+				{
+					"match": "!?\\[([^\\]]*)\\]\\(([^)\\s]*)(?:\\s+\"[^\"]*\")?\\)",
+					"captures": {
+						"1": {"name": "string.other.link.title.markdown"},
+						"2": {"name": "markup.underline.link.markdown"}
+					}
+				},
+				{
+					"match": "^\\s{0,3}\\[([^\\]]+)\\]:\\s*(\\S+)",
+					"captures": {
+						"1": {"name": "constant.other.reference.link.markdown"},
+						"2": {"name": "markup.underline.link.markdown"}
+					}
+				},
+				{"name": "markup.underline.link.markdown", "match": "<(?:https?|ftp|mailto):[^>\\s]+>|\\bhttps?://[^\\s)>\\]]+"}
+			]
+		}
+	}
+}`
@@ -0,0 +1,58 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package textmate
+
+const protobufGrammar = `{
+	"name": "Protocol Buffers",
+	"scopeName": "source.proto",
+	"fileTypes": ["proto"],
+	"patterns": [
+		{"include": "#comment"},
+		{"include": "#string"},
+		{
+			"match": "\\b(message|enum|service|extend|oneof)\\s+([A-Za-z_][A-Za-z0-9_.]*)",
+			"captures": {
+				"1": {"name": "keyword.other.proto"},
+				"2": {"name": "entity.name.type.proto"}
+			}
+		},
+		{
+			"match": "\\b(rpc)\\s+([A-Za-z_][A-Za-z0-9_]*)",
+			"captures": {
+				"1": {"name": "keyword.other.proto"},
+				"2": {"name": "entity.name.function.proto"}
+			}
+		},
+		{"name": "keyword.other.proto", "match": "\\b(?:syntax|edition|package|import|public|weak|option|returns|stream|reserved|extensions|to|max|map)\\b"},
+		{"name": "storage.modifier.proto", "match": "\\b(?:optional|required|repeated)\\b"},
+		{"name": "storage.type.proto", "match": "\\b(?:double|float|int32|int64|uint32|uint64|sint32|sint64|fixed32|fixed64|sfixed32|sfixed64|bool|string|bytes)\\b"},
+		{"name": "constant.language.proto", "match": "\\b(?:true|false)\\b"},
+		{"name": "constant.numeric.proto", "match": "-?\\b(?:0[xX][0-9a-fA-F]+|[0-9]+(?:\\.[0-9]+)?(?:[eE][+-]?[0-9]+)?)\\b"}
+	],
+	"repository": {
+		"comment": {
+			"patterns": [
+				{"name": "comment.line.double-slash.proto", "match": "//.*$"},
+				{"name": "comment.block.proto", "begin": "/\\*", "end": "\\*/"}
+			]
+		},
+		"string": {
+			"patterns": [
+				{
+					"name": "string.quoted.double.proto",
+					"begin": "\"",
+					"end": "\"|$",
+					"patterns": [{"name": "constant.character.escape.proto", "match": "\\\\."}]
+				},
+				{
+					"name": "string.quoted.single.proto",
+					"begin": "'",
+					"end": "'|$",
+					"patterns": [{"name": "constant.character.escape.proto", "match": "\\\\."}]
+				}
+			]
+		}
+	}
+}`
@@ -0,0 +1,62 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package textmate
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	mu      sync.RWMutex
+	byType  = make(map[string]*Grammar)
+	byScope = make(map[string]*Grammar)
+)
+
+// Register registers g for each of its FileTypes as well as any
+// extra fileTypes passed in.  File types may be extensions without
+// the leading dot (e.g. "json") or full file names (e.g.
+// "Makefile").  Registering a file type that is already registered
+// replaces the previous grammar.
+func Register(g *Grammar, fileTypes ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	byScope[g.ScopeName] = g
+	for _, t := range append(g.FileTypes, fileTypes...) {
+		byType[t] = g
+	}
+}
+
+// ForPath returns the grammar registered for path, or nil if
+// there is none.  Full file names take precedence over extensions,
+// and a file name with its extensions removed (e.g. Dockerfile.dev)
+// will match the file type for the file name.
+func ForPath(path string) *Grammar {
+	mu.RLock()
+	defer mu.RUnlock()
+	base := filepath.Base(path)
+	if g, ok := byType[base]; ok {
+		return g
+	}
+	for ext := filepath.Ext(base); ext != ""; ext = filepath.Ext(base) {
+		if g, ok := byType[strings.TrimPrefix(ext, ".")]; ok {
+			return g
+		}
+		base = strings.TrimSuffix(base, ext)
+		if g, ok := byType[base]; ok {
+			return g
+		}
+	}
+	return nil
+}
+
+// ForScope returns the grammar registered with scopeName, or nil
+// if there is none.
+func ForScope(scopeName string) *Grammar {
+	mu.RLock()
+	defer mu.RUnlock()
+	return byScope[scopeName]
+}
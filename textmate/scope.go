@@ -0,0 +1,77 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package textmate
+
+import (
+	"strings"
+
+	"github.com/nelsam/vidar/theme"
+)
+
+type scopeMapping struct {
+	prefix    string
+	construct theme.LanguageConstruct
+}
+
+// scopes maps TextMate scope prefixes to language constructs.
+// More specific prefixes must come before less specific ones.
+var scopes = []scopeMapping{
+	{"comment", theme.Comment},
+	{"punctuation.definition.comment", theme.Comment},
+	{"string", theme.String},
+	{"punctuation.definition.string", theme.String},
+	{"markup.raw", theme.String},
+	{"markup.underline.link", theme.String},
+	{"constant.numeric", theme.Num},
+	{"constant.language.null", theme.Nil},
+	{"constant.language", theme.Builtin},
+	{"constant.character.escape", theme.Keyword},
+	{"constant", theme.Ident},
+	{"keyword", theme.Keyword},
+	{"storage.type", theme.Type},
+	{"storage", theme.Keyword},
+	{"entity.name.function", theme.Func},
+	{"entity.name.type", theme.Type},
+	{"entity.name.class", theme.Type},
+	{"entity.name.tag", theme.Keyword},
+	{"entity.name.section", theme.Keyword},
+	{"entity.other.attribute-name", theme.Func},
+	{"entity.name", theme.Ident},
+	{"support.function", theme.Builtin},
+	{"support.type", theme.Type},
+	{"support", theme.Builtin},
+	{"variable.language", theme.Builtin},
+	{"variable", theme.Ident},
+	{"markup.heading", theme.Keyword},
+	{"markup.list", theme.Keyword},
+	{"markup.quote", theme.Comment},
+	{"markup.bold", theme.Func},
+	{"markup.italic", theme.Type},
+	{"invalid", theme.Bad},
+}
+
+// Construct returns the theme.LanguageConstruct that scope should
+// be highlighted as.  Scopes may contain multiple space-separated
+// names, in which case the last name that maps to a construct wins.
+// A return value of 0 means that the scope should not be
+// highlighted.
+func Construct(scope string) theme.LanguageConstruct {
+	var c theme.LanguageConstruct
+	for _, name := range strings.Fields(scope) {
+		if nc := construct(name); nc != 0 {
+			c = nc
+		}
+	}
+	return c
+}
+
+func construct(name string) theme.LanguageConstruct {
+	for _, m := range scopes {
+		if name == m.prefix || strings.HasPrefix(name, m.prefix+".") {
+			return m.construct
+		}
+	}
+	return 0
+}
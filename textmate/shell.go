@@ -0,0 +1,93 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package textmate
+
+const shellGrammar = `{
+	"name": "Shell",
+	"scopeName": "source.shell",
+	"fileTypes": ["sh", "bash", "zsh", "ksh", ".bashrc", ".bash_profile", ".profile", ".zshrc", "envrc", ".envrc"],
+	"patterns": [{"include": "#script"}],
+	"repository": {
+		"script": {
+			"patterns": [
+				{"include": "#comment"},
+				{"include": "#heredoc"},
+				{"include": "#string"},
+				{"include": "#variable"},
+				{
+					"match": "\\b([A-Za-z_][A-Za-z0-9_]*)(=)",
+					"captures": {
+						"1": {"name": "variable.other.assignment.shell"}
+					}
+				},
+				{
+					"match": "^\\s*(?:function\\s+)?([A-Za-z_][A-Za-z0-9_:-]*)\\s*\\(\\)",
+					"captures": {
+						"1": {"name": "entity.name.function.shell"}
+					}
+				},
+				{"name": "keyword.control.shell", "match": "\\b(?:if|then|else|elif|fi|for|in|do|done|while|until|case|esac|select|function|return|break|continue|time)\\b"},
+				{"name": "storage.modifier.shell", "match": "\\b(?:local|export|readonly|declare|typeset|unset)\\b"},
+				{"name": "support.function.builtin.shell", "match": "\\b(?:echo|printf|read|cd|pwd|source|exit|eval|exec|set|shift|test|trap|wait|alias|getopts|true|false)\\b"},
+				{"name": "constant.numeric.shell", "match": "\\b[0-9]+\\b"}
+			]
+		},
+		"comment": {
+			"name": "comment.line.number-sign.shell",
+			"match": "(?:^|\\s)#.*$"
+		},
+		"heredoc": {
+			"begin": "<<-?\\s*['\"]?([A-Za-z_][A-Za-z0-9_]*)['\"]?.*$",
+			"end": "^\\s*[A-Za-z_][A-Za-z0-9_]*$",
+			"contentName": "string.unquoted.heredoc.shell",
+			"beginCaptures": {
+				"1": {"name": "keyword.control.heredoc-token.shell"}
+			},
+			"endCaptures": {
+				"0": {"name": "keyword.control.heredoc-token.shell"}
+			}
+		},
+		"string": {
+			"patterns": [
+				{
+					"name": "string.quoted.single.shell",
+					"begin": "'",
+					"end": "'"
+				},
+				{
+					"name": "string.quoted.double.shell",
+					"begin": "\"",
+					"end": "\"",
+					"patterns": [
+						{"name": "constant.character.escape.shell", "match": "\\\\."},
+						{"include": "#variable"}
+					]
+				},
+				{
+					"name": "string.interpolated.backtick.shell",
+					"begin": "\u0060",
+					"end": "\u0060",
+					"patterns": [{"include": "#script"}]
+				}
+			]
+		},
+		"variable": {
+			"patterns": [
+				{
+					"name": "variable.other.bracket.shell",
+					"begin": "\\$\\{",
+					"end": "\\}"
+				},
+				{
+					"name": "string.interpolated.dollar.shell",
+					"begin": "\\$\\(",
+					"end": "\\)",
+					"patterns": [{"include": "#script"}]
+				},
+				{"name": "variable.other.normal.shell", "match": "\\$(?:[A-Za-z_][A-Za-z0-9_]*|[0-9@#?$!*-])"}
+			]
+		}
+	}
+}`
@@ -0,0 +1,49 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package textmate
+
+const sqlGrammar = `{
+	"name": "SQL",
+	"scopeName": "source.sql",
+	"fileTypes": ["sql", "ddl", "dml", "psql"],
+	"patterns": [
+		{"name": "comment.line.double-dash.sql", "match": "--.*$"},
+		{"name": "comment.line.number-sign.sql", "match": "#.*$"},
+		{"name": "comment.block.sql", "begin": "/\\*", "end": "\\*/"},
+		{
+			"name": "string.quoted.single.sql",
+			"begin": "'",
+			"end": "'",
+			"patterns": [
+				{"name": "constant.character.escape.sql", "match": "''|\\\\."}
+			]
+		},
+		{
+			"name": "string.quoted.double.sql",
+			"begin": "\"",
+			"end": "\""
+		},
+		{
+			"name": "string.quoted.other.backtick.sql",
+			"begin": "\u0060",
+			"end": "\u0060"
+		},
+		{
+			"match": "(?i)\\b(create|alter|drop)\\s+(?:or\\s+replace\\s+)?(?:temporary\\s+|temp\\s+|unique\\s+)?(table|view|index|function|procedure|trigger|schema|database|sequence|type)\\s+(?:if\\s+(?:not\\s+)?exists\\s+)?([A-Za-z_][A-Za-z0-9_.\"]*)",
+			"captures": {
+				"1": {"name": "keyword.other.create.sql"},
+				"2": {"name": "keyword.other.sql"},
+				"3": {"name": "entity.name.type.sql"}
+			}
+		},
+		{"name": "keyword.other.sql", "match": "(?i)\\b(?:select|from|where|and|or|not|in|is|like|ilike|between|exists|insert|into|values|update|set|delete|create|alter|drop|table|view|index|on|join|inner|outer|left|right|full|cross|natural|using|group|order|by|having|limit|offset|union|all|distinct|as|case|when|then|else|end|begin|commit|rollback|transaction|primary|foreign|key|references|unique|check|default|constraint|cascade|returning|with|recursive|asc|desc|if|grant|revoke|to|add|column|rename|truncate|explain|analyze)\\b"},
+		{"name": "constant.language.null.sql", "match": "(?i)\\bnull\\b"},
+		{"name": "constant.language.boolean.sql", "match": "(?i)\\b(?:true|false)\\b"},
+		{"name": "storage.type.sql", "match": "(?i)\\b(?:int|integer|smallint|bigint|serial|bigserial|decimal|numeric|real|float|double|precision|char|varchar|character|varying|text|bytea|blob|boolean|bool|date|time|timestamp|timestamptz|interval|uuid|json|jsonb|xml|money)\\b"},
+		{"name": "support.function.sql", "match": "(?i)\\b(?:count|sum|avg|min|max|coalesce|nullif|cast|now|lower|upper|length|substring|trim|concat|round|abs|greatest|least)\\b(?:\\s*\\()"},
+		{"name": "constant.numeric.sql", "match": "\\b[0-9]+(?:\\.[0-9]+)?\\b"},
+		{"name": "variable.parameter.sql", "match": "[$:@][A-Za-z0-9_]+|\\?"}
+	]
+}`
@@ -0,0 +1,67 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package textmate
+
+const tomlGrammar = `{
+	"name": "TOML",
+	"scopeName": "source.toml",
+	"fileTypes": ["toml", "Gopkg.lock"],
+	"patterns": [
+		{"include": "#comment"},
+		{
+			"match": "^\\s*(\\[\\[?)([^\\]]*)(\\]\\]?)",
+			"captures": {
+				"2": {"name": "entity.name.section.toml"}
+			}
+		},
+		{
+			"match": "([A-Za-z0-9_.-]+|\"(?:[^\"\\\\]|\\\\.)*\"|'[^']*')\\s*(=)",
+			"captures": {
+				"1": {"name": "variable.other.key.toml"},
+				"2": {"name": "keyword.operator.assignment.toml"}
+			}
+		},
+		{"include": "#value"}
+	],
+	"repository": {
+		"comment": {
+			"name": "comment.line.number-sign.toml",
+			"match": "#.*$"
+		},
+		"value": {
+			"patterns": [
+				{
+					"name": "string.quoted.triple.basic.toml",
+					"begin": "\"\"\"",
+					"end": "\"\"\"",
+					"patterns": [{"include": "#escape"}]
+				},
+				{
+					"name": "string.quoted.triple.literal.toml",
+					"begin": "'''",
+					"end": "'''"
+				},
+				{
+					"name": "string.quoted.double.basic.toml",
+					"begin": "\"",
+					"end": "\"|$",
+					"patterns": [{"include": "#escape"}]
+				},
+				{
+					"name": "string.quoted.single.literal.toml",
+					"match": "'[^']*'"
+				},
+				{"name": "constant.other.datetime.toml", "match": "\\b[0-9]{4}-[0-9]{2}-[0-9]{2}(?:[Tt ][0-9]{2}:[0-9]{2}:[0-9]{2}(?:\\.[0-9]+)?(?:[Zz]|[+-][0-9]{2}:[0-9]{2})?)?"},
+				{"name": "constant.language.boolean.toml", "match": "\\b(?:true|false)\\b"},
+				{"name": "constant.numeric.toml", "match": "[+-]?(?:0x[0-9a-fA-F_]+|0o[0-7_]+|0b[01_]+|inf|nan|[0-9][0-9_]*(?:\\.[0-9_]+)?(?:[eE][+-]?[0-9_]+)?)\\b"},
+				{"include": "#comment"}
+			]
+		},
+		"escape": {
+			"name": "constant.character.escape.toml",
+			"match": "\\\\(?:[btnfr\"\\\\]|u[0-9a-fA-F]{4}|U[0-9a-fA-F]{8})"
+		}
+	}
+}`
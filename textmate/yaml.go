@@ -0,0 +1,66 @@
+// This is free and unencumbered software released into the public
+// domain.  For more information, see <http://unlicense.org> or the
+// accompanying UNLICENSE file.
+
+package textmate
+
+const yamlGrammar = `{
+	"name": "YAML",
+	"scopeName": "source.yaml",
+	"fileTypes": ["yaml", "yml"],
+	"patterns": [
+		{"include": "#comment"},
+		{"name": "entity.other.document.begin.yaml", "match": "^(?:---|\\.\\.\\.)(?:\\s|$)"},
+		{"name": "keyword.other.directive.yaml", "match": "^%.*$"},
+		{"name": "keyword.control.flow.sequence.yaml", "match": "-(?:\\s|$)"},
+		{
+			"match": "([^\\s#:'\"\\[\\]{},][^#:]*?|\"(?:[^\"\\\\]|\\\\.)*\"|'(?:[^']|'')*')\\s*(:)(?:\\s|$)",
+			"captures": {
+				"1": {"name": "entity.name.tag.yaml"}
+			}
+		},
+		{"include": "#block-scalar"},
+		{"include": "#value"}
+	],
+	"repository": {
+		"comment": {
+			"name": "comment.line.number-sign.yaml",
+			"match": "(?:^|\\s)#.*$"
+		},
+		"block-scalar": {
+			"begin": "[|>][-+0-9]*\\s*$",
+			"end": "^\\b",
+			"beginCaptures": {
+				"0": {"name": "keyword.control.flow.block-scalar.yaml"}
+			},
+			"contentName": "string.unquoted.block.yaml"
+		},
+		"value": {
+			"patterns": [
+				{"name": "entity.name.type.anchor.yaml", "match": "[&*][^\\s,\\[\\]{}]+"},
+				{"name": "storage.type.tag-handle.yaml", "match": "!!?[^\\s,\\[\\]{}]*"},
+				{
+					"name": "string.quoted.double.yaml",
+					"begin": "\"",
+					"end": "\"",
+					"patterns": [
+						{"name": "constant.character.escape.yaml", "match": "\\\\."}
+					]
+				},
+				{
+					"name": "string.quoted.single.yaml",
+					"begin": "'",
+					"end": "'(?:[^']|$)",
+					"patterns": [
+						{"name": "constant.character.escape.yaml", "match": "''"}
+					]
+				},
+				{"name": "constant.language.null.yaml", "match": "(?:^|\\s)(?:null|Null|NULL|~)\\s*(?:$|#)"},
+				{"name": "constant.language.boolean.yaml", "match": "\\b(?:true|True|TRUE|false|False|FALSE|yes|Yes|YES|no|No|NO|on|On|ON|off|Off|OFF)\\b"},
+				{"name": "constant.numeric.yaml", "match": "[-+]?(?:0x[0-9a-fA-F]+|0o[0-7]+|[0-9][0-9_]*(?:\\.[0-9]*)?(?:[eE][-+]?[0-9]+)?|\\.(?:inf|Inf|INF|nan|NaN|NAN))\\b"},
+				{"name": "punctuation.definition.mapping.yaml", "match": "[\\[\\]{},]"},
+				{"include": "#comment"}
+			]
+		}
+	}
+}`
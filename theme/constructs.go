@@ -7,7 +7,13 @@ package theme
 type LanguageConstruct int
 
 const (
-	Keyword LanguageConstruct = 1 + iota
+	// Covered and Uncovered are used for the background of code
+	// that tests did and did not run.  They come first so that
+	// they are painted beneath every other construct.
+	Covered LanguageConstruct = 1 + iota
+	Uncovered
+
+	Keyword
 	Builtin
 	Func
 	Type
@@ -21,6 +27,18 @@ const (
 
 	Bad
 
+	// MatchedPair is used for the pair of brackets that the
+	// caret is on.
+	MatchedPair
+
+	// DebugLine is used for the line that a debugger is stopped
+	// on.
+	DebugLine
+
+	// Changed is used for lines that differ between two versions
+	// of a file that are being compared.
+	Changed
+
 	// ScopePair is a much higher value to provide extra space
 	// for other language constructs (e.g. for languages that
 	// have constructs that Go doesn't).  Because ScopePairs are
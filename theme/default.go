@@ -21,6 +21,76 @@ var Default = Theme{
 				A: 1,
 			},
 		},
+		Covered: Highlight{
+			Foreground: Color{
+				R: 0.9,
+				G: 0.9,
+				B: 0.9,
+				A: 1,
+			},
+			Background: Color{
+				R: 0.1,
+				G: 0.25,
+				B: 0.1,
+				A: 1,
+			},
+		},
+		Uncovered: Highlight{
+			Foreground: Color{
+				R: 0.9,
+				G: 0.9,
+				B: 0.9,
+				A: 1,
+			},
+			Background: Color{
+				R: 0.3,
+				G: 0.1,
+				B: 0.1,
+				A: 1,
+			},
+		},
+		MatchedPair: Highlight{
+			Foreground: Color{
+				R: 1,
+				G: 1,
+				B: 1,
+				A: 1,
+			},
+			Background: Color{
+				R: 0.3,
+				G: 0.4,
+				B: 0.6,
+				A: 1,
+			},
+		},
+		DebugLine: Highlight{
+			Foreground: Color{
+				R: 1,
+				G: 1,
+				B: 1,
+				A: 1,
+			},
+			Background: Color{
+				R: 0.2,
+				G: 0.4,
+				B: 0.2,
+				A: 1,
+			},
+		},
+		Changed: Highlight{
+			Foreground: Color{
+				R: 1,
+				G: 1,
+				B: 1,
+				A: 1,
+			},
+			Background: Color{
+				R: 0.35,
+				G: 0.3,
+				B: 0.1,
+				A: 1,
+			},
+		},
 		Ident: Highlight{Foreground: Color{
 			R: 0.9,
 			G: 0.9,